@@ -0,0 +1,11 @@
+// Package cfterraforming bundles the API responses recorded for the tests so
+// the mockserver command can serve them without the repository.
+package cfterraforming
+
+import "embed"
+
+// Cassettes are the VCR cassettes in testdata/cloudflare, in a directory for
+// each major version of the provider.
+//
+//go:embed testdata/cloudflare
+var Cassettes embed.FS
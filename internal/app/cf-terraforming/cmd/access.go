@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"net/http"
+)
+
+// accessClientID and accessClientSecret are a Cloudflare Access service token
+// for reaching the API through a gateway protected by Access.
+var accessClientID, accessClientSecret string
+
+// accessTransport adds the Access service token headers to every request.
+type accessTransport struct {
+	rt               http.RoundTripper
+	clientID, secret string
+}
+
+func (t *accessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("CF-Access-Client-Id", t.clientID)
+	req.Header.Set("CF-Access-Client-Secret", t.secret)
+	return t.rt.RoundTrip(req)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const apiSnapshotManifestFilename = "manifest.json"
+
+// snapshotDir is where the API response for every generated resource is kept,
+// so the data behind a configuration can be audited and compared between runs
+// without querying the API.
+var snapshotDir string
+
+// apiSnapshotResource is the API response a resource was generated from.
+type apiSnapshotResource struct {
+	resourceType string
+	name         string
+	id           string
+	data         map[string]interface{}
+}
+
+// apiSnapshotManifest lists the resources in the snapshot.
+type apiSnapshotManifest struct {
+	Time            time.Time                  `json:"time"`
+	ProviderVersion string                     `json:"provider_version"`
+	AccountID       string                     `json:"account_id,omitempty"`
+	ZoneID          string                     `json:"zone_id,omitempty"`
+	Resources       []apiSnapshotManifestEntry `json:"resources"`
+}
+
+type apiSnapshotManifestEntry struct {
+	Address string `json:"address"`
+	ID      string `json:"id,omitempty"`
+	// File is relative to the snapshot directory.
+	File string `json:"file"`
+}
+
+// newAPISnapshotResource copies the resource's API response with its secrets
+// redacted, both those the schema marks sensitive and those known by name.
+func newAPISnapshotResource(resourceType, name string, block *tfjson.SchemaBlock, data map[string]interface{}) apiSnapshotResource {
+	redacted := copyJSONValue(data).(map[string]interface{})
+	if block != nil {
+		for attrName, attr := range block.Attributes {
+			if attr.Sensitive && redacted[attrName] != nil {
+				redacted[attrName] = redactedValue
+			}
+		}
+	}
+	redactSecrets(redacted)
+
+	return apiSnapshotResource{
+		resourceType: resourceType,
+		name:         name,
+		id:           resourceAPIID(resourceType, data),
+		data:         redacted,
+	}
+}
+
+// writeAPISnapshot writes each resource to <type>/<name>.json within the
+// directory along with a manifest. The directory of each resource type that
+// was generated is replaced so resources that no longer exist are removed.
+func writeAPISnapshot(dir string, resources []apiSnapshotResource, providerVersion string, now time.Time) error {
+	manifest := apiSnapshotManifest{
+		Time:            now.UTC(),
+		ProviderVersion: providerVersion,
+		AccountID:       accountID,
+		ZoneID:          zoneID,
+		Resources:       make([]apiSnapshotManifestEntry, 0, len(resources)),
+	}
+
+	replaced := make(map[string]bool)
+	for _, resource := range resources {
+		typeDir := filepath.Join(dir, resource.resourceType)
+		if !replaced[resource.resourceType] {
+			if err := os.RemoveAll(typeDir); err != nil {
+				return fmt.Errorf("failed to replace the snapshot of %s: %w", resource.resourceType, err)
+			}
+			if err := os.MkdirAll(typeDir, 0755); err != nil {
+				return fmt.Errorf("failed to create snapshot directory %s: %w", typeDir, err)
+			}
+			replaced[resource.resourceType] = true
+		}
+
+		file := filepath.Join(resource.resourceType, resource.name+".json")
+		if err := writeAPISnapshotFile(filepath.Join(dir, file), resource.data); err != nil {
+			return err
+		}
+		manifest.Resources = append(manifest.Resources, apiSnapshotManifestEntry{
+			Address: fmt.Sprintf("%s.%s", resource.resourceType, resource.name),
+			ID:      resource.id,
+			File:    filepath.ToSlash(file),
+		})
+	}
+
+	return writeAPISnapshotFile(filepath.Join(dir, apiSnapshotManifestFilename), manifest)
+}
+
+// writeAPISnapshotFile writes the value as indented JSON, with the keys of
+// objects sorted so snapshots can be compared with diff.
+func writeAPISnapshotFile(path string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+	metrics.addBytesWritten(len(content) + 1)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPISnapshotResource(t *testing.T) {
+	block := &tfjson.SchemaBlock{Attributes: map[string]*tfjson.SchemaAttribute{
+		"name":  {},
+		"token": {Sensitive: true},
+	}}
+	data := map[string]interface{}{
+		"id":     "f70ff985a4ef4c71a8b4a8e4e2e4e4e4",
+		"name":   "office",
+		"token":  "eyJhIjoiMSJ9",
+		"config": map[string]interface{}{"tunnel_secret": "c2VjcmV0"},
+	}
+
+	resource := newAPISnapshotResource("cloudflare_zero_trust_tunnel_cloudflared", "office", block, data)
+	assert.Equal(t, "f70ff985a4ef4c71a8b4a8e4e2e4e4e4", resource.id)
+	assert.Equal(t, map[string]interface{}{
+		"id":     "f70ff985a4ef4c71a8b4a8e4e2e4e4e4",
+		"name":   "office",
+		"token":  redactedValue,
+		"config": map[string]interface{}{"tunnel_secret": redactedValue},
+	}, resource.data)
+
+	// The response used for generation is left as it is.
+	assert.Equal(t, "eyJhIjoiMSJ9", data["token"])
+	assert.Equal(t, "c2VjcmV0", data["config"].(map[string]interface{})["tunnel_secret"])
+}
+
+func TestWriteAPISnapshot(t *testing.T) {
+	defer func(account, zone string) { accountID, zoneID = account, zone }(accountID, zoneID)
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+
+	dir := t.TempDir()
+	// A record which no longer exists and a resource type which wasn't
+	// generated this time.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cloudflare_dns_record"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cloudflare_dns_record", "old.json"), []byte("{}"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cloudflare_page_rule"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cloudflare_page_rule", "redirect.json"), []byte("{}"), 0644))
+
+	resources := []apiSnapshotResource{
+		newAPISnapshotResource("cloudflare_dns_record", "www", nil, map[string]interface{}{
+			"id":      "023e105f4ecef8ad9ca31a8372d0c353",
+			"name":    "www.example.com",
+			"content": "192.0.2.1",
+		}),
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, writeAPISnapshot(dir, resources, "5.1.0", now))
+
+	content, err := os.ReadFile(filepath.Join(dir, "cloudflare_dns_record", "www.json"))
+	require.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		{
+		  "content": "192.0.2.1",
+		  "id": "023e105f4ecef8ad9ca31a8372d0c353",
+		  "name": "www.example.com"
+		}
+	`), string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "cloudflare_dns_record", "old.json"))
+	assert.FileExists(t, filepath.Join(dir, "cloudflare_page_rule", "redirect.json"))
+
+	manifest, err := os.ReadFile(filepath.Join(dir, apiSnapshotManifestFilename))
+	require.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		{
+		  "time": "2024-05-01T12:00:00Z",
+		  "provider_version": "5.1.0",
+		  "zone_id": "0da42c8d2132a9ddaf714f9e7c920711",
+		  "resources": [
+		    {
+		      "address": "cloudflare_dns_record.www",
+		      "id": "023e105f4ecef8ad9ca31a8372d0c353",
+		      "file": "cloudflare_dns_record/www.json"
+		    }
+		  ]
+		}
+	`), string(manifest))
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	importsFilename  = "imports.tf"
+	providerFilename = "provider.tf"
+)
+
+// reuseAPIResponses is enabled when the same resources are fetched more than
+// once in a single invocation so that subsequent requests are served from
+// memory instead of the API.
+var reuseAPIResponses bool
+
+func init() {
+	rootCmd.AddCommand(bootstrapCmd)
+}
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Write generated resources, import blocks and provider configuration into --output-dir using a single pass over the API",
+	Run:   runBootstrap(),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		reuseAPIResponses = true
+		sharedPreRun(cmd, args)
+	},
+}
+
+func runBootstrap() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if writesModule() {
+			log.Fatal("--as-module and --terragrunt cannot be used with bootstrap as import blocks are only valid in the root module")
+		}
+
+		if outputFormat != outputFormatHCL {
+			log.Fatalf("unsupported output format %q", outputFormat)
+		}
+
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			log.Fatalf("failed to create output directory %s: %s", outputDir, err)
+		}
+
+		defer func(v bool) { useModernImportBlock = v }(useModernImportBlock)
+
+		var providerVersion string
+		writeCommandOutput(cmd, mainFilename, func() { generateResources(&providerVersion)(cmd, args) })
+
+		// import only outputs a single resource type per run so each is
+		// imported separately.
+		useModernImportBlock = true
+		writeCommandOutput(cmd, importsFilename, func() {
+			defer func(types string) { resourceType = types }(resourceType)
+			for _, t := range strings.Split(resourceType, ",") {
+				resourceType = t
+				runImport()(cmd, args)
+			}
+		})
+
+		if err := writeConfigFile(providerFilename, buildProviderFile(providerVersion)); err != nil {
+			log.Fatal(err)
+		}
+
+		if verify {
+			results, err := verifyConfiguration(context.Background(), outputDir)
+			if err != nil {
+				log.Fatalf("failed to verify configuration: %s", err)
+			}
+			if !writeVerifyReport(cmd.OutOrStdout(), results) {
+				log.Fatal("plan is not empty after importing the generated configuration")
+			}
+		}
+
+		if viper.GetString("tfc-organization") != "" || viper.GetString("tfc-workspace") != "" {
+			if err := publishToTerraformCloud(context.Background()); err != nil {
+				log.Fatalf("failed to publish to Terraform Cloud: %s", err)
+			}
+		}
+	}
+}
+
+// writeCommandOutput runs a command with its output redirected to a file in
+// the output directory.
+func writeCommandOutput(cmd *cobra.Command, filename string, run func()) {
+	path := filepath.Join(outputDir, filename)
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("failed to create %s: %s", path, err)
+	}
+	defer f.Close()
+
+	cmd.SetOut(f)
+	defer cmd.SetOut(nil)
+
+	run()
+	log.Debugf("wrote %s", path)
+}
+
+// appendRequiredProviders appends a terraform block requiring the Cloudflare
+// provider at the same major version as the one used for generation.
+func appendRequiredProviders(body *hclwrite.Body, providerVersion string) {
+	major := strings.SplitN(providerVersion, ".", 2)[0]
+
+	terraform := body.AppendNewBlock("terraform", nil).Body()
+	terraform.AppendNewBlock("required_providers", nil).Body().SetAttributeValue("cloudflare", cty.ObjectVal(map[string]cty.Value{
+		"source":  cty.StringVal("cloudflare/cloudflare"),
+		"version": cty.StringVal("~> " + major),
+	}))
+}
+
+// buildProviderFile generates the provider requirements and an empty provider
+// block. Credentials are expected to be provided through the environment.
+func buildProviderFile(providerVersion string) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	appendRequiredProviders(f.Body(), providerVersion)
+	f.Body().AppendNewline()
+	f.Body().AppendNewBlock("provider", []string{"cloudflare"})
+
+	return f
+}
+
+// responseCacheTransport is an http.RoundTripper that remembers the responses
+// to successful GET requests and replays them for identical requests.
+type responseCacheTransport struct {
+	rt http.RoundTripper
+
+	mu        sync.Mutex
+	responses map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func newResponseCacheTransport(rt http.RoundTripper) *responseCacheTransport {
+	return &responseCacheTransport{rt: rt, responses: make(map[string]*cachedResponse)}
+}
+
+func (t *responseCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.rt.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	t.mu.Lock()
+	cached, ok := t.responses[key]
+	t.mu.Unlock()
+	if ok {
+		log.Debugf("reusing response for %s", key)
+		return cached.response(req), nil
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	cached = &cachedResponse{status: resp.StatusCode, header: resp.Header.Clone(), body: body}
+	t.mu.Lock()
+	t.responses[key] = cached
+	t.mu.Unlock()
+
+	return cached.response(req), nil
+}
+
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(c.status),
+		StatusCode:    c.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildProviderFile(t *testing.T) {
+	expected := heredoc.Doc(`
+		terraform {
+		  required_providers {
+		    cloudflare = {
+		      source  = "cloudflare/cloudflare"
+		      version = "~> 5"
+		    }
+		  }
+		}
+
+		provider "cloudflare" {
+		}
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(buildProviderFile("5.3.0").Bytes())))
+}
+
+func TestResponseCacheTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprintf(w, "response %d", requests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newResponseCacheTransport(http.DefaultTransport)}
+	get := func(path string) string {
+		resp, err := client.Get(server.URL + path)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "response 1", get("/zones"))
+	assert.Equal(t, "response 1", get("/zones"))
+	assert.Equal(t, "response 2", get("/zones?page=2"))
+
+	resp, err := client.Post(server.URL+"/zones", "application/json", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, 3, requests)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpointPath is a file recording the resources fetched for each resource
+// type so an interrupted run can resume without fetching them again.
+var checkpointPath string
+
+// checkpointFile is the checkpoint as written to disk.
+type checkpointFile struct {
+	AccountID string                   `json:"account_id,omitempty"`
+	ZoneID    string                   `json:"zone_id,omitempty"`
+	Resources map[string][]interface{} `json:"resources"`
+}
+
+// checkpoint holds the resources fetched so far. A nil checkpoint records
+// nothing.
+type checkpoint struct {
+	path string
+
+	mu   sync.Mutex
+	file checkpointFile
+}
+
+// loadCheckpoint reads the checkpoint at the path if there is one. A
+// checkpoint for a different account or zone is ignored.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{
+		path: path,
+		file: checkpointFile{AccountID: accountID, ZoneID: zoneID, Resources: make(map[string][]interface{})},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if file.AccountID != accountID || file.ZoneID != zoneID {
+		log.Warnf("ignoring checkpoint %s as it's for a different account or zone", path)
+		return c, nil
+	}
+	if file.Resources != nil {
+		c.file.Resources = file.Resources
+	}
+	log.Infof("resuming from checkpoint %s with %d resource types already fetched", path, len(c.file.Resources))
+
+	return c, nil
+}
+
+// get returns the resources recorded for the resource type.
+func (c *checkpoint) get(resourceType string) ([]interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.file.Resources[resourceType]
+	return data, ok
+}
+
+// record adds the resources fetched for a resource type and writes the
+// checkpoint. It's written to a temporary file first so that being
+// interrupted while writing doesn't lose the existing checkpoint.
+func (c *checkpoint) record(resourceType string, data []interface{}) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if data == nil {
+		data = []interface{}{}
+	}
+	c.file.Resources[resourceType] = data
+
+	content, err := json.Marshal(c.file)
+	if err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if dir := filepath.Dir(c.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, c.path)
+}
+
+// remove deletes the checkpoint once the run has finished.
+func (c *checkpoint) remove() error {
+	if c == nil {
+		return nil
+	}
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
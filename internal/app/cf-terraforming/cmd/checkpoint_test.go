@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	defer func(account, zone string) { accountID, zoneID = account, zone }(accountID, zoneID)
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	progress, err := loadCheckpoint(path)
+	require.NoError(t, err)
+	_, ok := progress.get("cloudflare_dns_record")
+	assert.False(t, ok)
+
+	require.NoError(t, progress.record("cloudflare_dns_record", []interface{}{map[string]interface{}{"id": "1"}}))
+	require.NoError(t, progress.record("cloudflare_page_rule", nil))
+
+	resumed, err := loadCheckpoint(path)
+	require.NoError(t, err)
+	data, ok := resumed.get("cloudflare_dns_record")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "1"}}, data)
+	data, ok = resumed.get("cloudflare_page_rule")
+	assert.True(t, ok)
+	assert.Empty(t, data)
+
+	// A checkpoint for another zone isn't resumed.
+	zoneID = "9d5b9a2fbc38a2d8e0a6bb6ee1f9e1d6"
+	other, err := loadCheckpoint(path)
+	require.NoError(t, err)
+	_, ok = other.get("cloudflare_dns_record")
+	assert.False(t, ok)
+
+	require.NoError(t, resumed.remove())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, resumed.remove())
+}
+
+func TestCheckpointNil(t *testing.T) {
+	var progress *checkpoint
+
+	_, ok := progress.get("cloudflare_dns_record")
+	assert.False(t, ok)
+	assert.NoError(t, progress.record("cloudflare_dns_record", nil))
+	assert.NoError(t, progress.remove())
+}
+
+func TestFetchAPIResponsesCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	progress, err := loadCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, progress.record("cloudflare_dns_record", []interface{}{"recorded"}))
+
+	// The endpoint is never requested as the resources are in the checkpoint.
+	responses := fetchAPIResponses([]apiRequest{
+		{resourceType: "cloudflare_dns_record", endpoints: []string{"/unreachable"}},
+	}, progress)
+
+	assert.Equal(t, map[string]apiResponse{"cloudflare_dns_record": {data: []interface{}{"recorded"}}}, responses)
+}
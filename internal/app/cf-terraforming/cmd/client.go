@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	cfv0 "github.com/cloudflare/cloudflare-go"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+)
+
+// apiURL is the base URL of the API, for reaching it through a gateway, a mock
+// server or the China network at https://api.cloudflare.cn/client/v4.
+var apiURL string
+
+// maxIdleConnsPerHost allows connections to the API to be reused by requests
+// made concurrently with --parallelism.
+const maxIdleConnsPerHost = 32
+
+// sharedTransport is the connection pool used by every HTTP client so that
+// connections are reused across both versions of the SDK.
+var sharedTransport = newSharedTransport()
+
+// apiTransport, if set, sends API requests in place of sharedTransport, for
+// tools embedding cf-terraforming with their own HTTP client.
+var apiTransport http.RoundTripper
+
+func newSharedTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	return transport
+}
+
+// newAPIHTTPClient builds the HTTP client shared by both versions of the SDK.
+// Every API request passes through the same middleware, from the outermost:
+// recording the transcript, caching, rate limiting, adding the User-Agent and
+// Access service token headers and counting requests.
+func newAPIHTTPClient() *http.Client {
+	var transport http.RoundTripper = sharedTransport
+	if apiTransport != nil {
+		transport = apiTransport
+	}
+	transport = &metricsTransport{rt: transport}
+	if accessClientID != "" {
+		transport = &accessTransport{rt: transport, clientID: accessClientID, secret: accessClientSecret}
+	}
+	transport = &userAgentTransport{rt: transport, suffix: userAgentSuffix, headers: apiRequestHeaders}
+	transport = newRateLimitTransport(transport, maxRequestsPerSecond, maxRetries, retryMaxBackoff, httpTimeout)
+	if cacheDir != "" {
+		transport = newDiskCacheTransport(transport, cacheDir, cacheTTL)
+	}
+	if reuseAPIResponses {
+		transport = newResponseCacheTransport(transport)
+	}
+	if debugHTTPOut != "" {
+		transport = newHTTPTranscriptTransport(transport, debugHTTPOut)
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// apiBaseURL returns the base URL set with --api-url or --hostname, or an
+// empty string to use the default.
+func apiBaseURL(rawURL, hostname string) (string, error) {
+	if rawURL == "" {
+		if hostname == "" {
+			return "", nil
+		}
+		return "https://" + hostname + "/client/v4", nil
+	}
+	if hostname != "" {
+		return "", errors.New("--api-url and --hostname are mutually exclusive")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid --api-url %q: %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid --api-url %q, expected an http or https URL", rawURL)
+	}
+
+	return strings.TrimSuffix(rawURL, "/"), nil
+}
+
+// configureAPIClients creates the clients for both versions of the SDK from
+// the credentials and flags, sharing a single HTTP client.
+func configureAPIClients() error {
+	// Don't initialise a client in CI as this messes with VCR and the ability to
+	// mock out the HTTP interactions.
+	if os.Getenv("CI") == "true" {
+		return nil
+	}
+
+	if (accessClientID == "") != (accessClientSecret == "") {
+		return errors.New("--access-client-id and --access-client-secret must be set together")
+	}
+	if err := configureTLS(sharedTransport, clientCertFile, clientKeyFile, caCertFile); err != nil {
+		return err
+	}
+	if err := configureProxy(sharedTransport, proxyURL); err != nil {
+		return err
+	}
+
+	var err error
+	if apiRequestHeaders, err = parseRequestHeaders(requestHeaders); err != nil {
+		return err
+	}
+
+	// Requests are only retried by the HTTP client's rate limiting, as the
+	// retries of each layer would otherwise multiply.
+	httpClient := newAPIHTTPClient()
+	options := []cfv0.Option{
+		cfv0.HTTPClient(httpClient),
+		cfv0.UsingRetryPolicy(0, 1, 1),
+	}
+	clientOptions := []option.RequestOption{option.WithHTTPClient(httpClient), option.WithMaxRetries(0)}
+
+	baseURL, err := apiBaseURL(apiURL, hostname)
+	if err != nil {
+		return err
+	}
+	if baseURL != "" {
+		options = append(options, cfv0.BaseURL(baseURL))
+		clientOptions = append(clientOptions, option.WithBaseURL(baseURL+"/"))
+	}
+
+	if verbose {
+		options = append(options, cfv0.Debug(true))
+	}
+
+	if apiToken != "" {
+		apiV0, err = cfv0.NewWithAPIToken(apiToken, options...)
+		clientOptions = append(clientOptions, option.WithAPIToken(apiToken))
+	} else {
+		apiV0, err = cfv0.New(apiKey, apiEmail, options...)
+		clientOptions = append(clientOptions, option.WithAPIKey(apiKey), option.WithAPIEmail(apiEmail))
+	}
+	if err != nil {
+		return err
+	}
+	api = cloudflare.NewClient(clientOptions...)
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIHTTPClient(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	defer func(reuse bool, rps float64) { reuseAPIResponses, maxRequestsPerSecond = reuse, rps }(reuseAPIResponses, maxRequestsPerSecond)
+	reuseAPIResponses, maxRequestsPerSecond = true, 0
+
+	client := newAPIHTTPClient()
+	for range 2 {
+		resp, err := client.Get(server.URL + "/zones")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// The second response is reused so only one request reaches the API.
+	require.Len(t, userAgents, 1)
+	assert.Contains(t, userAgents[0], "cf-terraforming/")
+}
+
+func TestAPIBaseURL(t *testing.T) {
+	for _, tc := range []struct {
+		url, hostname, want, err string
+	}{
+		{want: ""},
+		{hostname: "api.example.com", want: "https://api.example.com/client/v4"},
+		{url: "https://api.cloudflare.cn/client/v4/", want: "https://api.cloudflare.cn/client/v4"},
+		{url: "http://localhost:8080", want: "http://localhost:8080"},
+		{url: "api.cloudflare.cn", err: `invalid --api-url "api.cloudflare.cn", expected an http or https URL`},
+		{url: "https://api.cloudflare.cn", hostname: "api.example.com", err: "--api-url and --hostname are mutually exclusive"},
+	} {
+		got, err := apiBaseURL(tc.url, tc.hostname)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestNewAPIHTTPClientAccessHeaders(t *testing.T) {
+	var clientID, secret string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret = r.Header.Get("CF-Access-Client-Id"), r.Header.Get("CF-Access-Client-Secret")
+	}))
+	defer server.Close()
+
+	defer func(id, s string) { accessClientID, accessClientSecret = id, s }(accessClientID, accessClientSecret)
+	accessClientID, accessClientSecret = "client-id.access", "client-secret"
+
+	resp, err := newAPIHTTPClient().Get(server.URL + "/zones")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "client-id.access", clientID)
+	assert.Equal(t, "client-secret", secret)
+}
+
+// rateLimitedTransport responds to every request as rate limited.
+type rateLimitedTransport struct {
+	requests int
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Status:     "429 Too Many Requests",
+		Header:     http.Header{"Retry-After": []string{"0"}, "Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"success":false,"errors":[{"code":10000,"message":"rate limited"}],"messages":[],"result":null}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestAPIClientsRetryInOneLayer(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_dns_record", nil)
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"}, args...)
+
+	// The SDKs don't retry on top of the HTTP client's retries.
+	transport := &rateLimitedTransport{}
+	var stdout, stderr bytes.Buffer
+	_ = Run(context.Background(), RunConfig{Args: append(args, "--max-retries", "2"), Stdout: &stdout, Stderr: &stderr, Transport: transport})
+	assert.Equal(t, 3, transport.requests)
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// collapseMinimumResources is the smallest group of similar resources
+	// that is collapsed by --collapse-similar.
+	collapseMinimumResources = 3
+
+	// collapseMaximumDifferences is the most attributes that can differ
+	// between resources for them to be considered similar.
+	collapseMaximumDifferences = 3
+
+	collapsedResourceName = "collapsed"
+)
+
+// collapseSimilar collapses groups of similar resources into a single
+// resource using `for_each`.
+var collapseSimilar bool
+
+// forEachResource is a resource iterating over a local map of instances.
+type forEachResource struct {
+	resourceType string
+	name         string
+	local        string
+
+	// common are the attributes shared by every instance.
+	common map[string]hclwrite.Tokens
+
+	// instances are the attributes of each instance that are read from the
+	// map, keyed by the instance key.
+	instances map[string]map[string]hclwrite.Tokens
+	keys      []string
+
+	// blocks are the nested blocks shared by every instance.
+	blocks []*hclwrite.Block
+}
+
+// appendLocal appends the local map of instances to the body.
+func (r forEachResource) appendLocal(body *hclwrite.Body) {
+	entries := make([]hclwrite.ObjectAttrTokens, 0, len(r.keys))
+	for _, key := range r.keys {
+		attributes := r.instances[key]
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, len(attributes))
+		for _, name := range sortedKeys(attributes) {
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{
+				Name:  hclwrite.TokensForIdentifier(name),
+				Value: attributes[name],
+			})
+		}
+
+		entries = append(entries, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForValue(cty.StringVal(key)),
+			Value: hclwrite.TokensForObject(attrs),
+		})
+	}
+
+	body.AppendNewBlock("locals", nil).Body().SetAttributeRaw(r.local, hclwrite.TokensForObject(entries))
+}
+
+// appendResource appends the resource to the body. Attributes missing from
+// some instances are read with `try` so they're optional.
+func (r forEachResource) appendResource(body *hclwrite.Body) {
+	resource := body.AppendNewBlock("resource", []string{r.resourceType, r.name}).Body()
+	resource.SetAttributeTraversal("for_each", hcl.Traversal{
+		hcl.TraverseRoot{Name: "local"},
+		hcl.TraverseAttr{Name: r.local},
+	})
+	for _, name := range sortedKeys(r.common) {
+		resource.SetAttributeRaw(name, r.common[name])
+	}
+
+	counts := make(map[string]int)
+	for _, attributes := range r.instances {
+		for name := range attributes {
+			counts[name]++
+		}
+	}
+	for _, name := range sortedKeys(counts) {
+		value := hclwrite.TokensForTraversal(hcl.Traversal{
+			hcl.TraverseRoot{Name: "each"},
+			hcl.TraverseAttr{Name: "value"},
+			hcl.TraverseAttr{Name: name},
+		})
+		if counts[name] < len(r.instances) {
+			value = hclwrite.TokensForFunctionCall("try", value, hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType)))
+		}
+		resource.SetAttributeRaw(name, value)
+	}
+
+	for _, block := range r.blocks {
+		resource.AppendNewline()
+		resource.AppendBlock(block)
+	}
+}
+
+// address returns the address of an instance of the resource.
+func (r forEachResource) address(key string) string {
+	return fmt.Sprintf("%s.%s[%q]", r.resourceType, r.name, key)
+}
+
+// collapseSimilarResources finds groups of resources of the same type which
+// only differ in a few attributes and replaces each with a single resource
+// iterating over a local map of the differences. The new address of each
+// collapsed resource is returned by its previous address.
+func collapseSimilarResources(f *hclwrite.File) (*hclwrite.File, map[string]string) {
+	var (
+		order  []string
+		groups = make(map[string][]*hclwrite.Block)
+		names  = make(map[string]bool)
+	)
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+		names[strings.Join(block.Labels(), ".")] = true
+
+		key := resourceShape(block)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], block)
+	}
+
+	collapsed := make(map[*hclwrite.Block]*forEachResource)
+	renames := make(map[string]string)
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < collapseMinimumResources {
+			continue
+		}
+
+		differing := differingAttributes(group)
+		if len(differing) == 0 || len(differing) > collapseMaximumDifferences {
+			continue
+		}
+
+		resourceType := group[0].Labels()[0]
+		name := collapsedResourceName
+		for i := 2; names[resourceType+"."+name]; i++ {
+			name = fmt.Sprintf("%s_%d", collapsedResourceName, i)
+		}
+		names[resourceType+"."+name] = true
+
+		r := &forEachResource{
+			resourceType: resourceType,
+			name:         name,
+			local:        fmt.Sprintf("%s_%s", strings.TrimPrefix(resourceType, "cloudflare_"), name),
+			common:       make(map[string]hclwrite.Tokens),
+			instances:    make(map[string]map[string]hclwrite.Tokens, len(group)),
+			blocks:       group[0].Body().Blocks(),
+		}
+		for attrName, attr := range group[0].Body().Attributes() {
+			if !differing[attrName] {
+				r.common[attrName] = attr.Expr().BuildTokens(nil)
+			}
+		}
+		for _, block := range group {
+			instance := block.Labels()[1]
+			r.keys = append(r.keys, instance)
+			r.instances[instance] = make(map[string]hclwrite.Tokens, len(differing))
+			for attrName := range differing {
+				r.instances[instance][attrName] = block.Body().GetAttribute(attrName).Expr().BuildTokens(nil)
+			}
+
+			collapsed[block] = r
+			renames[strings.Join(block.Labels(), ".")] = r.address(instance)
+		}
+		log.Debugf("collapsed %d %s resources into %s.%s", len(group), resourceType, resourceType, name)
+	}
+
+	if len(renames) == 0 {
+		return f, nil
+	}
+
+	// The file is rebuilt so each collapsed resource takes the place of the
+	// first resource in its group.
+	output := hclwrite.NewEmptyFile()
+	body := output.Body()
+	written := make(map[*forEachResource]bool)
+	for _, block := range f.Body().Blocks() {
+		f.Body().RemoveBlock(block)
+
+		r, ok := collapsed[block]
+		if !ok {
+			body.AppendBlock(block)
+			body.AppendNewline()
+			continue
+		}
+		if written[r] {
+			continue
+		}
+		written[r] = true
+
+		r.appendLocal(body)
+		body.AppendNewline()
+		r.appendResource(body)
+		body.AppendNewline()
+	}
+
+	return output, renames
+}
+
+// resourceShape identifies resources that could be collapsed together as they
+// share a type, attribute names and nested blocks.
+func resourceShape(block *hclwrite.Block) string {
+	parts := []string{block.Labels()[0]}
+	parts = append(parts, sortedKeys(block.Body().Attributes())...)
+	for _, nested := range block.Body().Blocks() {
+		parts = append(parts, string(hclwrite.Format(nested.BuildTokens(nil).Bytes())))
+	}
+
+	return strings.Join(parts, "\x00")
+}
+
+// differingAttributes returns the attributes whose value isn't the same in
+// every resource.
+func differingAttributes(blocks []*hclwrite.Block) map[string]bool {
+	differing := make(map[string]bool)
+	for name, attr := range blocks[0].Body().Attributes() {
+		first := expressionSource(attr.Expr().BuildTokens(nil))
+		for _, block := range blocks[1:] {
+			if expressionSource(block.Body().GetAttribute(name).Expr().BuildTokens(nil)) != first {
+				differing[name] = true
+				break
+			}
+		}
+	}
+
+	return differing
+}
+
+func expressionSource(tokens hclwrite.Tokens) string {
+	return strings.TrimSpace(string(hclwrite.Format(tokens.Bytes())))
+}
+
+// renameResourceReferences updates references to resources that have been
+// renamed, such as `cloudflare_list.example.id`, everywhere in the body. The
+// previous address in moved blocks is left as it is.
+func renameResourceReferences(body *hclwrite.Body, renames map[string]string, skip ...string) {
+	for name, attr := range body.Attributes() {
+		if slices.Contains(skip, name) {
+			continue
+		}
+
+		tokens := attr.Expr().BuildTokens(nil)
+		var (
+			output  hclwrite.Tokens
+			changed bool
+		)
+		for i := 0; i < len(tokens); i++ {
+			if i+2 < len(tokens) &&
+				tokens[i].Type == hclsyntax.TokenIdent &&
+				tokens[i+1].Type == hclsyntax.TokenDot &&
+				tokens[i+2].Type == hclsyntax.TokenIdent &&
+				(i == 0 || tokens[i-1].Type != hclsyntax.TokenDot) {
+				if address, ok := renames[string(tokens[i].Bytes)+"."+string(tokens[i+2].Bytes)]; ok {
+					renamed := hclwrite.TokensForTraversal(referenceTraversal(address))
+					renamed[0].SpacesBefore = tokens[i].SpacesBefore
+					output = append(output, renamed...)
+					changed = true
+					i += 2
+					continue
+				}
+			}
+			output = append(output, tokens[i])
+		}
+
+		if changed {
+			body.SetAttributeRaw(name, output)
+		}
+	}
+
+	for _, block := range body.Blocks() {
+		if block.Type() == "moved" {
+			renameResourceReferences(block.Body(), renames, "from")
+			continue
+		}
+		renameResourceReferences(block.Body(), renames)
+	}
+}
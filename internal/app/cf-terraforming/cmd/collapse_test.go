@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollapseSimilarResources(t *testing.T) {
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_zero_trust_access_policy" "engineering" {
+		  account_id = "f037e56e89293a057740de681ac9abbe"
+		  decision   = "allow"
+		  include    = [{ email_domain = { domain = "example.com" } }]
+		  name       = "Engineering"
+		}
+
+		resource "cloudflare_zero_trust_access_policy" "support" {
+		  account_id = "f037e56e89293a057740de681ac9abbe"
+		  decision   = "allow"
+		  include    = [{ email_domain = { domain = "support.example.com" } }]
+		  name       = "Support"
+		}
+
+		resource "cloudflare_zero_trust_access_policy" "sales" {
+		  account_id = "f037e56e89293a057740de681ac9abbe"
+		  decision   = "allow"
+		  include    = [{ email_domain = { domain = "sales.example.com" } }]
+		  name       = "Sales"
+		}
+
+		resource "cloudflare_zero_trust_access_policy" "block" {
+		  account_id = "f037e56e89293a057740de681ac9abbe"
+		  decision   = "deny"
+		  name       = "Block"
+		}
+	`)), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	collapsed, renames := collapseSimilarResources(f)
+	assert.Equal(t, map[string]string{
+		"cloudflare_zero_trust_access_policy.engineering": `cloudflare_zero_trust_access_policy.collapsed["engineering"]`,
+		"cloudflare_zero_trust_access_policy.support":     `cloudflare_zero_trust_access_policy.collapsed["support"]`,
+		"cloudflare_zero_trust_access_policy.sales":       `cloudflare_zero_trust_access_policy.collapsed["sales"]`,
+	}, renames)
+
+	expected := heredoc.Doc(`
+		locals {
+		  zero_trust_access_policy_collapsed = {
+		    "engineering" = {
+		      include = [{ email_domain = { domain = "example.com" } }]
+		      name    = "Engineering"
+		    }
+		    "support" = {
+		      include = [{ email_domain = { domain = "support.example.com" } }]
+		      name    = "Support"
+		    }
+		    "sales" = {
+		      include = [{ email_domain = { domain = "sales.example.com" } }]
+		      name    = "Sales"
+		    }
+		  }
+		}
+
+		resource "cloudflare_zero_trust_access_policy" "collapsed" {
+		  for_each   = local.zero_trust_access_policy_collapsed
+		  account_id = "f037e56e89293a057740de681ac9abbe"
+		  decision   = "allow"
+		  include    = each.value.include
+		  name       = each.value.name
+		}
+
+		resource "cloudflare_zero_trust_access_policy" "block" {
+		  account_id = "f037e56e89293a057740de681ac9abbe"
+		  decision   = "deny"
+		  name       = "Block"
+		}
+
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(collapsed.Bytes())))
+}
+
+func TestCollapseSimilarResourcesTooDifferent(t *testing.T) {
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_list" "a" {
+		  description = "A"
+		  kind        = "ip"
+		  name        = "a"
+		}
+
+		resource "cloudflare_list" "b" {
+		  description = "B"
+		  kind        = "ip"
+		  name        = "b"
+		}
+	`)), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	collapsed, renames := collapseSimilarResources(f)
+	assert.Nil(t, renames)
+	assert.Same(t, f, collapsed)
+}
+
+func TestRenameResourceReferences(t *testing.T) {
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_zero_trust_access_application" "app" {
+		  policies   = [{ id = cloudflare_zero_trust_access_policy.support.id }]
+		  depends_on = [cloudflare_zero_trust_access_policy.sales]
+		}
+
+		moved {
+		  from = cloudflare_zero_trust_access_policy.support
+		  to   = cloudflare_zero_trust_access_policy.sales
+		}
+	`)), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	renameResourceReferences(f.Body(), map[string]string{
+		"cloudflare_zero_trust_access_policy.support": `cloudflare_zero_trust_access_policy.collapsed["support"]`,
+		"cloudflare_zero_trust_access_policy.sales":   `cloudflare_zero_trust_access_policy.collapsed["sales"]`,
+	})
+
+	expected := heredoc.Doc(`
+		resource "cloudflare_zero_trust_access_application" "app" {
+		  policies   = [{ id = cloudflare_zero_trust_access_policy.collapsed["support"].id }]
+		  depends_on = [cloudflare_zero_trust_access_policy.collapsed["sales"]]
+		}
+
+		moved {
+		  from = cloudflare_zero_trust_access_policy.support
+		  to   = cloudflare_zero_trust_access_policy.collapsed["sales"]
+		}
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(f.Bytes())))
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"plugin"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+)
+
+// Converter fetches the resources of a type which isn't supported yet, or
+// replaces how a supported type is fetched.
+type Converter = terraforming.Converter
+
+// converterPluginSymbol is the variable a plugin exports its converters in,
+// of type map[string]terraforming.Converter.
+const converterPluginSymbol = "Converters"
+
+// converterPlugins are the paths of Go plugins to load converters from.
+var converterPlugins []string
+
+// RegisterConverter registers the converter for the resource type, replacing
+// any registered before.
+func RegisterConverter(resourceType string, converter Converter) {
+	terraforming.RegisterConverter(resourceType, converter)
+}
+
+// converterFor returns the converter registered for the resource type.
+func converterFor(resourceType string) (Converter, bool) {
+	return terraforming.LookupConverter(resourceType)
+}
+
+// fetchConverted returns the resources from the converter in the form the API
+// responses are generated from.
+func fetchConverted(ctx context.Context, converter Converter) ([]interface{}, error) {
+	return converter.Resources(ctx, api, runScope())
+}
+
+// loadConverterPlugins opens the Go plugins and registers the converters they
+// export.
+func loadConverterPlugins(paths []string) error {
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to load converter plugin %s: %w", path, err)
+		}
+		symbol, err := p.Lookup(converterPluginSymbol)
+		if err != nil {
+			return fmt.Errorf("failed to load converter plugin %s: %w", path, err)
+		}
+		exported, ok := symbol.(*map[string]Converter)
+		if !ok {
+			return fmt.Errorf("failed to load converter plugin %s: %s is a %T rather than a map[string]terraforming.Converter", path, converterPluginSymbol, symbol)
+		}
+		for resourceType, converter := range *exported {
+			RegisterConverter(resourceType, converter)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerTestConverter registers the converter until the test finishes.
+func registerTestConverter(t *testing.T, resourceType string, converter Converter) {
+	previous, registered := converterFor(resourceType)
+	RegisterConverter(resourceType, converter)
+	t.Cleanup(func() {
+		if registered {
+			RegisterConverter(resourceType, previous)
+		} else {
+			terraforming.UnregisterConverter(resourceType)
+		}
+	})
+}
+
+func TestRegisterConverter(t *testing.T) {
+	defer func(account, zone string) { accountID, zoneID = account, zone }(accountID, zoneID)
+	accountID, zoneID = "f037e56e89293a057740de681ac9abbe", ""
+
+	registerTestConverter(t, "cloudflare_example", Converter{
+		Fetch: func(ctx context.Context, client *cloudflare.Client, account, zone string) ([]map[string]interface{}, error) {
+			return []map[string]interface{}{{"id": "1", "account_id": account}}, nil
+		},
+		ImportID: ":account_id/:id",
+	})
+
+	converter, ok := converterFor("cloudflare_example")
+	require.True(t, ok)
+	data, err := fetchConverted(context.Background(), converter)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": "1", "account_id": "f037e56e89293a057740de681ac9abbe"}}, data)
+
+	importID, err := buildImportID("cloudflare_example", "1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "f037e56e89293a057740de681ac9abbe/1", importID)
+	assert.Empty(t, resolveAPIRequests([]string{"cloudflare_example"}))
+
+	assert.Panics(t, func() { RegisterConverter("cloudflare_example", Converter{}) })
+}
+
+func TestLoadConverterPlugins(t *testing.T) {
+	assert.NoError(t, loadConverterPlugins(nil))
+
+	path := filepath.Join(t.TempDir(), "missing.so")
+	assert.ErrorContains(t, loadConverterPlugins([]string{path}), "failed to load converter plugin "+path)
+}
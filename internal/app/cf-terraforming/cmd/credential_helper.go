@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// credentialHelper is a command printing the API token, such as the 1Password
+// or Vault CLI, so the token never needs to be kept in the environment.
+var credentialHelper string
+
+// credentialHelperTimeout limits how long the helper can take, allowing for
+// it to prompt to unlock a vault.
+const credentialHelperTimeout = 2 * time.Minute
+
+// runCredentialHelper runs the command through the shell and returns the
+// API token it prints.
+func runCredentialHelper(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+
+	// The helper can prompt on the terminal, but its output is the token.
+	var stdout bytes.Buffer
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, &stdout, os.Stderr
+	if profile := viper.GetString("profile"); profile != "" {
+		cmd.Env = append(os.Environ(), "CF_TERRAFORMING_PROFILE="+profile)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("credential helper %q failed: %w", command, err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", errors.New("credential helper didn't print an API token")
+	}
+	if strings.ContainsAny(token, "\r\n") {
+		return "", errors.New("credential helper printed more than the API token")
+	}
+
+	return token, nil
+}
+
+// shellCommand runs the command through the shell, allowing for pipes and
+// quoting as it would be typed.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helpers are run through sh")
+	}
+
+	token, err := runCredentialHelper(context.Background(), "echo '  abc123  '")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", token)
+
+	_, err = runCredentialHelper(context.Background(), "true")
+	assert.EqualError(t, err, "credential helper didn't print an API token")
+
+	_, err = runCredentialHelper(context.Background(), "printf 'abc\\n123'")
+	assert.EqualError(t, err, "credential helper printed more than the API token")
+
+	_, err = runCredentialHelper(context.Background(), "exit 3")
+	assert.EqualError(t, err, `credential helper "exit 3" failed: exit status 3`)
+}
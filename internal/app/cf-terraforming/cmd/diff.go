@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/cobra"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&configDir, "config-dir", ".", "Directory containing the Terraform configuration to compare against")
+	diffCmd.Flags().StringVar(&statePath, "state", "", "Terraform state file to compare against instead of --config-dir")
+	diffCmd.Flags().BoolVar(&unmanagedOnly, "unmanaged", false, "Only report resources in Cloudflare that are missing from the --state file")
+}
+
+var diffCmd = &cobra.Command{
+	Use:    "diff",
+	Short:  "Compare the resources in Cloudflare against the Terraform configuration in --config-dir or the --state file",
+	Run:    runDiff(),
+	PreRun: sharedPreRun,
+}
+
+// diffResource is a resource along with the attributes that can be compared
+// without evaluating the configuration.
+type diffResource struct {
+	address    string
+	id         string
+	attributes map[string]interface{}
+}
+
+// diffReport lists the resources that are only in Cloudflare, only in
+// Terraform or differ between the two.
+type diffReport struct {
+	onlyCloudflare []diffResource
+	onlyTerraform  []diffResource
+	differs        map[string][]string
+}
+
+// diffEntry is a single resource within the JSON diff report.
+type diffEntry struct {
+	Address      string   `json:"address"`
+	ResourceType string   `json:"resource_type"`
+	ID           string   `json:"id,omitempty"`
+	ZoneID       string   `json:"zone_id,omitempty"`
+	AccountID    string   `json:"account_id,omitempty"`
+	Attributes   []string `json:"attributes,omitempty"`
+}
+
+type diffReportJSON struct {
+	OnlyInCloudflare []diffEntry `json:"only_in_cloudflare"`
+	OnlyInTerraform  []diffEntry `json:"only_in_terraform"`
+	Differs          []diffEntry `json:"differs"`
+}
+
+func runDiff() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if outputFormat != outputFormatHCL && outputFormat != outputFormatJSON {
+			log.Fatalf("unsupported output format %q", outputFormat)
+		}
+		if unmanagedOnly && statePath == "" {
+			log.Fatal("--unmanaged requires --state")
+		}
+
+		live, err := fetchLiveResources(cmd, args)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		resourceTypes := strings.Split(resourceType, ",")
+
+		var (
+			existing []diffResource
+			byID     bool
+		)
+		if statePath != "" {
+			content, err := os.ReadFile(statePath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if existing, err = stateDiffResources(content, resourceTypes); err != nil {
+				log.Fatalf("failed to parse %s: %s", statePath, err)
+			}
+			byID = true
+		} else {
+			if existing, err = configDiffResources(configDir, resourceTypes); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		report := compareResources(live, existing, byID)
+		if unmanagedOnly {
+			report.onlyTerraform, report.differs = nil, nil
+		}
+
+		if outputFormat == outputFormatJSON {
+			if err := writeDiffReportJSON(cmd.OutOrStdout(), report); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+		writeDiffReport(cmd.OutOrStdout(), report)
+	}
+}
+
+// fetchLiveResources generates the configuration for the requested resources
+// and reads it back along with the ID of each resource.
+func fetchLiveResources(cmd *cobra.Command, args []string) ([]diffResource, error) {
+	output, idx, err := generateLiveConfiguration(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	ids := idx.ids()
+
+	resources, err := parseDiffResources(output, "generated.tf", nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resources {
+		resources[i].id = ids[resources[i].address]
+	}
+
+	return resources, nil
+}
+
+// generateLiveConfiguration runs generate for the requested resources and
+// returns the configuration along with the address of each resource by ID.
+func generateLiveConfiguration(cmd *cobra.Command, args []string) ([]byte, referenceIndex, error) {
+	dir, err := os.MkdirTemp("", "cf-terraforming-live")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// The existing mapping is copied so that names match earlier runs without
+	// modifying it.
+	path := filepath.Join(dir, "mapping.json")
+	if mappingFilePath != "" {
+		idx, err := loadMapping(mappingFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeMapping(path, idx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	defer func(path, format string) { mappingFilePath, outputFormat = path, format }(mappingFilePath, outputFormat)
+	mappingFilePath, outputFormat = path, outputFormatHCL
+
+	// The output is restored even if generate exits, as watch carries on
+	// with the next run.
+	var output bytes.Buffer
+	defer cmd.SetOut(nil)
+	cmd.SetOut(&output)
+	generateResources(nil)(cmd, args)
+
+	idx, err := loadMapping(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output.Bytes(), idx, nil
+}
+
+// configDiffResources reads the resources of the requested types from the
+// Terraform configuration in the directory.
+func configDiffResources(dir string, resourceTypes []string) ([]diffResource, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []diffResource
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseDiffResources(content, path, resourceTypes)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, parsed...)
+	}
+
+	return resources, nil
+}
+
+// parseDiffResources reads the resources from a configuration file. Only
+// attributes with a literal value are kept as any others can't be compared
+// without Terraform.
+func parseDiffResources(content []byte, filename string, resourceTypes []string) ([]diffResource, error) {
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, diags.Error())
+	}
+
+	var resources []diffResource
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			continue
+		}
+		if resourceTypes != nil && !slices.Contains(resourceTypes, block.Labels[0]) {
+			continue
+		}
+
+		attributes := make(map[string]interface{})
+		for name, attr := range block.Body.Attributes {
+			value, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !value.IsWhollyKnown() {
+				continue
+			}
+
+			encoded, err := ctyjson.Marshal(value, value.Type())
+			if err != nil {
+				return nil, err
+			}
+			if attributes[name], err = terraforming.NormaliseJSON(encoded); err != nil {
+				return nil, err
+			}
+		}
+
+		resources = append(resources, diffResource{
+			address:    strings.Join(block.Labels, "."),
+			attributes: attributes,
+		})
+	}
+
+	return resources, nil
+}
+
+// stateDiffResources reads the resources of the requested types from a
+// Terraform state file.
+func stateDiffResources(content []byte, resourceTypes []string) ([]diffResource, error) {
+	var state terraformState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	var resources []diffResource
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" || resource.Module != "" || !slices.Contains(resourceTypes, resource.Type) {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			name := resource.Name
+			switch key := instance.IndexKey.(type) {
+			case float64:
+				name = fmt.Sprintf("%s[%d]", name, int(key))
+			case string:
+				name = fmt.Sprintf("%s[%q]", name, key)
+			}
+
+			resources = append(resources, diffResource{
+				address:    fmt.Sprintf("%s.%s", resource.Type, name),
+				id:         resourceAPIID(resource.Type, instance.Attributes),
+				attributes: instance.Attributes,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// compareResources matches the live resources against the existing ones by
+// either their ID or address. Only the attributes present in both are
+// compared.
+func compareResources(live, existing []diffResource, byID bool) diffReport {
+	key := func(r diffResource) string {
+		if byID {
+			return r.id
+		}
+		return r.address
+	}
+
+	existingByKey := make(map[string]diffResource, len(existing))
+	for _, r := range existing {
+		existingByKey[key(r)] = r
+	}
+
+	report := diffReport{differs: make(map[string][]string)}
+	matched := make(map[string]bool)
+	for _, r := range live {
+		other, ok := existingByKey[key(r)]
+		if !ok || key(r) == "" {
+			report.onlyCloudflare = append(report.onlyCloudflare, r)
+			continue
+		}
+		matched[key(r)] = true
+
+		var changed []string
+		for _, name := range sortedKeys(r.attributes) {
+			value, ok := other.attributes[name]
+			if !ok {
+				continue
+			}
+			normalised, err := terraforming.NormaliseValue(value)
+			if err != nil || !reflect.DeepEqual(r.attributes[name], normalised) {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) > 0 {
+			report.differs[other.address] = changed
+		}
+	}
+
+	for _, r := range existing {
+		if !matched[key(r)] {
+			report.onlyTerraform = append(report.onlyTerraform, r)
+		}
+	}
+
+	byAddress := func(a, b diffResource) int { return strings.Compare(a.address, b.address) }
+	slices.SortFunc(report.onlyCloudflare, byAddress)
+	slices.SortFunc(report.onlyTerraform, byAddress)
+
+	return report
+}
+
+// writeDiffReport outputs the differences between Cloudflare and Terraform.
+func writeDiffReport(w io.Writer, report diffReport) {
+	if len(report.onlyCloudflare) == 0 && len(report.onlyTerraform) == 0 && len(report.differs) == 0 {
+		_, _ = fmt.Fprintln(w, "no differences found")
+		return
+	}
+
+	for _, r := range report.onlyCloudflare {
+		_, _ = fmt.Fprintf(w, "+ %s (only in Cloudflare)\n", r.address)
+	}
+	for _, r := range report.onlyTerraform {
+		_, _ = fmt.Fprintf(w, "- %s (only in Terraform)\n", r.address)
+	}
+	for _, address := range sortedKeys(report.differs) {
+		_, _ = fmt.Fprintf(w, "~ %s (%s differ)\n", address, strings.Join(report.differs[address], ", "))
+	}
+}
+
+// writeDiffReportJSON outputs the differences in a machine readable form.
+func writeDiffReportJSON(w io.Writer, report diffReport) error {
+	entry := func(r diffResource) diffEntry {
+		resourceType, _, _ := strings.Cut(r.address, ".")
+		return diffEntry{Address: r.address, ResourceType: resourceType, ID: r.id, ZoneID: zoneID, AccountID: accountID}
+	}
+
+	output := diffReportJSON{
+		OnlyInCloudflare: []diffEntry{},
+		OnlyInTerraform:  []diffEntry{},
+		Differs:          []diffEntry{},
+	}
+	for _, r := range report.onlyCloudflare {
+		output.OnlyInCloudflare = append(output.OnlyInCloudflare, entry(r))
+	}
+	for _, r := range report.onlyTerraform {
+		output.OnlyInTerraform = append(output.OnlyInTerraform, entry(r))
+	}
+	for _, address := range sortedKeys(report.differs) {
+		e := entry(diffResource{address: address})
+		e.Attributes = report.differs[address]
+		output.Differs = append(output.Differs, e)
+	}
+
+	encoded, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(encoded))
+
+	return err
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareResourcesByAddress(t *testing.T) {
+	live, err := parseDiffResources([]byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "www_example_com_a" {
+		  name    = "www.example.com"
+		  content = "192.0.2.1"
+		  ttl     = 1
+		}
+
+		resource "cloudflare_dns_record" "api_example_com_a" {
+		  name    = "api.example.com"
+		  content = "192.0.2.2"
+		}
+	`)), "generated.tf", nil)
+	require.NoError(t, err)
+
+	existing, err := parseDiffResources([]byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "www_example_com_a" {
+		  zone_id = var.zone_id
+		  name    = "www.example.com"
+		  content = "192.0.2.10"
+		  ttl     = 1
+		}
+
+		resource "cloudflare_dns_record" "old_example_com_a" {
+		  name = "old.example.com"
+		}
+
+		resource "cloudflare_zone" "example" {
+		  name = "example.com"
+		}
+	`)), "main.tf", []string{"cloudflare_dns_record"})
+	require.NoError(t, err)
+
+	var output bytes.Buffer
+	writeDiffReport(&output, compareResources(live, existing, false))
+	assert.Equal(t, heredoc.Doc(`
+		+ cloudflare_dns_record.api_example_com_a (only in Cloudflare)
+		- cloudflare_dns_record.old_example_com_a (only in Terraform)
+		~ cloudflare_dns_record.www_example_com_a (content differ)
+	`), output.String())
+}
+
+func TestCompareResourcesByID(t *testing.T) {
+	existing, err := stateDiffResources([]byte(heredoc.Doc(`
+		{
+		  "version": 4,
+		  "resources": [
+		    {
+		      "mode": "managed",
+		      "type": "cloudflare_dns_record",
+		      "name": "www",
+		      "instances": [{"attributes": {"id": "372e67954025e0ba6aaa6d586b9e0b59", "ttl": 1, "proxied": true}}]
+		    }
+		  ]
+		}
+	`)), []string{"cloudflare_dns_record"})
+	require.NoError(t, err)
+
+	live := []diffResource{{
+		address:    "cloudflare_dns_record.www_example_com_a",
+		id:         "372e67954025e0ba6aaa6d586b9e0b59",
+		attributes: map[string]interface{}{"ttl": float64(1), "proxied": true},
+	}}
+
+	var output bytes.Buffer
+	writeDiffReport(&output, compareResources(live, existing, true))
+	assert.Equal(t, "no differences found\n", output.String())
+}
+
+func TestWriteDiffReportJSON(t *testing.T) {
+	zoneID = "0da42c8d2132a9ddaf714f9e7c920711"
+	defer func() { zoneID = "" }()
+
+	report := diffReport{
+		onlyCloudflare: []diffResource{{
+			address: "cloudflare_dns_record.shadow_example_com_a",
+			id:      "372e67954025e0ba6aaa6d586b9e0b59",
+		}},
+	}
+
+	var output bytes.Buffer
+	require.NoError(t, writeDiffReportJSON(&output, report))
+	assert.JSONEq(t, `{
+	  "only_in_cloudflare": [
+	    {
+	      "address": "cloudflare_dns_record.shadow_example_com_a",
+	      "resource_type": "cloudflare_dns_record",
+	      "id": "372e67954025e0ba6aaa6d586b9e0b59",
+	      "zone_id": "0da42c8d2132a9ddaf714f9e7c920711"
+	    }
+	  ],
+	  "only_in_terraform": [],
+	  "differs": []
+	}`, output.String())
+}
+
+func TestRunDiffEmptyResourceType(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_zone_lockdown,cloudflare_dns_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/firewall/lockdowns": `[]`,
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records":        `[{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"www.example.com","type":"A","content":"192.0.2.1","ttl":1}]`,
+	})
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "www_example_com_a" {
+		  content = "192.0.2.1"
+		  name    = "www.example.com"
+		  ttl     = 1
+		  type    = "A"
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		}
+	`)), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, Run(context.Background(), RunConfig{
+		Args:   append([]string{"diff", "--zone", "0da42c8d2132a9ddaf714f9e7c920711", "--config-dir", dir}, args...),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}))
+
+	// The record following the empty resource type is still compared.
+	assert.Equal(t, "no differences found\n", stdout.String())
+}
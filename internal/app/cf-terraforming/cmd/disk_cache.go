@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// cacheDir is where API responses are cached between runs. Disabled when
+	// empty.
+	cacheDir string
+
+	// cacheTTL is how long cached API responses are used for.
+	cacheTTL time.Duration
+)
+
+// diskCacheEntry is a cached response as written to disk.
+type diskCacheEntry struct {
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// diskCacheTransport is an http.RoundTripper that caches the responses to
+// successful GET requests on disk so they can be reused by later runs until
+// they're older than the TTL.
+type diskCacheTransport struct {
+	rt  http.RoundTripper
+	dir string
+	ttl time.Duration
+}
+
+func newDiskCacheTransport(rt http.RoundTripper, dir string, ttl time.Duration) *diskCacheTransport {
+	return &diskCacheTransport{rt: rt, dir: dir, ttl: ttl}
+}
+
+func (t *diskCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.rt.RoundTrip(req)
+	}
+
+	path := t.path(req)
+	if cached, ok := t.read(path); ok {
+		log.Debugf("using cached response for %s", req.URL)
+		return cached.response(req), nil
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	cached := &cachedResponse{status: resp.StatusCode, header: resp.Header.Clone(), body: body}
+	if err := t.write(path, req, cached); err != nil {
+		log.Warnf("failed to cache response for %s: %s", req.URL, err)
+	}
+
+	return cached.response(req), nil
+}
+
+// path returns where the response to the request is cached. The credentials
+// are part of the key so responses aren't shared between tokens that can see
+// different resources.
+func (t *diskCacheTransport) path(req *http.Request) string {
+	hash := sha256.New()
+	for _, value := range []string{req.URL.String(), req.Header.Get("Authorization"), req.Header.Get("X-Auth-Key"), req.Header.Get("X-Auth-Email")} {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+
+	return filepath.Join(t.dir, hex.EncodeToString(hash.Sum(nil))+".json")
+}
+
+func (t *diskCacheTransport) read(path string) (*cachedResponse, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Debugf("failed to read cached response %s: %s", path, err)
+		}
+		return nil, false
+	}
+	if time.Since(info.ModTime()) > t.ttl {
+		return nil, false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		log.Debugf("ignoring invalid cached response %s: %s", path, err)
+		return nil, false
+	}
+
+	return &cachedResponse{status: entry.Status, header: entry.Header, body: entry.Body}, true
+}
+
+// write caches the response. API responses can contain secrets so the cache
+// is only readable by the current user.
+func (t *diskCacheTransport) write(path string, req *http.Request, cached *cachedResponse) error {
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(diskCacheEntry{
+		URL:    req.URL.String(),
+		Status: cached.status,
+		Header: cached.header,
+		Body:   cached.body,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The response is written to a temporary file first so concurrent runs
+	// never read a partially written response.
+	tmp, err := os.CreateTemp(t.dir, "response-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCacheTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprintf(w, "response %d", requests)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	get := func(token string) string {
+		client := &http.Client{Transport: newDiskCacheTransport(http.DefaultTransport, dir, time.Hour)}
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/zones", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	assert.Equal(t, "response 1", get("first"))
+	assert.Equal(t, "response 1", get("first"))
+	assert.Equal(t, "response 2", get("second"))
+	assert.Equal(t, 2, requests)
+
+	// Responses older than the TTL are fetched again.
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	for _, path := range paths {
+		old := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	assert.Equal(t, "response 3", get("first"))
+	assert.Equal(t, 3, requests)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	dnsForEachLocals = "locals"
+	dnsForEachYAML   = "yaml"
+
+	dnsRecordsLocal    = "dns_records"
+	dnsRecordsFilename = "dns_records.yaml"
+)
+
+// dnsForEach collapses DNS records into a single resource using `for_each`
+// over a map of records held in either a local value or a YAML file.
+var dnsForEach string
+
+// collapseDNSRecords replaces the DNS records in the file with a single
+// resource named terraform_managed_resource which iterates over a map of the
+// records keyed by their generated name, matching `import --import-for-each`.
+// The zone is set on the resource and every other attribute is read from the
+// map. Records with nested blocks are left as they are as they can't be
+// expressed without dynamic blocks.
+//
+// The names of the collapsed records are returned.
+func collapseDNSRecords(f *hclwrite.File, resourceType, mode string) (*hclwrite.File, map[string]bool, error) {
+	var (
+		records []*hclwrite.Block
+		others  []*hclwrite.Block
+	)
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 || block.Labels()[0] != resourceType {
+			others = append(others, block)
+			continue
+		}
+		if len(block.Body().Blocks()) > 0 {
+			log.Warnf("%s.%s has nested blocks so is not collapsed into --dns-for-each", resourceType, block.Labels()[1])
+			others = append(others, block)
+			continue
+		}
+		records = append(records, block)
+	}
+
+	if len(records) == 0 {
+		return f, nil, nil
+	}
+
+	r := forEachResource{
+		resourceType: resourceType,
+		name:         terraformResourceNamePrefix,
+		local:        dnsRecordsLocal,
+		common:       make(map[string]hclwrite.Tokens),
+		instances:    make(map[string]map[string]hclwrite.Tokens, len(records)),
+	}
+	names := make(map[string]bool, len(records))
+	values := make(map[string]map[string]interface{}, len(records))
+	for _, block := range records {
+		name := block.Labels()[1]
+		names[name] = true
+		r.keys = append(r.keys, name)
+
+		attributes := block.Body().Attributes()
+		r.instances[name] = make(map[string]hclwrite.Tokens, len(attributes))
+		values[name] = make(map[string]interface{}, len(attributes))
+		for attrName, attr := range attributes {
+			tokens := attr.Expr().BuildTokens(nil)
+			if attrName == "zone_id" {
+				if _, ok := r.common[attrName]; !ok {
+					r.common[attrName] = tokens
+				}
+				continue
+			}
+			r.instances[name][attrName] = tokens
+
+			if mode == dnsForEachYAML {
+				value, err := yamlValue(tokens)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s.%s can't be written to %s: %w", resourceType, name, dnsRecordsFilename, err)
+				}
+				values[name][attrName] = value
+			}
+		}
+	}
+
+	collapsed := hclwrite.NewEmptyFile()
+	body := collapsed.Body()
+
+	if mode == dnsForEachYAML {
+		content, err := yaml.Marshal(values)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, dnsRecordsFilename), content, 0644); err != nil {
+			return nil, nil, err
+		}
+
+		// The path is a template so can't be built from a value which would
+		// escape the interpolation.
+		path := hclwrite.Tokens{
+			{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+			{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")},
+		}
+		path = append(path, hclwrite.TokensForTraversal(hcl.Traversal{hcl.TraverseRoot{Name: "path"}, hcl.TraverseAttr{Name: "module"}})...)
+		path = append(path,
+			&hclwrite.Token{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")},
+			&hclwrite.Token{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("/" + dnsRecordsFilename)},
+			&hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+		)
+		locals := body.AppendNewBlock("locals", nil).Body()
+		locals.SetAttributeRaw(dnsRecordsLocal, hclwrite.TokensForFunctionCall("yamldecode", hclwrite.TokensForFunctionCall("file", path)))
+	} else {
+		r.appendLocal(body)
+	}
+	body.AppendNewline()
+
+	r.appendResource(body)
+	body.AppendNewline()
+
+	for _, block := range others {
+		f.Body().RemoveBlock(block)
+		body.AppendBlock(block)
+		body.AppendNewline()
+	}
+
+	return collapsed, names, nil
+}
+
+// yamlValue converts a literal expression into a value that can be written to
+// YAML.
+func yamlValue(tokens hclwrite.Tokens) (interface{}, error) {
+	value, ok := literalValue(tokens)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a literal value", string(tokens.Bytes()))
+	}
+
+	encoded, err := ctyjson.Marshal(value, value.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	return terraforming.NormaliseJSON(encoded)
+}
+
+// collapsedDNSRecordAddress is the address of a DNS record once collapsed by
+// --dns-for-each.
+func collapsedDNSRecordAddress(resourceType, name string) string {
+	return fmt.Sprintf("%s.%s[%q]", resourceType, terraformResourceNamePrefix, name)
+}
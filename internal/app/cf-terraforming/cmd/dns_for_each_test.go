@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dnsForEachConfig = heredoc.Doc(`
+	resource "cloudflare_dns_record" "www_example_com_a" {
+	  content = "198.51.100.4"
+	  name    = "www.example.com"
+	  proxied = true
+	  ttl     = 1
+	  type    = "A"
+	  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+	}
+
+	resource "cloudflare_dns_record" "example_com_txt" {
+	  comment = "Domain verification"
+	  content = "verification=abc"
+	  name    = "example.com"
+	  ttl     = 3600
+	  type    = "TXT"
+	  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+	}
+`)
+
+func TestCollapseDNSRecordsLocals(t *testing.T) {
+	f, diags := hclwrite.ParseConfig([]byte(dnsForEachConfig), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	collapsed, names, err := collapseDNSRecords(f, "cloudflare_dns_record", dnsForEachLocals)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"www_example_com_a": true, "example_com_txt": true}, names)
+
+	expected := heredoc.Doc(`
+		locals {
+		  dns_records = {
+		    "www_example_com_a" = {
+		      content = "198.51.100.4"
+		      name    = "www.example.com"
+		      proxied = true
+		      ttl     = 1
+		      type    = "A"
+		    }
+		    "example_com_txt" = {
+		      comment = "Domain verification"
+		      content = "verification=abc"
+		      name    = "example.com"
+		      ttl     = 3600
+		      type    = "TXT"
+		    }
+		  }
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource" {
+		  for_each = local.dns_records
+		  zone_id  = "0da42c8d2132a9ddaf714f9e7c920711"
+		  comment  = try(each.value.comment, null)
+		  content  = each.value.content
+		  name     = each.value.name
+		  proxied  = try(each.value.proxied, null)
+		  ttl      = each.value.ttl
+		  type     = each.value.type
+		}
+
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(collapsed.Bytes())))
+}
+
+func TestCollapseDNSRecordsYAML(t *testing.T) {
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = t.TempDir()
+
+	f, diags := hclwrite.ParseConfig([]byte(dnsForEachConfig), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	collapsed, _, err := collapseDNSRecords(f, "cloudflare_dns_record", dnsForEachYAML)
+	require.NoError(t, err)
+	assert.Contains(t, string(hclwrite.Format(collapsed.Bytes())), `dns_records = yamldecode(file("${path.module}/dns_records.yaml"))`)
+
+	content, err := os.ReadFile(filepath.Join(outputDir, dnsRecordsFilename))
+	require.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		example_com_txt:
+		    comment: Domain verification
+		    content: verification=abc
+		    name: example.com
+		    ttl: 3600
+		    type: TXT
+		www_example_com_a:
+		    content: 198.51.100.4
+		    name: www.example.com
+		    proxied: true
+		    ttl: 1
+		    type: A
+	`), string(content))
+}
+
+func TestCollapseDNSRecordsKeepsNestedBlocks(t *testing.T) {
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_record" "srv" {
+		  name = "_sip._tcp"
+		  type = "SRV"
+
+		  data {
+		    port = 5060
+		  }
+		}
+	`)), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	collapsed, names, err := collapseDNSRecords(f, "cloudflare_record", dnsForEachLocals)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+	assert.Same(t, f, collapsed)
+}
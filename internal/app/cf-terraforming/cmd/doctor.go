@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tidwall/gjson"
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:    "doctor",
+	Short:  "Check the credentials, API access and Terraform setup before generating resources",
+	Run:    runDoctor(),
+	PreRun: sharedPreRun,
+}
+
+const (
+	doctorStatusOK   = "ok"
+	doctorStatusFail = "fail"
+	doctorStatusSkip = "skip"
+)
+
+// doctorCheck is the outcome of a single preflight check.
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+}
+
+func checkPassed(name, detail string) doctorCheck {
+	return doctorCheck{name: name, status: doctorStatusOK, detail: detail}
+}
+
+func checkFailed(name string, err error) doctorCheck {
+	return doctorCheck{name: name, status: doctorStatusFail, detail: err.Error()}
+}
+
+func checkSkipped(name, detail string) doctorCheck {
+	return doctorCheck{name: name, status: doctorStatusSkip, detail: detail}
+}
+
+func runDoctor() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		checks := runAPIChecks(context.Background())
+		checks = append(checks, checkProvider())
+
+		if n := writeDoctorChecks(cmd.OutOrStdout(), checks); n > 0 {
+			log.Fatalf("%d check(s) failed", n)
+		}
+	}
+}
+
+// runAPIChecks checks the API can be reached with the credentials and that
+// each requested resource type can be read.
+func runAPIChecks(ctx context.Context) []doctorCheck {
+	checks := []doctorCheck{checkConnectivity(ctx), checkCredentials(ctx)}
+	if checks[0].status == doctorStatusFail || checks[1].status == doctorStatusFail {
+		return checks
+	}
+
+	checks = append(checks, checkAccountOrZone(ctx))
+	if resourceType != "" {
+		var resourceTypes []string
+		for _, t := range strings.Split(resourceType, ",") {
+			resourceTypes = append(resourceTypes, strings.TrimSpace(t))
+		}
+
+		checks = append(checks, checkTokenPermissions(ctx, resourceTypes))
+		for _, t := range resourceTypes {
+			checks = append(checks, checkResourceType(ctx, t))
+		}
+	}
+
+	return checks
+}
+
+// writeDoctorChecks outputs the checks, returning how many failed.
+func writeDoctorChecks(w io.Writer, checks []doctorCheck) int {
+	var failures int
+	for _, check := range checks {
+		if check.status == doctorStatusFail {
+			failures++
+		}
+		_, _ = fmt.Fprintf(w, "[%s] %s: %s\n", check.status, check.name, check.detail)
+	}
+
+	return failures
+}
+
+// doctorGet fetches the result of an API endpoint.
+func doctorGet(ctx context.Context, endpoint string) (gjson.Result, error) {
+	var result *http.Response
+	if err := api.Get(ctx, endpoint, nil, &result); err != nil {
+		return gjson.Result{}, err
+	}
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	return gjson.GetBytes(body, "result"), nil
+}
+
+func checkConnectivity(ctx context.Context) doctorCheck {
+	const name = "API connectivity"
+	if _, err := doctorGet(ctx, "/ips"); err != nil {
+		return checkFailed(name, err)
+	}
+
+	return checkPassed(name, "reached the Cloudflare API")
+}
+
+func checkCredentials(ctx context.Context) doctorCheck {
+	const name = "Credentials"
+	if apiToken == "" {
+		user, err := doctorGet(ctx, "/user")
+		if err != nil {
+			return checkFailed(name, err)
+		}
+		return checkPassed(name, fmt.Sprintf("API key is valid for %s", user.Get("email").String()))
+	}
+
+	token, err := doctorGet(ctx, "/user/tokens/verify")
+	// Tokens owned by an account can only be verified through the account.
+	if err != nil && accountID != "" {
+		token, err = doctorGet(ctx, fmt.Sprintf("/accounts/%s/tokens/verify", accountID))
+	}
+	if err != nil {
+		return checkFailed(name, err)
+	}
+	if status := token.Get("status").String(); status != "active" {
+		return checkFailed(name, fmt.Errorf("API token is %s", status))
+	}
+
+	return checkPassed(name, "API token is active")
+}
+
+func checkAccountOrZone(ctx context.Context) doctorCheck {
+	switch {
+	case accountID != "":
+		account, err := doctorGet(ctx, "/accounts/"+accountID)
+		if err != nil {
+			return checkFailed("Account", err)
+		}
+		return checkPassed("Account", fmt.Sprintf("%s (%s)", account.Get("name").String(), accountID))
+	case zoneID != "":
+		zone, err := doctorGet(ctx, "/zones/"+zoneID)
+		if err != nil {
+			return checkFailed("Zone", err)
+		}
+		return checkPassed("Zone", fmt.Sprintf("%s (%s)", zone.Get("name").String(), zoneID))
+	default:
+		return checkFailed("Account or zone", errors.New("either -a/--account or -z/--zone must be set"))
+	}
+}
+
+// checkResourceType fetches the first page of the resource type to check the
+// credentials have permission to read it.
+func checkResourceType(ctx context.Context, resourceType string) doctorCheck {
+	endpoint := resourceEndpoint(resourceType)
+	if endpoint == "" {
+		return checkSkipped(resourceType, "not supported by the v5 provider")
+	}
+	if strings.Contains(endpoint, "{") {
+		return checkSkipped(resourceType, "read by ID so can't be checked ahead of time")
+	}
+
+	_, err := doctorGet(ctx, endpoint)
+	var apierr *cloudflare.Error
+	switch {
+	case err == nil:
+		return checkPassed(resourceType, "can be read")
+	case errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound:
+		return checkPassed(resourceType, "not found for this account or zone")
+	case errors.As(err, &apierr) && apierr.StatusCode == http.StatusForbidden:
+		if groups := requiredPermissionGroups[resourceType]; len(groups) > 0 {
+			return checkFailed(resourceType, fmt.Errorf("the credentials are missing a permission to read it, which needs %s", strings.Join(groups, ", ")))
+		}
+		return checkFailed(resourceType, errors.New("the credentials are missing a permission to read it"))
+	default:
+		return checkFailed(resourceType, err)
+	}
+}
+
+func checkProvider() doctorCheck {
+	const name = "Terraform provider"
+	execPath, err := findOrInstallTerraform()
+	if err != nil {
+		return checkFailed(name, fmt.Errorf("could not find or install Terraform: %w", err))
+	}
+
+	tf, err := tfexec.NewTerraform(viper.GetString("terraform-install-path"), execPath)
+	if err != nil {
+		return checkFailed(name, err)
+	}
+
+	registryPath, version, err := detectProvider(tf)
+	if err != nil {
+		return checkFailed(name, err)
+	}
+	if err := checkWorkingDirProvider(viper.GetString("terraform-install-path"), version); err != nil {
+		return checkFailed(name, err)
+	}
+
+	return checkPassed(name, fmt.Sprintf("%s %s", registryPath, version))
+}
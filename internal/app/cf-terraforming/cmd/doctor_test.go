@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAPIChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ips":
+			_, _ = w.Write([]byte(`{"success":true,"result":{"ipv4_cidrs":[]}}`))
+		case "/user/tokens/verify":
+			_, _ = w.Write([]byte(`{"success":true,"result":{"id":"ed17574386854bf78a67040be0a770b0","status":"active"}}`))
+		case "/user/tokens/ed17574386854bf78a67040be0a770b0":
+			_, _ = w.Write([]byte(`{"success":true,"result":{"id":"ed17574386854bf78a67040be0a770b0","policies":[{"effect":"allow","permission_groups":[{"name":"DNS Write"},{"name":"API Tokens Read"}]}]}}`))
+		case "/zones/0da42c8d2132a9ddaf714f9e7c920711":
+			_, _ = w.Write([]byte(`{"success":true,"result":{"id":"0da42c8d2132a9ddaf714f9e7c920711","name":"example.com"}}`))
+		case "/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records":
+			_, _ = w.Write([]byte(`{"success":true,"result":[]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"result":null}`))
+		}
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, token, account, zone, types string) {
+		api, apiToken, accountID, zoneID, resourceType = client, token, account, zone, types
+	}(api, apiToken, accountID, zoneID, resourceType)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"), option.WithMaxRetries(0))
+	apiToken, accountID, zoneID = "token", "", "0da42c8d2132a9ddaf714f9e7c920711"
+	resourceType = "cloudflare_dns_record,cloudflare_page_rule,cloudflare_zone_setting"
+
+	var output bytes.Buffer
+	assert.Equal(t, 2, writeDoctorChecks(&output, runAPIChecks(context.Background())))
+	assert.Equal(t, heredoc.Doc(`
+		[ok] API connectivity: reached the Cloudflare API
+		[ok] Credentials: API token is active
+		[ok] Zone: example.com (0da42c8d2132a9ddaf714f9e7c920711)
+		[fail] Token permissions: the API token is missing Page Rules Read (cloudflare_page_rule), Zone Settings Read (cloudflare_zone_setting)
+		[ok] cloudflare_dns_record: can be read
+		[fail] cloudflare_page_rule: the credentials are missing a permission to read it, which needs Page Rules Read
+		[skip] cloudflare_zone_setting: read by ID so can't be checked ahead of time
+	`), output.String())
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// RunConfig configures a command run in-process, such as by serve.
+type RunConfig struct {
+	// Args are the command line arguments, starting with the command.
+	Args []string
+	// Stdout receives the generated output and Stderr the logs.
+	Stdout, Stderr io.Writer
+	// Transport, if set, sends the API requests in place of the default
+	// connection pool.
+	Transport http.RoundTripper
+}
+
+// runMu serialises in-process runs as the commands keep their settings in
+// package state.
+var runMu sync.Mutex
+
+// exitCode is panicked with in place of exiting the process during an
+// in-process run.
+type exitCode int
+
+// fatalHook remembers the last fatal error logged so it can be returned.
+type fatalHook struct {
+	message string
+}
+
+func (h *fatalHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.FatalLevel}
+}
+
+func (h *fatalHook) Fire(entry *logrus.Entry) error {
+	h.message = strings.TrimSpace(entry.Message)
+	return nil
+}
+
+// Run runs the command in-process, returning an error where the CLI would
+// have exited unsuccessfully.
+func Run(ctx context.Context, config RunConfig) error {
+	runMu.Lock()
+	defer runMu.Unlock()
+
+	resetFlags(rootCmd.PersistentFlags())
+	for _, c := range rootCmd.Commands() {
+		resetFlags(c.Flags())
+	}
+	resetRunState()
+	defer func(out io.Writer, transport http.RoundTripper) {
+		log.Out, apiTransport = out, transport
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	}(log.Out, apiTransport)
+
+	if config.Stderr != nil {
+		log.Out = config.Stderr
+	}
+	apiTransport = config.Transport
+	rootCmd.SetArgs(config.Args)
+	rootCmd.SetOut(config.Stdout)
+	rootCmd.SetErr(config.Stderr)
+
+	var err error
+	if exitErr := catchExit(func() { err = rootCmd.ExecuteContext(ctx) }); exitErr != nil {
+		return exitErr
+	}
+	return err
+}
+
+// catchExit calls fn, returning an error where it would have exited the
+// process. Exiting with code 0 returns nil.
+func catchExit(fn func()) (err error) {
+	defer func(exit func(int), hooks logrus.LevelHooks, report *errorReport) {
+		log.ExitFunc, log.Hooks, exitReport = exit, hooks, report
+	}(log.ExitFunc, log.Hooks, exitReport)
+
+	// The hooks are copied so the fatal hook is only added for this call.
+	hook := &fatalHook{}
+	hooks := make(logrus.LevelHooks)
+	for level, h := range log.Hooks {
+		hooks[level] = slices.Clone(h)
+	}
+	log.Hooks = hooks
+	log.AddHook(hook)
+	log.ExitFunc = func(code int) { panic(exitCode(code)) }
+
+	defer func() {
+		if r := recover(); r != nil {
+			code, ok := r.(exitCode)
+			if !ok {
+				panic(r)
+			}
+			if code != 0 {
+				err = &exitError{code: int(code), message: hook.message}
+			}
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+// resetRunState clears the state a run keeps in the package outside of its
+// flags, such as its metrics, the provider version detected by import and
+// whether bootstrap reuses API responses, so the next run starts afresh.
+func resetRunState() {
+	metrics = newRunMetrics()
+	providerVersionString = ""
+	reuseAPIResponses = false
+	reportMetricsOnce = sync.Once{}
+	interrupted.Store(false)
+}
+
+// resetFlags sets every flag back to its default so that a run isn't affected
+// by the flags of the previous one.
+func resetFlags(flags *pflag.FlagSet) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			var defaults []string
+			if d := strings.Trim(flag.DefValue, "[]"); d != "" {
+				defaults = strings.Split(d, ",")
+			}
+			_ = value.Replace(defaults)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	cfv0 "github.com/cloudflare/cloudflare-go"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), RunConfig{
+		Args:   []string{"generate", "--config", os.DevNull, "--account", "0da42c8d2132a9ddaf714f9e7c920711", "--zone", "81b06ss3228f488fh84e5e993c2dc17"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	assert.EqualError(t, err, "--account and --zone are mutually exclusive, support for both is deprecated")
+	assert.Contains(t, stderr.String(), "mutually exclusive")
+	assert.Empty(t, stdout.String())
+
+	// The flags of the previous run are reset.
+	require.NoError(t, Run(context.Background(), RunConfig{Args: []string{"version", "--config", os.DevNull}, Stdout: &stdout, Stderr: &stderr}))
+	assert.False(t, rootCmd.PersistentFlags().Lookup("zone").Changed)
+	assert.Empty(t, zoneID+accountID+resourceType)
+}
+
+func TestResetFlags(t *testing.T) {
+	flags := rootCmd.PersistentFlags()
+	require.NoError(t, flags.Set("exclude-resource-type", "cloudflare_ruleset"))
+	require.NoError(t, flags.Set("name-prefix", "acme_"))
+
+	resetFlags(flags)
+	assert.Empty(t, excludeResourceTypes)
+	assert.Empty(t, namePrefix)
+	assert.False(t, flags.Lookup("name-prefix").Changed)
+}
+
+// testProviderSchema is the provider schema used by in-process runs so that
+// they don't need Terraform.
+const testProviderSchema = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/cloudflare/cloudflare": {
+      "provider": {"version": 0, "block": {}},
+      "resource_schemas": {
+        "cloudflare_dns_record": {"version": 0, "block": {"attributes": {
+          "id": {"type": "string", "computed": true},
+          "zone_id": {"type": "string", "required": true},
+          "name": {"type": "string", "required": true},
+          "type": {"type": "string", "required": true},
+          "content": {"type": "string", "optional": true},
+          "ttl": {"type": "number", "optional": true}
+        }}},
+        "cloudflare_record": {"version": 0, "block": {"attributes": {
+          "id": {"type": "string", "computed": true},
+          "zone_id": {"type": "string", "required": true},
+          "name": {"type": "string", "required": true},
+          "type": {"type": "string", "required": true},
+          "content": {"type": "string", "optional": true},
+          "ttl": {"type": "number", "optional": true}
+        }}},
+        "cloudflare_zero_trust_tunnel_cloudflared": {"version": 0, "block": {"attributes": {
+          "id": {"type": "string", "computed": true},
+          "account_id": {"type": "string", "required": true},
+          "name": {"type": "string", "required": true},
+          "tunnel_secret": {"type": "string", "optional": true, "sensitive": true}
+        }}},
+        "cloudflare_zone_setting": {"version": 0, "block": {"attributes": {
+          "id": {"type": "string", "computed": true},
+          "zone_id": {"type": "string", "required": true},
+          "setting_id": {"type": "string", "required": true},
+          "value": {"type": "string", "optional": true}
+        }}},
+        "cloudflare_zone_lockdown": {"version": 0, "block": {"attributes": {
+          "id": {"type": "string", "computed": true},
+          "zone_id": {"type": "string", "required": true},
+          "urls": {"type": ["list", "string"], "optional": true}
+        }}}
+      }
+    }
+  }
+}`
+
+// fakeGenerateArgs starts a fake API serving the results by path, and an
+// authentication error for any other path, and returns the arguments to
+// generate the resource types from it without Terraform, leaving the account
+// or zone to the caller.
+func fakeGenerateArgs(t *testing.T, resourceTypes string, results map[string]string) []string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		result, ok := results[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, `{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"messages":[],"result":null}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"success":true,"errors":[],"messages":[],"result":%s,"result_info":{"page":1,"total_pages":1}}`, result)
+	}))
+	t.Cleanup(server.Close)
+
+	schema := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(schema, []byte(testProviderSchema), 0o600))
+	t.Setenv("CLOUDFLARE_TERRAFORM_BINARY_PATH", "/bin/false")
+	// The API clients are only configured outside of CI and resources are
+	// named from their attributes.
+	t.Setenv("CI", "")
+	t.Setenv("USE_STATIC_RESOURCE_IDS", "")
+	t.Cleanup(func(client *cloudflare.Client, clientV0 *cfv0.API) func() {
+		return func() {
+			api, apiV0 = client, clientV0
+			// Flags such as --fail-fast would otherwise affect later tests.
+			resetFlags(rootCmd.PersistentFlags())
+			for _, c := range rootCmd.Commands() {
+				resetFlags(c.Flags())
+			}
+		}
+	}(api, apiV0))
+
+	return []string{
+		"--config", os.DevNull,
+		"--token", "token",
+		"--api-url", server.URL,
+		"--provider-schema", schema,
+		"--provider-version", "5.5.0",
+		"--max-retries", "0",
+		"--resource-type", resourceTypes,
+	}
+}
+
+func TestRunGenerateEmptyResourceType(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_zone_lockdown,cloudflare_dns_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/firewall/lockdowns": `[]`,
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records":        `[{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"www.example.com","type":"A","content":"192.0.2.1","ttl":1}]`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"}, args...)
+	require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+
+	// The empty resource type is reported on stderr so that it doesn't end up
+	// in the configuration.
+	assert.Contains(t, stderr.String(), `no resources of type "cloudflare_zone_lockdown" found to generate`)
+	assert.NotContains(t, stdout.String(), "no resources")
+	resources, err := parseDiffResources(stdout.Bytes(), "generated.tf", nil)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "cloudflare_dns_record.www_example_com_a", resources[0].address)
+}
+
+func TestRunGenerateDNSNameGlobV4(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711":             `{"id":"0da42c8d2132a9ddaf714f9e7c920711","name":"example.com"}`,
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records": `[{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"www.example.com","type":"A","content":"192.0.2.1","ttl":1},{"id":"48ab0c4d2132a9ddaf714f9e7c920711","name":"example.com","type":"A","content":"192.0.2.2","ttl":1}]`,
+	})
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"}, args...)
+	args = append(args, "--provider-version", "4.52.0", "--dns-name-glob", "*.example.com")
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+
+	// The glob matches the full name while the zone is left out of the
+	// generated one.
+	resources, err := parseDiffResources(stdout.Bytes(), "generated.tf", nil)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "www", resources[0].attributes["name"])
+}
+
+func TestRunResourceIDsPerRun(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_zone_setting", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/settings":               `[{"id":"cache_level","value":"aggressive","editable":true,"modified_on":"2025-02-13T10:51:23.426493Z"}]`,
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/settings/always_online": `{"id":"always_online","value":"on","editable":true,"modified_on":null}`,
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/settings/cache_level":   `{"id":"cache_level","value":"aggressive","editable":true,"modified_on":"2025-02-13T10:51:23.426493Z"}`,
+	})
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"}, args...)
+
+	settings := func(args ...string) []string {
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+		resources, err := parseDiffResources(stdout.Bytes(), "generated.tf", nil)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, r := range resources {
+			ids = append(ids, r.attributes["setting_id"].(string))
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"always_online"}, settings(append(args, "--resource-id", "cloudflare_zone_setting=always_online")...))
+	// Neither the IDs provided nor those discovered carry over to later runs.
+	assert.Equal(t, []string{"cache_level"}, settings(args...))
+	assert.Equal(t, []string{"cache_level"}, settings(args...))
+}
+
+func TestRunFailFastParallel(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_zone_lockdown,cloudflare_dns_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/firewall/lockdowns": `[]`,
+	})
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711", "--fail-fast", "--parallelism", "2"}, args...)
+
+	// The failure is returned rather than exiting from the goroutine fetching
+	// the resources.
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr})
+	require.Error(t, err)
+	assert.Equal(t, exitCodePermissionDenied, exitCodeOf(err))
+	assert.Contains(t, err.Error(), "failed to generate cloudflare_dns_record")
+}
+
+func TestRunImportConverterFailure(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_example", nil)
+	registerTestConverter(t, "cloudflare_example", Converter{
+		Fetch: func(ctx context.Context, client *cloudflare.Client, account, zone string) ([]map[string]interface{}, error) {
+			request := httptest.NewRequest(http.MethodGet, "https://api.cloudflare.com/client/v4/zones/"+zone+"/example", nil)
+			return nil, fmt.Errorf("failed to fetch API endpoint: %w", &cloudflare.Error{StatusCode: http.StatusForbidden, Request: request})
+		},
+		ImportID: ":zone_id/:id",
+	})
+
+	// Import reads the provider version from Terraform rather than the flag.
+	t.Cleanup(func() { providerVersionString = "" })
+	dir := t.TempDir()
+	terraform := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(terraform, []byte(`#!/bin/sh
+echo '{"terraform_version":"1.9.0","provider_selections":{"registry.terraform.io/cloudflare/cloudflare":"5.5.0"}}'
+`), 0o700))
+	args = append([]string{"import", "--zone", "0da42c8d2132a9ddaf714f9e7c920711", "--terraform-binary-path", terraform, "--terraform-install-path", dir}, args...)
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr})
+	require.Error(t, err)
+	assert.Equal(t, exitCodePermissionDenied, exitCodeOf(err))
+	assert.Contains(t, stderr.String(), "Failed to import 1 resource type(s)")
+}
+
+func TestRunBootstrapProviderVersion(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_dns_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records": `[{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"www.example.com","type":"A","content":"192.0.2.1","ttl":1}]`,
+	})
+
+	t.Cleanup(func() { providerVersionString = "" })
+	dir := t.TempDir()
+	terraform := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(terraform, []byte(`#!/bin/sh
+echo '{"terraform_version":"1.9.0","provider_selections":{"registry.terraform.io/cloudflare/cloudflare":"5.5.0"}}'
+`), 0o700))
+	output := filepath.Join(dir, "output")
+	args = append([]string{"bootstrap", "--zone", "0da42c8d2132a9ddaf714f9e7c920711", "--terraform-binary-path", terraform, "--terraform-install-path", dir, "--output-dir", output}, args...)
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+
+	// The provider is required at the version the resources were generated
+	// for.
+	provider, err := os.ReadFile(filepath.Join(output, providerFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(provider), `version = "~> 5"`)
+}
+
+func TestRunMetricsPerRun(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_dns_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records": `[{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"www.example.com","type":"A","content":"192.0.2.1","ttl":1}]`,
+	})
+	metricsPath := filepath.Join(t.TempDir(), "metrics.json")
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711", "--metrics-out", metricsPath}, args...)
+
+	// Each run reports its own metrics rather than those of every run so far.
+	for range 2 {
+		require.NoError(t, os.RemoveAll(metricsPath))
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+
+		content, err := os.ReadFile(metricsPath)
+		require.NoError(t, err)
+		var metrics runMetricsFile
+		require.NoError(t, json.Unmarshal(content, &metrics))
+		assert.Equal(t, int64(1), metrics.APICalls)
+		assert.Equal(t, 1, metrics.ResourceTypes["cloudflare_dns_record"].Resources)
+	}
+}
+
+// headerTransport records the Authorization header of each request.
+type headerTransport struct {
+	authorization []string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.authorization = append(t.authorization, req.Header.Get("Authorization"))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRunCredentialHelperPerRun(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_dns_record", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records": `[]`,
+	})
+	args = append([]string{"generate", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"}, args...)
+	// The helper is only run without other credentials.
+	t.Setenv("CLOUDFLARE_API_KEY", "")
+	t.Setenv("CLOUDFLARE_EMAIL", "")
+	i := slices.Index(args, "--token")
+	withoutToken := slices.Delete(slices.Clone(args), i, i+2)
+
+	authorization := func(args ...string) string {
+		transport := &headerTransport{}
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr, Transport: transport}))
+		require.Len(t, transport.authorization, 1)
+		return transport.authorization[0]
+	}
+
+	assert.Equal(t, "Bearer helper-token", authorization(append(withoutToken, "--credential-helper", "echo helper-token")...))
+	// The token from the helper isn't used by later runs given their own.
+	assert.Equal(t, "Bearer token", authorization(args...))
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+)
+
+// emitTemplates are Go templates executed with the generated resources to
+// write supplementary files, such as docs pages or CMDB records, into
+// --output-dir.
+var emitTemplates []string
+
+// emittedResource is a generated resource as given to the --emit-template
+// templates.
+type emittedResource struct {
+	Type        string
+	Name        string
+	Address     string
+	ID          string
+	DisplayName string
+	// Attributes are the resource's attributes from the API.
+	Attributes map[string]interface{}
+}
+
+// emitModel is the data the --emit-template templates are executed with.
+type emitModel struct {
+	AccountID       string
+	ZoneID          string
+	ProviderVersion string
+	Resources       []emittedResource
+	// ByType groups the resources by their resource type.
+	ByType map[string][]emittedResource
+}
+
+var emitTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		b, err := json.Marshal(value)
+		return string(b), err
+	},
+	"join": strings.Join,
+}
+
+// newEmittedResource builds the model of a resource, copying the attributes
+// as generation removes them as they're written.
+func newEmittedResource(resourceType, name string, data map[string]interface{}) emittedResource {
+	return emittedResource{
+		Type:        resourceType,
+		Name:        name,
+		Address:     fmt.Sprintf("%s.%s", resourceType, name),
+		ID:          resourceAPIID(resourceType, data),
+		DisplayName: terraforming.DisplayName(resourceType, data),
+		Attributes:  copyJSONValue(data).(map[string]interface{}),
+	}
+}
+
+// newEmitModel builds the model of the generated resources.
+func newEmitModel(resources []emittedResource, providerVersion string) emitModel {
+	model := emitModel{
+		AccountID:       accountID,
+		ZoneID:          zoneID,
+		ProviderVersion: providerVersion,
+		Resources:       resources,
+		ByType:          make(map[string][]emittedResource),
+	}
+	for _, resource := range resources {
+		model.ByType[resource.Type] = append(model.ByType[resource.Type], resource)
+	}
+
+	return model
+}
+
+// parseEmitTemplates parses the templates, which must be named after the file
+// they write with a .tmpl extension, such as resources.md.tmpl.
+func parseEmitTemplates(paths []string) ([]*template.Template, error) {
+	templates := make([]*template.Template, 0, len(paths))
+	for _, path := range paths {
+		if filepath.Ext(path) != ".tmpl" || strings.TrimSuffix(filepath.Base(path), ".tmpl") == "" {
+			return nil, fmt.Errorf("--emit-template %s must be named after the file to write with a .tmpl extension, e.g. resources.md.tmpl", path)
+		}
+
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --emit-template: %w", err)
+		}
+		tmpl, err := template.New(filepath.Base(path)).Funcs(terraforming.NameTemplateFuncs()).Funcs(emitTemplateFuncs).Option("missingkey=zero").Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse --emit-template %s: %w", path, err)
+		}
+		templates = append(templates, tmpl)
+	}
+
+	return templates, nil
+}
+
+// writeEmittedFiles executes each template with the model and writes the
+// output into --output-dir.
+func writeEmittedFiles(templates []*template.Template, model emitModel) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	for _, tmpl := range templates {
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, model); err != nil {
+			return fmt.Errorf("failed to execute --emit-template %s: %w", tmpl.Name(), err)
+		}
+
+		path := filepath.Join(outputDir, strings.TrimSuffix(tmpl.Name(), ".tmpl"))
+		if err := os.WriteFile(path, content.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		log.Debugf("wrote %s", path)
+		metrics.addBytesWritten(content.Len())
+	}
+
+	return nil
+}
+
+// copyJSONValue deeply copies a value decoded from JSON.
+func copyJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c[key] = copyJSONValue(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = copyJSONValue(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteEmittedFiles(t *testing.T) {
+	defer func(dir, zone string) { outputDir, zoneID = dir, zone }(outputDir, zoneID)
+	outputDir, zoneID = t.TempDir(), "0da42c8d2132a9ddaf714f9e7c920711"
+
+	path := filepath.Join(t.TempDir(), "records.md.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(heredoc.Doc(`
+		# Zone {{ .ZoneID }}
+		{{ range .ByType.cloudflare_dns_record }}
+		- {{ .DisplayName }} ({{ .Address }}): {{ .Attributes.content }} {{ json .Attributes.tags }}
+		{{- end }}
+	`)), 0600))
+
+	data := map[string]interface{}{
+		"id":      "023e105f4ecef8ad9ca31a8372d0c353",
+		"name":    "www.example.com",
+		"content": "192.0.2.1",
+		"tags":    []interface{}{"owner:web"},
+	}
+	resource := newEmittedResource("cloudflare_dns_record", "www_example_com", data)
+	// Generation removes attributes as they're written.
+	delete(data, "content")
+	data["tags"].([]interface{})[0] = "changed"
+
+	templates, err := parseEmitTemplates([]string{path})
+	require.NoError(t, err)
+	require.NoError(t, writeEmittedFiles(templates, newEmitModel([]emittedResource{resource}, "5.1.0")))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "records.md"))
+	require.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		# Zone 0da42c8d2132a9ddaf714f9e7c920711
+
+		- www.example.com (cloudflare_dns_record.www_example_com): 192.0.2.1 ["owner:web"]
+	`), string(content))
+}
+
+func TestParseEmitTemplates(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := parseEmitTemplates([]string{filepath.Join(dir, "records.md")})
+	assert.ErrorContains(t, err, "must be named after the file to write with a .tmpl extension")
+
+	path := filepath.Join(dir, "records.md.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{ .Resources"), 0600))
+	_, err = parseEmitTemplates([]string{path})
+	assert.ErrorContains(t, err, "failed to parse --emit-template "+path)
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The exit codes of unsuccessful runs let automation branch on why a run
+// failed rather than reading the logs. Drift found by watch exits with
+// exitCodeDrift and an interrupted run with exitCodeInterrupted. A 403 exits
+// with exitCodePermissionDenied rather than exitCodeAuthentication as the
+// credentials are valid but lack a permission.
+const (
+	exitCodeError            = 1
+	exitCodeAuthentication   = 3
+	exitCodeScopeMissing     = 4
+	exitCodeUnsupported      = 5
+	exitCodePartialFailure   = 6
+	exitCodeRateLimited      = 7
+	exitCodePermissionDenied = 8
+)
+
+// exitKinds names the exit codes in the JSON error report.
+var exitKinds = map[int]string{
+	exitCodeError:            "error",
+	exitCodeDrift:            "drift",
+	exitCodeAuthentication:   "authentication",
+	exitCodeScopeMissing:     "scope_missing",
+	exitCodeUnsupported:      "unsupported_resource",
+	exitCodePartialFailure:   "partial_failure",
+	exitCodeRateLimited:      "rate_limited",
+	exitCodePermissionDenied: "permission_denied",
+	exitCodeInterrupted:      "interrupted",
+}
+
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+// errorFormat is the format of the error reported when a run exits
+// unsuccessfully. The JSON report is written to stderr as a single line after
+// the logs.
+var errorFormat string
+
+// errUnsupportedResource is the failure of a resource type which
+// cf-terraforming can't generate.
+var errUnsupportedResource = errors.New("not yet supported for automatic generation")
+
+// errScopeMissing is returned when neither an account nor a zone is set.
+var errScopeMissing = errors.New("either -a/--account or -z/--zone must be set")
+
+// exitReport is set with the failures a run is about to exit for so that
+// they're included in the JSON error report.
+var exitReport *errorReport
+
+// errorReport is why a run exited unsuccessfully.
+type errorReport struct {
+	Kind     string       `json:"kind"`
+	ExitCode int          `json:"exit_code"`
+	Message  string       `json:"message"`
+	Failures []runFailure `json:"failures,omitempty"`
+}
+
+// exitError is returned by catchExit in place of exiting.
+type exitError struct {
+	code    int
+	message string
+}
+
+func (e *exitError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("exited with code %d", e.code)
+	}
+
+	return e.message
+}
+
+// exitCodeOf returns the code the run which returned the error would have
+// exited with.
+func exitCodeOf(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+
+	return exitCodeError
+}
+
+// exitWith logs the error and exits with the code.
+func exitWith(code int, args ...interface{}) {
+	log.Log(logrus.FatalLevel, args...)
+	log.Exit(code)
+}
+
+// exitWithf formats and logs the error and exits with the code.
+func exitWithf(code int, format string, args ...interface{}) {
+	log.Logf(logrus.FatalLevel, format, args...)
+	log.Exit(code)
+}
+
+// exitCode returns the code a run exits with when the failure is its only
+// one.
+func (f resourceFailure) exitCode() int {
+	switch {
+	case f.status == http.StatusUnauthorized:
+		return exitCodeAuthentication
+	case f.status == http.StatusForbidden:
+		return exitCodePermissionDenied
+	case f.status == http.StatusTooManyRequests:
+		return exitCodeRateLimited
+	case errors.Is(f.err, errUnsupportedResource):
+		return exitCodeUnsupported
+	}
+
+	return exitCodeError
+}
+
+// exitCode returns the code for the failures: that of their kind where they
+// all share one, otherwise exitCodePartialFailure.
+func (f *runFailures) exitCode() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	code := exitCodePartialFailure
+	for i, failure := range f.failures {
+		if i > 0 && failure.exitCode() != code {
+			return exitCodePartialFailure
+		}
+		code = failure.exitCode()
+	}
+	if code == exitCodeError {
+		return exitCodePartialFailure
+	}
+
+	return code
+}
+
+// configureErrorReport checks --error-format and, for JSON, writes the report
+// to the writer returned by w whenever the logger exits unsuccessfully.
+func configureErrorReport(logger *logrus.Logger, w func() io.Writer) error {
+	exitReport = nil
+
+	switch errorFormat {
+	case errorFormatText:
+		return nil
+	case errorFormatJSON:
+	default:
+		return fmt.Errorf("unsupported --error-format %q", errorFormat)
+	}
+
+	hook := &fatalHook{}
+	logger.AddHook(hook)
+	exit := logger.ExitFunc
+	logger.ExitFunc = func(code int) {
+		if code != 0 {
+			writeErrorReport(w(), code, hook.message)
+		}
+		exit(code)
+	}
+
+	return nil
+}
+
+// writeErrorReport writes the report of the run exiting with the code, using
+// the failures the run is exiting for or the last fatal error logged.
+func writeErrorReport(w io.Writer, code int, message string) {
+	report := errorReport{Message: message}
+	if exitReport != nil {
+		report = *exitReport
+	}
+	report.ExitCode = code
+	if report.Kind = exitKinds[code]; report.Kind == "" {
+		report.Kind = exitKinds[exitCodeError]
+	}
+	if report.Message == "" {
+		report.Message = fmt.Sprintf("exited with code %d", code)
+	}
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%s\n", encoded)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceFailureExitCode(t *testing.T) {
+	assert.Equal(t, exitCodeAuthentication, resourceFailure{status: http.StatusUnauthorized}.exitCode())
+	assert.Equal(t, exitCodePermissionDenied, resourceFailure{status: http.StatusForbidden}.exitCode())
+	assert.Equal(t, exitCodeRateLimited, resourceFailure{status: http.StatusTooManyRequests}.exitCode())
+	assert.Equal(t, exitCodeUnsupported, resourceFailure{err: errUnsupportedResource}.exitCode())
+	assert.Equal(t, exitCodeError, resourceFailure{status: http.StatusInternalServerError, err: errors.New("500")}.exitCode())
+}
+
+func TestRunFailuresExitCode(t *testing.T) {
+	var f runFailures
+	f.add("cloudflare_ruleset", resourceFailure{resourceType: "cloudflare_ruleset", status: http.StatusUnauthorized, err: errors.New("unauthorized")})
+	f.add("cloudflare_page_rule", resourceFailure{resourceType: "cloudflare_page_rule", status: http.StatusUnauthorized, err: errors.New("unauthorized")})
+	assert.Equal(t, exitCodeAuthentication, f.exitCode(), "every failure is of the same kind")
+
+	f.add("cloudflare_list", resourceFailure{resourceType: "cloudflare_list", status: http.StatusForbidden, err: errors.New("forbidden")})
+	assert.Equal(t, exitCodePartialFailure, f.exitCode(), "missing permissions are told apart from rejected credentials")
+
+	f = runFailures{}
+	f.add("cloudflare_ruleset", resourceFailure{resourceType: "cloudflare_ruleset", status: http.StatusUnauthorized, err: errors.New("unauthorized")})
+
+	f.add("cloudflare_list", errUnsupportedResource)
+	assert.Equal(t, exitCodePartialFailure, f.exitCode())
+
+	f = runFailures{}
+	f.add("cloudflare_list", errors.New("500 Internal Server Error"))
+	assert.Equal(t, exitCodePartialFailure, f.exitCode())
+
+	f = runFailures{}
+	f.add("cloudflare_example", errUnsupportedResource)
+	assert.Equal(t, exitCodeUnsupported, f.exitCode())
+}
+
+func TestConfigureErrorReport(t *testing.T) {
+	defer func(format string) { errorFormat = format }(errorFormat)
+	defer func(report *errorReport) { exitReport = report }(exitReport)
+
+	var output bytes.Buffer
+	newLogger := func() (*logrus.Logger, *int) {
+		logger := logrus.New()
+		logger.Out = io.Discard
+		code := -1
+		logger.ExitFunc = func(c int) { code = c }
+		require.NoError(t, configureErrorReport(logger, func() io.Writer { return &output }))
+		return logger, &code
+	}
+
+	errorFormat = errorFormatText
+	logger, code := newLogger()
+	logger.Fatal("boom")
+	assert.Equal(t, exitCodeError, *code)
+	assert.Empty(t, output.String())
+
+	errorFormat = errorFormatJSON
+	logger, code = newLogger()
+	logger.Fatal("boom")
+	assert.Equal(t, exitCodeError, *code)
+	assert.JSONEq(t, `{"kind":"error","exit_code":1,"message":"boom"}`, output.String())
+
+	output.Reset()
+	var failures runFailures
+	failures.add("cloudflare_ruleset", resourceFailure{resourceType: "cloudflare_ruleset", endpoint: "GET /zones/0da42c8d2132a9ddaf714f9e7c920711/rulesets", status: http.StatusForbidden, err: errors.New("forbidden")})
+	exitReport = &errorReport{Message: "failed to generate 1 resource type(s) or resource(s)", Failures: failures.summary()}
+	logger.Exit(failures.exitCode())
+	assert.Equal(t, exitCodePermissionDenied, *code)
+	assert.JSONEq(t, `{
+		"kind": "permission_denied",
+		"exit_code": 8,
+		"message": "failed to generate 1 resource type(s) or resource(s)",
+		"failures": [{
+			"resource_type": "cloudflare_ruleset",
+			"endpoint": "GET /zones/0da42c8d2132a9ddaf714f9e7c920711/rulesets",
+			"status": 403,
+			"kind": "permission_denied",
+			"error": "forbidden"
+		}]
+	}`, output.String())
+
+	output.Reset()
+	logger.Exit(0)
+	assert.Empty(t, output.String(), "successful runs aren't reported")
+
+	errorFormat = "yaml"
+	assert.EqualError(t, configureErrorReport(logrus.New(), nil), `unsupported --error-format "yaml"`)
+}
+
+func TestCatchExitCode(t *testing.T) {
+	err := catchExit(func() { exitWith(exitCodeScopeMissing, errScopeMissing) })
+	assert.EqualError(t, err, errScopeMissing.Error())
+	assert.Equal(t, exitCodeScopeMissing, exitCodeOf(err))
+
+	err = catchExit(func() { log.Exit(exitCodeDrift) })
+	assert.EqualError(t, err, "exited with code 2")
+	assert.Equal(t, exitCodeDrift, exitCodeOf(err))
+
+	assert.NoError(t, catchExit(func() { log.Exit(0) }))
+	assert.Equal(t, exitCodeError, exitCodeOf(errors.New("failed")))
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	cfv0 "github.com/cloudflare/cloudflare-go"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+var (
+	// failFast aborts the run on the first resource type that fails rather
+	// than continuing with the others.
+	failFast bool
+
+	// stubFailures writes a commented-out stub in the output for each
+	// resource, or resource type, that failed.
+	stubFailures bool
+)
+
+// resourceFailure is a resource type, or a single resource of it, that
+// couldn't be generated along with the API request that failed, if any.
+type resourceFailure struct {
+	resourceType string
+	// name is the name the resource would have been generated with, which is
+	// empty when the whole resource type failed.
+	name     string
+	id       string
+	endpoint string
+	status   int
+	err      error
+}
+
+// newResourceFailure returns the failure of the resource type, taking the
+// endpoint and status from the error where it came from the API.
+func newResourceFailure(resourceType string, err error) resourceFailure {
+	failure := resourceFailure{resourceType: resourceType, err: err}
+
+	var apierr *cloudflare.Error
+	var v0err *cfv0.Error
+	var converr *terraforming.ConversionError
+	switch {
+	case errors.As(err, &converr):
+		failure.endpoint = converr.Endpoint
+	case errors.As(err, &apierr):
+		failure.status = apierr.StatusCode
+		if apierr.Request != nil {
+			failure.endpoint = apierr.Request.Method + " " + apierr.Request.URL.String()
+		}
+	case errors.As(err, &v0err):
+		failure.status = v0err.StatusCode
+	}
+
+	return failure
+}
+
+// address returns the resource type, or the address of the resource.
+func (f resourceFailure) address() string {
+	if f.name == "" {
+		return f.resourceType
+	}
+
+	return f.resourceType + "." + f.name
+}
+
+// message returns the cause of the failure, using the messages of API errors
+// rather than the whole response.
+func (f resourceFailure) message() string {
+	var apierr *cloudflare.Error
+	if !errors.As(f.err, &apierr) {
+		return f.err.Error()
+	}
+
+	var messages []string
+	for _, e := range apierr.Errors {
+		messages = append(messages, fmt.Sprintf("%s (%d)", e.Message, e.Code))
+	}
+	if len(messages) == 0 {
+		return http.StatusText(apierr.StatusCode)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Error describes the failure along with the ID, endpoint and status where
+// they're known, such as "cloudflare_ruleset (GET https://..., HTTP 403
+// Forbidden): Authentication error (10000)".
+func (f resourceFailure) Error() string {
+	var details []string
+	if f.id != "" {
+		details = append(details, "ID "+f.id)
+	}
+	if f.endpoint != "" {
+		details = append(details, f.endpoint)
+	}
+	if f.status != 0 {
+		details = append(details, fmt.Sprintf("HTTP %d %s", f.status, http.StatusText(f.status)))
+	}
+
+	if len(details) == 0 {
+		return fmt.Sprintf("%s: %s", f.address(), f.message())
+	}
+
+	return fmt.Sprintf("%s (%s): %s", f.address(), strings.Join(details, ", "), f.message())
+}
+
+func (f resourceFailure) Unwrap() error {
+	return f.err
+}
+
+// runFailures collects the resource types and resources that failed so they
+// can be reported once the others have been generated.
+type runFailures struct {
+	// action is what the run was doing, such as "import", for the messages.
+	// Defaults to "generate".
+	action string
+
+	mu       sync.Mutex
+	failures []resourceFailure
+}
+
+// verb returns what failed to be done to the resources.
+func (f *runFailures) verb() string {
+	if f.action == "" {
+		return "generate"
+	}
+
+	return f.action
+}
+
+// add records that the resource type failed, exiting immediately with
+// --fail-fast.
+func (f *runFailures) add(resourceType string, err error) {
+	var failure resourceFailure
+	if !errors.As(err, &failure) {
+		failure = newResourceFailure(resourceType, err)
+	}
+	f.record(failure)
+}
+
+// record records the failure, exiting immediately with --fail-fast.
+func (f *runFailures) record(failure resourceFailure) {
+	if failFast {
+		exitWithf(failure.exitCode(), "failed to %s %s", f.verb(), failure)
+	}
+	if failure.name != "" {
+		log.Errorf("failed to %s %s, continuing with the remaining resources", f.verb(), failure)
+	} else {
+		log.Errorf("failed to %s %s, continuing with the remaining resource types", f.verb(), failure)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, failure)
+}
+
+// any returns whether any resource types failed.
+func (f *runFailures) any() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.failures) > 0
+}
+
+// resourceTypes returns the failures of whole resource types.
+func (f *runFailures) resourceTypes() []resourceFailure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var failures []resourceFailure
+	for _, failure := range f.failures {
+		if failure.name == "" {
+			failures = append(failures, failure)
+		}
+	}
+
+	return failures
+}
+
+// report outputs the resource types and resources that failed, returning
+// whether there were any.
+func (f *runFailures) report(w io.Writer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.failures) == 0 {
+		return false
+	}
+
+	var resourceTypes, resources []resourceFailure
+	for _, failure := range f.failures {
+		if failure.name == "" {
+			resourceTypes = append(resourceTypes, failure)
+		} else {
+			resources = append(resources, failure)
+		}
+	}
+
+	if len(resourceTypes) > 0 {
+		_, _ = fmt.Fprintf(w, "\nFailed to %s %d resource type(s):\n", f.verb(), len(resourceTypes))
+		for _, failure := range resourceTypes {
+			_, _ = fmt.Fprintf(w, "  %s\n", failure)
+		}
+	}
+	if len(resources) > 0 {
+		_, _ = fmt.Fprintf(w, "\nFailed to %s %d resource(s):\n", f.verb(), len(resources))
+		for _, failure := range resources {
+			_, _ = fmt.Fprintf(w, "  %s\n", failure)
+		}
+	}
+
+	return true
+}
+
+// exit reports the failures and exits unsuccessfully if there were any, with
+// the code for their kind. An interrupted run is left to exit with its own
+// code.
+func (f *runFailures) exit(w io.Writer) {
+	if f.report(w) && !interrupted.Load() {
+		failures := f.summary()
+		exitReport = &errorReport{
+			Message:  fmt.Sprintf("failed to %s %d resource type(s) or resource(s)", f.verb(), len(failures)),
+			Failures: failures,
+		}
+		log.Exit(f.exitCode())
+	}
+}
+
+// summary returns the failures as they're reported to webhooks and in the
+// JSON error report.
+func (f *runFailures) summary() []runFailure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var failures []runFailure
+	for _, failure := range f.failures {
+		summary := runFailure{
+			ResourceType: failure.resourceType,
+			ID:           failure.id,
+			Endpoint:     failure.endpoint,
+			Status:       failure.status,
+			Kind:         exitKinds[failure.exitCode()],
+			Error:        failure.message(),
+		}
+		if failure.name != "" {
+			summary.Address = failure.address()
+		}
+		failures = append(failures, summary)
+	}
+
+	return failures
+}
+
+// convertResource calls convert, returning a panic as an error so that a
+// resource with an unexpected API response doesn't stop the others from being
+// generated. Exits from log.Fatal are left to unwind.
+func convertResource(convert func()) error {
+	var exit *exitCode
+	err := terraforming.ConvertResource(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				code, ok := r.(exitCode)
+				if !ok {
+					panic(r)
+				}
+				exit = &code
+			}
+		}()
+		convert()
+	})
+	if exit != nil {
+		panic(*exit)
+	}
+
+	return err
+}
+
+// appendFailureStub appends a commented-out stub noting the failure, with an
+// empty block for a resource so that it can be written by hand.
+func appendFailureStub(body *hclwrite.Body, failure resourceFailure) {
+	lines := strings.Split("Failed to generate "+failure.Error(), "\n")
+	if failure.name != "" {
+		lines = append(lines, fmt.Sprintf("resource %q %q {", failure.resourceType, failure.name), "}")
+	}
+	appendComment(body, lines...)
+}
+
+// buildFailureStubs returns a file of stubs for the resource types that
+// failed, or nil if none did.
+func buildFailureStubs(failures *runFailures) *hclwrite.File {
+	resourceTypes := failures.resourceTypes()
+	if len(resourceTypes) == 0 {
+		return nil
+	}
+
+	f := hclwrite.NewEmptyFile()
+	for _, failure := range resourceTypes {
+		appendFailureStub(f.Body(), failure)
+		f.Body().AppendNewline()
+	}
+
+	return f
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	cfv0 "github.com/cloudflare/cloudflare-go"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/shared"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFailuresReport(t *testing.T) {
+	var f runFailures
+
+	var output bytes.Buffer
+	assert.False(t, f.report(&output))
+	assert.Empty(t, output.String())
+
+	f.add("cloudflare_access_application", errors.New("403 Forbidden"))
+	f.add("cloudflare_waiting_room", errors.New("500 Internal Server Error"))
+
+	assert.True(t, f.report(&output))
+	assert.Equal(t, heredoc.Doc(`
+
+		Failed to generate 2 resource type(s):
+		  cloudflare_access_application: 403 Forbidden
+		  cloudflare_waiting_room: 500 Internal Server Error
+	`), output.String())
+}
+
+func TestRunFailuresReportResources(t *testing.T) {
+	var f runFailures
+	f.add("cloudflare_waiting_room", errors.New("500 Internal Server Error"))
+	f.record(resourceFailure{resourceType: "cloudflare_dns_record", name: "www", id: "023e105f4ecef8ad9ca31a8372d0c353", err: errors.New("unexpected type")})
+
+	var output bytes.Buffer
+	assert.True(t, f.report(&output))
+	assert.Equal(t, heredoc.Doc(`
+
+		Failed to generate 1 resource type(s):
+		  cloudflare_waiting_room: 500 Internal Server Error
+
+		Failed to generate 1 resource(s):
+		  cloudflare_dns_record.www (ID 023e105f4ecef8ad9ca31a8372d0c353): unexpected type
+	`), output.String())
+	assert.Len(t, f.resourceTypes(), 1)
+}
+
+func TestRunFailuresReportAction(t *testing.T) {
+	f := runFailures{action: "import"}
+	f.record(resourceFailure{resourceType: "cloudflare_waiting_room_event", name: "sale", id: "25756b2dfe6e378a06b033b670413757", err: errors.New(`failed to build the import ID: no value found for "waiting_room_id"`)})
+
+	var output bytes.Buffer
+	assert.True(t, f.report(&output))
+	assert.Equal(t, heredoc.Doc(`
+
+		Failed to import 1 resource(s):
+		  cloudflare_waiting_room_event.sale (ID 25756b2dfe6e378a06b033b670413757): failed to build the import ID: no value found for "waiting_room_id"
+	`), output.String())
+}
+
+func TestNewResourceFailure(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "https://api.cloudflare.com/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/rulesets", nil)
+	apierr := &cloudflare.Error{
+		StatusCode: http.StatusForbidden,
+		Request:    request,
+		Errors:     []shared.ErrorData{{Code: 10000, Message: "Authentication error"}},
+	}
+	failure := newResourceFailure("cloudflare_ruleset", fmt.Errorf("failed to fetch API endpoint: %w", apierr))
+	assert.Equal(t, "GET https://api.cloudflare.com/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/rulesets", failure.endpoint)
+	assert.Equal(t, http.StatusForbidden, failure.status)
+	assert.EqualError(t, failure, "cloudflare_ruleset (GET https://api.cloudflare.com/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/rulesets, HTTP 403 Forbidden): Authentication error (10000)")
+	assert.ErrorIs(t, failure, apierr)
+
+	apierr.Errors = nil
+	assert.Equal(t, "Forbidden", newResourceFailure("cloudflare_ruleset", apierr).message())
+
+	v0err := cfv0.NewRequestError(&cfv0.Error{StatusCode: http.StatusBadRequest, ErrorMessages: []string{"bad request"}})
+	failure = newResourceFailure("cloudflare_page_rule", v0err)
+	assert.Equal(t, http.StatusBadRequest, failure.status)
+	assert.Empty(t, failure.endpoint)
+	assert.Equal(t, "cloudflare_page_rule (HTTP 400 Bad Request): "+v0err.Error(), failure.Error())
+
+	assert.EqualError(t, newResourceFailure("cloudflare_list", errors.New("forbidden")), "cloudflare_list: forbidden")
+}
+
+func TestConvertResource(t *testing.T) {
+	assert.NoError(t, convertResource(func() {}))
+
+	err := convertResource(func() {
+		var data interface{} = "www"
+		_ = data.(map[string]interface{})
+	})
+	assert.ErrorContains(t, err, "failed to convert the API response: interface conversion")
+
+	assert.PanicsWithValue(t, exitCode(1), func() {
+		_ = convertResource(func() { panic(exitCode(1)) })
+	}, "exits are left to unwind")
+}
+
+func TestAppendFailureStub(t *testing.T) {
+	f := hclwrite.NewEmptyFile()
+	appendFailureStub(f.Body(), resourceFailure{resourceType: "cloudflare_dns_record", name: "www", id: "023e105f4ecef8ad9ca31a8372d0c353", err: errors.New("unexpected type")})
+	assert.Equal(t, heredoc.Doc(`
+		# Failed to generate cloudflare_dns_record.www (ID 023e105f4ecef8ad9ca31a8372d0c353): unexpected type
+		# resource "cloudflare_dns_record" "www" {
+		# }
+	`), string(f.Bytes()))
+
+	var failures runFailures
+	assert.Nil(t, buildFailureStubs(&failures))
+
+	failures.add("cloudflare_ruleset", errors.New("forbidden\nmissing permission"))
+	failures.record(resourceFailure{resourceType: "cloudflare_dns_record", name: "www", err: errors.New("unexpected type")})
+	stubs := buildFailureStubs(&failures)
+	require.NotNil(t, stubs)
+	assert.Equal(t, heredoc.Doc(`
+		# Failed to generate cloudflare_ruleset: forbidden
+		# missing permission
+
+	`), string(stubs.Bytes()), "only resource types are stubbed at the end")
+}
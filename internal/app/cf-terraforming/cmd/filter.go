@@ -0,0 +1,469 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/itchyny/gojq"
+)
+
+var (
+	// filterExpressions are the expressions passed with --filter which every
+	// resource must match.
+	filterExpressions []string
+
+	// excludeIDs and excludeNameRegex skip resources which are managed
+	// elsewhere.
+	excludeIDs       []string
+	excludeNameRegex string
+
+	// modifiedSince skips resources which haven't been created or modified
+	// since the timestamp.
+	modifiedSince string
+
+	// dnsRecordTypes and dnsNameGlob select a subset of the DNS records in a
+	// zone.
+	dnsRecordTypes []string
+	dnsNameGlob    string
+
+	// excludeResourceTypes are globs of resource types removed from
+	// --resource-type.
+	excludeResourceTypes []string
+
+	// responseFilter is a jq expression applied to each list of resources
+	// returned by the API.
+	responseFilter string
+)
+
+// dnsRecordResourceTypes are the resources selected by --dns-record-type and
+// --dns-name-glob.
+var dnsRecordResourceTypes = []string{"cloudflare_dns_record", "cloudflare_record"}
+
+// excludeTypes removes the resource types matching any of the globs from the
+// comma separated list of types.
+func excludeTypes(types string, globs []string) (string, error) {
+	var remaining []string
+	for _, t := range strings.Split(types, ",") {
+		excluded := false
+		for _, glob := range globs {
+			matched, err := path.Match(glob, t)
+			if err != nil {
+				return "", fmt.Errorf("invalid --exclude-resource-type %q: %w", glob, err)
+			}
+			if matched {
+				excluded = true
+				break
+			}
+		}
+
+		if excluded {
+			log.Debugf("excluding resource type %s", t)
+			continue
+		}
+		remaining = append(remaining, t)
+	}
+
+	return strings.Join(remaining, ","), nil
+}
+
+// resourceTimestampAttributes are the attributes in API responses recording
+// when a resource was created or last modified.
+var resourceTimestampAttributes = []string{"created_on", "modified_on", "created_at", "updated_at", "last_modified"}
+
+// resourceSelection is the subset of resources requested with --filter,
+// --exclude-id, --exclude-name-regex, --modified-since, --response-filter and
+// the DNS record filters.
+type resourceSelection struct {
+	response       *gojq.Code
+	filters        []resourceFilter
+	exclusions     resourceExclusions
+	modifiedSince  time.Time
+	dnsRecordTypes map[string]bool
+	dnsNameGlob    string
+}
+
+// parseResourceSelection reads the flags selecting which resources to
+// include.
+func parseResourceSelection() resourceSelection {
+	selection := resourceSelection{
+		filters:        parseResourceFilters(),
+		exclusions:     parseResourceExclusions(),
+		dnsRecordTypes: make(map[string]bool, len(dnsRecordTypes)),
+		dnsNameGlob:    strings.ToLower(dnsNameGlob),
+	}
+
+	for _, recordType := range dnsRecordTypes {
+		selection.dnsRecordTypes[strings.ToUpper(recordType)] = true
+	}
+	if _, err := path.Match(selection.dnsNameGlob, ""); err != nil {
+		log.Fatalf("invalid --dns-name-glob %q: %s", dnsNameGlob, err)
+	}
+
+	if responseFilter != "" {
+		var err error
+		if selection.response, err = compileResponseFilter(responseFilter); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if modifiedSince != "" {
+		var err error
+		if selection.modifiedSince, err = parseModifiedSince(modifiedSince); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return selection
+}
+
+// empty reports whether every resource is selected.
+func (s resourceSelection) empty() bool {
+	return s.response == nil && len(s.filters) == 0 && len(s.exclusions.ids) == 0 && s.exclusions.name == nil && s.modifiedSince.IsZero() &&
+		len(s.dnsRecordTypes) == 0 && s.dnsNameGlob == "" && !hasDefaultFilters()
+}
+
+// apply returns the selected resources from the API response.
+func (s resourceSelection) apply(resourceType string, data []interface{}) []interface{} {
+	if s.response != nil {
+		var err error
+		if data, err = runResponseFilter(s.response, data); err != nil {
+			log.Fatalf("failed to apply --response-filter to %s: %s", resourceType, err)
+		}
+	}
+	data = filterResources(s.filters, data)
+	data = filterResources(defaultsFor(resourceType).filters, data)
+	data = excludeResources(s.exclusions, resourceType, data)
+	if slices.Contains(dnsRecordResourceTypes, resourceType) {
+		data = filterDNSRecords(s.dnsRecordTypes, s.dnsNameGlob, data)
+	}
+	return modifiedSinceResources(s.modifiedSince, resourceType, data)
+}
+
+var filterExpressionSyntax = regexp.MustCompile(`^\s*([A-Za-z0-9_.]+)\s*(==|!=|=~|!~)\s*(.+?)\s*$`)
+
+// resourceFilter compares an attribute of the API response against a value.
+// Nested attributes are separated by a `.`, such as `meta.auto_added`.
+type resourceFilter struct {
+	path     []string
+	operator string
+	value    interface{}
+	pattern  *regexp.Regexp
+}
+
+// parseResourceFilter parses an expression such as `type == "CNAME"` or
+// `name =~ "^prod-"`. Values are written as JSON.
+func parseResourceFilter(expression string) (resourceFilter, error) {
+	matches := filterExpressionSyntax.FindStringSubmatch(expression)
+	if matches == nil {
+		return resourceFilter{}, fmt.Errorf("invalid filter %q, expected <attribute> <==|!=|=~|!~> <value>", expression)
+	}
+
+	filter := resourceFilter{path: strings.Split(matches[1], "."), operator: matches[2]}
+	if err := json.Unmarshal([]byte(matches[3]), &filter.value); err != nil {
+		return resourceFilter{}, fmt.Errorf("invalid value in filter %q, strings must be quoted: %w", expression, err)
+	}
+
+	if filter.operator == "=~" || filter.operator == "!~" {
+		pattern, ok := filter.value.(string)
+		if !ok {
+			return resourceFilter{}, fmt.Errorf("filter %q must match against a string", expression)
+		}
+
+		var err error
+		if filter.pattern, err = regexp.Compile(pattern); err != nil {
+			return resourceFilter{}, fmt.Errorf("invalid pattern in filter %q: %w", expression, err)
+		}
+	}
+
+	return filter, nil
+}
+
+// matches reports whether the resource matches the filter. Missing attributes
+// are treated as null.
+func (f resourceFilter) matches(data map[string]interface{}) bool {
+	var value interface{} = data
+	for _, key := range f.path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = object[key]
+	}
+
+	switch f.operator {
+	case "==":
+		return reflect.DeepEqual(value, f.value)
+	case "!=":
+		return !reflect.DeepEqual(value, f.value)
+	case "=~":
+		return value != nil && f.pattern.MatchString(fmt.Sprint(value))
+	default:
+		return value == nil || !f.pattern.MatchString(fmt.Sprint(value))
+	}
+}
+
+// parseResourceFilters parses all of the expressions passed with --filter.
+func parseResourceFilters() []resourceFilter {
+	filters := make([]resourceFilter, 0, len(filterExpressions))
+	for _, expression := range filterExpressions {
+		filter, err := parseResourceFilter(expression)
+		if err != nil {
+			log.Fatal(err)
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
+
+// filterResources returns the resources from the API response that match
+// every filter.
+func filterResources(filters []resourceFilter, data []interface{}) []interface{} {
+	if len(filters) == 0 {
+		return data
+	}
+
+	filtered := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		resource, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		matched := true
+		for _, filter := range filters {
+			if !filter.matches(resource) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
+
+// resourceExclusions skips resources by their ID or name.
+type resourceExclusions struct {
+	ids  map[string]bool
+	name *regexp.Regexp
+}
+
+// parseResourceExclusions reads the exclusions passed with --exclude-id and
+// --exclude-name-regex.
+func parseResourceExclusions() resourceExclusions {
+	exclusions := resourceExclusions{ids: make(map[string]bool, len(excludeIDs))}
+	for _, id := range excludeIDs {
+		exclusions.ids[id] = true
+	}
+
+	if excludeNameRegex != "" {
+		var err error
+		if exclusions.name, err = regexp.Compile(excludeNameRegex); err != nil {
+			log.Fatalf("invalid --exclude-name-regex: %s", err)
+		}
+	}
+
+	return exclusions
+}
+
+// excludes reports whether the resource should be skipped. Names are matched
+// against each of the attributes used to name the resource.
+func (e resourceExclusions) excludes(resourceType string, data map[string]interface{}) bool {
+	if e.ids[resourceAPIID(resourceType, data)] {
+		return true
+	}
+	if e.name == nil {
+		return false
+	}
+
+	for _, attribute := range terraforming.NameAttributes(resourceType) {
+		if value, ok := data[attribute].(string); ok && e.name.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// excludeResources removes the excluded resources from the API response.
+func excludeResources(exclusions resourceExclusions, resourceType string, data []interface{}) []interface{} {
+	if len(exclusions.ids) == 0 && exclusions.name == nil {
+		return data
+	}
+
+	remaining := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		if resource, ok := item.(map[string]interface{}); ok && exclusions.excludes(resourceType, resource) {
+			log.Debugf("excluding %s %s", resourceType, resourceAPIID(resourceType, resource))
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+
+	return remaining
+}
+
+// parseModifiedSince parses either an RFC 3339 timestamp or a date.
+func parseModifiedSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --modified-since %q, expected an RFC 3339 timestamp or a date such as 2024-01-31", value)
+}
+
+// lastModified returns the latest timestamp recorded on the resource,
+// reporting false if the API doesn't expose any.
+func lastModified(data map[string]interface{}) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for _, attribute := range resourceTimestampAttributes {
+		value, ok := data[attribute].(string)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			continue
+		}
+		if !found || t.After(latest) {
+			latest, found = t, true
+		}
+	}
+
+	return latest, found
+}
+
+// modifiedSinceResources removes the resources which haven't changed since
+// the timestamp. Resources without any timestamps are always kept as there's
+// no way to tell whether they've changed.
+func modifiedSinceResources(since time.Time, resourceType string, data []interface{}) []interface{} {
+	if since.IsZero() {
+		return data
+	}
+
+	remaining := make([]interface{}, 0, len(data))
+	missing := 0
+	for _, item := range data {
+		resource, ok := item.(map[string]interface{})
+		if !ok {
+			remaining = append(remaining, item)
+			continue
+		}
+
+		modified, ok := lastModified(resource)
+		if !ok {
+			missing++
+			remaining = append(remaining, item)
+			continue
+		}
+		if !modified.Before(since) {
+			remaining = append(remaining, item)
+		}
+	}
+
+	if missing > 0 {
+		log.Warnf("%d %s resources don't have a timestamp and are included regardless of --modified-since", missing, resourceType)
+	}
+
+	return remaining
+}
+
+// filterDNSRecords returns the DNS records with one of the types whose name
+// matches the glob, such as `*.staging.example.com`.
+func filterDNSRecords(types map[string]bool, glob string, data []interface{}) []interface{} {
+	if len(types) == 0 && glob == "" {
+		return data
+	}
+
+	remaining := make([]interface{}, 0, len(data))
+	for _, item := range data {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		recordType, _ := record["type"].(string)
+		if len(types) > 0 && !types[strings.ToUpper(recordType)] {
+			continue
+		}
+
+		name, _ := record["name"].(string)
+		if glob != "" {
+			if matched, _ := path.Match(glob, strings.TrimSuffix(strings.ToLower(name), ".")); !matched {
+				continue
+			}
+		}
+
+		remaining = append(remaining, item)
+	}
+
+	return remaining
+}
+
+// compileResponseFilter parses the jq expression passed with
+// --response-filter.
+func compileResponseFilter(expression string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --response-filter: %w", err)
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --response-filter: %w", err)
+	}
+
+	return code, nil
+}
+
+// runResponseFilter runs the jq expression with the list of resources as its
+// input. Either a single array or a stream of resources can be output, so both
+// `map(select(.proxied))` and `.[] | select(.proxied)` work.
+func runResponseFilter(code *gojq.Code, data []interface{}) ([]interface{}, error) {
+	var outputs []interface{}
+	iter := code.Run(data)
+	for {
+		output, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := output.(error); ok {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+
+	if len(outputs) == 1 {
+		if resources, ok := outputs[0].([]interface{}); ok {
+			return resources, nil
+		}
+	}
+
+	resources := make([]interface{}, 0, len(outputs))
+	for _, output := range outputs {
+		if output == nil {
+			continue
+		}
+		if _, ok := output.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("expected resources to be objects but got %T", output)
+		}
+		resources = append(resources, output)
+	}
+
+	return resources, nil
+}
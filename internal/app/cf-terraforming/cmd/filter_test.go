@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceFilter(t *testing.T) {
+	tests := map[string]struct {
+		expression string
+		err        string
+	}{
+		"equals string":   {expression: `type == "CNAME"`},
+		"equals bool":     {expression: `proxied==true`},
+		"not equals":      {expression: `meta.auto_added != false`},
+		"matches":         {expression: `name =~ "^prod-"`},
+		"unquoted string": {expression: `type == CNAME`, err: "strings must be quoted"},
+		"bad operator":    {expression: `type > "CNAME"`, err: "invalid filter"},
+		"bad pattern":     {expression: `name =~ "("`, err: "invalid pattern"},
+		"pattern number":  {expression: `ttl =~ 1`, err: "must match against a string"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseResourceFilter(tc.expression)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestFilterResources(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"name": "prod-api.example.com", "type": "CNAME", "proxied": true, "ttl": float64(1)},
+		map[string]interface{}{"name": "prod-db.example.com", "type": "A", "proxied": false, "ttl": float64(300)},
+		map[string]interface{}{"name": "staging.example.com", "type": "CNAME", "proxied": true, "meta": map[string]interface{}{"auto_added": true}},
+	}
+
+	tests := map[string]struct {
+		expressions []string
+		expected    []string
+	}{
+		"none":           {expected: []string{"prod-api.example.com", "prod-db.example.com", "staging.example.com"}},
+		"equals":         {expressions: []string{`type == "CNAME"`}, expected: []string{"prod-api.example.com", "staging.example.com"}},
+		"number":         {expressions: []string{`ttl == 300`}, expected: []string{"prod-db.example.com"}},
+		"matches":        {expressions: []string{`name =~ "^prod-"`}, expected: []string{"prod-api.example.com", "prod-db.example.com"}},
+		"does not match": {expressions: []string{`name !~ "^prod-"`}, expected: []string{"staging.example.com"}},
+		"nested":         {expressions: []string{`meta.auto_added != true`}, expected: []string{"prod-api.example.com", "prod-db.example.com"}},
+		"all must match": {expressions: []string{`proxied == true`, `name =~ "^prod-"`}, expected: []string{"prod-api.example.com"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var filters []resourceFilter
+			for _, expression := range tc.expressions {
+				filter, err := parseResourceFilter(expression)
+				require.NoError(t, err)
+				filters = append(filters, filter)
+			}
+
+			var names []string
+			for _, item := range filterResources(filters, data) {
+				names = append(names, item.(map[string]interface{})["name"].(string))
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestExcludeResources(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"id": "372e67954025e0ba6aaa6d586b9e0b59", "name": "example.com", "type": "A"},
+		map[string]interface{}{"id": "372e67954025e0ba6aaa6d586b9e0b5a", "name": "www.example.com", "type": "CNAME"},
+		map[string]interface{}{"id": "372e67954025e0ba6aaa6d586b9e0b5b", "name": "api.example.com", "type": "A"},
+	}
+
+	tests := map[string]struct {
+		exclusions resourceExclusions
+		expected   []string
+	}{
+		"none": {
+			expected: []string{"example.com", "www.example.com", "api.example.com"},
+		},
+		"id": {
+			exclusions: resourceExclusions{ids: map[string]bool{"372e67954025e0ba6aaa6d586b9e0b5a": true}},
+			expected:   []string{"example.com", "api.example.com"},
+		},
+		"name": {
+			exclusions: resourceExclusions{name: regexp.MustCompile(`^example\.com$`)},
+			expected:   []string{"www.example.com", "api.example.com"},
+		},
+		"id and name": {
+			exclusions: resourceExclusions{
+				ids:  map[string]bool{"372e67954025e0ba6aaa6d586b9e0b5b": true},
+				name: regexp.MustCompile(`^www\.`),
+			},
+			expected: []string{"example.com"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			for _, item := range excludeResources(tc.exclusions, "cloudflare_dns_record", data) {
+				names = append(names, item.(map[string]interface{})["name"].(string))
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestModifiedSinceResources(t *testing.T) {
+	since, err := parseModifiedSince("2024-03-01")
+	require.NoError(t, err)
+
+	data := []interface{}{
+		map[string]interface{}{"name": "old.example.com", "created_on": "2023-05-01T10:00:00.123456Z", "modified_on": "2023-06-01T10:00:00Z"},
+		map[string]interface{}{"name": "modified.example.com", "created_on": "2023-05-01T10:00:00Z", "modified_on": "2024-03-02T08:30:00.5Z"},
+		map[string]interface{}{"name": "new.example.com", "created_on": "2024-03-01T00:00:00Z"},
+		map[string]interface{}{"name": "unknown.example.com"},
+	}
+
+	var names []string
+	for _, item := range modifiedSinceResources(since, "cloudflare_dns_record", data) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"modified.example.com", "new.example.com", "unknown.example.com"}, names)
+}
+
+func TestParseModifiedSince(t *testing.T) {
+	parsed, err := parseModifiedSince("2024-03-01T12:00:00+01:00")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01T11:00:00Z", parsed.UTC().Format(time.RFC3339))
+
+	_, err = parseModifiedSince("yesterday")
+	assert.ErrorContains(t, err, "invalid --modified-since")
+}
+
+func TestFilterDNSRecords(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"name": "example.com", "type": "A"},
+		map[string]interface{}{"name": "api.staging.example.com", "type": "CNAME"},
+		map[string]interface{}{"name": "web.staging.example.com", "type": "AAAA"},
+		map[string]interface{}{"name": "staging.example.com", "type": "TXT"},
+	}
+
+	tests := map[string]struct {
+		types    []string
+		glob     string
+		expected []string
+	}{
+		"none":           {expected: []string{"example.com", "api.staging.example.com", "web.staging.example.com", "staging.example.com"}},
+		"types":          {types: []string{"a", "CNAME"}, expected: []string{"example.com", "api.staging.example.com"}},
+		"glob":           {glob: "*.staging.example.com", expected: []string{"api.staging.example.com", "web.staging.example.com"}},
+		"types and glob": {types: []string{"CNAME", "TXT"}, glob: "*staging.example.com", expected: []string{"api.staging.example.com", "staging.example.com"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			types := make(map[string]bool)
+			for _, recordType := range tc.types {
+				types[strings.ToUpper(recordType)] = true
+			}
+
+			var names []string
+			for _, item := range filterDNSRecords(types, tc.glob, data) {
+				names = append(names, item.(map[string]interface{})["name"].(string))
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestExcludeTypes(t *testing.T) {
+	types, err := excludeTypes("cloudflare_dns_record,cloudflare_zone_setting,cloudflare_zero_trust_list,cloudflare_zero_trust_gateway_policy", []string{"cloudflare_zone_setting", "cloudflare_zero_trust_*"})
+	require.NoError(t, err)
+	assert.Equal(t, "cloudflare_dns_record", types)
+
+	types, err = excludeTypes("cloudflare_dns_record", []string{"cloudflare_dns_record"})
+	require.NoError(t, err)
+	assert.Equal(t, "", types)
+
+	_, err = excludeTypes("cloudflare_dns_record", []string{"cloudflare_["})
+	assert.ErrorContains(t, err, "invalid --exclude-resource-type")
+}
+
+func TestRunResponseFilter(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"name": "a.example.com", "ttl": float64(1), "tags": []interface{}{"team:web"}},
+		map[string]interface{}{"name": "b.example.com", "ttl": float64(3600), "tags": []interface{}{"team:web"}},
+		map[string]interface{}{"name": "c.example.com", "ttl": float64(3600), "tags": []interface{}{}},
+	}
+
+	tests := map[string]struct {
+		expression string
+		expected   []string
+		err        string
+	}{
+		"array":      {expression: `map(select(.ttl > 300))`, expected: []string{"b.example.com", "c.example.com"}},
+		"stream":     {expression: `.[] | select(.tags | index("team:web"))`, expected: []string{"a.example.com", "b.example.com"}},
+		"empty":      {expression: `empty`, expected: []string{}},
+		"not object": {expression: `.[].name`, err: "expected resources to be objects"},
+		"error":      {expression: `error("boom")`, err: "boom"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := compileResponseFilter(tc.expression)
+			require.NoError(t, err)
+
+			resources, err := runResponseFilter(code, data)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, item := range resources {
+				names = append(names, item.(map[string]interface{})["name"].(string))
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	_, err := compileResponseFilter(`map(`)
+	assert.ErrorContains(t, err, "invalid --response-filter")
+}
@@ -3,14 +3,17 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
 	cfv0 "github.com/cloudflare/cloudflare-go"
 
 	"github.com/hashicorp/go-version"
@@ -18,13 +21,14 @@ import (
 	"github.com/hashicorp/hc-install/releases"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	"github.com/zclconf/go-cty/cty"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -34,7 +38,7 @@ var (
 	generateCmd = &cobra.Command{
 		Use:    "generate",
 		Short:  "Fetch resources from the Cloudflare API and generate the respective Terraform stanzas",
-		Run:    generateResources(),
+		Run:    generateResources(nil),
 		PreRun: sharedPreRun,
 	}
 
@@ -43,14 +47,71 @@ var (
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
+
+	generateCmd.Flags().StringVar(&gitRepoDir, "git-repo", "", "Clone of a GitHub or GitLab repository to commit the generated configuration to on a new branch and open a pull request for, using GITHUB_TOKEN or GITLAB_TOKEN")
+	generateCmd.Flags().StringVar(&gitFile, "git-file", "cloudflare.tf", "Path within --git-repo the generated configuration is written to, unless writing a module into --output-dir")
+	generateCmd.Flags().StringVar(&gitBranch, "git-branch", "", "Branch of --git-repo to commit to. Defaults to cf-terraforming/ followed by the time")
+	generateCmd.Flags().StringVar(&gitRemote, "git-remote", "origin", "Remote of --git-repo the branch is pushed to")
+	generateCmd.Flags().StringVar(&gitProvider, "git-provider", "", "Whether --git-remote is hosted by GitHub or GitLab, detected from its host by default. Supported values: github, gitlab")
 }
 
-func generateResources() func(cmd *cobra.Command, args []string) {
+// generateResources returns the command generating the resources, setting
+// providerVersion, where given, to the version of the provider they're
+// generated for.
+func generateResources(providerVersion *string) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
 		if resourceType == "" {
 			log.Fatal("you must define a resource type to generate")
 		}
 
+		if accountID == "" && zoneID == "" {
+			exitWith(exitCodeScopeMissing, errScopeMissing)
+		}
+
+		if outputFormat != outputFormatHCL && outputFormat != outputFormatJSON {
+			log.Fatalf("unsupported output format %q", outputFormat)
+		}
+
+		if graphFormat != "" && graphFormat != graphFormatDOT {
+			log.Fatalf("unsupported graph format %q", graphFormat)
+		}
+
+		if generationHeader && outputFormat == outputFormatJSON {
+			log.Fatal("--header can't be used with the JSON output format as it doesn't support comments")
+		}
+
+		if stubFailures && outputFormat == outputFormatJSON {
+			log.Fatal("--stub-failures can't be used with the JSON output format as it doesn't support comments")
+		}
+
+		if postHookMode != postHookModeFile && postHookMode != postHookModeResource {
+			log.Fatalf("unsupported --post-hook-mode %q", postHookMode)
+		}
+
+		if summaryFormat != "" && summaryFormat != summaryFormatMarkdown {
+			log.Fatalf("unsupported summary format %q", summaryFormat)
+		}
+
+		if dnsForEach != "" && dnsForEach != dnsForEachLocals && dnsForEach != dnsForEachYAML {
+			log.Fatalf("unsupported --dns-for-each mode %q", dnsForEach)
+		}
+
+		if err := validateNestedSyntax(); err != nil {
+			log.Fatal(err)
+		}
+
+		if (dnsForEach != "" || collapseSimilar) && writesModule() {
+			log.Fatal("--dns-for-each and --collapse-similar cannot be used with --as-module or --terragrunt as outputs are only written for individual resources")
+		}
+
+		failures := &runFailures{}
+		// extractedSecrets holds every secret extracted during the run so that
+		// the example tfvars file covers all generated resource types.
+		var extractedSecrets []secretVariable
+		defer exitIfInterrupted()
+		defer failures.exit(cmd.ErrOrStderr())
+		defer notifyInterrupt()()
+
 		zoneID = viper.GetString("zone")
 		accountID = viper.GetString("account")
 		workingDir := viper.GetString("terraform-install-path")
@@ -59,55 +120,169 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 			log.Fatalf("Could not find or install Terraform: %v", err)
 		}
 
-		// Setup and configure Terraform to operate in the temporary directory where
-		// the provider is already configured.
-		log.WithFields(logrus.Fields{
-			"directory": workingDir,
-		}).Debug("initializing Terraform")
-		tf, err := tfexec.NewTerraform(workingDir, execPath)
+		var (
+			s                     *tfjson.ProviderSchema
+			providerVersionString string
+		)
+		if providerSchemaSource != "" {
+			s, providerVersionString, err = loadProviderSchemaSource(context.Background(), execPath, providerSchemaSource)
+		} else {
+			s, providerVersionString, err = workingDirProviderSchema(context.Background(), workingDir, execPath)
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
+		if providerVersion != nil {
+			*providerVersion = providerVersionString
+		}
 
-		_, providerVersion, err := tf.Version(context.Background(), true)
-		if err != nil {
-			log.Fatalf("failed to retrieve terraform and provider version information: %s", err)
+		var generated []*hclwrite.File
+
+		// The generated files are committed once everything else has been
+		// written, so this is deferred first.
+		var publisher *gitPublisher
+		if gitRepoDir != "" {
+			if publisher, err = newGitPublisher(runContext, writesModule() || cmd.Flags().Changed("output-dir")); err != nil {
+				log.Fatal(err)
+			}
+			defer func() { publishGenerated(publisher, generated, failures) }()
 		}
 
-		var registryPath string
-		for provider := range providerVersion {
-			if strings.Contains(provider, "/cloudflare/cloudflare") {
-				registryPath = provider
-				continue
+		// Terragrunt configurations are written to a directory per account or
+		// zone with the identifier passed in as an input.
+		if useTerragrunt {
+			defer func(dir string) { outputDir = dir }(outputDir)
+
+			out, err := prepareTerragruntOutput(providerVersionString)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer out.Close()
+
+			cmd.SetOut(out)
+			defer cmd.SetOut(nil)
+		} else if asModule {
+			out, err := createMainFile()
+			if err != nil {
+				log.Fatal(err)
 			}
+			defer out.Close()
+
+			cmd.SetOut(out)
+			defer cmd.SetOut(nil)
+		} else if publisher != nil {
+			out, err := publisher.createOutputFile()
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer out.Close()
+
+			cmd.SetOut(out)
+			defer cmd.SetOut(nil)
 		}
 
-		detectedVersion, ok := providerVersion[registryPath]
-		if !ok {
-			log.WithFields(logrus.Fields{
-				"available_registries": providerVersion,
-			}).Fatal("failed to find registry")
+		var moduleResources []string
+		if asModule {
+			defer func() {
+				if len(outputResourceTypes) > 0 {
+					moduleResources = slices.DeleteFunc(moduleResources, func(address string) bool {
+						resourceType, _, _ := strings.Cut(address, ".")
+						return !matchesOutputResourceType(resourceType)
+					})
+				}
+				if err := writeConfigFile(outputsFilename, buildOutputsFile(moduleResources)); err != nil {
+					log.Fatal(err)
+				}
+			}()
 		}
 
-		providerVersionString := detectedVersion.String()
-		log.WithFields(logrus.Fields{
-			"version":  providerVersionString,
-			"registry": registryPath,
-		}).Info("detected provider")
+		if useVariables || writesModule() {
+			if err := writeVariableFiles(scopeVariables()); err != nil {
+				log.Fatal(err)
+			}
+		}
 
-		log.Debug("reading Terraform schema")
-		ps, err := tf.ProvidersSchema(context.Background())
-		if err != nil {
-			log.Fatal("failed to read provider schema", err)
+		// The JSON syntax requires a single object per file so all resource
+		// types are collected and output together once generation finishes.
+		jsonOutput := hclwrite.NewEmptyFile()
+		if outputFormat == outputFormatJSON {
+			defer writeJSONOutput(cmd, jsonOutput)
 		}
 
-		s := ps.Schemas[registryPath]
-		if s == nil {
-			log.Fatal("failed to detect provider installation")
+		// Runs of generate for other commands, such as watch, notify
+		// themselves.
+		if cmd.Name() == "generate" {
+			defer func() { notifyRun(runContext, newRunSummary(cmd.Name(), generated, failures)) }()
+		}
+		selection := parseResourceSelection()
+		references := loadMappingOrEmpty()
+		previous := make(referenceIndex)
+		if movedFrom != "" {
+			if previous, err = loadPreviousAddresses(movedFrom); err != nil {
+				log.Fatal(err)
+			}
+		}
+		var overrides overridesFile
+		if overridesFilePath != "" {
+			if overrides, err = loadOverrides(overridesFilePath); err != nil {
+				log.Fatal(err)
+			}
+		}
+		set, err := parseSetOverrides(setOverrides)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ignored, err := parseIgnoreChanges(ignoreChanges)
+		if err != nil {
+			log.Fatal(err)
+		}
+		excluded := defaultOverrides()
+		emitters, err := parseEmitTemplates(emitTemplates)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var emitted []emittedResource
+		var snapshotted []apiSnapshotResource
+		if mappingFilePath != "" {
+			defer func() {
+				if err := writeMapping(mappingFilePath, references); err != nil {
+					log.Fatal(err)
+				}
+			}()
 		}
 
 		resources := strings.Split(resourceType, ",")
+
+		var progress *checkpoint
+		if checkpointPath != "" {
+			if progress, err = loadCheckpoint(checkpointPath); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		// Resources are fetched before any are generated so that independent
+		// resource types can be fetched concurrently.
+		var responses map[string]apiResponse
+		if strings.HasPrefix(providerVersionString, "5") {
+			responses = fetchAPIResponses(resolveAPIRequests(resources), progress)
+		}
+
+		// The span for each resource type is ended when the next starts, as
+		// there are many ways on to the next resource type.
+		var span trace.Span
+	RESOURCES:
 		for _, resourceType := range resources {
+			if span != nil {
+				span.End()
+			}
+			_, span = startSpan(runContext, "generate", resourceType)
+
+			if interrupted.Load() {
+				log.Warnf("skipping %s and any remaining resource types as the run was interrupted", resourceType)
+				break
+			}
+
+			start := time.Now()
 			r := s.ResourceSchemas[resourceType]
 			log.WithFields(logrus.Fields{
 				"resource": resourceType,
@@ -127,65 +302,39 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 			// to ensure the same compatability using the generated SDK.
 			useOldSDK := resourceType == "cloudflare_ruleset"
 
-			if strings.HasPrefix(providerVersionString, "5") && !useOldSDK {
-				resourceIDsMap := make(map[string][]string)
-				if isSupportedPathParam(resources, resourceType) {
-					resourceIDsMap = getResourceMappings()
-
-					ids, ok := resourceIDsMap[resourceType]
-					if ok && len(ids) == 0 {
-						log.Fatalf("No resource IDs defined in Terraform for resource %s", resourceType)
+			if converter, ok := converterFor(resourceType); ok {
+				data, ok := progress.get(resourceType)
+				if !ok {
+					if data, err = fetchConverted(runContext, converter); err != nil {
+						failures.add(resourceType, err)
+						continue
 					}
-				}
-
-				if resourceToEndpoint[resourceType]["list"] == "" && resourceToEndpoint[resourceType]["get"] == "" {
-					log.WithFields(logrus.Fields{
-						"resource": resourceType,
-					}).Warn("Unsupported terraform v5 provider resource")
-					continue
-				}
-
-				var result *http.Response
-
-				// by default, we want to use the `list` operation however, there are times
-				// when resources exist only as `get` operations but contain multiple
-				// resources.
-				endpoint := resourceToEndpoint[resourceType]["list"]
-				if endpoint == "" {
-					endpoint = resourceToEndpoint[resourceType]["get"]
-				}
-
-				// if we encounter a combined endpoint, we need to rewrite to use the correct
-				// endpoint depending on what parameters are being provided.
-				if strings.Contains(endpoint, "{accounts_or_zones}") {
-					if accountID != "" {
-						endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/accounts/{account_id}/", 1)
-					} else {
-						endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/zones/{zone_id}/", 1)
+					if err := progress.record(resourceType, data); err != nil {
+						log.Fatal(err)
 					}
 				}
+				jsonStructData, resourceCount = data, len(data)
+				goto GEN_HCL
+			}
 
-				// replace the URL placeholders with the actual values we have.
-				placeholderReplacer := strings.NewReplacer("{account_id}", accountID, "{zone_id}", zoneID)
-				endpoint = placeholderReplacer.Replace(endpoint)
-
-				pathParams, ok := resourceIDsMap[resourceType]
-				if ok && len(pathParams) > 0 {
-					endpoints := replacePathParams(pathParams, endpoint, resourceType)
-					jsonStructData, err = getAPIResponse(result, pathParams, endpoints...)
-					if err != nil {
-						log.Infof("error getting API response for resource %s: %s", resourceType, err)
-						continue
+			if strings.HasPrefix(providerVersionString, "5") && !useOldSDK {
+				response, ok := responses[resourceType]
+				if !ok {
+					if resourceEndpoint(resourceType) == "" {
+						failures.add(resourceType, errUnsupportedResource)
 					}
-					resourceCount = len(jsonStructData)
-				} else {
-					jsonStructData, err = getAPIResponse(result, pathParams, endpoint)
-					if err != nil {
-						log.Infof("error getting API response for resource %s: %s", resourceType, err)
+					continue
+				}
+				if response.err != nil {
+					if !terraforming.IsNoResourcesError(response.err) {
+						failures.add(resourceType, response.err)
 						continue
 					}
-					resourceCount = len(jsonStructData)
+					log.Infof("error getting API response for resource %s: %s", resourceType, response.err)
+					continue
 				}
+				jsonStructData = response.data
+				resourceCount = len(jsonStructData)
 
 				// Transform flat structure to nested body structure for cloudflare_filter
 				if resourceType == "cloudflare_filter" {
@@ -224,6 +373,11 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				}
 
 			} else {
+				if data, ok := progress.get(resourceType); ok {
+					jsonStructData, resourceCount = data, len(data)
+					goto GEN_HCL
+				}
+
 				var identifier *cfv0.ResourceContainer
 				if accountID != "" {
 					identifier = cfv0.AccountIdentifier(accountID)
@@ -235,100 +389,116 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_access_application":
 					jsonPayload, _, err := apiV0.ListAccessApplications(context.Background(), identifier, cfv0.ListAccessApplicationsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_access_group":
 					jsonPayload, _, err := apiV0.ListAccessGroups(context.Background(), identifier, cfv0.ListAccessGroupsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_access_identity_provider":
 					jsonPayload, _, err := apiV0.ListAccessIdentityProviders(context.Background(), identifier, cfv0.ListAccessIdentityProvidersParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_access_service_token":
 					jsonPayload, _, err := apiV0.ListAccessServiceTokens(context.Background(), identifier, cfv0.ListAccessServiceTokensParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_access_mutual_tls_certificate":
 					jsonPayload, _, err := apiV0.ListAccessMutualTLSCertificates(context.Background(), identifier, cfv0.ListAccessMutualTLSCertificatesParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_access_rule":
 					if accountID != "" {
 						jsonPayload, err := apiV0.ListAccountAccessRules(context.Background(), accountID, cfv0.AccessRule{}, 1)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 
 						resourceCount = len(jsonPayload.Result)
 						m, _ := json.Marshal(jsonPayload.Result)
 						err = json.Unmarshal(m, &jsonStructData)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 					} else {
 						jsonPayload, err := apiV0.ListZoneAccessRules(context.Background(), zoneID, cfv0.AccessRule{}, 1)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 
 						resourceCount = len(jsonPayload.Result)
 						m, _ := json.Marshal(jsonPayload.Result)
 						err = json.Unmarshal(m, &jsonStructData)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 					}
 				case "cloudflare_account_member":
 					jsonPayload, _, err := apiV0.AccountMembers(context.Background(), accountID, cfv0.PaginationOptions{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// remap email and role_ids into the right structure.
@@ -345,13 +515,15 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 
 					argoSmartRouting, err := apiV0.ArgoSmartRouting(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					jsonPayload = append(jsonPayload, argoSmartRouting)
 
 					argoTieredCaching, err := apiV0.ArgoTieredCaching(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					jsonPayload = append(jsonPayload, argoTieredCaching)
 
@@ -360,7 +532,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i, b := range jsonStructData {
@@ -371,7 +544,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					jsonPayload := []cfv0.APIShield{}
 					apiShieldConfig, _, err := apiV0.GetAPIShieldConfiguration(context.Background(), identifier)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					// the response can contain an empty APIShield struct. Verify we have data before we attempt to do anything
 					jsonPayload = append(jsonPayload, apiShieldConfig)
@@ -380,7 +554,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// this is only every a 1:1 so we can just verify if the 0th element has they key we expect
@@ -396,7 +571,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					for {
 						res, err := apiV0.ListUserAgentRules(context.Background(), zoneID, page)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 
 						jsonPayload = append(jsonPayload, res.Result...)
@@ -412,12 +588,14 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err := json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_bot_management":
 					botManagement, err := apiV0.GetBotManagement(context.Background(), identifier)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					var jsonPayload []cfv0.BotManagement
 					jsonPayload = append(jsonPayload, botManagement)
@@ -426,21 +604,24 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					jsonStructData[0].(map[string]interface{})["id"] = zoneID
 				case "cloudflare_byo_ip_prefix":
 					jsonPayload, err := apiV0.ListPrefixes(context.Background(), accountID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// remap ID to prefix_id and advertised to advertisement on the JSON payloads.
@@ -456,7 +637,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_certificate_pack":
 					jsonPayload, err := apiV0.ListCertificatePacks(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					var customerManagedCertificates []cfv0.CertificatePack
@@ -471,34 +653,39 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_custom_pages":
 					if accountID != "" {
 						acc := cfv0.CustomPageOptions{AccountID: accountID}
 						jsonPayload, err := apiV0.CustomPages(context.Background(), &acc)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 
 						resourceCount = len(jsonPayload)
 						m, _ := json.Marshal(jsonPayload)
 						err = json.Unmarshal(m, &jsonStructData)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 					} else {
 						zo := cfv0.CustomPageOptions{ZoneID: zoneID}
 						jsonPayload, err := apiV0.CustomPages(context.Background(), &zo)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 
 						resourceCount = len(jsonPayload)
 						m, _ := json.Marshal(jsonPayload)
 						err = json.Unmarshal(m, &jsonStructData)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 					}
 
@@ -517,7 +704,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					var jsonPayload []cfv0.CustomHostnameFallbackOrigin
 					apiCall, err := apiV0.CustomHostnameFallbackOrigin(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					if apiCall.Origin != "" {
@@ -528,7 +716,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -538,26 +727,30 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_filter":
 					jsonPayload, _, err := apiV0.Filters(context.Background(), identifier, cfv0.FilterListParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_firewall_rule":
 					jsonPayload, _, err := apiV0.FirewallRules(context.Background(), identifier, cfv0.FirewallRuleListParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// remap Filter.ID to `filter_id` on the JSON payloads.
@@ -567,14 +760,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_custom_hostname":
 					jsonPayload, _, err := apiV0.CustomHostnames(context.Background(), zoneID, 1, cfv0.CustomHostname{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -583,40 +778,47 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_custom_ssl":
 					jsonPayload, err := apiV0.ListSSL(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_healthcheck":
 					jsonPayload, err := apiV0.Healthchecks(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_list":
 					jsonPayload, err := apiV0.ListLists(context.Background(), identifier, cfv0.ListListsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					m, err := json.Marshal(jsonPayload)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					if err = json.Unmarshal(m, &jsonStructData); err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 
@@ -626,7 +828,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 
 						listItems, err := apiV0.ListListItems(context.Background(), identifier, cfv0.ListListItemsParams{ID: listID})
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						items := make([]interface{}, 0)
 
@@ -689,14 +892,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_load_balancer":
 					jsonPayload, err := apiV0.ListLoadBalancers(context.Background(), identifier, cfv0.ListLoadBalancerParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -733,14 +938,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_load_balancer_pool":
 					jsonPayload, err := apiV0.ListLoadBalancerPools(context.Background(), identifier, cfv0.ListLoadBalancerPoolParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -754,26 +961,30 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_load_balancer_monitor":
 					jsonPayload, err := apiV0.ListLoadBalancerMonitors(context.Background(), identifier, cfv0.ListLoadBalancerMonitorParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_logpush_job":
 					jsonPayload, err := apiV0.ListLogpushJobs(context.Background(), identifier, cfv0.ListLogpushJobsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -787,7 +998,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					// only grab the enabled headers
 					jsonPayload, err := apiV0.ListZoneManagedHeaders(context.Background(), cfv0.ResourceIdentifier(zoneID), cfv0.ListManagedHeadersParams{Status: "enabled"})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					var managedHeaders []cfv0.ManagedHeaders
@@ -797,7 +1009,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(managedHeaders)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -806,26 +1019,30 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_origin_ca_certificate":
 					jsonPayload, err := apiV0.ListOriginCACertificates(context.Background(), cfv0.ListOriginCertificatesParams{ZoneID: zoneID})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_page_rule":
 					jsonPayload, err := apiV0.ListPageRules(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -872,14 +1089,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_rate_limit":
 					jsonPayload, err := apiV0.ListAllRateLimits(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -902,26 +1121,23 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_record":
 					jsonPayload, _, err := apiV0.ListDNSRecords(context.Background(), identifier, cfv0.ListDNSRecordsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
-					zone, _ := apiV0.ZoneDetails(context.Background(), identifier.Identifier)
-
 					for i := 0; i < resourceCount; i++ {
 						// Drop the proxiable values as they are not usable
 						jsonStructData[i].(map[string]interface{})["proxiable"] = nil
 						jsonStructData[i].(map[string]interface{})["value"] = nil
 
-						if jsonStructData[i].(map[string]interface{})["name"].(string) != zone.Name {
-							jsonStructData[i].(map[string]interface{})["name"] = strings.ReplaceAll(jsonStructData[i].(map[string]interface{})["name"].(string), "."+zone.Name, "")
-						}
 						if _, hasData := jsonStructData[i].(map[string]interface{})["data"]; hasData {
 							jsonStructData[i].(map[string]interface{})["content"] = nil
 						}
@@ -929,7 +1145,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_ruleset":
 					jsonPayload, err := apiV0.ListRulesets(context.Background(), identifier, cfv0.ListRulesetsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					var nonManagedRules []cfv0.Ruleset
@@ -983,7 +1200,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					if strings.HasPrefix(providerVersionString, "5") {
@@ -995,7 +1213,10 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 								}
 							}
 						}
-						processCustomCasesV5(&jsonStructData, resourceType, "")
+						if jsonStructData, err = terraforming.ProcessResponse(runContext, api, resourceType, "", jsonStructData, fetchOptions()); err != nil {
+							failures.add(resourceType, err)
+							continue RESOURCES
+						}
 						goto GEN_HCL
 					}
 
@@ -1083,19 +1304,22 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_spectrum_application":
 					jsonPayload, err := apiV0.SpectrumApplications(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_teams_list":
 					jsonPayload, _, err := apiV0.ListTeamsLists(context.Background(), identifier, cfv0.ListTeamListsParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					// get items for the lists and add it the specific list struct
 					for i, TeamsList := range jsonPayload {
@@ -1104,18 +1328,21 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 							identifier,
 							cfv0.ListTeamsListItemsParams{ListID: TeamsList.ID})
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						TeamsList.Items = append(TeamsList.Items, items_struct...)
 						jsonPayload[i] = TeamsList
 					}
 					m, err := json.Marshal(jsonPayload)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 
@@ -1133,35 +1360,41 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_teams_location":
 					jsonPayload, _, err := apiV0.TeamsLocations(context.Background(), accountID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_teams_proxy_endpoint":
 					jsonPayload, _, err := apiV0.TeamsProxyEndpoints(context.Background(), accountID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_teams_rule":
 					jsonPayload, err := apiV0.TeamsRules(context.Background(), accountID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// flatten add_headers of rule setting to a string
@@ -1198,14 +1431,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 							},
 						})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -1215,7 +1450,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 							jsonStructData[i].(map[string]interface{})["id"].(string),
 						)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						jsonStructData[i].(map[string]interface{})["secret"] = secret
 						jsonStructData[i].(map[string]interface{})["account_id"] = accountID
@@ -1225,14 +1461,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_turnstile_widget":
 					jsonPayload, _, err := apiV0.ListTurnstileWidgets(context.Background(), identifier, cfv0.ListTurnstileWidgetParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -1247,7 +1485,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_url_normalization_settings":
 					jsonPayload, err := apiV0.URLNormalizationSettings(context.Background(), &cfv0.ResourceContainer{Identifier: zoneID, Level: cfv0.ZoneRouteLevel})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					var newJsonPayload []interface{}
 					newJsonPayload = append(newJsonPayload, jsonPayload)
@@ -1255,7 +1494,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(newJsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// this is only every a 1:1 so we can just verify if the 0th element has they key we expect
@@ -1263,13 +1503,15 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_waiting_room":
 					jsonPayload, err := apiV0.ListWaitingRooms(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					for i := 0; i < resourceCount; i++ {
@@ -1280,21 +1522,25 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_waiting_room_event":
 					waitingRooms, err := apiV0.ListWaitingRooms(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					for i := 0; i < len(waitingRooms); i++ {
 						roomEvents, err := apiV0.ListWaitingRoomEvents(context.Background(), zoneID, waitingRooms[i].ID)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						m, err := json.Marshal(roomEvents)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						jsonRoomEvents := []interface{}{}
 						err = json.Unmarshal(m, &jsonRoomEvents)
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						for i := 0; i < len(jsonRoomEvents); i++ {
 							jsonRoomEvents[i].(map[string]interface{})["waiting_room_id"] = waitingRooms[i].ID
@@ -1305,7 +1551,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_waiting_room_rules":
 					waitingRooms, err := apiV0.ListWaitingRooms(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					roomRules := []struct {
 						ID            string                 `json:"id"`
@@ -1317,7 +1564,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 							WaitingRoomID: waitingRooms[i].ID,
 						})
 						if err != nil {
-							log.Fatal(err)
+							failures.add(resourceType, err)
+							continue RESOURCES
 						}
 						roomRules = append(roomRules, struct {
 							ID            string                 `json:"id"`
@@ -1332,16 +1580,19 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					resourceCount = len(roomRules)
 					m, err := json.Marshal(roomRules)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_waiting_room_settings":
 					waitingRoomSettings, err := apiV0.GetWaitingRoomSettings(context.Background(), cfv0.ZoneIdentifier(zoneID))
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					var jsonPayload []cfv0.WaitingRoomSettings
 					jsonPayload = append(jsonPayload, waitingRoomSettings)
@@ -1350,7 +1601,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					jsonStructData[0].(map[string]interface{})["id"] = zoneID
@@ -1358,24 +1610,28 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_workers_kv_namespace":
 					jsonPayload, _, err := apiV0.ListWorkersKVNamespaces(context.Background(), identifier, cfv0.ListWorkersKVNamespacesParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_worker_route":
 					jsonPayload, err := apiV0.ListWorkerRoutes(context.Background(), identifier, cfv0.ListWorkerRoutesParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					resourceCount = len(jsonPayload.Routes)
 					m, _ := json.Marshal(jsonPayload.Routes)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// remap "script_name" to the "script" value.
@@ -1385,14 +1641,16 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_zone":
 					jsonPayload, err := apiV0.ListZones(context.Background())
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					// - remap "zone" to the "name" value
@@ -1410,26 +1668,30 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_zone_lockdown":
 					jsonPayload, _, err := apiV0.ListZoneLockdowns(context.Background(), identifier, cfv0.LockdownListParams{})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = len(jsonPayload)
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 				case "cloudflare_zone_settings_override":
 					jsonPayload, err := apiV0.ZoneSettings(context.Background(), zoneID)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					resourceCount = 1
 					m, _ := json.Marshal(jsonPayload.Result)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					zoneSettingsStruct := make(map[string]interface{})
@@ -1466,7 +1728,8 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 				case "cloudflare_tiered_cache":
 					tieredCache, err := apiV0.GetTieredCache(context.Background(), &cfv0.ResourceContainer{Identifier: zoneID})
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 					var jsonPayload []cfv0.TieredCache
 					jsonPayload = append(jsonPayload, tieredCache)
@@ -1475,14 +1738,15 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					m, _ := json.Marshal(jsonPayload)
 					err = json.Unmarshal(m, &jsonStructData)
 					if err != nil {
-						log.Fatal(err)
+						failures.add(resourceType, err)
+						continue RESOURCES
 					}
 
 					jsonStructData[0].(map[string]interface{})["id"] = zoneID
 					jsonStructData[0].(map[string]interface{})["cache_type"] = tieredCache.Type.String()
 				default:
-					fmt.Fprintf(cmd.OutOrStderr(), "%q is not yet supported for automatic generation", resourceType)
-					return
+					failures.add(resourceType, errUnsupportedResource)
+					continue
 				}
 			}
 			log.WithFields(logrus.Fields{
@@ -1491,14 +1755,72 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 			}).Debug("generating resource output")
 
 		GEN_HCL:
+			if _, ok := progress.get(resourceType); !ok && (useOldSDK || !strings.HasPrefix(providerVersionString, "5")) {
+				if err := progress.record(resourceType, jsonStructData[:min(resourceCount, len(jsonStructData))]); err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if !selection.empty() {
+				jsonStructData = selection.apply(resourceType, jsonStructData[:min(resourceCount, len(jsonStructData))])
+				resourceCount = len(jsonStructData)
+			}
+
+			// The zone is only left out of the names once the records have been
+			// selected so that --dns-name globs match the full name, as with v5.
+			if resourceType == "cloudflare_record" && !strings.HasPrefix(providerVersionString, "5") {
+				zone, _ := apiV0.ZoneDetails(context.Background(), zoneID)
+				trimZoneName(jsonStructData[:min(resourceCount, len(jsonStructData))], zone.Name)
+			}
+
+			if r != nil && r.Block != nil {
+				for _, item := range jsonStructData[:min(resourceCount, len(jsonStructData))] {
+					if data, ok := item.(map[string]interface{}); ok {
+						sortUnorderedLists(r.Block, data)
+					}
+				}
+			}
+
 			// If we don't have any resources to generate, just bail out early.
 			if resourceCount == 0 {
-				fmt.Fprintf(cmd.OutOrStderr(), "no resources of type %q found to generate", resourceType)
-				return
+				fmt.Fprintf(cmd.ErrOrStderr(), "no resources of type %q found to generate\n", resourceType)
+				continue
+			}
+
+			if zoneFilePath != "" && slices.Contains(dnsRecordResourceTypes, resourceType) {
+				if err := writeZoneFile(jsonStructData[:min(resourceCount, len(jsonStructData))]); err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if modernize && !strings.HasPrefix(providerVersionString, "5") {
+				var modern *hclwrite.File
+				modern, jsonStructData = modernizeResources(resourceType, jsonStructData)
+				if modern != nil {
+					moduleResources = append(moduleResources, resourceAddresses(modern)...)
+					generated = append(generated, modern)
+				}
+
+				resourceCount = len(jsonStructData)
+				if resourceCount == 0 {
+					continue
+				}
 			}
 
 			f := hclwrite.NewEmptyFile()
 			rootBody := f.Body()
+			var secrets []secretVariable
+			namer := newResourceNamer(references[resourceType])
+			var moves []resourceMove
+			// Resources which fail to convert are reported along with the
+			// endpoint they were listed from where it's known.
+			var listEndpoint string
+			if _, ok := converterFor(resourceType); !ok && !useOldSDK && strings.HasPrefix(providerVersionString, "5") {
+				if endpoint := resourceEndpoint(resourceType); endpoint != "" && !strings.Contains(endpoint, "{") {
+					listEndpoint = "GET " + endpoint
+				}
+			}
+			failed := 0
 			for i := 0; i < resourceCount; i++ {
 				structData := jsonStructData[i].(map[string]interface{})
 
@@ -1509,99 +1831,309 @@ func generateResources() func(cmd *cobra.Command, args []string) {
 					} else {
 						resourceID = fmt.Sprintf("terraform_managed_resource_%d", i)
 					}
+				} else if name, ok := references.name(resourceType, structData); ok {
+					resourceID = name
 				} else {
-					id := ""
-					switch structData["id"].(type) {
-					case float64:
-						id = fmt.Sprintf("%f", structData["id"].(float64))
-					default:
-						if structData["id"] == nil {
-							if accountID != "" {
-								id = accountID
-							}
-
-							if zoneID != "" {
-								id = zoneID
-							}
-						} else {
-							id = structData["id"].(string)
-						}
-					}
-					resourceID = fmt.Sprintf("terraform_managed_resource_%s_%d", id, i)
+					resourceID = namer.name(resourceType, structData)
 				}
-				resource := rootBody.AppendNewBlock("resource", []string{resourceType, resourceID}).Body()
 
 				if r == nil {
 					log.Fatalf("failed to find %q in the initialized provider schema", resourceType)
 				}
 
-				sortedBlockAttributes := make([]string, 0, len(r.Block.Attributes))
-				for k := range r.Block.Attributes {
-					sortedBlockAttributes = append(sortedBlockAttributes, k)
+				// The resource is converted from a copy of its API response so
+				// that a resource which fails to convert leaves nothing behind.
+				block := hclwrite.NewBlock("resource", []string{resourceType, resourceID})
+				resource := block.Body()
+				data := copyJSONValue(structData).(map[string]interface{})
+				var resourceSecrets []secretVariable
+				err := convertResource(func() {
+					if extractSecrets {
+						resourceSecrets = extractSensitiveAttributes(resourceType, resourceID, r.Block, data)
+					}
+
+					terraforming.WriteResource(resource, resourceType, r.Block, data, hclOptions())
+				})
+				if err != nil {
+					failure := resourceFailure{resourceType: resourceType, name: resourceID, id: resourceAPIID(resourceType, structData), endpoint: listEndpoint, err: err}
+					failures.record(failure)
+					failed++
+					if stubFailures {
+						appendFailureStub(rootBody, failure)
+						rootBody.AppendNewline()
+					}
+					continue
 				}
-				sort.Strings(sortedBlockAttributes)
 
-				// Block attributes are for any attributes where assignment is involved.
-				for _, attrName := range sortedBlockAttributes {
-					// Don't bother outputting the ID for the resource as that is only for
-					// internal use (such as importing state).
-					if attrName == "id" {
-						continue
-					}
+				rootBody.AppendBlock(block)
+				references.add(resourceType, resourceID, structData)
+				if generatedResourceObserver != nil {
+					generatedResourceObserver(resourceType, resourceID, structData)
+				}
+				if len(emitters) > 0 {
+					emitted = append(emitted, newEmittedResource(resourceType, resourceID, structData))
+				}
+				if snapshotDir != "" {
+					snapshotted = append(snapshotted, newAPISnapshotResource(resourceType, resourceID, r.Block, structData))
+				}
+				if move, ok := previous.move(resourceType, resourceID, structData); ok {
+					moves = append(moves, move)
+				}
+				secrets = append(secrets, resourceSecrets...)
+				f.Body().AppendNewline()
+			}
 
-					// No need to output computed attributes that are also not
-					// optional.
-					if r.Block.Attributes[attrName].Computed && !r.Block.Attributes[attrName].Optional {
-						continue
-					}
-					if attrName == "account_id" && accountID != "" {
-						writeAttrLine(attrName, accountID, "", resource)
-						continue
-					}
+			if len(secrets) > 0 {
+				appendSecretVariables(rootBody, secrets)
+				extractedSecrets = append(extractedSecrets, secrets...)
+				if err := writeSecretsExampleFile(extractedSecrets); err != nil {
+					log.Fatal(err)
+				}
+			}
 
-					if attrName == "zone_id" && zoneID != "" && accountID == "" {
-						writeAttrLine(attrName, zoneID, "", resource)
-						continue
-					}
+			postProcess(f, resourceType)
+			if converter, ok := converterFor(resourceType); ok && converter.PostProcess != nil {
+				converter.PostProcess(f)
+			}
+			excluded.apply(f)
+			overrides.apply(f)
+			set.apply(f)
+			if dnsForEach != "" && slices.Contains(dnsRecordResourceTypes, resourceType) {
+				collapsed, names, err := collapseDNSRecords(f, resourceType, dnsForEach)
+				if err != nil {
+					log.Fatal(err)
+				}
+				f, rootBody = collapsed, collapsed.Body()
 
-					ty := r.Block.Attributes[attrName].AttributeType
-					switch {
-					case ty.IsPrimitiveType():
-						switch ty {
-						case cty.String, cty.Bool, cty.Number:
-							writeAttrLine(attrName, structData[attrName], "", resource)
-							delete(structData, attrName)
-						default:
-							log.Debugf("unexpected primitive type %q", ty.FriendlyName())
-						}
-					case ty.IsCollectionType():
-						switch {
-						case ty.IsListType(), ty.IsSetType(), ty.IsMapType():
-							writeAttrLine(attrName, structData[attrName], "", resource)
-							delete(structData, attrName)
-						default:
-							log.Debugf("unexpected collection type %q", ty.FriendlyName())
-						}
-					case ty.IsTupleType():
-						fmt.Printf("tuple found. attrName %s\n", attrName)
-					case ty.IsObjectType():
-						fmt.Printf("object found. attrName %s\n", attrName)
-					default:
-						log.Debugf("attribute %q has not been generated", attrName)
+				for i, move := range moves {
+					if name := strings.TrimPrefix(move.to, resourceType+"."); names[name] {
+						moves[i].to = collapsedDNSRecordAddress(resourceType, name)
 					}
 				}
+			}
+			for _, move := range moves {
+				appendMovedBlock(rootBody, move)
+				rootBody.AppendNewline()
+			}
+			moduleResources = append(moduleResources, resourceAddresses(f)...)
+			generated = append(generated, f)
+			runProgress.converted(resourceType, resourceCount-failed)
+			metrics.addResourceType(resourceType, resourceCount-failed, time.Since(start))
+			log.WithFields(logrus.Fields{
+				"resource": resourceType,
+				"count":    resourceCount - failed,
+				"duration": time.Since(start),
+			}).Debug("generated resource type")
+		}
+		if span != nil {
+			span.End()
+		}
 
-				processBlocks(r.Block, jsonStructData[i].(map[string]interface{}), resource, "")
-				f.Body().AppendNewline()
+		if stubFailures {
+			if stubs := buildFailureStubs(failures); stubs != nil {
+				generated = append(generated, stubs)
+			}
+		}
+
+		// Resources can only be linked once every resource type has been
+		// generated so the output is held until the end.
+		addresses := addressesByType(generated)
+		for _, f := range generated {
+			linkReferences(f, references)
+			addDependencies(f, addresses)
+		}
+
+		// Similar resources are collapsed once linked so that the map of
+		// instances contains references rather than IDs.
+		if collapseSimilar {
+			renames := make(map[string]string)
+			for i, f := range generated {
+				var collapsed map[string]string
+				generated[i], collapsed = collapseSimilarResources(f)
+				maps.Copy(renames, collapsed)
+			}
+			if len(renames) > 0 {
+				for _, f := range generated {
+					renameResourceReferences(f.Body(), renames)
+				}
+			}
+		}
+
+		for _, f := range generated {
+			overrides.addIgnoreChanges(f)
+			ignored.addIgnoreChanges(f)
+		}
+
+		if postHook != "" {
+			if generated, err = runPostHook(runContext, postHook, postHookMode, generated); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if validateOutput {
+			if problems := validateGenerated(generated, s); len(problems) > 0 {
+				for _, problem := range problems {
+					log.Error(problem)
+				}
+				log.Fatalf("the generated configuration failed validation with %d problem(s)", len(problems))
+			}
+		}
+
+		if len(outputResourceTypes) > 0 && !asModule {
+			outputs, err := buildResourceOutputs(generated)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := writeConfigFile(outputsFilename, outputs); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if generateTests {
+			if err := writeTestFile(generated); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if summaryFormat == summaryFormatMarkdown {
+			if err := writeMarkdownSummary(generated); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if len(emitters) > 0 {
+			if err := writeEmittedFiles(emitters, newEmitModel(emitted, providerVersionString)); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if snapshotDir != "" {
+			if err := writeAPISnapshot(snapshotDir, snapshotted, providerVersionString, time.Now()); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		// Every resource type has been generated so the run doesn't need to be
+		// resumed.
+		if !interrupted.Load() {
+			if err := progress.remove(); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if graphFormat == graphFormatDOT {
+			nodes, edges := resourceGraph(generated)
+			writeDOTGraph(cmd.OutOrStdout(), nodes, edges)
+			return
+		}
+
+		if generationHeader && outputFormat == outputFormatHCL {
+			n, _ := fmt.Fprint(cmd.OutOrStdout(), buildGenerationHeader(providerVersionString, time.Now()))
+			metrics.addBytesWritten(n)
+		}
+
+		for _, f := range generated {
+			if outputFormat == outputFormatJSON {
+				jsonOutput.Body().AppendUnstructuredTokens(f.BuildTokens(nil))
+				continue
 			}
 
-			postProcess(f, resourceType)
 			tfOutput := string(hclwrite.Format(f.Bytes()))
-			_, _ = fmt.Fprint(cmd.OutOrStdout(), tfOutput)
+			n, _ := fmt.Fprint(cmd.OutOrStdout(), tfOutput)
+			metrics.addBytesWritten(n)
 		}
 	}
 }
 
+// trimZoneName removes the zone from the names of the DNS records, leaving
+// the record at the apex of the zone as is.
+func trimZoneName(records []interface{}, zoneName string) {
+	if zoneName == "" {
+		return
+	}
+
+	for _, item := range records {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _ := record["name"].(string); name != zoneName {
+			record["name"] = strings.ReplaceAll(name, "."+zoneName, "")
+		}
+	}
+}
+
+// workingDirProviderSchema returns the schema and version of the Cloudflare
+// provider installed in the Terraform working directory, or of the release set
+// with --provider-version.
+func workingDirProviderSchema(ctx context.Context, workingDir, execPath string) (*tfjson.ProviderSchema, string, error) {
+	// Setup and configure Terraform to operate in the temporary directory where
+	// the provider is already configured.
+	log.WithFields(logrus.Fields{
+		"directory": workingDir,
+	}).Debug("initializing Terraform")
+	tf, err := tfexec.NewTerraform(workingDir, execPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	registryPath, providerVersionString, err := detectProvider(tf)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkWorkingDirProvider(workingDir, providerVersionString); err != nil {
+		return nil, "", err
+	}
+	log.WithFields(logrus.Fields{
+		"version":  providerVersionString,
+		"registry": registryPath,
+	}).Info("detected provider")
+
+	// Generating for another release uses its schema so that attributes
+	// added since are left out.
+	if targetsOtherProviderRelease(providerVersionString, providerVersionOverride) {
+		s, _, err := loadProviderSchema(ctx, execPath, providerVersionConstraint(providerVersionOverride))
+		return s, providerVersionOverride, err
+	}
+
+	log.Debug("reading Terraform schema")
+	ps, err := tf.ProvidersSchema(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read provider schema: %w", err)
+	}
+
+	s := ps.Schemas[registryPath]
+	if s == nil {
+		return nil, "", errors.New("failed to detect provider installation")
+	}
+
+	return s, providerVersionString, nil
+}
+
+// detectProvider returns the registry path and version of the Cloudflare
+// provider installed in the Terraform working directory.
+func detectProvider(tf *tfexec.Terraform) (string, string, error) {
+	_, providerVersion, err := tf.Version(context.Background(), true)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to retrieve terraform and provider version information: %w", err)
+	}
+
+	var registryPath string
+	for provider := range providerVersion {
+		if strings.Contains(provider, "/cloudflare/cloudflare") {
+			registryPath = provider
+			continue
+		}
+	}
+
+	detectedVersion, ok := providerVersion[registryPath]
+	if !ok {
+		return "", "", fmt.Errorf("failed to find registry, available registries: %v", providerVersion)
+	}
+
+	return registryPath, detectedVersion.String(), nil
+}
+
 func findOrInstallTerraform() (string, error) {
 	// Check if the user has provided an explicit path to the binary. This is the highest priority.
 	if execPath := viper.GetString("terraform-binary-path"); execPath != "" {
@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"fmt"
+	"bytes"
 	"net/http"
 	"os"
 	"strings"
@@ -16,77 +16,30 @@ import (
 	"github.com/dnaeon/go-vcr/cassette"
 	"github.com/dnaeon/go-vcr/recorder"
 
-	"github.com/hashicorp/hcl/v2/hclwrite"
-
 	"github.com/spf13/viper"
 
 	"github.com/stretchr/testify/assert"
 )
 
 var (
-	// listOfString is an example representation of a key where the value is a
-	// list of string values.
-	//
-	//   resource "example" "example" {
-	//     attr = [ "b", "c", "d"]
-	//   }
-	listOfString = []interface{}{"b", "c", "d"}
-
-	// configBlockOfStrings is an example of where a key is a "block" assignment
-	// in HCL.
-	//
-	//   resource "example" "example" {
-	//     attr = {
-	//       c = "d"
-	//       e = "f"
-	//     }
-	//   }
-	configBlockOfStrings = map[string]interface{}{
-		"c": "d",
-		"e": "f",
-	}
-
 	cloudflareTestZoneID    = "0da42c8d2132a9ddaf714f9e7c920711"
 	cloudflareTestAccountID = "f037e56e89293a057740de681ac9abbe"
 )
 
-func TestGenerate_writeAttrLineV4(t *testing.T) {
-	multilineListOfStrings := heredoc.Doc(`
-		a = ["b", "c", "d"]
-	`)
-	multilineBlock := heredoc.Doc(`
-		a = {
-		  c = "d"
-		  e = "f"
-		}
-	`)
-	tests := map[string]struct {
-		key   string
-		value interface{}
-		want  string
-	}{
-		"value is string":           {key: "a", value: "b", want: fmt.Sprintf("a = %q\n", "b")},
-		"value is int":              {key: "a", value: 1, want: "a = 1\n"},
-		"value is float":            {key: "a", value: 1.0, want: "a = 1\n"},
-		"value is bool":             {key: "a", value: true, want: "a = true\n"},
-		"value is list of strings":  {key: "a", value: listOfString, want: multilineListOfStrings},
-		"value is block of strings": {key: "a", value: configBlockOfStrings, want: multilineBlock},
-		"value is nil":              {key: "a", value: nil, want: ""},
-	}
-
-	for name, tc := range tests {
-		f := hclwrite.NewEmptyFile()
-		t.Run(name, func(t *testing.T) {
-			writeAttrLine(tc.key, tc.value, "", f.Body())
-			assert.Equal(t, tc.want, string(f.Bytes()))
-		})
-	}
-}
-
 func TestGenerate_ResourceNotSupportedV4(t *testing.T) {
-	output, err := executeCommandC(rootCmd, "generate", "--resource-type", "notreal")
-	assert.Nil(t, err)
-	assert.Equal(t, `"notreal" is not yet supported for automatic generation`, output)
+	// The run exits with the unsupported status once the failures are
+	// reported, so the output is read from the command rather than returned.
+	output := new(bytes.Buffer)
+	rootCmd.SetOut(output)
+	rootCmd.SetErr(output)
+	rootCmd.SetArgs([]string{"generate", "--resource-type", "notreal"})
+	err := catchExit(func() { _, _ = rootCmd.ExecuteC() })
+	assert.Equal(t, exitCodeUnsupported, exitCodeOf(err))
+	assert.Equal(t, heredoc.Doc(`
+
+		Failed to generate 1 resource type(s):
+		  notreal: not yet supported for automatic generation
+	`), output.String())
 }
 
 func TestResourceGenerationV4(t *testing.T) {
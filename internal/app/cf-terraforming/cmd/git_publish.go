@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const (
+	gitProviderGitHub = "github"
+	gitProviderGitLab = "gitlab"
+)
+
+var (
+	// gitRepoDir is a clone of the repository the generated configuration is
+	// committed to on a new branch, for which a pull request is then opened.
+	gitRepoDir string
+
+	// gitFile is the path within the repository the configuration is written
+	// to, unless it's written as a module into --output-dir.
+	gitFile string
+
+	// gitBranch is the branch the configuration is committed to.
+	gitBranch string
+
+	// gitRemote is the remote the branch is pushed to.
+	gitRemote string
+
+	// gitProvider is whether the remote is hosted by GitHub or GitLab,
+	// otherwise detected from its host.
+	gitProvider string
+)
+
+// gitHost is where the pull request is opened.
+type gitHost struct {
+	provider string
+	apiURL   string
+	// project is the owner and name of the repository.
+	project string
+}
+
+// gitPublisher commits the generated files and opens a pull request.
+type gitPublisher struct {
+	dir    string
+	remote string
+	branch string
+	host   gitHost
+	token  string
+	// paths are committed relative to the repository.
+	paths []string
+}
+
+// newGitPublisher checks the repository and credentials before anything is
+// generated, so a run isn't wasted on a repository it can't publish to.
+// withOutputDir also commits --output-dir, which must then be within the
+// repository.
+func newGitPublisher(ctx context.Context, withOutputDir bool) (*gitPublisher, error) {
+	paths, err := gitPublishPaths(gitRepoDir, withOutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteURL, err := runGit(ctx, gitRepoDir, "remote", "get-url", gitRemote)
+	if err != nil {
+		return nil, err
+	}
+	host, err := parseGitRemote(remoteURL, gitProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenVariable := "GITHUB_TOKEN"
+	if host.provider == gitProviderGitLab {
+		tokenVariable = "GITLAB_TOKEN"
+	}
+	token := os.Getenv(tokenVariable)
+	if token == "" {
+		return nil, fmt.Errorf("%s must be set to open a pull request", tokenVariable)
+	}
+
+	branch := gitBranch
+	if branch == "" {
+		branch = "cf-terraforming/" + time.Now().UTC().Format("20060102-150405")
+	}
+
+	return &gitPublisher{dir: gitRepoDir, remote: gitRemote, branch: branch, host: host, token: token, paths: paths}, nil
+}
+
+// createOutputFile creates --git-file in the repository for the generated
+// configuration to be written to.
+func (p *gitPublisher) createOutputFile() (*os.File, error) {
+	path := filepath.Join(p.dir, gitFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	return os.Create(path)
+}
+
+// publish commits any changes to a new branch, pushes it and opens a pull
+// request, returning its URL. Nothing is published if nothing changed.
+func (p *gitPublisher) publish(ctx context.Context, title, description string) (string, error) {
+	base, err := runGit(ctx, p.dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	committed, err := commitToBranch(ctx, p.dir, p.branch, p.paths, title)
+	if err != nil || !committed {
+		return "", err
+	}
+	if _, err := runGit(ctx, p.dir, "push", p.remote, p.branch); err != nil {
+		return "", err
+	}
+
+	return openPullRequest(ctx, p.host, p.token, p.branch, base, title, description)
+}
+
+// commitToBranch commits any changes to the paths to a new branch, returning
+// whether there were any.
+func commitToBranch(ctx context.Context, dir, branch string, paths []string, message string) (bool, error) {
+	status, err := runGit(ctx, dir, append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil {
+		return false, err
+	}
+	if status == "" {
+		return false, nil
+	}
+
+	for _, args := range [][]string{
+		{"checkout", "-b", branch},
+		append([]string{"add", "--all", "--"}, paths...),
+		{"commit", "--message", message},
+	} {
+		if _, err := runGit(ctx, dir, args...); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// runGit runs git in the repository and returns its output.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// parseGitRemote returns where pull requests are opened for the remote's URL,
+// in either the HTTPS or SSH form.
+func parseGitRemote(remoteURL, provider string) (gitHost, error) {
+	var host, path string
+	if u, err := url.Parse(remoteURL); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if _, rest, ok := strings.Cut(remoteURL, "@"); ok && strings.Contains(rest, ":") {
+		// SSH remotes such as git@github.com:owner/repo.git.
+		host, path, _ = strings.Cut(rest, ":")
+	} else {
+		return gitHost{}, fmt.Errorf("can't open a pull request for the remote %s", remoteURL)
+	}
+	project := strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	if provider == "" {
+		switch {
+		case host == "github.com":
+			provider = gitProviderGitHub
+		case strings.Contains(host, "gitlab"):
+			provider = gitProviderGitLab
+		default:
+			return gitHost{}, fmt.Errorf("can't tell whether %s is GitHub or GitLab, set --git-provider", host)
+		}
+	}
+
+	switch provider {
+	case gitProviderGitHub:
+		apiURL := "https://api.github.com"
+		if host != "github.com" {
+			apiURL = "https://" + host + "/api/v3"
+		}
+		return gitHost{provider: provider, apiURL: apiURL, project: project}, nil
+	case gitProviderGitLab:
+		return gitHost{provider: provider, apiURL: "https://" + host + "/api/v4", project: project}, nil
+	default:
+		return gitHost{}, fmt.Errorf("unsupported --git-provider %q", provider)
+	}
+}
+
+// openPullRequest opens a GitHub pull request or GitLab merge request and
+// returns its URL.
+func openPullRequest(ctx context.Context, host gitHost, token, branch, base, title, description string) (string, error) {
+	var (
+		endpoint string
+		payload  map[string]string
+	)
+	if host.provider == gitProviderGitLab {
+		endpoint = fmt.Sprintf("%s/projects/%s/merge_requests", host.apiURL, url.PathEscape(host.project))
+		payload = map[string]string{"source_branch": branch, "target_branch": base, "title": title, "description": description}
+	} else {
+		endpoint = fmt.Sprintf("%s/repos/%s/pulls", host.apiURL, host.project)
+		payload = map[string]string{"head": branch, "base": base, "title": title, "body": description}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if host.provider == gitProviderGitLab {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+
+	resp, err := (&http.Client{Transport: sharedTransport, Timeout: time.Minute}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		HTMLURL string          `json:"html_url"`
+		WebURL  string          `json:"web_url"`
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read the response opening the pull request: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to open the pull request: %s %s", resp.Status, result.Message)
+	}
+
+	return result.HTMLURL + result.WebURL, nil
+}
+
+// gitPublishPaths returns the paths within the repository to commit: --git-file
+// unless a module is written, and --output-dir if requested.
+func gitPublishPaths(repo string, withOutputDir bool) ([]string, error) {
+	var paths []string
+	if !writesModule() {
+		paths = append(paths, filepath.Clean(gitFile))
+	}
+	if !withOutputDir {
+		return paths, nil
+	}
+
+	absRepo, err := filepath.Abs(repo)
+	if err != nil {
+		return nil, err
+	}
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(absRepo, absOutputDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("--output-dir %s must be within --git-repo to be committed", outputDir)
+	}
+
+	return append(paths, rel), nil
+}
+
+// publishGenerated publishes the generated files and logs the pull request,
+// unless the run failed or was interrupted so a partial configuration isn't
+// proposed.
+func publishGenerated(p *gitPublisher, generated []*hclwrite.File, failures *runFailures) {
+	if interrupted.Load() || failures.any() {
+		log.Warn("not opening a pull request as the run didn't complete")
+		return
+	}
+
+	scope := "zone " + zoneID
+	if accountID != "" {
+		scope = "account " + accountID
+	}
+	url, err := p.publish(runContext, "Update Cloudflare configuration for "+scope, buildMarkdownSummary(generated))
+	if err != nil {
+		log.Fatalf("failed to publish the generated configuration: %s", err)
+	}
+	if url == "" {
+		log.Info("not opening a pull request as nothing changed")
+		return
+	}
+	log.Infof("opened %s", url)
+}
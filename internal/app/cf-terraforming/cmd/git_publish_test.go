@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitRemote(t *testing.T) {
+	tests := map[string]struct {
+		remoteURL string
+		provider  string
+		expected  gitHost
+		err       string
+	}{
+		"github https": {
+			remoteURL: "https://github.com/cloudflare/infrastructure.git",
+			expected:  gitHost{provider: gitProviderGitHub, apiURL: "https://api.github.com", project: "cloudflare/infrastructure"},
+		},
+		"github ssh": {
+			remoteURL: "git@github.com:cloudflare/infrastructure.git",
+			expected:  gitHost{provider: gitProviderGitHub, apiURL: "https://api.github.com", project: "cloudflare/infrastructure"},
+		},
+		"github enterprise": {
+			remoteURL: "https://git.example.com/platform/dns",
+			provider:  gitProviderGitHub,
+			expected:  gitHost{provider: gitProviderGitHub, apiURL: "https://git.example.com/api/v3", project: "platform/dns"},
+		},
+		"gitlab subgroup": {
+			remoteURL: "ssh://git@gitlab.example.com/platform/network/dns.git",
+			expected:  gitHost{provider: gitProviderGitLab, apiURL: "https://gitlab.example.com/api/v4", project: "platform/network/dns"},
+		},
+		"unknown host": {
+			remoteURL: "https://git.example.com/platform/dns",
+			err:       "can't tell whether git.example.com is GitHub or GitLab, set --git-provider",
+		},
+		"unsupported provider": {
+			remoteURL: "https://github.com/cloudflare/infrastructure",
+			provider:  "bitbucket",
+			err:       `unsupported --git-provider "bitbucket"`,
+		},
+		"local path": {
+			remoteURL: "/srv/git/infrastructure.git",
+			err:       "can't open a pull request for the remote /srv/git/infrastructure.git",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			host, err := parseGitRemote(tc.remoteURL, tc.provider)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, host)
+		})
+	}
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	var (
+		path    string
+		headers http.Header
+		body    map[string]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, headers, body = r.URL.EscapedPath(), r.Header, nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusCreated)
+		if r.Header.Get("PRIVATE-TOKEN") != "" {
+			_, _ = w.Write([]byte(`{"web_url": "https://gitlab.example.com/platform/dns/-/merge_requests/1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"html_url": "https://github.com/cloudflare/infrastructure/pull/1"}`))
+	}))
+	defer server.Close()
+
+	url, err := openPullRequest(context.Background(), gitHost{provider: gitProviderGitHub, apiURL: server.URL, project: "cloudflare/infrastructure"}, "token", "cf-terraforming/update", "main", "Update", "Summary")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/cloudflare/infrastructure/pull/1", url)
+	assert.Equal(t, "/repos/cloudflare/infrastructure/pulls", path)
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+	assert.Equal(t, map[string]string{"head": "cf-terraforming/update", "base": "main", "title": "Update", "body": "Summary"}, body)
+
+	url, err = openPullRequest(context.Background(), gitHost{provider: gitProviderGitLab, apiURL: server.URL, project: "platform/dns"}, "token", "cf-terraforming/update", "main", "Update", "Summary")
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.example.com/platform/dns/-/merge_requests/1", url)
+	assert.Equal(t, "/projects/platform%2Fdns/merge_requests", path)
+	assert.Equal(t, "token", headers.Get("PRIVATE-TOKEN"))
+	assert.Equal(t, map[string]string{"source_branch": "cf-terraforming/update", "target_branch": "main", "title": "Update", "description": "Summary"}, body)
+}
+
+func TestOpenPullRequestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message": ["Another open merge request already exists for this source branch"]}`))
+	}))
+	defer server.Close()
+
+	_, err := openPullRequest(context.Background(), gitHost{provider: gitProviderGitLab, apiURL: server.URL, project: "platform/dns"}, "token", "update", "main", "Update", "")
+	assert.EqualError(t, err, `failed to open the pull request: 409 Conflict ["Another open merge request already exists for this source branch"]`)
+}
+
+func TestCommitToBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		out, err := runGit(context.Background(), dir, args...)
+		require.NoError(t, err)
+		return out
+	}
+	git("init", "--initial-branch", "main")
+	git("config", "user.name", "cf-terraforming")
+	git("config", "user.email", "cf-terraforming@example.com")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Infrastructure\n"), 0644))
+	git("add", "README.md")
+	git("commit", "--message", "Initial commit")
+
+	committed, err := commitToBranch(context.Background(), dir, "update", []string{"cloudflare.tf"}, "Update")
+	require.NoError(t, err)
+	assert.False(t, committed, "nothing changed")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cloudflare.tf"), []byte("resource \"cloudflare_zone\" \"example\" {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated.txt"), []byte("left alone\n"), 0644))
+
+	committed, err = commitToBranch(context.Background(), dir, "update", []string{"cloudflare.tf"}, "Update")
+	require.NoError(t, err)
+	assert.True(t, committed)
+	assert.Equal(t, "update", git("rev-parse", "--abbrev-ref", "HEAD"))
+	assert.Equal(t, "cloudflare.tf", git("show", "--name-only", "--format=", "HEAD"))
+	assert.Equal(t, "?? unrelated.txt", git("status", "--porcelain"))
+}
+
+func TestGitPublishPaths(t *testing.T) {
+	defer func(dir, file string, module bool) { outputDir, gitFile, asModule = dir, file, module }(outputDir, gitFile, asModule)
+	repo := t.TempDir()
+	gitFile = "cloudflare/main.tf"
+
+	outputDir = filepath.Join(repo, "cloudflare")
+	paths, err := gitPublishPaths(repo, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cloudflare/main.tf"}, paths)
+
+	paths, err = gitPublishPaths(repo, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cloudflare/main.tf", "cloudflare"}, paths)
+
+	asModule = true
+	paths, err = gitPublishPaths(repo, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cloudflare"}, paths)
+
+	outputDir = t.TempDir()
+	_, err = gitPublishPaths(repo, true)
+	assert.ErrorContains(t, err, "must be within --git-repo to be committed")
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const graphFormatDOT = "dot"
+
+// resourceEdge means that the resource `to` references `from`, so `from` must
+// be imported first.
+type resourceEdge struct {
+	from, to string
+}
+
+// resourceGraph finds every reference between the generated resources,
+// including those from `depends_on`.
+func resourceGraph(files []*hclwrite.File) ([]string, []resourceEdge) {
+	var nodes []string
+	known := make(map[string]bool)
+	for _, f := range files {
+		for _, address := range resourceAddresses(f) {
+			nodes = append(nodes, address)
+			known[address] = true
+		}
+	}
+
+	var edges []resourceEdge
+	for _, f := range files {
+		for _, block := range f.Body().Blocks() {
+			if block.Type() != "resource" || len(block.Labels()) != 2 {
+				continue
+			}
+
+			address := strings.Join(block.Labels(), ".")
+			for _, referenced := range bodyReferences(block.Body()) {
+				edge := resourceEdge{from: referenced, to: address}
+				if known[referenced] && referenced != address && !slices.Contains(edges, edge) {
+					edges = append(edges, edge)
+				}
+			}
+		}
+	}
+
+	slices.Sort(nodes)
+	slices.SortFunc(edges, func(a, b resourceEdge) int {
+		if c := strings.Compare(a.from, b.from); c != 0 {
+			return c
+		}
+		return strings.Compare(a.to, b.to)
+	})
+
+	return nodes, edges
+}
+
+// bodyReferences returns the resource addresses referenced anywhere in the
+// body, such as `cloudflare_load_balancer_pool.primary` from
+// `cloudflare_load_balancer_pool.primary.id`.
+func bodyReferences(body *hclwrite.Body) []string {
+	var references []string
+	for _, attr := range body.Attributes() {
+		tokens := attr.Expr().BuildTokens(nil)
+		for i := 0; i+2 < len(tokens); i++ {
+			if tokens[i].Type != hclsyntax.TokenIdent || tokens[i+1].Type != hclsyntax.TokenDot || tokens[i+2].Type != hclsyntax.TokenIdent {
+				continue
+			}
+			if i > 0 && tokens[i-1].Type == hclsyntax.TokenDot {
+				continue
+			}
+			references = append(references, fmt.Sprintf("%s.%s", tokens[i].Bytes, tokens[i+2].Bytes))
+		}
+	}
+
+	for _, block := range body.Blocks() {
+		references = append(references, bodyReferences(block.Body())...)
+	}
+
+	return references
+}
+
+// writeDOTGraph outputs the graph in the Graphviz DOT language.
+func writeDOTGraph(w io.Writer, nodes []string, edges []resourceEdge) {
+	_, _ = fmt.Fprintln(w, "digraph cloudflare {")
+	_, _ = fmt.Fprintln(w, "  rankdir = \"LR\";")
+	for _, node := range nodes {
+		_, _ = fmt.Fprintf(w, "  %q;\n", node)
+	}
+	for _, edge := range edges {
+		_, _ = fmt.Fprintf(w, "  %q -> %q;\n", edge.from, edge.to)
+	}
+	_, _ = fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceGraph(t *testing.T) {
+	pools := heredoc.Doc(`
+		resource "cloudflare_load_balancer_pool" "primary" {
+		  monitor = cloudflare_load_balancer_monitor.http.id
+		}
+	`)
+	balancers := heredoc.Doc(`
+		resource "cloudflare_load_balancer" "www" {
+		  default_pool_ids = [cloudflare_load_balancer_pool.primary.id]
+		  fallback_pool    = cloudflare_load_balancer_pool.primary.id
+		  zone_id          = var.zone_id
+		}
+	`)
+
+	var files []*hclwrite.File
+	for _, content := range []string{pools, balancers} {
+		f, diags := hclwrite.ParseConfig([]byte(content), "main.tf", hcl.InitialPos)
+		require.False(t, diags.HasErrors())
+		files = append(files, f)
+	}
+
+	nodes, edges := resourceGraph(files)
+
+	var output bytes.Buffer
+	writeDOTGraph(&output, nodes, edges)
+	assert.Equal(t, heredoc.Doc(`
+		digraph cloudflare {
+		  rankdir = "LR";
+		  "cloudflare_load_balancer.www";
+		  "cloudflare_load_balancer_pool.primary";
+		  "cloudflare_load_balancer_pool.primary" -> "cloudflare_load_balancer.www";
+		}
+	`), output.String())
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+)
+
+// heredocThreshold is the length at which multi-line strings are written as a
+// heredoc rather than a single escaped line. Zero disables heredocs.
+var heredocThreshold int
+
+// useJSONEncode writes strings containing JSON objects or arrays using
+// `jsonencode` so they can be read and modified as HCL.
+var useJSONEncode bool
+
+// hclOptions returns how the flags write resources as HCL.
+func hclOptions() terraforming.HCLOptions {
+	return terraforming.HCLOptions{
+		Scope:            runScope(),
+		ScopeVariables:   useVariables || writesModule(),
+		JSONEncode:       useJSONEncode,
+		HeredocThreshold: heredocThreshold,
+		NestedSyntax:     nestedSyntax,
+		Logger:           log,
+	}
+}
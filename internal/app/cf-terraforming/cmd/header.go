@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// generationHeader writes a comment at the start of the generated
+// configuration recording how and when it was generated.
+var generationHeader bool
+
+// buildGenerationHeader returns the comment written by --header. The time is
+// always in UTC so the header doesn't depend on where it was generated.
+func buildGenerationHeader(providerVersion string, generatedAt time.Time) string {
+	lines := []string{
+		fmt.Sprintf("Generated by cf-terraforming %s", versionString),
+		fmt.Sprintf("Provider: cloudflare/cloudflare %s", providerVersion),
+	}
+	if accountID != "" {
+		lines = append(lines, fmt.Sprintf("Account: %s", accountID))
+	}
+	if zoneID != "" {
+		lines = append(lines, fmt.Sprintf("Zone: %s", zoneID))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Resource types: %s", strings.ReplaceAll(resourceType, ",", ", ")),
+		fmt.Sprintf("Generated at: %s", generatedAt.UTC().Format(time.RFC3339)),
+	)
+
+	var header strings.Builder
+	for _, line := range lines {
+		header.WriteString("# " + line + "\n")
+	}
+	header.WriteString("\n")
+
+	return header.String()
+}
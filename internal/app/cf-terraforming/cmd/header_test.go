@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildGenerationHeader(t *testing.T) {
+	defer func(account, zone, types, version string) {
+		accountID, zoneID, resourceType, versionString = account, zone, types, version
+	}(accountID, zoneID, resourceType, versionString)
+
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+	resourceType = "cloudflare_dns_record,cloudflare_ruleset"
+	versionString = "v0.23.0"
+
+	generatedAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("BST", 3600))
+	want := heredoc.Doc(`
+		# Generated by cf-terraforming v0.23.0
+		# Provider: cloudflare/cloudflare 5.1.0
+		# Zone: 0da42c8d2132a9ddaf714f9e7c920711
+		# Resource types: cloudflare_dns_record, cloudflare_ruleset
+		# Generated at: 2024-05-01T11:30:00Z
+
+	`)
+
+	assert.Equal(t, want, buildGenerationHeader("5.1.0", generatedAt))
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// headerName matches the characters allowed in the name of a header.
+var headerName = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+var (
+	// userAgentSuffix is appended to the User-Agent of every API request.
+	userAgentSuffix string
+
+	// requestHeaders are extra headers sent with every API request, such as
+	// those required by an egress gateway, in the form "Name: value".
+	requestHeaders []string
+
+	// apiRequestHeaders are the parsed --request-header values.
+	apiRequestHeaders http.Header
+)
+
+// parseRequestHeaders parses headers in the form "Name: value". Repeating a
+// header sends each of its values.
+func parseRequestHeaders(values []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		name, v = strings.TrimSpace(name), strings.TrimSpace(v)
+		if !ok || !headerName.MatchString(name) || strings.ContainsAny(v, "\r\n") {
+			return nil, fmt.Errorf("invalid --request-header %q, expected Name: value", value)
+		}
+		if strings.EqualFold(name, "User-Agent") {
+			return nil, fmt.Errorf("invalid --request-header %q, use --user-agent-suffix instead", value)
+		}
+		headers.Add(name, v)
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequestHeaders(t *testing.T) {
+	headers, err := parseRequestHeaders([]string{"x-audit-id: terraform", "X-Audit-Id:nightly", "X-Team: platform: infra"})
+	require.NoError(t, err)
+	assert.Equal(t, http.Header{
+		"X-Audit-Id": {"terraform", "nightly"},
+		"X-Team":     {"platform: infra"},
+	}, headers)
+
+	_, err = parseRequestHeaders([]string{"X-Audit-Id"})
+	assert.EqualError(t, err, `invalid --request-header "X-Audit-Id", expected Name: value`)
+	_, err = parseRequestHeaders([]string{"X Audit: terraform"})
+	assert.EqualError(t, err, `invalid --request-header "X Audit: terraform", expected Name: value`)
+	_, err = parseRequestHeaders([]string{"User-Agent: acme"})
+	assert.EqualError(t, err, `invalid --request-header "User-Agent: acme", use --user-agent-suffix instead`)
+}
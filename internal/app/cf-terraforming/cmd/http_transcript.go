@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dnaeon/go-vcr/cassette"
+)
+
+const redactedValue = "REDACTED"
+
+// debugHTTPOut is the file every API request and response is recorded to, in
+// the same format as the VCR cassettes used by the tests, so the transcript
+// can be attached to bug reports.
+var debugHTTPOut string
+
+// redactedHeaders are headers which hold credentials so are never recorded.
+var redactedHeaders = []string{
+	"Authorization",
+	"Cf-Access-Client-Secret",
+	"Cookie",
+	"Set-Cookie",
+	"X-Auth-Email",
+	"X-Auth-Key",
+	"X-Auth-User-Service-Key",
+}
+
+// httpTranscriptTransport records each request and response to a cassette
+// with credentials and secrets redacted. The cassette is saved after every
+// request so the transcript is complete even when the run fails.
+type httpTranscriptTransport struct {
+	rt       http.RoundTripper
+	mu       sync.Mutex
+	cassette *cassette.Cassette
+}
+
+func newHTTPTranscriptTransport(rt http.RoundTripper, path string) *httpTranscriptTransport {
+	c := cassette.New(strings.TrimSuffix(path, ".yaml"))
+	c.File = path
+
+	return &httpTranscriptTransport{rt: rt, cassette: c}
+}
+
+func (t *httpTranscriptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Since(start)
+
+	responseBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.AddInteraction(&cassette.Interaction{
+		Request: cassette.Request{
+			Body:    redactBody(requestBody),
+			Headers: redactHeaders(req.Header),
+			URL:     req.URL.String(),
+			Method:  req.Method,
+		},
+		Response: cassette.Response{
+			Body:     redactBody(responseBody),
+			Headers:  redactHeaders(resp.Header),
+			Status:   resp.Status,
+			Code:     resp.StatusCode,
+			Duration: duration.String(),
+		},
+	})
+	if err := t.cassette.Save(); err != nil {
+		log.Warnf("failed to write HTTP transcript to %s: %s", t.cassette.File, err)
+	}
+
+	return resp, nil
+}
+
+// redactHeaders returns a copy of the headers with credentials redacted.
+func redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := redacted[name]; ok {
+			redacted.Set(name, redactedValue)
+		}
+	}
+
+	return redacted
+}
+
+// redactBody redacts the values of attributes which are known to be secrets
+// from a JSON body. Other bodies are recorded as they are.
+func redactBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body)
+	}
+	if !redactSecrets(value) {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+
+	return string(redacted)
+}
+
+// redactSecrets replaces secrets anywhere in the value, reporting whether any
+// were found.
+func redactSecrets(value interface{}) bool {
+	return redactAttributes(value, sensitiveAttributeNames)
+}
+
+// redactAttributes replaces the values of the named attributes anywhere in the
+// value, reporting whether any were found.
+func redactAttributes(value interface{}, names []string) bool {
+	var found bool
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if slices.Contains(names, key) && item != nil {
+				v[key] = redactedValue
+				found = true
+				continue
+			}
+			found = redactAttributes(item, names) || found
+		}
+	case []interface{}:
+		for _, item := range v {
+			found = redactAttributes(item, names) || found
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTranscriptTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"result":[{"id":"f2a6ba4a","name":"tunnel","tunnel_secret":"c2VjcmV0"}]}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "transcript.yaml")
+	client := &http.Client{Transport: newHTTPTranscriptTransport(http.DefaultTransport, path)}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/accounts/1/cfd_tunnel", strings.NewReader(`{"name":"tunnel","secret":"c2VjcmV0"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The response is passed on as it is, only the transcript is redacted.
+	assert.Contains(t, string(body), "c2VjcmV0")
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	c, err := cassette.Load(strings.TrimSuffix(path, ".yaml"))
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 1)
+
+	interaction := c.Interactions[0]
+	assert.Equal(t, http.MethodPost, interaction.Request.Method)
+	assert.Equal(t, server.URL+"/accounts/1/cfd_tunnel", interaction.Request.URL)
+	assert.Equal(t, "REDACTED", interaction.Request.Headers.Get("Authorization"))
+	assert.Equal(t, "application/json", interaction.Request.Headers.Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"tunnel","secret":"REDACTED"}`, interaction.Request.Body)
+	assert.Equal(t, http.StatusOK, interaction.Response.Code)
+	assert.JSONEq(t, `{"result":[{"id":"f2a6ba4a","name":"tunnel","tunnel_secret":"REDACTED"}]}`, interaction.Response.Body)
+}
+
+func TestRedactBody(t *testing.T) {
+	assert.Equal(t, "not json", redactBody([]byte("not json")))
+	assert.Equal(t, `{"result": {"name": "example"}}`, redactBody([]byte(`{"result": {"name": "example"}}`)))
+	assert.Equal(t, `{"result":{"private_key":"REDACTED"}}`, redactBody([]byte(`{"result": {"private_key": "-----BEGIN"}}`)))
+}
@@ -6,10 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
 	cfv0 "github.com/cloudflare/cloudflare-go"
 	"github.com/cloudflare/cloudflare-go/v4/option"
 	"github.com/hashicorp/hcl/v2/hclwrite"
@@ -137,6 +137,29 @@ var importCommand = &cobra.Command{
 
 func runImport() func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		if outputFormat != outputFormatHCL && outputFormat != outputFormatJSON && outputFormat != outputFormatPulumiImport && outputFormat != outputFormatShell {
+			log.Fatalf("unsupported output format %q", outputFormat)
+		}
+
+		if err := validateTarget(); err != nil {
+			log.Fatal(err)
+		}
+
+		if useImportForEach && !useModernImportBlock {
+			log.Fatal("--import-for-each requires --modern-import-block")
+		}
+
+		if accountID == "" && zoneID == "" {
+			exitWith(exitCodeScopeMissing, errScopeMissing)
+		}
+
+		if writeStateDirectly && outputFormat != outputFormatHCL {
+			log.Fatal("--write-state cannot be combined with --format")
+		}
+
+		failures := &runFailures{action: "import"}
+		defer failures.exit(cmd.ErrOrStderr())
+
 		zoneID = viper.GetString("zone")
 		accountID = viper.GetString("account")
 		workingDir := viper.GetString("terraform-install-path")
@@ -177,12 +200,16 @@ func runImport() func(cmd *cobra.Command, args []string) {
 		}
 
 		providerVersionString = detectedVersion.String()
+		if err := checkWorkingDirProvider(workingDir, providerVersionString); err != nil {
+			log.Fatal(err)
+		}
 		log.WithFields(logrus.Fields{
 			"version":  providerVersionString,
 			"registry": registryPath,
 		}).Debug("detected provider")
 
 		resourceIDsMap := make(map[string][]string)
+		mapping := loadMappingOrEmpty()
 		var (
 			jsonStructData                       []interface{}
 			pathParams, endpointsWithResourceIDs []string
@@ -191,21 +218,37 @@ func runImport() func(cmd *cobra.Command, args []string) {
 		if strings.HasPrefix(providerVersionString, "5") {
 			resources := strings.Split(resourceType, ",")
 			for _, resourceType := range resources {
+				if converter, ok := converterFor(resourceType); ok {
+					if jsonStructData, err = fetchConverted(runContext, converter); err != nil {
+						failures.add(resourceType, err)
+					}
+					continue
+				}
 				if isSupportedPathParam(resources, resourceType) {
 					resourceIDsMap = getResourceMappings()
-					pathParams, ok = resourceIDsMap[resourceType]
-					if ok && len(pathParams) == 0 {
-						log.Fatalf("No resource IDs defined in Terraform for resource %s", resourceType)
+					pathParams, err = resolvePathParams(resourceIDsMap, resourceType)
+					if err != nil {
+						log.Fatalf("No resource IDs defined in Terraform for resource %s: %s", resourceType, err)
+					}
+					if len(pathParams) == 0 {
+						log.WithFields(logrus.Fields{
+							"resource": resourceType,
+						}).Info("no parent resources found")
+						continue
 					}
+					resourceIDsMap[resourceType] = pathParams
 				}
 				var result *http.Response
 
 				// by default, we want to use the `list` operation however, there are times
 				// when resources exist only as `get` operations but contain multiple
 				// resources.
-				endpoint := resourceToEndpoint[resourceType]["list"]
+				endpoint, get := terraforming.Endpoints(resourceType)
+				if endpoint == "" {
+					endpoint = get
+				}
 				if endpoint == "" {
-					endpoint = resourceToEndpoint[resourceType]["get"]
+					exitWithf(exitCodeUnsupported, "%q is not yet supported for state import", resourceType)
 				}
 
 				// if we encounter a combined endpoint, we need to rewrite to use the correct
@@ -230,14 +273,22 @@ func runImport() func(cmd *cobra.Command, args []string) {
 
 				if len(pathParams) > 0 {
 					endpointsWithResourceIDs = replacePathParams(pathParams, endpoint, resourceType)
-					jsonStructData, err = getAPIResponse(result, pathParams, endpointsWithResourceIDs...)
+					jsonStructData, err = getAPIResponse(runContext, resourceType, result, pathParams, endpointsWithResourceIDs...)
 					if err != nil {
+						if !terraforming.IsNoResourcesError(err) {
+							failure := newResourceFailure(resourceType, err)
+							exitWith(failure.exitCode(), failure)
+						}
 						log.Infof("error getting API response for resource %s: %s", resourceType, err)
 						continue
 					}
 				} else {
-					jsonStructData, err = getAPIResponse(result, pathParams, endpoint)
+					jsonStructData, err = getAPIResponse(runContext, resourceType, result, pathParams, endpoint)
 					if err != nil {
+						if !terraforming.IsNoResourcesError(err) {
+							failure := newResourceFailure(resourceType, err)
+							exitWith(failure.exitCode(), failure)
+						}
 						log.Infof("error getting API response for resource %s: %s", resourceType, err)
 						continue
 					}
@@ -253,6 +304,12 @@ func runImport() func(cmd *cobra.Command, args []string) {
 
 			resources := strings.Split(resourceType, ",")
 			for _, resourceType := range resources {
+				if converter, ok := converterFor(resourceType); ok {
+					if jsonStructData, err = fetchConverted(runContext, converter); err != nil {
+						failures.add(resourceType, err)
+					}
+					continue
+				}
 				switch resourceType {
 				case "cloudflare_access_application":
 					jsonPayload, _, err := apiV0.ListAccessApplications(context.Background(), identifier, cfv0.ListAccessApplicationsParams{})
@@ -717,15 +774,26 @@ func runImport() func(cmd *cobra.Command, args []string) {
 						log.Fatal(err)
 					}
 				default:
-					fmt.Fprintf(cmd.OutOrStderr(), "%q is not yet supported for state import", resourceType)
-					return
+					exitWithf(exitCodeUnsupported, "%q is not yet supported for state import", resourceType)
 				}
 			}
 		}
 
+		if target == targetOpenTofu && outputFormat == outputFormatHCL && !writeStateDirectly && len(jsonStructData) > 0 {
+			_, _ = fmt.Fprint(cmd.OutOrStdout(), openTofuStateEncryptionHint)
+		}
+
 		importFile := hclwrite.NewEmptyFile()
 		importBody := importFile.Body()
-		for i, data := range jsonStructData {
+		var (
+			pulumiResources []pulumiImportResource
+			importRecords   []importRecord
+			stateImports    []stateImport
+		)
+		jsonStructData = parseResourceSelection().apply(resourceType, jsonStructData)
+		forEachImports := newForEachImport(resourceType)
+		namer := newResourceNamer(mapping[resourceType])
+		for _, data := range jsonStructData {
 			var id string
 
 			if data.(map[string]interface{})["id"] == nil {
@@ -744,18 +812,72 @@ func runImport() func(cmd *cobra.Command, args []string) {
 					id = data.(map[string]interface{})["id"].(string)
 				}
 			}
-			if useModernImportBlock {
-				idvalue := buildRawImportAddress(resourceType, id, resourceToEndpoint[resourceType]["get"])
+			name, ok := mapping.name(resourceType, data.(map[string]interface{}))
+			if !ok {
+				name = namer.name(resourceType, data.(map[string]interface{}))
+			}
+			importID, err := buildImportID(resourceType, id, data.(map[string]interface{}))
+			if err != nil {
+				failures.record(resourceFailure{resourceType: resourceType, name: name, id: id, err: err})
+				continue
+			}
+
+			if writeStateDirectly || outputFormat == outputFormatShell {
+				stateImports = append(stateImports, stateImport{
+					address: fmt.Sprintf("%s.%s", resourceType, name),
+					id:      importID,
+				})
+			} else if outputFormat == outputFormatJSON {
+				importRecords = append(importRecords, importRecord{
+					ResourceType: resourceType,
+					ResourceName: name,
+					ImportID:     importID,
+					ZoneID:       zoneID,
+					AccountID:    accountID,
+				})
+			} else if outputFormat == outputFormatPulumiImport {
+				pulumiResources = append(pulumiResources, pulumiImportResource{
+					Type: pulumiResourceType(resourceType),
+					Name: name,
+					ID:   importID,
+				})
+			} else if useModernImportBlock && useImportForEach {
+				forEachImports.add(name, importID)
+			} else if useModernImportBlock {
 				imp := importBody.AppendNewBlock("import", []string{}).Body()
-				imp.SetAttributeRaw("to", hclwrite.TokensForIdentifier(fmt.Sprintf("%s.%s", resourceType, fmt.Sprintf("%s_%s_%d", terraformResourceNamePrefix, id, i))))
-				imp.SetAttributeValue("id", cty.StringVal(idvalue))
+				imp.SetAttributeRaw("to", hclwrite.TokensForIdentifier(fmt.Sprintf("%s.%s", resourceType, name)))
+				imp.SetAttributeValue("id", cty.StringVal(importID))
 				importFile.Body().AppendNewline()
 			} else {
-				_, _ = fmt.Fprint(cmd.OutOrStdout(), buildTerraformImportCommand(i, resourceType, id, resourceToEndpoint[resourceType]["get"]))
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), buildTerraformImportCommand(resourceType, name, importID))
+			}
+		}
+
+		if writeStateDirectly {
+			if err := writeState(context.Background(), tf, stateImports); err != nil {
+				log.Fatal(err)
 			}
+			return
+		}
+
+		if outputFormat == outputFormatShell {
+			writeImportScript(cmd, stateImports)
+			return
+		}
+
+		if outputFormat == outputFormatJSON {
+			writeImportRecords(cmd, importRecords)
+			return
+		}
+
+		if outputFormat == outputFormatPulumiImport {
+			writePulumiImportFile(cmd, pulumiResources)
+			return
 		}
 
 		if useModernImportBlock {
+			forEachImports.appendTo(importBody)
+
 			// don't format the output; there is a bug in hclwrite.Format that
 			// splits incorrectly on certain characters. instead, manually
 			// insert new lines on the block.
@@ -764,91 +886,39 @@ func runImport() func(cmd *cobra.Command, args []string) {
 	}
 }
 
-// buildTerraformImportCommand takes the resourceType and resourceID in order to
-// look up the resource type import string and then return a suitable composite
-// value that is compatible with `terraform import`.
-//
-// Note: `endpoint` is only used on > v4. Otherwise, it is ignored.
-func buildTerraformImportCommand(i int, resourceType, resourceID, endpoint string) string {
-	resourceImportAddress := buildRawImportAddress(resourceType, resourceID, endpoint)
-	return fmt.Sprintf("%s %s.%s %s\n", terraformImportCmdPrefix, resourceType, fmt.Sprintf("%s_%s_%d", terraformResourceNamePrefix, resourceID, i), resourceImportAddress)
+// buildTerraformImportCommand returns a command that is compatible with
+// `terraform import` (or `tofu import` when targeting OpenTofu) for importing
+// the resource.
+func buildTerraformImportCommand(resourceType, resourceName, importID string) string {
+	return fmt.Sprintf("%s %s.%s %s\n", importCmdPrefix(), resourceType, resourceName, importID)
 }
 
 // buildRawImportAddress takes the resourceType and resourceID in order to look up
-// the resource type import string and then return a suitable address.
-//
-// Note: `endpoint` is only used on > v4. Otherwise, it is ignored.
-func buildRawImportAddress(resourceType, resourceID, endpoint string) string {
-	if strings.HasPrefix(providerVersionString, "5") {
-		prefix := ""
-		if strings.Contains(endpoint, "{accounts_or_zones}") {
-			if accountID != "" {
-				prefix = "accounts"
-				endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/accounts/{account_id}/", 1)
-			} else {
-				prefix = "zones"
-				endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/zones/{zone_id}/", 1)
-			}
-		}
-
-		r, _ := regexp.Compile("({[a-z0-9_]*})")
-		matches := r.FindAllString(endpoint, -1)
-
-		if len(matches) > 0 {
-			// Naive assumptions below but if we only have a single placeholder (`{}`)
-			// we can replace that with the `resourceID` however, if we have more than
-			// a single one, we assume it is the second match since that is our URL
-			// conventions.
-			//
-			// Note: this will likely break on un-RESTful routes.
-			if len(matches) == 1 {
-				matches[0] = resourceID
-			} else {
-				if matches[0] == "{account_id}" {
-					matches[0] = accountID
-				} else if matches[0] == "{zone_id}" {
-					matches[0] = zoneID
-				}
-				matches[1] = resourceID
-			}
-		}
-
-		output := strings.Join(matches, "/")
+// the v4 resource type import string and then return a suitable address.
+func buildRawImportAddress(resourceType, resourceID string) string {
+	if _, ok := resourceImportStringFormats[resourceType]; !ok {
+		log.Fatalf("%s does not have an import format defined", resourceType)
+	}
 
-		replacer := strings.NewReplacer(
-			"{account_id}", accountID,
-			"{zone_id}", zoneID,
-		)
+	var identiferType string
+	var identiferValue string
 
-		if prefix != "" {
-			output = prefix + "/" + output
-		}
-		return replacer.Replace(output)
+	if accountID != "" {
+		identiferType = "account"
+		identiferValue = accountID
 	} else {
-		if _, ok := resourceImportStringFormats[resourceType]; !ok {
-			log.Fatalf("%s does not have an import format defined", resourceType)
-		}
-
-		var identiferType string
-		var identiferValue string
-
-		if accountID != "" {
-			identiferType = "account"
-			identiferValue = accountID
-		} else {
-			identiferType = "zone"
-			identiferValue = zoneID
-		}
+		identiferType = "zone"
+		identiferValue = zoneID
+	}
 
-		s := resourceImportStringFormats[resourceType]
-		replacer := strings.NewReplacer(
-			":identifier_type", identiferType,
-			":identifier_value", identiferValue,
-			":zone_id", zoneID,
-			":account_id", accountID,
-			":id", resourceID,
-		)
+	s := resourceImportStringFormats[resourceType]
+	replacer := strings.NewReplacer(
+		":identifier_type", identiferType,
+		":identifier_value", identiferValue,
+		":zone_id", zoneID,
+		":account_id", accountID,
+		":id", resourceID,
+	)
 
-		return replacer.Replace(s)
-	}
+	return replacer.Replace(s)
 }
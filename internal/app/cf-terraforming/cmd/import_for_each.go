@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// forEachImport collects the import IDs for resources of a single type so they
+// can be imported with one `for_each` driven import block.
+type forEachImport struct {
+	resourceType string
+	names        []string
+	ids          map[string]string
+}
+
+func newForEachImport(resourceType string) *forEachImport {
+	return &forEachImport{resourceType: resourceType, ids: make(map[string]string)}
+}
+
+func (f *forEachImport) add(name, id string) {
+	if _, ok := f.ids[name]; !ok {
+		f.names = append(f.names, name)
+	}
+	f.ids[name] = id
+}
+
+// localName is the name of the local value holding the import IDs.
+func (f *forEachImport) localName() string {
+	return fmt.Sprintf("%s_import_ids", f.resourceType)
+}
+
+// appendTo writes the locals map of resource names to import IDs followed by
+// an import block iterating over it. Each instance is imported into the
+// resource named terraform_managed_resource keyed by the generated name so the
+// resource configuration must use `for_each` over the same keys.
+//
+// This is only compatible with Terraform 1.7+ and OpenTofu 1.7+.
+func (f *forEachImport) appendTo(body *hclwrite.Body) {
+	if len(f.names) == 0 {
+		return
+	}
+
+	items := make([]hclwrite.ObjectAttrTokens, 0, len(f.names))
+	for _, name := range f.names {
+		items = append(items, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForValue(cty.StringVal(name)),
+			Value: hclwrite.TokensForValue(cty.StringVal(f.ids[name])),
+		})
+	}
+
+	locals := body.AppendNewBlock("locals", nil).Body()
+	locals.SetAttributeRaw(f.localName(), hclwrite.TokensForObject(items))
+	body.AppendNewline()
+
+	imp := body.AppendNewBlock("import", nil).Body()
+	imp.SetAttributeTraversal("for_each", hcl.Traversal{
+		hcl.TraverseRoot{Name: "local"},
+		hcl.TraverseAttr{Name: f.localName()},
+	})
+	imp.SetAttributeRaw("to", hclwrite.TokensForIdentifier(fmt.Sprintf("%s.%s[each.key]", f.resourceType, terraformResourceNamePrefix)))
+	imp.SetAttributeTraversal("id", hcl.Traversal{
+		hcl.TraverseRoot{Name: "each"},
+		hcl.TraverseAttr{Name: "value"},
+	})
+	body.AppendNewline()
+}
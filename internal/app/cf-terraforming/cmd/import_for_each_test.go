@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachImport(t *testing.T) {
+	imports := newForEachImport("cloudflare_dns_record")
+	imports.add("terraform_managed_resource_abc_0", "0da42c8d2132a9ddaf714f9e7c920711/abc")
+	imports.add("terraform_managed_resource_def_1", "0da42c8d2132a9ddaf714f9e7c920711/def")
+
+	f := hclwrite.NewEmptyFile()
+	imports.appendTo(f.Body())
+
+	expected := heredoc.Doc(`
+		locals {
+		  cloudflare_dns_record_import_ids = {
+		    "terraform_managed_resource_abc_0" = "0da42c8d2132a9ddaf714f9e7c920711/abc"
+		    "terraform_managed_resource_def_1" = "0da42c8d2132a9ddaf714f9e7c920711/def"
+		  }
+		}
+
+		import {
+		  for_each = local.cloudflare_dns_record_import_ids
+		  to       = cloudflare_dns_record.terraform_managed_resource[each.key]
+		  id       = each.value
+		}
+
+	`)
+	assert.Equal(t, expected, string(f.Bytes()))
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+)
+
+// buildImportID returns the import ID for a resource. v4 import IDs use the
+// formats in resourceImportStringFormats unless the resource type has a
+// converter with its own format.
+func buildImportID(resourceType, resourceID string, data map[string]interface{}) (string, error) {
+	converter, ok := converterFor(resourceType)
+	if strings.HasPrefix(providerVersionString, "5") || (ok && converter.ImportID != "") {
+		return terraforming.ImportID(resourceType, resourceID, data, runScope())
+	}
+
+	return buildRawImportAddress(resourceType, resourceID), nil
+}
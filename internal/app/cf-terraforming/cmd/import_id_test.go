@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildImportID(t *testing.T) {
+	defer func(version, account, zone string) {
+		providerVersionString, accountID, zoneID = version, account, zone
+	}(providerVersionString, accountID, zoneID)
+	providerVersionString = "5.0.0"
+
+	tests := map[string]struct {
+		resourceType string
+		resourceID   string
+		accountID    string
+		zoneID       string
+		data         map[string]interface{}
+		expected     string
+		err          string
+	}{
+		"list item": {
+			resourceType: "cloudflare_list_item",
+			resourceID:   "6cafa626bdb6453fac7a9be3aacf73ca",
+			accountID:    "f037e56e89293a057740de681ac9abbe",
+			data:         map[string]interface{}{"list_id": "2a4b8b2017aa4b3cb9e1151b52c81d22"},
+			expected:     "f037e56e89293a057740de681ac9abbe/2a4b8b2017aa4b3cb9e1151b52c81d22/6cafa626bdb6453fac7a9be3aacf73ca",
+		},
+		"queue consumer": {
+			resourceType: "cloudflare_queue_consumer",
+			resourceID:   "f037e56e89293a057740de681ac9abbe",
+			accountID:    "f037e56e89293a057740de681ac9abbe",
+			data:         map[string]interface{}{"queue_id": "2dde6ac405cd457c9ce59dc4bda20c65", "consumer_id": "2f4e3428eaa0472bb6954cf6b7fb932f"},
+			expected:     "f037e56e89293a057740de681ac9abbe/2dde6ac405cd457c9ce59dc4bda20c65/2f4e3428eaa0472bb6954cf6b7fb932f",
+		},
+		"zone setting": {
+			resourceType: "cloudflare_zone_setting",
+			resourceID:   "always_online",
+			zoneID:       "0da42c8d2132a9ddaf714f9e7c920711",
+			data:         map[string]interface{}{"setting_id": "always_online"},
+			expected:     "0da42c8d2132a9ddaf714f9e7c920711/always_online",
+		},
+		"r2 custom domain": {
+			resourceType: "cloudflare_r2_custom_domain",
+			accountID:    "f037e56e89293a057740de681ac9abbe",
+			data:         map[string]interface{}{"bucket_name": "jb-test-bucket", "domain": "example.com"},
+			expected:     "f037e56e89293a057740de681ac9abbe/jb-test-bucket/example.com",
+		},
+		"missing attribute": {
+			resourceType: "cloudflare_waiting_room_event",
+			resourceID:   "40aa062d83bfbd6c9a6062f1d4b2b23c",
+			zoneID:       "0da42c8d2132a9ddaf714f9e7c920711",
+			data:         map[string]interface{}{},
+			err:          `failed to build the import ID: no value found for "waiting_room_id"`,
+		},
+		"no format defined": {
+			resourceType: "cloudflare_dns_record",
+			resourceID:   "3c0b456bc2aa443089c5f40f45f51b31",
+			zoneID:       "0da42c8d2132a9ddaf714f9e7c920711",
+			data:         map[string]interface{}{},
+			expected:     "0da42c8d2132a9ddaf714f9e7c920711/3c0b456bc2aa443089c5f40f45f51b31",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			accountID, zoneID = tc.accountID, tc.zoneID
+			id, err := buildImportID(tc.resourceType, tc.resourceID, tc.data)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, id)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const outputFormatShell = "shell"
+
+// importScriptHeader sets up the import script. The existing state is only
+// listed once so resources that are already imported can be skipped cheaply
+// when resuming an interrupted run.
+//
+// Imports are run one after another as each one needs to acquire the lock on
+// the same state.
+const importScriptHeader = `#!/usr/bin/env bash
+# Generated by cf-terraforming. Run from the Terraform working directory
+# containing the generated configuration.
+set -euo pipefail
+
+BINARY="${BINARY:-%s}"
+EXISTING="$("$BINARY" state list 2>/dev/null || true)"
+TOTAL=%d
+COUNT=0
+
+import_resource() {
+  COUNT=$((COUNT + 1))
+  if grep -qxF -- "$1" <<<"$EXISTING"; then
+    echo "[$COUNT/$TOTAL] skipping $1 as it is already imported"
+    return
+  fi
+
+  echo "[$COUNT/$TOTAL] importing $1"
+  "$BINARY" import "$1" "$2"
+}
+
+`
+
+// buildImportScript generates a bash script that imports each of the
+// resources, skipping any that are already present in state.
+func buildImportScript(imports []stateImport) string {
+	var script strings.Builder
+	fmt.Fprintf(&script, importScriptHeader, targetBinary(), len(imports))
+	for _, imp := range imports {
+		fmt.Fprintf(&script, "import_resource %s %s\n", shellQuote(imp.address), shellQuote(imp.id))
+	}
+	script.WriteString("\necho \"imported $TOTAL resources\"\n")
+
+	return script.String()
+}
+
+// writeImportScript outputs the import script.
+func writeImportScript(cmd *cobra.Command, imports []stateImport) {
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), buildImportScript(imports))
+}
+
+// shellQuote wraps a value in single quotes so it is passed to the command
+// as-is.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'abc/123'`, shellQuote("abc/123"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestBuildImportScript(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+
+	// Stub out the binary so that the script can be run for real.
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "terraform")
+	stub := "#!/usr/bin/env bash\nif [ \"$1\" = state ]; then echo cloudflare_dns_record.a; else echo \"$@\" >> " + filepath.Join(dir, "calls") + "; fi\n"
+	assert.NoError(t, os.WriteFile(binary, []byte(stub), 0755))
+
+	script := buildImportScript([]stateImport{
+		{address: "cloudflare_dns_record.a", id: "zone/a"},
+		{address: "cloudflare_dns_record.b", id: "zone/b"},
+	})
+
+	cmd := exec.Command("bash", "-c", script)
+	cmd.Env = append(os.Environ(), "BINARY="+binary)
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+	assert.Equal(t, "[1/2] skipping cloudflare_dns_record.a as it is already imported\n[2/2] importing cloudflare_dns_record.b\nimported 2 resources\n", string(output))
+
+	calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+	assert.NoError(t, err)
+	assert.Equal(t, "import cloudflare_dns_record.b zone/b\n", string(calls))
+}
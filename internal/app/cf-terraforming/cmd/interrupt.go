@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// exitCodeInterrupted is the exit code when a run is interrupted, matching the
+// convention for processes terminated by SIGINT.
+const exitCodeInterrupted = 130
+
+// interrupted is set once the run has been interrupted. No more resource types
+// are started and the resources generated so far are output.
+var interrupted atomic.Bool
+
+// notifyInterrupt stops the run gracefully on the first SIGINT or SIGTERM.
+// Another signal terminates immediately. The returned function stops
+// listening for signals.
+func notifyInterrupt() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			interrupted.Store(true)
+			signal.Stop(signals)
+			log.Warn("interrupted, finishing the current resource type and writing the resources generated so far. Interrupt again to exit immediately")
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// exitIfInterrupted exits with exitCodeInterrupted if the run was interrupted.
+// This is deferred before anything else so that the partial output is written
+// first.
+func exitIfInterrupted() {
+	if interrupted.Load() {
+		log.Warn("the generated configuration is incomplete as the run was interrupted")
+		log.Exit(exitCodeInterrupted)
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can't be sent to the current process on Windows")
+	}
+	defer interrupted.Store(false)
+
+	stop := notifyInterrupt()
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, process.Signal(os.Interrupt))
+	assert.Eventually(t, interrupted.Load, time.Second, 10*time.Millisecond)
+}
+
+func TestFetchAPIResponsesInterrupted(t *testing.T) {
+	defer interrupted.Store(false)
+	interrupted.Store(true)
+
+	// Nothing is requested once interrupted.
+	responses := fetchAPIResponses([]apiRequest{
+		{resourceType: "cloudflare_dns_record", endpoints: []string{"/unreachable"}},
+	}, nil)
+
+	assert.Empty(t, responses)
+}
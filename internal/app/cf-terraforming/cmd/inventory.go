@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/spf13/cobra"
+)
+
+const outputFormatCSV = "csv"
+
+// generatedResourceObserver is called with the API response of every resource
+// as it is generated, allowing other commands to reuse generation to discover
+// resources.
+var generatedResourceObserver func(resourceType, name string, data map[string]interface{})
+
+// createdAttributes lists the attributes holding when a resource was created,
+// which differs between APIs.
+var createdAttributes = []string{"created_on", "created_at", "created", "creation_date"}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+}
+
+var inventoryCmd = &cobra.Command{
+	Use:    "inventory",
+	Short:  "List the discovered resources as CSV or JSON without generating any Terraform configuration",
+	Run:    runInventory(),
+	PreRun: sharedPreRun,
+}
+
+// inventoryRecord describes a single discovered resource.
+type inventoryRecord struct {
+	ResourceType string `json:"resource_type"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ZoneID       string `json:"zone_id,omitempty"`
+	AccountID    string `json:"account_id,omitempty"`
+	CreatedOn    string `json:"created_on,omitempty"`
+	Address      string `json:"address"`
+}
+
+func runInventory() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		format := outputFormat
+		if format == outputFormatHCL {
+			format = outputFormatCSV
+		}
+		if format != outputFormatCSV && format != outputFormatJSON {
+			log.Fatalf("unsupported output format %q, inventory supports csv or json", outputFormat)
+		}
+
+		var records []inventoryRecord
+		generatedResourceObserver = func(resourceType, name string, data map[string]interface{}) {
+			records = append(records, newInventoryRecord(resourceType, name, data))
+		}
+		defer func() { generatedResourceObserver = nil }()
+
+		defer func(format string) { outputFormat = format }(outputFormat)
+		outputFormat = outputFormatHCL
+
+		cmd.SetOut(io.Discard)
+		generateResources(nil)(cmd, args)
+		cmd.SetOut(nil)
+
+		var err error
+		if format == outputFormatJSON {
+			err = writeInventoryJSON(cmd.OutOrStdout(), records)
+		} else {
+			err = writeInventoryCSV(cmd.OutOrStdout(), records)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// newInventoryRecord builds the record for a resource from its API response.
+func newInventoryRecord(resourceType, name string, data map[string]interface{}) inventoryRecord {
+	record := inventoryRecord{
+		ResourceType: resourceType,
+		ID:           resourceAPIID(resourceType, data),
+		Name:         terraforming.DisplayName(resourceType, data),
+		ZoneID:       zoneID,
+		AccountID:    accountID,
+		Address:      fmt.Sprintf("%s.%s", resourceType, name),
+	}
+	if id, ok := data["id"].(float64); ok && record.ID == "" {
+		record.ID = fmt.Sprintf("%d", int(id))
+	}
+	if id, ok := data["zone_id"].(string); ok && record.ZoneID == "" {
+		record.ZoneID = id
+	}
+	for _, attribute := range createdAttributes {
+		if created, ok := data[attribute].(string); ok && created != "" {
+			record.CreatedOn = created
+			break
+		}
+	}
+
+	return record
+}
+
+func writeInventoryJSON(w io.Writer, records []inventoryRecord) error {
+	if records == nil {
+		records = []inventoryRecord{}
+	}
+
+	output, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(output))
+
+	return err
+}
+
+func writeInventoryCSV(w io.Writer, records []inventoryRecord) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"resource_type", "id", "name", "zone_id", "account_id", "created_on", "address"})
+	for _, r := range records {
+		_ = writer.Write([]string{r.ResourceType, r.ID, r.Name, r.ZoneID, r.AccountID, r.CreatedOn, r.Address})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryRecords(t *testing.T) {
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+	defer func() { zoneID = "" }()
+
+	records := []inventoryRecord{
+		newInventoryRecord("cloudflare_dns_record", "www_example_com_a", map[string]interface{}{
+			"id":         "372e67954025e0ba6aaa6d586b9e0b59",
+			"name":       "www.example.com",
+			"type":       "A",
+			"created_on": "2014-01-01T05:20:00.12345Z",
+		}),
+		newInventoryRecord("cloudflare_healthcheck", "terraform_managed_resource_1234", map[string]interface{}{
+			"id":          float64(1234),
+			"description": "Origin, health check",
+		}),
+	}
+
+	var output bytes.Buffer
+	require.NoError(t, writeInventoryCSV(&output, records))
+	assert.Equal(t, heredoc.Doc(`
+		resource_type,id,name,zone_id,account_id,created_on,address
+		cloudflare_dns_record,372e67954025e0ba6aaa6d586b9e0b59,www.example.com A,0da42c8d2132a9ddaf714f9e7c920711,,2014-01-01T05:20:00.12345Z,cloudflare_dns_record.www_example_com_a
+		cloudflare_healthcheck,1234,"Origin, health check",0da42c8d2132a9ddaf714f9e7c920711,,,cloudflare_healthcheck.terraform_managed_resource_1234
+	`), output.String())
+}
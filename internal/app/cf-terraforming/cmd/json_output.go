@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputFormatHCL  = "hcl"
+	outputFormatJSON = "json"
+)
+
+// writeJSONOutput converts the collected configuration to the JSON syntax and
+// outputs it.
+func writeJSONOutput(cmd *cobra.Command, f *hclwrite.File) {
+	if len(f.Body().Blocks()) == 0 {
+		return
+	}
+
+	output, err := terraforming.ConvertToJSONSyntax(hclwrite.Format(f.Bytes()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	n, _ := fmt.Fprint(cmd.OutOrStdout(), string(output))
+	metrics.addBytesWritten(n)
+}
+
+// importRecord describes how to import a single resource for consumption by
+// other tooling.
+type importRecord struct {
+	ResourceType string `json:"resource_type"`
+	ResourceName string `json:"resource_name"`
+	ImportID     string `json:"import_id"`
+	ZoneID       string `json:"zone_id,omitempty"`
+	AccountID    string `json:"account_id,omitempty"`
+}
+
+// writeImportRecords outputs the import records as a JSON array.
+func writeImportRecords(cmd *cobra.Command, records []importRecord) {
+	if records == nil {
+		records = []importRecord{}
+	}
+
+	output, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(output))
+}
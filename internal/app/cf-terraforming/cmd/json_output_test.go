@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteImportRecords(t *testing.T) {
+	expected := heredoc.Doc(`
+		[
+		  {
+		    "resource_type": "cloudflare_dns_record",
+		    "resource_name": "terraform_managed_resource_abc_0",
+		    "import_id": "0da42c8d2132a9ddaf714f9e7c920711/abc",
+		    "zone_id": "0da42c8d2132a9ddaf714f9e7c920711"
+		  }
+		]
+	`)
+
+	output := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.SetOut(output)
+	writeImportRecords(cmd, []importRecord{{
+		ResourceType: "cloudflare_dns_record",
+		ResourceName: "terraform_managed_resource_abc_0",
+		ImportID:     "0da42c8d2132a9ddaf714f9e7c920711/abc",
+		ZoneID:       "0da42c8d2132a9ddaf714f9e7c920711",
+	}})
+	assert.Equal(t, expected, output.String())
+
+	output.Reset()
+	writeImportRecords(cmd, nil)
+	assert.Equal(t, "[]\n", output.String())
+}
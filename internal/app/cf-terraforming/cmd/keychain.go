@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+// keychainService is the name the API token is stored under in the OS
+// keychain.
+const keychainService = "cf-terraforming"
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store an API token in the OS keychain",
+	Long: "Store an API token in the OS keychain so it doesn't need to be set in the environment or config file. " +
+		"The token is read from standard input unless set with --token and is stored for the selected --profile.",
+	Run: runLogin,
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the API token stored in the OS keychain",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := keyring.Delete(keychainService, keychainUser()); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			log.Fatalf("failed to remove the API token from the keychain: %s", err)
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Removed the API token for %s from the keychain\n", keychainUser())
+	},
+}
+
+// keychainUser returns the keychain entry for the selected profile.
+func keychainUser() string {
+	if profile := viper.GetString("profile"); profile != "" {
+		return profile
+	}
+
+	return "default"
+}
+
+// keychainToken returns the API token stored in the OS keychain for the
+// selected profile, or an empty string if there isn't one.
+func keychainToken() (string, error) {
+	token, err := keyring.Get(keychainService, keychainUser())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", nil
+	}
+
+	return token, err
+}
+
+// readToken reads the API token from the first line of r.
+func readToken(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if token := strings.TrimSpace(line); token != "" {
+		return token, nil
+	}
+
+	return "", errors.New("no API token was given")
+}
+
+func runLogin(cmd *cobra.Command, args []string) {
+	token := viper.GetString("token")
+	if token == "" {
+		_, _ = fmt.Fprint(cmd.ErrOrStderr(), "API token: ")
+		var err error
+		if token, err = readToken(os.Stdin); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	apiToken, accountID = token, viper.GetString("account")
+	if err := configureAPIClients(); err != nil {
+		log.Fatal(err)
+	}
+	if check := checkCredentials(context.Background()); check.status != doctorStatusOK {
+		log.Fatalf("failed to verify the API token: %s", check.detail)
+	}
+
+	if err := keyring.Set(keychainService, keychainUser(), token); err != nil {
+		log.Fatalf("failed to store the API token in the keychain: %s", err)
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Stored the API token for %s in the keychain\n", keychainUser())
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeychainToken(t *testing.T) {
+	keyring.MockInit()
+	defer viper.Set("profile", viper.GetString("profile"))
+
+	viper.Set("profile", "")
+	token, err := keychainToken()
+	require.NoError(t, err)
+	assert.Empty(t, token)
+
+	require.NoError(t, keyring.Set(keychainService, "default", "default-token"))
+	require.NoError(t, keyring.Set(keychainService, "acme", "acme-token"))
+
+	token, err = keychainToken()
+	require.NoError(t, err)
+	assert.Equal(t, "default-token", token)
+
+	viper.Set("profile", "acme")
+	token, err = keychainToken()
+	require.NoError(t, err)
+	assert.Equal(t, "acme-token", token)
+}
+
+func TestReadToken(t *testing.T) {
+	token, err := readToken(strings.NewReader("  abc123\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", token)
+
+	token, err = readToken(strings.NewReader("abc123"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", token)
+
+	_, err = readToken(strings.NewReader("\n"))
+	assert.EqualError(t, err, "no API token was given")
+}
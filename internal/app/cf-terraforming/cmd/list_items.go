@@ -0,0 +1,6 @@
+package cmd
+
+// maxItemsPerList caps the items generated for each list so that lists with
+// many thousands of entries, such as IP lists, stay manageable. Disabled when
+// 0.
+var maxItemsPerList int
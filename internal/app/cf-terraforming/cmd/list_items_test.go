@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAPIResponseListItems(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page := 1
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			_, _ = fmt.Sscanf(cursor, "page-%d", &page)
+		}
+		after := ""
+		if page < 3 {
+			after = fmt.Sprintf(`,"after":"page-%d"`, page+1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":"item-%d-a"},{"id":"item-%d-b"}],"result_info":{"cursors":{"before":"page-%d"%s}}}`, page, page, page, after)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, n int) { api, maxItemsPerList = client, n }(api, maxItemsPerList)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	endpoint := "/accounts/f037e56e89293a057740de681ac9abbe/rules/lists/2a4b8b2017aa4b3cb9e1151b52c81d22/items"
+	listID := []string{"2a4b8b2017aa4b3cb9e1151b52c81d22"}
+
+	tests := map[string]struct {
+		maxItems int
+		ids      []string
+		requests int32
+	}{
+		"every item": {
+			maxItems: 0,
+			ids:      []string{"item-1-a", "item-1-b", "item-2-a", "item-2-b", "item-3-a", "item-3-b"},
+			requests: 3,
+		},
+		"limited": {
+			maxItems: 3,
+			ids:      []string{"item-1-a", "item-1-b", "item-2-a"},
+			requests: 2,
+		},
+		"limit on a page boundary": {
+			maxItems: 2,
+			ids:      []string{"item-1-a", "item-1-b"},
+			requests: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests.Store(0)
+			maxItemsPerList = tc.maxItems
+
+			data, err := getAPIResponse(context.Background(), "cloudflare_list_item", nil, listID, endpoint)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, item := range data {
+				ids = append(ids, item.(map[string]interface{})["id"].(string))
+				assert.Equal(t, listID[0], item.(map[string]interface{})["list_id"])
+			}
+			assert.Equal(t, tc.ids, ids)
+			assert.Equal(t, tc.requests, requests.Load())
+		})
+	}
+}
+
+func TestGetAPIResponseListItemsPages(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			_, _ = fmt.Sscanf(p, "%d", &page)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":"item-%d-a"},{"id":"item-%d-b"}],"result_info":{"page":%d,"per_page":2,"total_pages":4}}`, page, page, page)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, n int) { api, maxItemsPerList = client, n }(api, maxItemsPerList)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	endpoint := "/accounts/f037e56e89293a057740de681ac9abbe/rules/lists/2a4b8b2017aa4b3cb9e1151b52c81d22/items"
+	listID := []string{"2a4b8b2017aa4b3cb9e1151b52c81d22"}
+
+	tests := map[string]struct {
+		maxItems int
+		ids      []string
+		requests int32
+	}{
+		"every item": {
+			maxItems: 0,
+			ids:      []string{"item-1-a", "item-1-b", "item-2-a", "item-2-b", "item-3-a", "item-3-b", "item-4-a", "item-4-b"},
+			requests: 4,
+		},
+		"limited": {
+			maxItems: 3,
+			ids:      []string{"item-1-a", "item-1-b", "item-2-a"},
+			requests: 2,
+		},
+		"limit on a page boundary": {
+			maxItems: 2,
+			ids:      []string{"item-1-a", "item-1-b"},
+			requests: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests.Store(0)
+			maxItemsPerList = tc.maxItems
+
+			data, err := getAPIResponse(context.Background(), "cloudflare_list_item", nil, listID, endpoint)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, item := range data {
+				ids = append(ids, item.(map[string]interface{})["id"].(string))
+			}
+			assert.Equal(t, tc.ids, ids)
+			assert.Equal(t, tc.requests, requests.Load())
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/spf13/cobra"
+)
+
+const outputFormatTable = "table"
+
+func init() {
+	rootCmd.AddCommand(listResourcesCmd)
+}
+
+var listResourcesCmd = &cobra.Command{
+	Use:   "list-resources",
+	Short: "List the supported resource types as a table or JSON",
+	Run:   runListResources(),
+}
+
+// v4GenerateResourceTypes are the resource types generated with the v4
+// provider, which are each handled by the old SDK in `generate`. The list is
+// sorted.
+var v4GenerateResourceTypes = []string{
+	"cloudflare_access_application",
+	"cloudflare_access_group",
+	"cloudflare_access_identity_provider",
+	"cloudflare_access_mutual_tls_certificate",
+	"cloudflare_access_rule",
+	"cloudflare_access_service_token",
+	"cloudflare_account_member",
+	"cloudflare_api_shield",
+	"cloudflare_argo",
+	"cloudflare_bot_management",
+	"cloudflare_byo_ip_prefix",
+	"cloudflare_certificate_pack",
+	"cloudflare_custom_hostname",
+	"cloudflare_custom_hostname_fallback_origin",
+	"cloudflare_custom_pages",
+	"cloudflare_custom_ssl",
+	"cloudflare_filter",
+	"cloudflare_firewall_rule",
+	"cloudflare_healthcheck",
+	"cloudflare_list",
+	"cloudflare_load_balancer",
+	"cloudflare_load_balancer_monitor",
+	"cloudflare_load_balancer_pool",
+	"cloudflare_logpush_job",
+	"cloudflare_managed_headers",
+	"cloudflare_origin_ca_certificate",
+	"cloudflare_page_rule",
+	"cloudflare_rate_limit",
+	"cloudflare_record",
+	"cloudflare_ruleset",
+	"cloudflare_spectrum_application",
+	"cloudflare_teams_list",
+	"cloudflare_teams_location",
+	"cloudflare_teams_proxy_endpoint",
+	"cloudflare_teams_rule",
+	"cloudflare_tiered_cache",
+	"cloudflare_tunnel",
+	"cloudflare_turnstile_widget",
+	"cloudflare_url_normalization_settings",
+	"cloudflare_user_agent_blocking_rule",
+	"cloudflare_waiting_room",
+	"cloudflare_waiting_room_event",
+	"cloudflare_waiting_room_rules",
+	"cloudflare_waiting_room_settings",
+	"cloudflare_worker_route",
+	"cloudflare_workers_kv_namespace",
+	"cloudflare_zone",
+	"cloudflare_zone_lockdown",
+	"cloudflare_zone_settings_override",
+}
+
+// resourceSupport describes what is supported for a resource type with a
+// version of the provider.
+type resourceSupport struct {
+	ResourceType        string   `json:"resource_type"`
+	Provider            string   `json:"provider"`
+	Generate            bool     `json:"generate"`
+	Import              bool     `json:"import"`
+	RequiredPermissions []string `json:"required_permissions"`
+}
+
+func runListResources() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		format := outputFormat
+		if format == outputFormatHCL {
+			format = outputFormatTable
+		}
+		if format != outputFormatTable && format != outputFormatJSON {
+			log.Fatalf("unsupported output format %q, list-resources supports table or json", outputFormat)
+		}
+
+		var err error
+		if format == outputFormatJSON {
+			err = writeSupportMatrixJSON(cmd.OutOrStdout(), supportMatrix())
+		} else {
+			err = writeSupportMatrixTable(cmd.OutOrStdout(), supportMatrix())
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// supportMatrix lists what is supported for every resource type with each
+// version of the provider, sorted by version and then resource type.
+func supportMatrix() []resourceSupport {
+	var matrix []resourceSupport
+	for _, resourceType := range v4GenerateResourceTypes {
+		matrix = append(matrix, resourceSupport{
+			ResourceType:        resourceType,
+			Provider:            "v4",
+			Generate:            true,
+			Import:              resourceImportStringFormats[resourceType] != "",
+			RequiredPermissions: permissionGroupsFor(resourceType),
+		})
+	}
+
+	for _, resourceType := range terraforming.SupportedResourceTypes() {
+		if resourceEndpoint(resourceType) == "" {
+			continue
+		}
+		matrix = append(matrix, resourceSupport{
+			ResourceType:        resourceType,
+			Provider:            "v5",
+			Generate:            true,
+			Import:              true,
+			RequiredPermissions: permissionGroupsFor(resourceType),
+		})
+	}
+
+	return matrix
+}
+
+// permissionGroupsFor returns the permission groups needed to read a resource
+// type, looking up v4 resource types by their v5 equivalent.
+func permissionGroupsFor(resourceType string) []string {
+	if groups, ok := requiredPermissionGroups[resourceType]; ok {
+		return slices.Clone(groups)
+	}
+	if migration, ok := resourceMigrations[resourceType]; ok {
+		return slices.Clone(requiredPermissionGroups[migration.v5Type])
+	}
+
+	return nil
+}
+
+func writeSupportMatrixTable(w io.Writer, matrix []resourceSupport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RESOURCE TYPE\tPROVIDER\tGENERATE\tIMPORT\tREQUIRED PERMISSIONS")
+	for _, support := range matrix {
+		permissions := strings.Join(support.RequiredPermissions, ", ")
+		if permissions == "" {
+			permissions = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", support.ResourceType, support.Provider, yesNo(support.Generate), yesNo(support.Import), permissions)
+	}
+
+	return tw.Flush()
+}
+
+func writeSupportMatrixJSON(w io.Writer, matrix []resourceSupport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(matrix)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
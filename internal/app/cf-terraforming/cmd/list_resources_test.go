@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestV4GenerateResourceTypes checks the list matches the resource types the
+// old SDK handles in generate.go so that list-resources doesn't go stale.
+func TestV4GenerateResourceTypes(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "generate.go", nil, 0)
+	require.NoError(t, err)
+
+	var handled []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		clause, ok := n.(*ast.CaseClause)
+		if !ok {
+			return true
+		}
+		for _, expr := range clause.List {
+			if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if value, err := strconv.Unquote(lit.Value); err == nil && strings.HasPrefix(value, "cloudflare_") {
+					handled = append(handled, value)
+				}
+			}
+		}
+		return true
+	})
+	slices.Sort(handled)
+
+	assert.Equal(t, handled, v4GenerateResourceTypes)
+}
+
+func TestSupportMatrix(t *testing.T) {
+	matrix := supportMatrix()
+
+	assert.Contains(t, matrix, resourceSupport{
+		ResourceType:        "cloudflare_record",
+		Provider:            "v4",
+		Generate:            true,
+		Import:              true,
+		RequiredPermissions: []string{"DNS Read"},
+	})
+	assert.Contains(t, matrix, resourceSupport{
+		ResourceType:        "cloudflare_dns_record",
+		Provider:            "v5",
+		Generate:            true,
+		Import:              true,
+		RequiredPermissions: []string{"DNS Read"},
+	})
+	for _, support := range matrix {
+		assert.NotEqual(t, "cloudflare_logpush_ownership_challenge", support.ResourceType, "resource types without an endpoint aren't supported")
+	}
+}
+
+func TestWriteSupportMatrix(t *testing.T) {
+	matrix := []resourceSupport{
+		{ResourceType: "cloudflare_record", Provider: "v4", Generate: true, Import: true, RequiredPermissions: []string{"DNS Read"}},
+		{ResourceType: "cloudflare_zero_trust_access_custom_page", Provider: "v5", Generate: true, Import: true},
+	}
+
+	var table bytes.Buffer
+	require.NoError(t, writeSupportMatrixTable(&table, matrix))
+	assert.Equal(t, strings.Join([]string{
+		"RESOURCE TYPE                             PROVIDER  GENERATE  IMPORT  REQUIRED PERMISSIONS",
+		"cloudflare_record                         v4        yes       yes     DNS Read",
+		"cloudflare_zero_trust_access_custom_page  v5        yes       yes     -",
+		"",
+	}, "\n"), table.String())
+
+	var output bytes.Buffer
+	require.NoError(t, writeSupportMatrixJSON(&output, matrix[:1]))
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &decoded))
+	assert.Equal(t, []map[string]interface{}{{
+		"resource_type":        "cloudflare_record",
+		"provider":             "v4",
+		"generate":             true,
+		"import":               true,
+		"required_permissions": []interface{}{"DNS Read"},
+	}}, decoded)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var (
+	// logLevel is the least severe level of log that is written.
+	logLevel string
+
+	// logFormat is either text or JSON, with one object per line, so logs
+	// from CI can be searched by their fields.
+	logFormat string
+)
+
+// configureLogging sets the level and format of the logger from --log-level
+// and --log-format. --verbose takes precedence over --log-level.
+func configureLogging(logger *logrus.Logger) error {
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("unsupported log level %q", logLevel)
+	}
+	if verbose {
+		level = logrus.DebugLevel
+	}
+	logger.SetLevel(level)
+
+	switch logFormat {
+	case logFormatText:
+		logger.SetFormatter(&logrus.TextFormatter{})
+	case logFormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("unsupported log format %q", logFormat)
+	}
+
+	return nil
+}
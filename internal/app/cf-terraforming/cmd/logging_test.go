@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	defer func(level, format string, v bool) { logLevel, logFormat, verbose = level, format, v }(logLevel, logFormat, verbose)
+
+	logger := logrus.New()
+	var output bytes.Buffer
+	logger.SetOutput(&output)
+
+	logLevel, logFormat, verbose = "warn", logFormatJSON, false
+	require.NoError(t, configureLogging(logger))
+	assert.Equal(t, logrus.WarnLevel, logger.GetLevel())
+
+	logger.WithFields(logrus.Fields{"resource": "cloudflare_dns_record"}).Warn("failed to fetch API endpoint")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &entry))
+	assert.Equal(t, "cloudflare_dns_record", entry["resource"])
+	assert.Equal(t, "failed to fetch API endpoint", entry["msg"])
+	assert.Equal(t, "warning", entry["level"])
+
+	verbose = true
+	require.NoError(t, configureLogging(logger))
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+
+	logLevel = "loud"
+	assert.EqualError(t, configureLogging(logger), `unsupported log level "loud"`)
+
+	logLevel, logFormat = "info", "xml"
+	assert.EqualError(t, configureLogging(logger), `unsupported log format "xml"`)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const mappingFileVersion = 1
+
+// mappingFile is the format of the file persisted with --mapping-file which
+// records the Terraform address of every generated resource by its ID.
+type mappingFile struct {
+	Version   int            `json:"version"`
+	Resources referenceIndex `json:"resources"`
+}
+
+// loadMapping reads the mapping file, returning an empty index when it
+// doesn't exist yet.
+func loadMapping(path string) (referenceIndex, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(referenceIndex), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+
+	idx, err := parseMapping(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file %s: %w", path, err)
+	}
+
+	return idx, nil
+}
+
+// parseMapping parses the contents of a mapping file.
+func parseMapping(content []byte) (referenceIndex, error) {
+	var mapping mappingFile
+	if err := json.Unmarshal(content, &mapping); err != nil {
+		return nil, err
+	}
+	if mapping.Version != mappingFileVersion {
+		return nil, fmt.Errorf("unsupported mapping file version %d", mapping.Version)
+	}
+	if mapping.Resources == nil {
+		mapping.Resources = make(referenceIndex)
+	}
+
+	return mapping.Resources, nil
+}
+
+// writeMapping persists the index to the mapping file.
+func writeMapping(path string, idx referenceIndex) error {
+	content, err := json.MarshalIndent(mappingFile{Version: mappingFileVersion, Resources: idx}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write mapping file: %w", err)
+	}
+	log.Debugf("wrote %s", path)
+
+	return nil
+}
+
+// loadMappingOrEmpty loads the mapping file when --mapping-file is set.
+func loadMappingOrEmpty() referenceIndex {
+	if mappingFilePath == "" {
+		return make(referenceIndex)
+	}
+
+	idx, err := loadMapping(mappingFilePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return idx
+}
+
+// name returns the resource name previously recorded for the resource so
+// that it is stable across runs.
+func (idx referenceIndex) name(resourceType string, data map[string]interface{}) (string, bool) {
+	address, ok := idx[resourceType][resourceAPIID(resourceType, data)]
+	if !ok {
+		return "", false
+	}
+
+	_, name, _ := strings.Cut(address, ".")
+	return name, true
+}
+
+// ids returns the ID of each resource by its address.
+func (idx referenceIndex) ids() map[string]string {
+	ids := make(map[string]string)
+	for _, addresses := range idx {
+		for id, address := range addresses {
+			ids[address] = id
+		}
+	}
+
+	return ids
+}
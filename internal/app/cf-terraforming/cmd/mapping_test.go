@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cf-terraforming-map.json")
+
+	idx, err := loadMapping(path)
+	require.NoError(t, err)
+	assert.Empty(t, idx)
+
+	idx.add("cloudflare_list", "blocked_ips", map[string]interface{}{"id": "2c0fc9fa937b11eaa1b71c4d701ab86e"})
+	require.NoError(t, writeMapping(path, idx))
+
+	loaded, err := loadMapping(path)
+	require.NoError(t, err)
+	assert.Equal(t, idx, loaded)
+
+	name, ok := loaded.name("cloudflare_list", map[string]interface{}{"id": "2c0fc9fa937b11eaa1b71c4d701ab86e"})
+	assert.True(t, ok)
+	assert.Equal(t, "blocked_ips", name)
+
+	_, ok = loaded.name("cloudflare_list", map[string]interface{}{"id": "023e105f4ecef8ad9ca31a8372d0c353"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// showMetrics reports how the run performed to stderr once it finishes.
+	showMetrics bool
+
+	// metricsOut is the file the metrics are written to as JSON, for
+	// tracking generation performance over time.
+	metricsOut string
+)
+
+// resourceTypeMetrics is how long was spent fetching and generating a
+// resource type.
+type resourceTypeMetrics struct {
+	Resources int     `json:"resources"`
+	Duration  float64 `json:"duration_seconds"`
+}
+
+// runMetricsFile is the JSON written to --metrics-out.
+type runMetricsFile struct {
+	Duration      float64                         `json:"duration_seconds"`
+	APICalls      int64                           `json:"api_calls"`
+	Retries       int64                           `json:"retries"`
+	BytesWritten  int64                           `json:"bytes_written"`
+	ResourceTypes map[string]*resourceTypeMetrics `json:"resource_types"`
+}
+
+// runMetrics is collected throughout the run.
+type runMetrics struct {
+	start        time.Time
+	apiCalls     atomic.Int64
+	retries      atomic.Int64
+	bytesWritten atomic.Int64
+
+	mu            sync.Mutex
+	resourceTypes map[string]*resourceTypeMetrics
+}
+
+// metrics is collected for the current run.
+var metrics = newRunMetrics()
+
+func newRunMetrics() *runMetrics {
+	return &runMetrics{start: time.Now(), resourceTypes: make(map[string]*resourceTypeMetrics)}
+}
+
+// addResourceType adds the resources generated and the time spent on a
+// resource type, which is recorded separately for fetching and generating.
+func (m *runMetrics) addResourceType(resourceType string, resources int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics, ok := m.resourceTypes[resourceType]
+	if !ok {
+		metrics = &resourceTypeMetrics{}
+		m.resourceTypes[resourceType] = metrics
+	}
+	metrics.Resources += resources
+	metrics.Duration += duration.Seconds()
+}
+
+// addBytesWritten records that the generated configuration was written.
+func (m *runMetrics) addBytesWritten(n int) {
+	m.bytesWritten.Add(int64(n))
+}
+
+func (m *runMetrics) snapshot(now time.Time) runMetricsFile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resourceTypes := make(map[string]*resourceTypeMetrics, len(m.resourceTypes))
+	for resourceType, metrics := range m.resourceTypes {
+		copied := *metrics
+		resourceTypes[resourceType] = &copied
+	}
+
+	return runMetricsFile{
+		Duration:      now.Sub(m.start).Seconds(),
+		APICalls:      m.apiCalls.Load(),
+		Retries:       m.retries.Load(),
+		BytesWritten:  m.bytesWritten.Load(),
+		ResourceTypes: resourceTypes,
+	}
+}
+
+// report outputs the metrics as text.
+func (m *runMetrics) report(w io.Writer, now time.Time) {
+	snapshot := m.snapshot(now)
+
+	_, _ = fmt.Fprintf(w, "\nRun metrics:\n")
+	_, _ = fmt.Fprintf(w, "  Duration: %s\n", time.Duration(snapshot.Duration*float64(time.Second)).Round(time.Millisecond))
+	_, _ = fmt.Fprintf(w, "  API calls: %d (%d retries)\n", snapshot.APICalls, snapshot.Retries)
+	_, _ = fmt.Fprintf(w, "  Bytes written: %d\n", snapshot.BytesWritten)
+	for _, resourceType := range sortedKeys(snapshot.ResourceTypes) {
+		metrics := snapshot.ResourceTypes[resourceType]
+		duration := time.Duration(metrics.Duration * float64(time.Second)).Round(time.Millisecond)
+		_, _ = fmt.Fprintf(w, "  %s: %d resource(s) in %s\n", resourceType, metrics.Resources, duration)
+	}
+}
+
+// write writes the metrics as JSON to the file.
+func (m *runMetrics) write(path string, now time.Time) error {
+	content, err := json.MarshalIndent(m.snapshot(now), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+
+	return nil
+}
+
+var reportMetricsOnce sync.Once
+
+func init() {
+	logrus.RegisterExitHandler(reportMetrics)
+}
+
+// reportMetrics outputs the metrics once the run finishes, including when it
+// exits early.
+func reportMetrics() {
+	reportMetricsOnce.Do(func() {
+		now := time.Now()
+		if showMetrics {
+			metrics.report(os.Stderr, now)
+		}
+		if metricsOut != "" {
+			if err := metrics.write(metricsOut, now); err != nil {
+				log.Warn(err)
+			}
+		}
+	})
+}
+
+// metricsTransport counts the requests made to the API, including retries.
+type metricsTransport struct {
+	rt http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	metrics.apiCalls.Add(1)
+	// The v4 SDK numbers each attempt when retrying a request.
+	if retry := req.Header.Get("X-Stainless-Retry-Count"); retry != "" && retry != "0" {
+		metrics.retries.Add(1)
+	}
+
+	return t.rt.RoundTrip(req)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMetrics(t *testing.T) {
+	m := newRunMetrics()
+	m.start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.apiCalls.Add(12)
+	m.retries.Add(2)
+	m.addBytesWritten(2048)
+	m.addResourceType("cloudflare_dns_record", 0, 1500*time.Millisecond)
+	m.addResourceType("cloudflare_dns_record", 150, 250*time.Millisecond)
+	m.addResourceType("cloudflare_page_rule", 3, 100*time.Millisecond)
+
+	now := m.start.Add(2 * time.Second)
+
+	var output bytes.Buffer
+	m.report(&output, now)
+	assert.Equal(t, heredoc.Doc(`
+
+		Run metrics:
+		  Duration: 2s
+		  API calls: 12 (2 retries)
+		  Bytes written: 2048
+		  cloudflare_dns_record: 150 resource(s) in 1.75s
+		  cloudflare_page_rule: 3 resource(s) in 100ms
+	`), output.String())
+
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	require.NoError(t, m.write(path, now))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"duration_seconds": 2,
+		"api_calls": 12,
+		"retries": 2,
+		"bytes_written": 2048,
+		"resource_types": {
+			"cloudflare_dns_record": {"resources": 150, "duration_seconds": 1.75},
+			"cloudflare_page_rule": {"resources": 3, "duration_seconds": 0.1}
+		}
+	}`, string(content))
+}
+
+func TestMetricsTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	defer func(m *runMetrics) { metrics = m }(metrics)
+	metrics = newRunMetrics()
+
+	client := &http.Client{Transport: &metricsTransport{rt: http.DefaultTransport}}
+	for _, retry := range []string{"0", "1"} {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Stainless-Retry-Count", retry)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, int64(2), metrics.apiCalls.Load())
+	assert.Equal(t, int64(1), metrics.retries.Load())
+}
@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/cobra"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const migrateImportsFilename = "migrate_imports.tf"
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().StringVar(&configDir, "config-dir", ".", "Directory containing the existing Terraform configuration to migrate")
+	migrateCmd.Flags().StringVar(&statePath, "state", "", "Terraform state file for the configuration being migrated, used to import renamed resources")
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite a v4 provider configuration in --config-dir for the v5 provider into --output-dir",
+	Run:   runMigrate(),
+}
+
+// resourceMigration describes how a v4 resource is rewritten for v5. Nested
+// blocks are always converted to attributes and don't need listing.
+type resourceMigration struct {
+	// v5Type is the name of the resource in v5 when it has been renamed.
+	v5Type string
+
+	// renames maps v4 attribute names to their v5 equivalent.
+	renames map[string]string
+
+	// removed lists attributes that no longer exist in v5.
+	removed []string
+
+	// listBlocks lists nested blocks which become a list of objects in v5
+	// even when only a single block is present.
+	listBlocks []string
+
+	// transform makes any further changes that can't be described above.
+	transform func(body *hclwrite.Body)
+}
+
+var resourceMigrations = map[string]resourceMigration{
+	"cloudflare_access_application": {
+		v5Type:     "cloudflare_zero_trust_access_application",
+		listBlocks: []string{"cors_headers", "destinations", "footer_links", "policies"},
+	},
+	"cloudflare_access_group": {
+		v5Type:     "cloudflare_zero_trust_access_group",
+		listBlocks: []string{"include", "exclude", "require"},
+	},
+	"cloudflare_access_policy": {
+		v5Type:     "cloudflare_zero_trust_access_policy",
+		listBlocks: []string{"include", "exclude", "require", "approval_group"},
+	},
+	"cloudflare_access_service_token": {v5Type: "cloudflare_zero_trust_access_service_token"},
+	"cloudflare_argo_tunnel": {
+		v5Type:  "cloudflare_zero_trust_tunnel_cloudflared",
+		renames: map[string]string{"secret": "tunnel_secret"},
+	},
+	"cloudflare_device_posture_rule": {
+		v5Type:     "cloudflare_zero_trust_device_posture_rule",
+		listBlocks: []string{"match"},
+	},
+	"cloudflare_record": {
+		v5Type:  "cloudflare_dns_record",
+		renames: map[string]string{"value": "content"},
+		removed: []string{"allow_overwrites", "hostname"},
+	},
+	"cloudflare_teams_list": {v5Type: "cloudflare_zero_trust_list"},
+	"cloudflare_teams_location": {
+		v5Type:     "cloudflare_zero_trust_dns_location",
+		listBlocks: []string{"networks"},
+	},
+	"cloudflare_teams_rule":   {v5Type: "cloudflare_zero_trust_gateway_policy"},
+	"cloudflare_tunnel":       {v5Type: "cloudflare_zero_trust_tunnel_cloudflared", renames: map[string]string{"secret": "tunnel_secret"}},
+	"cloudflare_tunnel_route": {v5Type: "cloudflare_zero_trust_tunnel_cloudflared_route"},
+	"cloudflare_tunnel_config": {
+		v5Type:     "cloudflare_zero_trust_tunnel_cloudflared_config",
+		listBlocks: []string{"ingress_rule"},
+	},
+	"cloudflare_tunnel_virtual_network": {v5Type: "cloudflare_zero_trust_tunnel_cloudflared_virtual_network"},
+	"cloudflare_worker_domain":          {v5Type: "cloudflare_workers_custom_domain"},
+	"cloudflare_worker_route":           {v5Type: "cloudflare_workers_route", renames: map[string]string{"script_name": "script"}},
+	"cloudflare_worker_script": {
+		v5Type:  "cloudflare_workers_script",
+		renames: map[string]string{"name": "script_name"},
+	},
+	"cloudflare_load_balancer": {
+		listBlocks: []string{"rules", "region_pools", "pop_pools", "country_pools"},
+	},
+	"cloudflare_load_balancer_pool": {
+		listBlocks: []string{"origins", "load_shedding", "origin_steering"},
+	},
+	"cloudflare_ruleset": {
+		listBlocks: []string{"rules"},
+	},
+	"cloudflare_zone": {
+		renames: map[string]string{"zone": "name"},
+		removed: []string{"jump_start", "plan"},
+		transform: func(body *hclwrite.Body) {
+			// The account is now an object rather than just the ID.
+			if attr := body.GetAttribute("account_id"); attr != nil {
+				tokens := attr.Expr().BuildTokens(nil)
+				body.RemoveAttribute("account_id")
+				body.SetAttributeRaw("account", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+					{Name: hclwrite.TokensForIdentifier("id"), Value: tokens},
+				}))
+			}
+		},
+	},
+}
+
+// unsupportedMigrations lists v4 resources that can't be rewritten
+// automatically along with what to do instead.
+var unsupportedMigrations = map[string]string{
+	"cloudflare_zone_settings_override": "generate a cloudflare_zone_setting for each setting instead",
+}
+
+// metaBlocks are nested blocks that are part of the Terraform language rather
+// than the resource schema so are left as blocks.
+var metaBlocks = map[string]bool{
+	"connection":  true,
+	"dynamic":     true,
+	"lifecycle":   true,
+	"provisioner": true,
+}
+
+func runMigrate() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		source, err := filepath.Abs(configDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		destination, err := filepath.Abs(outputDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if source == destination {
+			log.Fatal("--output-dir must be different to --config-dir so the v4 configuration isn't overwritten")
+		}
+
+		paths, err := filepath.Glob(filepath.Join(configDir, "*.tf"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(paths) == 0 {
+			log.Fatalf("no Terraform configuration found in %s", configDir)
+		}
+
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			f, diags := hclwrite.ParseConfig(content, path, hcl.InitialPos)
+			if diags.HasErrors() {
+				log.Fatalf("failed to parse %s: %s", path, diags.Error())
+			}
+
+			migrateFile(f)
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				log.Fatalf("failed to create output directory %s: %s", outputDir, err)
+			}
+			if err := os.WriteFile(filepath.Join(outputDir, filepath.Base(path)), formatMigratedFile(f), 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if statePath == "" {
+			log.Warn("no --state provided so renamed resources need removing from state and importing manually")
+			return
+		}
+
+		content, err := os.ReadFile(statePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		imports, err := migrationImports(content)
+		if err != nil {
+			log.Fatalf("failed to parse %s: %s", statePath, err)
+		}
+		if len(imports) == 0 {
+			return
+		}
+
+		f := hclwrite.NewEmptyFile()
+		for _, i := range imports {
+			appendImportBlock(f.Body(), i)
+			f.Body().AppendNewline()
+		}
+		if err := writeHCLFile(filepath.Join(outputDir, migrateImportsFilename), f); err != nil {
+			log.Fatal(err)
+		}
+
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "# Remove the v4 resources from state before planning with the v5 provider.\n# They are imported under their new address by %s.\n", migrateImportsFilename)
+		for _, i := range imports {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s state rm %s\n", targetBinary(), shellQuote(i.from))
+		}
+	}
+}
+
+// migrateFile rewrites every v4 resource in the file and any references to
+// renamed resource types.
+func migrateFile(f *hclwrite.File) {
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+
+		resourceType := block.Labels()[0]
+		if !strings.HasPrefix(resourceType, "cloudflare_") {
+			continue
+		}
+		if hint, ok := unsupportedMigrations[resourceType]; ok {
+			log.Warnf("%s.%s cannot be migrated automatically, %s", resourceType, block.Labels()[1], hint)
+			continue
+		}
+
+		migration := resourceMigrations[resourceType]
+		migrateBody(block.Body(), migration)
+		if migration.v5Type != "" {
+			block.SetLabels([]string{migration.v5Type, block.Labels()[1]})
+		}
+	}
+
+	renamed := make(map[string]string)
+	for v4Type, migration := range resourceMigrations {
+		if migration.v5Type != "" {
+			renamed[v4Type] = migration.v5Type
+		}
+	}
+	renameBodyReferences(f.Body(), renamed)
+}
+
+// migrateBody applies the attribute changes to a resource and converts its
+// nested blocks to attributes.
+func migrateBody(body *hclwrite.Body, migration resourceMigration) {
+	for _, from := range sortedKeys(migration.renames) {
+		to := migration.renames[from]
+		if attr := body.GetAttribute(from); attr != nil && from != to {
+			tokens := attr.Expr().BuildTokens(nil)
+			body.RemoveAttribute(from)
+			body.SetAttributeRaw(to, tokens)
+		}
+	}
+
+	for _, name := range migration.removed {
+		body.RemoveAttribute(name)
+	}
+
+	lists := make(map[string]bool, len(migration.listBlocks))
+	for _, name := range migration.listBlocks {
+		lists[name] = true
+	}
+	convertBlocksToAttributes(body, lists)
+
+	if migration.transform != nil {
+		migration.transform(body)
+	}
+}
+
+// convertBlocksToAttributes replaces nested blocks with the equivalent object
+// attribute. Repeated blocks, or those listed, become a list of objects.
+func convertBlocksToAttributes(body *hclwrite.Body, lists map[string]bool) {
+	var order []string
+	grouped := make(map[string][]*hclwrite.Block)
+	for _, block := range body.Blocks() {
+		if metaBlocks[block.Type()] {
+			if block.Type() == "dynamic" {
+				log.Warnf("dynamic %q blocks need rewriting as a for expression manually", block.Labels()[0])
+			}
+			continue
+		}
+		if _, ok := grouped[block.Type()]; !ok {
+			order = append(order, block.Type())
+		}
+		grouped[block.Type()] = append(grouped[block.Type()], block)
+	}
+
+	for _, name := range order {
+		blocks := grouped[name]
+
+		objects := make([]hclwrite.Tokens, 0, len(blocks))
+		for _, block := range blocks {
+			objects = append(objects, blockToObject(block))
+			body.RemoveBlock(block)
+		}
+
+		if len(objects) == 1 && !lists[name] {
+			body.SetAttributeRaw(name, objects[0])
+		} else {
+			body.SetAttributeRaw(name, hclwrite.TokensForTuple(objects))
+		}
+	}
+
+	// The converted attributes are appended to the body so the remaining
+	// blocks are moved after them.
+	if len(order) == 0 {
+		return
+	}
+	for _, block := range body.Blocks() {
+		body.RemoveBlock(block)
+		body.AppendNewline()
+		body.AppendBlock(block)
+	}
+}
+
+var repeatedBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// formatMigratedFile formats the migrated configuration, removing the blank
+// lines left behind by removed attributes and blocks.
+func formatMigratedFile(f *hclwrite.File) []byte {
+	return repeatedBlankLines.ReplaceAll(hclwrite.Format(f.Bytes()), []byte("\n\n"))
+}
+
+// blockToObject converts a block, including any blocks nested within it, to
+// an object expression.
+func blockToObject(block *hclwrite.Block) hclwrite.Tokens {
+	convertBlocksToAttributes(block.Body(), nil)
+
+	attributes := block.Body().Attributes()
+	attrs := make([]hclwrite.ObjectAttrTokens, 0, len(attributes))
+	for _, name := range sortedKeys(attributes) {
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(name),
+			Value: attributes[name].Expr().BuildTokens(nil),
+		})
+	}
+
+	return hclwrite.TokensForObject(attrs)
+}
+
+// renameBodyReferences updates references to renamed resource types, such as
+// `cloudflare_record.www.id`, everywhere in the body.
+func renameBodyReferences(body *hclwrite.Body, renamed map[string]string) {
+	for name, attr := range body.Attributes() {
+		tokens := attr.Expr().BuildTokens(nil)
+
+		changed := false
+		for i := 0; i+1 < len(tokens); i++ {
+			if tokens[i].Type != hclsyntax.TokenIdent || tokens[i+1].Type != hclsyntax.TokenDot {
+				continue
+			}
+			if i > 0 && tokens[i-1].Type == hclsyntax.TokenDot {
+				continue
+			}
+			if v5Type, ok := renamed[string(tokens[i].Bytes)]; ok {
+				tokens[i] = &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(v5Type), SpacesBefore: tokens[i].SpacesBefore}
+				changed = true
+			}
+		}
+
+		if changed {
+			body.SetAttributeRaw(name, tokens)
+		}
+	}
+
+	for _, block := range body.Blocks() {
+		renameBodyReferences(block.Body(), renamed)
+	}
+}
+
+// migrationImport is a resource which needs removing from state and importing
+// again as it has been renamed.
+type migrationImport struct {
+	from, to, id string
+}
+
+// migrationImports finds the resources in a v4 state file whose type has been
+// renamed in v5.
+func migrationImports(content []byte) ([]migrationImport, error) {
+	var state terraformState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	var imports []migrationImport
+	for _, resource := range state.Resources {
+		migration, ok := resourceMigrations[resource.Type]
+		if resource.Mode != "managed" || !ok || migration.v5Type == "" {
+			continue
+		}
+		if resource.Module != "" {
+			log.Warnf("skipping %s.%s.%s as only resources in the root module are migrated", resource.Module, resource.Type, resource.Name)
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			name := resource.Name
+			switch key := instance.IndexKey.(type) {
+			case float64:
+				name = fmt.Sprintf("%s[%d]", name, int(key))
+			case string:
+				name = fmt.Sprintf("%s[%q]", name, key)
+			}
+
+			id, err := migrationImportID(migration.v5Type, instance.Attributes)
+			if err != nil {
+				log.Warnf("skipping %s.%s: %s", resource.Type, name, err)
+				continue
+			}
+
+			imports = append(imports, migrationImport{
+				from: fmt.Sprintf("%s.%s", resource.Type, name),
+				to:   fmt.Sprintf("%s.%s", migration.v5Type, name),
+				id:   id,
+			})
+		}
+	}
+
+	sort.Slice(imports, func(i, j int) bool { return imports[i].from < imports[j].from })
+
+	return imports, nil
+}
+
+// migrationImportID builds the v5 import ID for a resource from its v4 state.
+func migrationImportID(resourceType string, attributes map[string]interface{}) (string, error) {
+	format, ok := terraforming.ImportIDFormat(resourceType)
+	if !ok {
+		if format, ok = resourceImportStringFormats[resourceType]; !ok {
+			return "", fmt.Errorf("%s does not have an import format defined", resourceType)
+		}
+	}
+
+	// Resources which can belong to either an account or a zone use the same
+	// identifier as `import`.
+	values := map[string]string{"identifier_type": "account"}
+	values["identifier_value"], _ = attributes["account_id"].(string)
+	if values["identifier_value"] == "" {
+		values["identifier_type"] = "zone"
+		values["identifier_value"], _ = attributes["zone_id"].(string)
+	}
+
+	segments := strings.Split(format, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok {
+			switch v := attributes[name].(type) {
+			case string:
+				value = v
+			case float64:
+				value = fmt.Sprintf("%d", int(v))
+			}
+		}
+		if value == "" {
+			return "", fmt.Errorf("no value found for %q", name)
+		}
+		segments[i] = value
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// appendImportBlock imports a renamed resource under its new address.
+func appendImportBlock(body *hclwrite.Body, i migrationImport) {
+	block := body.AppendNewBlock("import", nil).Body()
+	block.SetAttributeTraversal("to", referenceTraversal(i.to))
+	block.SetAttributeValue("id", cty.StringVal(i.id))
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateFile(t *testing.T) {
+	input := heredoc.Doc(`
+		resource "cloudflare_record" "srv" {
+		  zone_id          = var.zone_id
+		  name             = "_sip._tcp"
+		  type             = "SRV"
+		  allow_overwrites = true
+
+		  data {
+		    priority = 10
+		    target   = "sip.example.com"
+		  }
+
+		  lifecycle {
+		    prevent_destroy = true
+		  }
+		}
+
+		resource "cloudflare_access_policy" "allow" {
+		  name = "Allow employees"
+
+		  include {
+		    email_domain = ["example.com"]
+		  }
+		}
+
+		output "srv_hostname" {
+		  value = cloudflare_record.srv.hostname
+		}
+	`)
+	expected := heredoc.Doc(`
+		resource "cloudflare_dns_record" "srv" {
+		  zone_id = var.zone_id
+		  name    = "_sip._tcp"
+		  type    = "SRV"
+
+		  data = {
+		    priority = 10
+		    target   = "sip.example.com"
+		  }
+
+		  lifecycle {
+		    prevent_destroy = true
+		  }
+		}
+
+		resource "cloudflare_zero_trust_access_policy" "allow" {
+		  name = "Allow employees"
+
+		  include = [{
+		    email_domain = ["example.com"]
+		  }]
+		}
+
+		output "srv_hostname" {
+		  value = cloudflare_dns_record.srv.hostname
+		}
+	`)
+
+	f, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	migrateFile(f)
+	assert.Equal(t, expected, string(formatMigratedFile(f)))
+}
+
+func TestMigrationImports(t *testing.T) {
+	state := heredoc.Doc(`
+		{
+		  "version": 4,
+		  "resources": [
+		    {
+		      "mode": "managed",
+		      "type": "cloudflare_record",
+		      "name": "www",
+		      "instances": [{"attributes": {"id": "372e67954025e0ba6aaa6d586b9e0b59", "zone_id": "0da42c8d2132a9ddaf714f9e7c920711"}}]
+		    },
+		    {
+		      "mode": "managed",
+		      "type": "cloudflare_access_application",
+		      "name": "wiki",
+		      "instances": [{"attributes": {"id": "aa0a4aab-672b-4bdb-bc33-a59f1130a11f", "account_id": "f037e56e89293a057740de681ac9abbe"}}]
+		    },
+		    {
+		      "mode": "managed",
+		      "type": "cloudflare_load_balancer",
+		      "name": "www",
+		      "instances": [{"attributes": {"id": "699d98642c564d2e855e9661899b7252", "zone_id": "0da42c8d2132a9ddaf714f9e7c920711"}}]
+		    }
+		  ]
+		}
+	`)
+
+	imports, err := migrationImports([]byte(state))
+	require.NoError(t, err)
+	assert.Equal(t, []migrationImport{
+		{
+			from: "cloudflare_access_application.wiki",
+			to:   "cloudflare_zero_trust_access_application.wiki",
+			id:   "account/f037e56e89293a057740de681ac9abbe/aa0a4aab-672b-4bdb-bc33-a59f1130a11f",
+		},
+		{
+			from: "cloudflare_record.www",
+			to:   "cloudflare_dns_record.www",
+			id:   "0da42c8d2132a9ddaf714f9e7c920711/372e67954025e0ba6aaa6d586b9e0b59",
+		},
+	}, imports)
+}
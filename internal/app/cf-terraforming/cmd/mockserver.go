@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	cfterraforming "github.com/cloudflare/cf-terraforming"
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// mockCassetteDir is a directory of cassettes to serve in place of those
+	// bundled with cf-terraforming.
+	mockCassetteDir string
+
+	// mockCassettes limits the cassettes served to those named.
+	mockCassettes []string
+)
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+
+	mockServerCmd.Flags().StringVar(&serveListenAddress, "listen", "127.0.0.1:8080", "Address to listen on")
+	mockServerCmd.Flags().StringVar(&fixtureVersion, "fixture-version", "v5", "Major version of the provider to serve the responses of. Supported values: v4, v5")
+	mockServerCmd.Flags().StringVar(&mockCassetteDir, "cassette-dir", "", "Directory of VCR cassettes to serve in place of those bundled with cf-terraforming")
+	mockServerCmd.Flags().StringArrayVar(&mockCassettes, "cassette", []string{}, "Name of a cassette to serve, such as cloudflare_dns_record, instead of all of them. Can be repeated")
+}
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mockserver",
+	Short: "Serve the recorded API responses used by the tests as a fake Cloudflare API",
+	Long: "Serve the recorded API responses used by the tests as a fake Cloudflare API, so cf-terraforming can be tried, " +
+		"and tools built on it tested, without credentials. Point --api-url at the server and use the account " +
+		fixtureAccountID + " or zone " + fixtureZoneID + " with any credentials.",
+	Run: runMockServer,
+}
+
+// mockInteraction is a recorded response along with the query of the request
+// it was recorded for.
+type mockInteraction struct {
+	cassette string
+	query    string
+	response cassette.Response
+}
+
+// mockAPI replays the recorded responses for requests with the same method,
+// path and query. A request with a different query, such as for another page,
+// is given the first response recorded for its path.
+type mockAPI struct {
+	routes map[string][]mockInteraction
+}
+
+func runMockServer(cmd *cobra.Command, args []string) {
+	if fixtureVersion != "v4" && fixtureVersion != "v5" {
+		log.Fatalf("unsupported --fixture-version %q", fixtureVersion)
+	}
+
+	var (
+		fsys fs.FS
+		err  error
+	)
+	if mockCassetteDir != "" {
+		fsys = os.DirFS(mockCassetteDir)
+	} else if fsys, err = fs.Sub(cfterraforming.Cassettes, path.Join("testdata/cloudflare", fixtureVersion)); err != nil {
+		log.Fatal(err)
+	}
+
+	api, err := loadMockAPI(fsys, mockCassettes)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("serving %d recorded responses on %s, use --api-url http://%s/client/v4 with --account %s or --zone %s",
+		api.len(), serveListenAddress, serveListenAddress, fixtureAccountID, fixtureZoneID)
+	if err := http.ListenAndServe(serveListenAddress, api); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadMockAPI loads the cassettes in the directory, or only those named. The
+// first response recorded for a request is served where several cassettes
+// recorded the same one.
+func loadMockAPI(fsys fs.FS, names []string) (*mockAPI, error) {
+	paths, err := fs.Glob(fsys, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 {
+		for _, name := range names {
+			if !slices.Contains(paths, name+".yaml") {
+				return nil, fmt.Errorf("no cassette named %q", name)
+			}
+		}
+		paths = slices.DeleteFunc(paths, func(p string) bool {
+			return !slices.Contains(names, strings.TrimSuffix(p, ".yaml"))
+		})
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no cassettes to serve")
+	}
+
+	api := &mockAPI{routes: make(map[string][]mockInteraction)}
+	for _, p := range paths {
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		var c struct {
+			Interactions []*cassette.Interaction `yaml:"interactions"`
+		}
+		if err := yaml.Unmarshal(content, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", p, err)
+		}
+
+		name := strings.TrimSuffix(p, ".yaml")
+		for _, interaction := range c.Interactions {
+			u, err := url.Parse(interaction.Request.URL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URL in cassette %s: %w", p, err)
+			}
+			key := interaction.Request.Method + " " + u.Path
+			query := u.Query().Encode()
+			if slices.ContainsFunc(api.routes[key], func(i mockInteraction) bool { return i.query == query }) {
+				log.Debugf("%s %s is already recorded, ignoring the response in %s", key, query, name)
+				continue
+			}
+			api.routes[key] = append(api.routes[key], mockInteraction{cassette: name, query: query, response: interaction.Response})
+		}
+	}
+
+	return api, nil
+}
+
+// len returns the number of responses served.
+func (api *mockAPI) len() int {
+	n := 0
+	for _, interactions := range api.routes {
+		n += len(interactions)
+	}
+
+	return n
+}
+
+func (api *mockAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	interactions := api.routes[r.Method+" "+r.URL.Path]
+	if len(interactions) == 0 {
+		log.Warnf("no response recorded for %s %s", r.Method, r.URL)
+		writeMockError(w, http.StatusNotFound, 7003, "No route for that URI")
+		return
+	}
+
+	interaction := interactions[0]
+	query := r.URL.Query().Encode()
+	if i := slices.IndexFunc(interactions, func(i mockInteraction) bool { return i.query == query }); i >= 0 {
+		interaction = interactions[i]
+	}
+	log.Debugf("serving %s %s from %s", r.Method, r.URL, interaction.cassette)
+
+	for name, values := range interaction.response.Headers {
+		// The body is served in full rather than with its recorded length.
+		if name == "Content-Length" {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	code := interaction.response.Code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(interaction.response.Body))
+}
+
+// writeMockError responds with an error in the API's format.
+func writeMockError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  false,
+		"errors":   []map[string]interface{}{{"code": code, "message": message}},
+		"messages": []interface{}{},
+		"result":   nil,
+	})
+}
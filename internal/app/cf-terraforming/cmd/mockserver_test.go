@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	cfterraforming "github.com/cloudflare/cf-terraforming"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockCassette(url, body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(heredoc.Docf(`
+		---
+		version: 1
+		interactions:
+		- request:
+		    url: %s
+		    method: GET
+		  response:
+		    body: '%s'
+		    headers:
+		      Content-Type:
+		      - application/json
+		    status: 200 OK
+		    code: 200
+	`, url, body))}
+}
+
+func TestMockAPI(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cloudflare_dns_record.yaml": mockCassette(
+			"https://api.cloudflare.com/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?page=1&per_page=100",
+			`{"result":[{"name":"www.example.com"}]}`,
+		),
+		"cloudflare_dns_record_caa.yaml": mockCassette(
+			"https://api.cloudflare.com/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?per_page=100&page=1",
+			`{"result":[{"name":"caa.example.com"}]}`,
+		),
+		"cloudflare_dns_record_page_2.yaml": mockCassette(
+			"https://api.cloudflare.com/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?page=2&per_page=100",
+			`{"result":[]}`,
+		),
+	}
+
+	api, err := loadMockAPI(fsys, nil)
+	require.NoError(t, err)
+	// The CAA cassette recorded the same request as the first.
+	assert.Equal(t, 2, api.len())
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?per_page=100&page=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"result":[{"name":"www.example.com"}]}`, body)
+
+	code, body = get("/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?page=2&per_page=100")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"result":[]}`, body)
+
+	_, body = get("/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?page=1&per_page=50")
+	assert.Equal(t, `{"result":[{"name":"www.example.com"}]}`, body, "falls back to the first response for the path")
+
+	code, body = get("/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/page_rules")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.JSONEq(t, `{"success":false,"errors":[{"code":7003,"message":"No route for that URI"}],"messages":[],"result":null}`, body)
+
+	api, err = loadMockAPI(fsys, []string{"cloudflare_dns_record_caa"})
+	require.NoError(t, err)
+	server.Config.Handler = api
+	_, body = get("/client/v4/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records?page=1&per_page=100")
+	assert.Equal(t, `{"result":[{"name":"caa.example.com"}]}`, body)
+
+	_, err = loadMockAPI(fsys, []string{"cloudflare_page_rule"})
+	assert.EqualError(t, err, `no cassette named "cloudflare_page_rule"`)
+}
+
+func TestMockAPIBundledCassettes(t *testing.T) {
+	for _, version := range []string{"v4", "v5"} {
+		fsys, err := fs.Sub(cfterraforming.Cassettes, "testdata/cloudflare/"+version)
+		require.NoError(t, err)
+
+		api, err := loadMockAPI(fsys, nil)
+		require.NoError(t, err, version)
+		assert.NotZero(t, api.len(), version)
+	}
+
+	fsys, err := fs.Sub(cfterraforming.Cassettes, "testdata/cloudflare/v5")
+	require.NoError(t, err)
+	api, err := loadMockAPI(fsys, []string{"cloudflare_account"})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/client/v4/accounts", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response struct {
+		Result []struct {
+			Name string `json:"name"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Result)
+	assert.Equal(t, "Foo Production", response.Result[0].Name)
+}
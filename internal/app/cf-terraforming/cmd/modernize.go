@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// firewallRuleActions maps the actions of firewall rules to the equivalent
+// custom rule action, explaining the translation where the behaviour differs.
+var firewallRuleActions = map[string]struct {
+	action      string
+	explanation string
+}{
+	"allow":             {action: "skip", explanation: "allow is replaced by skipping the remaining custom rules"},
+	"block":             {action: "block"},
+	"bypass":            {action: "skip", explanation: "bypass is replaced by skipping the same products"},
+	"challenge":         {action: "challenge"},
+	"js_challenge":      {action: "js_challenge"},
+	"log":               {action: "log"},
+	"managed_challenge": {action: "managed_challenge"},
+}
+
+var pageRulePlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// modernizeResources translates deprecated v4 resources into the ruleset
+// equivalent, returning the resources that couldn't be translated so they can
+// be generated as before.
+func modernizeResources(resourceType string, data []interface{}) (*hclwrite.File, []interface{}) {
+	switch resourceType {
+	case "cloudflare_filter":
+		f := hclwrite.NewEmptyFile()
+		appendComment(f.Body(), "cloudflare_filter expressions are inlined into the cloudflare_ruleset generated for cloudflare_firewall_rule by --modernize.")
+		return f, nil
+	case "cloudflare_firewall_rule":
+		return modernizeFirewallRules(data), nil
+	case "cloudflare_page_rule":
+		return modernizePageRules(data)
+	default:
+		return nil, data
+	}
+}
+
+// modernizeFirewallRules translates firewall rules into a single ruleset in
+// the custom rules phase, ordered by their priority.
+func modernizeFirewallRules(data []interface{}) *hclwrite.File {
+	rules := make([]map[string]interface{}, 0, len(data))
+	for _, item := range data {
+		rules = append(rules, item.(map[string]interface{}))
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, aOK := rules[i]["priority"].(float64)
+		b, bOK := rules[j]["priority"].(float64)
+		if aOK && bOK {
+			return a < b
+		}
+		return aOK && !bOK
+	})
+
+	f := hclwrite.NewEmptyFile()
+	appendComment(f.Body(),
+		"Translated from cloudflare_firewall_rule by --modernize. Firewall rules are",
+		"deprecated in favour of custom rules in the http_request_firewall_custom phase.",
+	)
+	ruleset := newModernRuleset(f.Body(), "firewall_custom_rules", "http_request_firewall_custom", "Custom rules migrated from firewall rules")
+
+	for _, rule := range rules {
+		action, _ := rule["action"].(string)
+		translation, ok := firewallRuleActions[action]
+		if !ok {
+			log.Warnf("firewall rule %v has unsupported action %q and was not translated", rule["id"], action)
+			continue
+		}
+
+		filter, _ := rule["filter"].(map[string]interface{})
+		expression, _ := filter["expression"].(string)
+		paused, _ := rule["paused"].(bool)
+
+		ruleset.AppendNewline()
+		if translation.explanation != "" {
+			appendComment(ruleset, translation.explanation)
+		}
+		body := ruleset.AppendNewBlock("rules", nil).Body()
+		body.SetAttributeValue("action", cty.StringVal(translation.action))
+		if description, ok := rule["description"].(string); ok && description != "" {
+			body.SetAttributeValue("description", cty.StringVal(description))
+		}
+		body.SetAttributeValue("enabled", cty.BoolVal(!paused))
+		body.SetAttributeValue("expression", cty.StringVal(expression))
+
+		switch action {
+		case "allow":
+			body.AppendNewBlock("action_parameters", nil).Body().SetAttributeValue("ruleset", cty.StringVal("current"))
+		case "bypass":
+			var products []cty.Value
+			items, _ := rule["products"].([]interface{})
+			for _, product := range items {
+				products = append(products, cty.StringVal(fmt.Sprint(product)))
+			}
+			if len(products) > 0 {
+				body.AppendNewBlock("action_parameters", nil).Body().SetAttributeValue("products", cty.ListVal(products))
+			}
+		}
+	}
+
+	return f
+}
+
+// modernizePageRules translates page rules which only forward to another URL
+// into dynamic redirects. Page rules with other actions are returned as they
+// have no single equivalent.
+func modernizePageRules(data []interface{}) (*hclwrite.File, []interface{}) {
+	var (
+		redirects []map[string]interface{}
+		remaining []interface{}
+	)
+	for _, item := range data {
+		rule := item.(map[string]interface{})
+		actions, _ := rule["actions"].(map[string]interface{})
+		if _, ok := actions["forwarding_url"].(map[string]interface{}); ok && len(actions) == 1 {
+			redirects = append(redirects, rule)
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+
+	if len(remaining) > 0 {
+		log.Warnf("%d page rules have actions other than forwarding_url and are generated as cloudflare_page_rule", len(remaining))
+	}
+	if len(redirects) == 0 {
+		return nil, remaining
+	}
+
+	sort.SliceStable(redirects, func(i, j int) bool {
+		a, _ := redirects[i]["priority"].(float64)
+		b, _ := redirects[j]["priority"].(float64)
+		return a > b
+	})
+
+	f := hclwrite.NewEmptyFile()
+	appendComment(f.Body(),
+		"Translated from cloudflare_page_rule by --modernize. Forwarding URL page rules",
+		"are replaced by dynamic redirects which match the same URLs using wildcards.",
+	)
+	ruleset := newModernRuleset(f.Body(), "redirects_from_page_rules", "http_request_dynamic_redirect", "Redirects migrated from page rules")
+
+	for _, rule := range redirects {
+		forwarding := rule["actions"].(map[string]interface{})["forwarding_url"].(map[string]interface{})
+		pattern := fmt.Sprint(rule["target"])
+		if !strings.Contains(pattern, "://") {
+			pattern = "http*://" + pattern
+		}
+		url := pageRulePlaceholder.ReplaceAllString(fmt.Sprint(forwarding["url"]), "$${$1}")
+		statusCode := int64(301)
+		if status, ok := forwarding["status_code"].(float64); ok && status != 0 {
+			statusCode = int64(status)
+		}
+
+		ruleset.AppendNewline()
+		body := ruleset.AppendNewBlock("rules", nil).Body()
+		body.SetAttributeValue("action", cty.StringVal("redirect"))
+		body.SetAttributeValue("description", cty.StringVal(fmt.Sprintf("Forward %s", rule["target"])))
+		body.SetAttributeValue("enabled", cty.BoolVal(rule["status"] != "disabled"))
+		body.SetAttributeValue("expression", cty.StringVal(fmt.Sprintf("(http.request.full_uri wildcard %q)", pattern)))
+
+		fromValue := body.AppendNewBlock("action_parameters", nil).Body().AppendNewBlock("from_value", nil).Body()
+		fromValue.SetAttributeValue("preserve_query_string", cty.False)
+		fromValue.SetAttributeValue("status_code", cty.NumberIntVal(statusCode))
+		fromValue.AppendNewBlock("target_url", nil).Body().SetAttributeValue("expression", cty.StringVal(fmt.Sprintf("wildcard_replace(http.request.full_uri, %q, %q)", pattern, url)))
+	}
+
+	return f, remaining
+}
+
+// newModernRuleset appends a zone ruleset for the phase to the body.
+func newModernRuleset(body *hclwrite.Body, name, phase, description string) *hclwrite.Body {
+	ruleset := body.AppendNewBlock("resource", []string{"cloudflare_ruleset", name}).Body()
+	if useVariables || writesModule() {
+		writeVariableReference("zone_id", "zone_id", ruleset)
+	} else {
+		ruleset.SetAttributeValue("zone_id", cty.StringVal(zoneID))
+	}
+	ruleset.SetAttributeValue("name", cty.StringVal(description))
+	ruleset.SetAttributeValue("kind", cty.StringVal("zone"))
+	ruleset.SetAttributeValue("phase", cty.StringVal(phase))
+
+	return ruleset
+}
+
+// appendComment appends a comment with a line for each of the provided lines.
+func appendComment(body *hclwrite.Body, lines ...string) {
+	for _, line := range lines {
+		body.AppendUnstructuredTokens(hclwrite.Tokens{
+			{Type: hclsyntax.TokenComment, Bytes: []byte("# " + line + "\n")},
+		})
+	}
+}
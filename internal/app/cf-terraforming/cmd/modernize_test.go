@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModernizeFirewallRules(t *testing.T) {
+	zoneID = "0da42c8d2132a9ddaf714f9e7c920711"
+	defer func() { zoneID = "" }()
+
+	data := []interface{}{
+		map[string]interface{}{
+			"id":       "372e67954025e0ba6aaa6d586b9e0b60",
+			"action":   "bypass",
+			"priority": float64(2),
+			"products": []interface{}{"waf", "rateLimit"},
+			"filter":   map[string]interface{}{"expression": "ip.src eq 192.0.2.1"},
+		},
+		map[string]interface{}{
+			"id":          "372e67954025e0ba6aaa6d586b9e0b61",
+			"action":      "block",
+			"description": "Block bad bots",
+			"paused":      true,
+			"priority":    float64(1),
+			"filter":      map[string]interface{}{"expression": "cf.client.bot"},
+		},
+	}
+
+	f, remaining := modernizeResources("cloudflare_firewall_rule", data)
+	assert.Empty(t, remaining)
+	assert.Equal(t, heredoc.Doc(`
+		# Translated from cloudflare_firewall_rule by --modernize. Firewall rules are
+		# deprecated in favour of custom rules in the http_request_firewall_custom phase.
+		resource "cloudflare_ruleset" "firewall_custom_rules" {
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		  name    = "Custom rules migrated from firewall rules"
+		  kind    = "zone"
+		  phase   = "http_request_firewall_custom"
+
+		  rules {
+		    action      = "block"
+		    description = "Block bad bots"
+		    enabled     = false
+		    expression  = "cf.client.bot"
+		  }
+
+		  # bypass is replaced by skipping the same products
+		  rules {
+		    action     = "skip"
+		    enabled    = true
+		    expression = "ip.src eq 192.0.2.1"
+		    action_parameters {
+		      products = ["waf", "rateLimit"]
+		    }
+		  }
+		}
+	`), string(hclwrite.Format(f.Bytes())))
+}
+
+func TestModernizePageRules(t *testing.T) {
+	zoneID = "0da42c8d2132a9ddaf714f9e7c920711"
+	defer func() { zoneID = "" }()
+
+	cacheRule := map[string]interface{}{
+		"target":  "example.com/static/*",
+		"actions": map[string]interface{}{"cache_level": "cache_everything"},
+	}
+	data := []interface{}{
+		map[string]interface{}{
+			"target":   "www.example.com/*",
+			"priority": float64(1),
+			"status":   "active",
+			"actions": map[string]interface{}{
+				"forwarding_url": map[string]interface{}{"url": "https://example.com/$1", "status_code": float64(302)},
+			},
+		},
+		cacheRule,
+	}
+
+	f, remaining := modernizeResources("cloudflare_page_rule", data)
+	assert.Equal(t, []interface{}{cacheRule}, remaining)
+	assert.Equal(t, heredoc.Doc(`
+		# Translated from cloudflare_page_rule by --modernize. Forwarding URL page rules
+		# are replaced by dynamic redirects which match the same URLs using wildcards.
+		resource "cloudflare_ruleset" "redirects_from_page_rules" {
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		  name    = "Redirects migrated from page rules"
+		  kind    = "zone"
+		  phase   = "http_request_dynamic_redirect"
+
+		  rules {
+		    action      = "redirect"
+		    description = "Forward www.example.com/*"
+		    enabled     = true
+		    expression  = "(http.request.full_uri wildcard \"http*://www.example.com/*\")"
+		    action_parameters {
+		      from_value {
+		        preserve_query_string = false
+		        status_code           = 302
+		        target_url {
+		          expression = "wildcard_replace(http.request.full_uri, \"http*://www.example.com/*\", \"https://example.com/$${1}\")"
+		        }
+		      }
+		    }
+		  }
+		}
+	`), string(hclwrite.Format(f.Bytes())))
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const (
+	mainFilename    = "main.tf"
+	outputsFilename = "outputs.tf"
+)
+
+// writesModule reports whether the generated resources are being written to a
+// directory as a reusable module rather than to stdout.
+func writesModule() bool {
+	return asModule || useTerragrunt
+}
+
+// createMainFile creates the file in the output directory that generated
+// resources are written to when outputting a module.
+func createMainFile() (*os.File, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	filename := mainFilename
+	if outputFormat == outputFormatJSON {
+		filename += ".json"
+	}
+
+	return os.Create(filepath.Join(outputDir, filename))
+}
+
+// writeConfigFile formats and writes a supplementary configuration file into
+// the output directory, converting it to the JSON syntax if requested.
+func writeConfigFile(filename string, f *hclwrite.File) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	content := hclwrite.Format(f.Bytes())
+	if outputFormat == outputFormatJSON {
+		var err error
+		if content, err = terraforming.ConvertToJSONSyntax(content); err != nil {
+			return err
+		}
+		filename += ".json"
+	}
+
+	path := filepath.Join(outputDir, filename)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+	metrics.addBytesWritten(len(content))
+
+	return nil
+}
+
+// resourceAddresses returns the address of every resource block in the file.
+func resourceAddresses(f *hclwrite.File) []string {
+	var addresses []string
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+		addresses = append(addresses, strings.Join(block.Labels(), "."))
+	}
+
+	return addresses
+}
+
+// buildOutputsFile generates an output exposing the ID of each of the provided
+// resource addresses so they can be referenced from the calling module.
+func buildOutputsFile(addresses []string) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	for _, address := range addresses {
+		resourceType, name, _ := strings.Cut(address, ".")
+		outputName := fmt.Sprintf("%s_%s_id", strings.TrimPrefix(resourceType, "cloudflare_"), name)
+
+		body := f.Body().AppendNewBlock("output", []string{outputName}).Body()
+		body.SetAttributeTraversal("value", hcl.Traversal{
+			hcl.TraverseRoot{Name: resourceType},
+			hcl.TraverseAttr{Name: name},
+			hcl.TraverseAttr{Name: "id"},
+		})
+		f.Body().AppendNewline()
+	}
+
+	return f
+}
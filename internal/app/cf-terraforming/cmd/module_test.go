@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceAddresses(t *testing.T) {
+	input := heredoc.Doc(`
+		resource "cloudflare_dns_record" "terraform_managed_resource_0" {
+		  name = "example.com"
+		}
+
+		variable "zone_id" {
+		  type = string
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_1" {
+		  name = "www.example.com"
+		}
+	`)
+	f, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	assert.False(t, diags.HasErrors())
+
+	assert.Equal(t, []string{
+		"cloudflare_dns_record.terraform_managed_resource_0",
+		"cloudflare_dns_record.terraform_managed_resource_1",
+	}, resourceAddresses(f))
+}
+
+func TestBuildOutputsFile(t *testing.T) {
+	expected := heredoc.Doc(`
+		output "dns_record_terraform_managed_resource_0_id" {
+		  value = cloudflare_dns_record.terraform_managed_resource_0.id
+		}
+
+		output "page_rule_terraform_managed_resource_id" {
+		  value = cloudflare_page_rule.terraform_managed_resource.id
+		}
+
+	`)
+
+	f := buildOutputsFile([]string{
+		"cloudflare_dns_record.terraform_managed_resource_0",
+		"cloudflare_page_rule.terraform_managed_resource",
+	})
+	assert.Equal(t, expected, string(hclwrite.Format(f.Bytes())))
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// terraformState is the subset of the Terraform state file format needed to
+// find the address of each resource by its ID.
+type terraformState struct {
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Module    string `json:"module"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// loadPreviousAddresses reads the addresses resources previously had from
+// either a mapping file or a Terraform state file.
+func loadPreviousAddresses(path string) (referenceIndex, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var probe struct {
+		Resources json.RawMessage `json:"resources"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var idx referenceIndex
+	if len(probe.Resources) > 0 && probe.Resources[0] == '[' {
+		idx, err = parseStateAddresses(content)
+	} else {
+		idx, err = parseMapping(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return idx, nil
+}
+
+// parseStateAddresses indexes the managed resources in the root module of a
+// Terraform state file by their ID.
+func parseStateAddresses(content []byte) (referenceIndex, error) {
+	var state terraformState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	idx := make(referenceIndex)
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" || resource.Module != "" {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			name := resource.Name
+			switch key := instance.IndexKey.(type) {
+			case float64:
+				name = fmt.Sprintf("%s[%d]", name, int(key))
+			case string:
+				name = fmt.Sprintf("%s[%q]", name, key)
+			}
+			idx.add(resource.Type, name, instance.Attributes)
+		}
+	}
+
+	return idx, nil
+}
+
+// resourceMove is a resource whose address differs from its previous one.
+type resourceMove struct {
+	from, to string
+}
+
+// move returns the move for a resource when it was previously known by a
+// different address.
+func (idx referenceIndex) move(resourceType, name string, data map[string]interface{}) (resourceMove, bool) {
+	from, ok := idx[resourceType][resourceAPIID(resourceType, data)]
+	to := fmt.Sprintf("%s.%s", resourceType, name)
+	if !ok || from == to {
+		return resourceMove{}, false
+	}
+
+	return resourceMove{from: from, to: to}, true
+}
+
+// appendMovedBlock records that a resource has been renamed.
+func appendMovedBlock(body *hclwrite.Body, move resourceMove) {
+	moved := body.AppendNewBlock("moved", nil).Body()
+	moved.SetAttributeTraversal("from", referenceTraversal(move.from))
+	moved.SetAttributeTraversal("to", referenceTraversal(move.to))
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPreviousAddressesFromState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(heredoc.Doc(`
+		{
+		  "version": 4,
+		  "resources": [
+		    {
+		      "mode": "managed",
+		      "type": "cloudflare_dns_record",
+		      "name": "terraform_managed_resource_372e67954025e0ba6aaa6d586b9e0b59_0",
+		      "instances": [{"attributes": {"id": "372e67954025e0ba6aaa6d586b9e0b59"}}]
+		    },
+		    {
+		      "mode": "managed",
+		      "type": "cloudflare_dns_record",
+		      "name": "records",
+		      "instances": [{"index_key": "www", "attributes": {"id": "9a7806061c88ada191ed06f989cc3dac"}}]
+		    },
+		    {
+		      "mode": "data",
+		      "type": "cloudflare_zone",
+		      "name": "example",
+		      "instances": [{"attributes": {"id": "0da42c8d2132a9ddaf714f9e7c920711"}}]
+		    }
+		  ]
+		}
+	`)), 0644))
+
+	idx, err := loadPreviousAddresses(path)
+	require.NoError(t, err)
+	assert.Equal(t, referenceIndex{
+		"cloudflare_dns_record": {
+			"372e67954025e0ba6aaa6d586b9e0b59": "cloudflare_dns_record.terraform_managed_resource_372e67954025e0ba6aaa6d586b9e0b59_0",
+			"9a7806061c88ada191ed06f989cc3dac": `cloudflare_dns_record.records["www"]`,
+		},
+	}, idx)
+}
+
+func TestAppendMovedBlock(t *testing.T) {
+	idx := referenceIndex{
+		"cloudflare_dns_record": {
+			"9a7806061c88ada191ed06f989cc3dac": `cloudflare_dns_record.records["www"]`,
+			"372e67954025e0ba6aaa6d586b9e0b59": "cloudflare_dns_record.example_com_a",
+		},
+	}
+
+	_, ok := idx.move("cloudflare_dns_record", "example_com_a", map[string]interface{}{"id": "372e67954025e0ba6aaa6d586b9e0b59"})
+	assert.False(t, ok)
+
+	move, ok := idx.move("cloudflare_dns_record", "www_example_com_a", map[string]interface{}{"id": "9a7806061c88ada191ed06f989cc3dac"})
+	require.True(t, ok)
+
+	f := hclwrite.NewEmptyFile()
+	appendMovedBlock(f.Body(), move)
+	assert.Equal(t, heredoc.Doc(`
+		moved {
+		  from = cloudflare_dns_record.records["www"]
+		  to   = cloudflare_dns_record.www_example_com_a
+		}
+	`), string(hclwrite.Format(f.Bytes())))
+}
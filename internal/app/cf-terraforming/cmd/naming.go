@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+)
+
+// resourceNamer names the resources of a single resource type using
+// --name-template, or the template configured for the type, along with
+// --name-prefix and --name-suffix.
+type resourceNamer struct {
+	reserved map[string]string
+	namer    *terraforming.Namer
+}
+
+// newResourceNamer returns a namer for a single resource type which avoids
+// the names already recorded for it in the mapping file.
+func newResourceNamer(reserved map[string]string) *resourceNamer {
+	return &resourceNamer{reserved: reserved}
+}
+
+// name returns the next unused name for the resource.
+func (n *resourceNamer) name(resourceType string, data map[string]interface{}) string {
+	if n.namer == nil {
+		naming := terraforming.Naming{Template: nameTemplate, Prefix: namePrefix, Suffix: nameSuffix}
+		if d := defaultsFor(resourceType); d.NameTemplate != "" {
+			naming.Template = d.NameTemplate
+		}
+
+		namer, err := terraforming.NewNamer(naming, runScope())
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, address := range n.reserved {
+			_, name, _ := strings.Cut(address, ".")
+			namer.Reserve(name)
+		}
+		n.namer = namer
+	}
+
+	name, err := n.namer.Name(resourceType, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return name
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceNamer(t *testing.T) {
+	namer := newResourceNamer(map[string]string{
+		"023e105f4ecef8ad9ca31a8372d0c353": "cloudflare_dns_record.mail_example_com_mx",
+	})
+
+	records := []map[string]interface{}{
+		{"id": "372e67954025e0ba6aaa6d586b9e0b59", "name": "example.com", "type": "A"},
+		{"id": "9a7806061c88ada191ed06f989cc3dac", "name": "example.com", "type": "A"},
+		{"id": "0da42c8d2132a9ddaf714f9e7c920711", "name": "*.example.com", "type": "CNAME"},
+		{"id": "699d98642c564d2e855e9661899b7252", "name": "mail.example.com", "type": "MX"},
+	}
+
+	var names []string
+	for _, record := range records {
+		names = append(names, namer.name("cloudflare_dns_record", record))
+	}
+
+	assert.Equal(t, []string{
+		"example_com_a",
+		"example_com_a_2",
+		"wildcard_example_com_cname",
+		"mail_example_com_mx_2",
+	}, names)
+}
+
+func TestResourceNamerAffixes(t *testing.T) {
+	namePrefix, nameSuffix = "cf_", "_imported"
+	defer func() { namePrefix, nameSuffix = "", "" }()
+
+	namer := newResourceNamer(nil)
+	data := map[string]interface{}{"name": "example.com", "type": "A"}
+
+	assert.Equal(t, "cf_example_com_a_imported", namer.name("cloudflare_dns_record", data))
+	assert.Equal(t, "cf_example_com_a_imported_2", namer.name("cloudflare_dns_record", data))
+}
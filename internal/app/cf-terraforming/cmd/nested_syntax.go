@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+)
+
+// nestedSyntax overrides whether the nested objects at a path, such as
+// `cloudflare_load_balancer.rules`, are written as blocks or attributes. This
+// is for v4 resources where the schema doesn't match the syntax the provider
+// accepts.
+var nestedSyntax map[string]string
+
+// validateNestedSyntax checks the values passed with --nested-syntax.
+func validateNestedSyntax() error {
+	for path, syntax := range nestedSyntax {
+		if syntax != terraforming.NestedSyntaxBlock && syntax != terraforming.NestedSyntaxAttribute {
+			return fmt.Errorf("unsupported --nested-syntax %q for %s, expected %q or %q", syntax, path, terraforming.NestedSyntaxBlock, terraforming.NestedSyntaxAttribute)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNestedSyntax(t *testing.T) {
+	defer func(syntax map[string]string) { nestedSyntax = syntax }(nestedSyntax)
+
+	nestedSyntax = map[string]string{"cloudflare_load_balancer.rules": terraforming.NestedSyntaxAttribute}
+	assert.NoError(t, validateNestedSyntax())
+
+	nestedSyntax = map[string]string{"cloudflare_load_balancer.rules": "map"}
+	assert.Error(t, validateNestedSyntax())
+}
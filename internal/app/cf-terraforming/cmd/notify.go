@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const (
+	notifyFormatJSON  = "json"
+	notifyFormatSlack = "slack"
+)
+
+// notifyTimeout limits how long posting a notification can take.
+const notifyTimeout = 30 * time.Second
+
+var (
+	// notifyWebhookURL receives a summary once generate finishes or watch
+	// finds drift.
+	notifyWebhookURL string
+
+	// notifyFormat is whether the summary is posted as JSON or as a Slack
+	// compatible message.
+	notifyFormat string
+)
+
+// runSummary is the summary of a run posted to --notify-webhook.
+type runSummary struct {
+	Command   string         `json:"command"`
+	AccountID string         `json:"account_id,omitempty"`
+	ZoneID    string         `json:"zone_id,omitempty"`
+	Resources map[string]int `json:"resources,omitempty"`
+	Drift     []driftEvent   `json:"drift,omitempty"`
+	Failures  []runFailure   `json:"failures,omitempty"`
+}
+
+// runFailure is a resource type, a resource, or the whole run, which failed.
+type runFailure struct {
+	ResourceType string `json:"resource_type,omitempty"`
+	Address      string `json:"address,omitempty"`
+	ID           string `json:"id,omitempty"`
+	Endpoint     string `json:"endpoint,omitempty"`
+	Status       int    `json:"status,omitempty"`
+	Kind         string `json:"kind,omitempty"`
+	Error        string `json:"error"`
+}
+
+// newRunSummary summarises the run with the number of resources of each type
+// that were generated.
+func newRunSummary(command string, generated []*hclwrite.File, failures *runFailures) runSummary {
+	summary := runSummary{Command: command, AccountID: accountID, ZoneID: zoneID, Resources: make(map[string]int)}
+	for _, f := range generated {
+		for _, address := range resourceAddresses(f) {
+			resourceType, _, _ := strings.Cut(address, ".")
+			summary.Resources[resourceType]++
+		}
+	}
+	if failures != nil {
+		summary.Failures = failures.summary()
+	}
+
+	return summary
+}
+
+// notifyRun posts the summary to --notify-webhook if one is set. A failure
+// to notify is logged rather than failing the run.
+func notifyRun(ctx context.Context, summary runSummary) {
+	if notifyWebhookURL == "" {
+		return
+	}
+
+	if err := postNotification(ctx, notifyWebhookURL, notifyFormat, summary); err != nil {
+		log.Warnf("failed to post the notification: %s", err)
+	}
+}
+
+// postNotification posts the summary to the webhook.
+func postNotification(ctx context.Context, url, format string, summary runSummary) error {
+	var payload interface{} = summary
+	if format == notifyFormatSlack {
+		payload = map[string]string{"text": slackMessage(summary)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// The webhook is sent through the same proxy and certificate authorities
+	// as the API, but without its credentials.
+	resp, err := (&http.Client{Transport: sharedTransport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+// slackMessage formats the summary as the text of a Slack message.
+func slackMessage(summary runSummary) string {
+	scope := "zone " + summary.ZoneID
+	if summary.AccountID != "" {
+		scope = "account " + summary.AccountID
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "*cf-terraforming %s* for %s", summary.Command, scope)
+	if len(summary.Resources) > 0 {
+		total := 0
+		var counts []string
+		for _, resourceType := range sortedKeys(summary.Resources) {
+			total += summary.Resources[resourceType]
+			counts = append(counts, fmt.Sprintf("%d %s", summary.Resources[resourceType], resourceType))
+		}
+		fmt.Fprintf(&message, "\nGenerated %d resource(s): %s", total, strings.Join(counts, ", "))
+	}
+	if len(summary.Drift) > 0 {
+		fmt.Fprintf(&message, "\nFound drift in %d resource(s):", len(summary.Drift))
+		for _, event := range summary.Drift {
+			fmt.Fprintf(&message, "\n• %s `%s`", event.Event, event.Address)
+			if len(event.Attributes) > 0 {
+				fmt.Fprintf(&message, " (%s)", strings.Join(event.Attributes, ", "))
+			}
+		}
+	}
+	if len(summary.Failures) > 0 {
+		fmt.Fprintf(&message, "\nFailed:")
+		for _, failure := range summary.Failures {
+			if failure.Address != "" {
+				fmt.Fprintf(&message, "\n• `%s`: %s", failure.Address, failure.Error)
+			} else if failure.ResourceType != "" {
+				fmt.Fprintf(&message, "\n• `%s`: %s", failure.ResourceType, failure.Error)
+			} else {
+				fmt.Fprintf(&message, "\n• %s", failure.Error)
+			}
+		}
+	}
+
+	return message.String()
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunSummary(t *testing.T) {
+	defer func(account, zone string) { accountID, zoneID = account, zone }(accountID, zoneID)
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+
+	failures := &runFailures{}
+	failures.failures = append(failures.failures, resourceFailure{resourceType: "cloudflare_ruleset", err: errors.New("forbidden")})
+	failures.failures = append(failures.failures, resourceFailure{resourceType: "cloudflare_dns_record", name: "www", id: "023e105f4ecef8ad9ca31a8372d0c353", endpoint: "GET /zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records", err: errors.New("unexpected type")})
+
+	generated := []*hclwrite.File{
+		parseGeneratedFile(t, `
+			resource "cloudflare_dns_record" "www" {}
+			resource "cloudflare_dns_record" "api" {}
+		`),
+		parseGeneratedFile(t, `
+			resource "cloudflare_page_rule" "redirect" {}
+		`),
+	}
+	assert.Equal(t, runSummary{
+		Command:   "generate",
+		ZoneID:    "0da42c8d2132a9ddaf714f9e7c920711",
+		Resources: map[string]int{"cloudflare_dns_record": 2, "cloudflare_page_rule": 1},
+		Failures: []runFailure{
+			{ResourceType: "cloudflare_ruleset", Kind: "error", Error: "forbidden"},
+			{ResourceType: "cloudflare_dns_record", Address: "cloudflare_dns_record.www", ID: "023e105f4ecef8ad9ca31a8372d0c353", Endpoint: "GET /zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records", Kind: "error", Error: "unexpected type"},
+		},
+	}, newRunSummary("generate", generated, failures))
+}
+
+func TestPostNotification(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	summary := runSummary{
+		Command: "watch",
+		ZoneID:  "0da42c8d2132a9ddaf714f9e7c920711",
+		Drift: []driftEvent{
+			{Event: driftEventChanged, Address: "cloudflare_dns_record.www", ResourceType: "cloudflare_dns_record", Attributes: []string{"content", "ttl"}},
+			{Event: driftEventAdded, Address: "cloudflare_dns_record.new", ResourceType: "cloudflare_dns_record"},
+		},
+	}
+
+	require.NoError(t, postNotification(context.Background(), server.URL, notifyFormatJSON, summary))
+	var posted runSummary
+	require.NoError(t, json.Unmarshal(body, &posted))
+	assert.Equal(t, summary, posted)
+
+	require.NoError(t, postNotification(context.Background(), server.URL, notifyFormatSlack, summary))
+	assert.JSONEq(t, `{"text": "*cf-terraforming watch* for zone 0da42c8d2132a9ddaf714f9e7c920711\nFound drift in 2 resource(s):\n• changed `+"`cloudflare_dns_record.www`"+` (content, ttl)\n• added `+"`cloudflare_dns_record.new`"+`"}`, string(body))
+
+	assert.EqualError(t, postNotification(context.Background(), server.URL+"/broken", notifyFormatJSON, summary), "the webhook responded with 404 Not Found")
+}
+
+func TestSlackMessage(t *testing.T) {
+	assert.Equal(t, "*cf-terraforming generate* for account f037e56e89293a057740de681ac9abbe\n"+
+		"Generated 3 resource(s): 2 cloudflare_list, 1 cloudflare_ruleset\n"+
+		"Failed:\n• `cloudflare_worker_route`: forbidden",
+		slackMessage(runSummary{
+			Command:   "generate",
+			AccountID: "f037e56e89293a057740de681ac9abbe",
+			Resources: map[string]int{"cloudflare_ruleset": 1, "cloudflare_list": 2},
+			Failures:  []runFailure{{ResourceType: "cloudflare_worker_route", Error: "forbidden"}},
+		}))
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import "fmt"
+
+const (
+	targetTerraform = "terraform"
+	targetOpenTofu  = "opentofu"
+
+	tofuImportCmdPrefix = "tofu import"
+
+	// openTofuStateEncryptionHint is output alongside imports targeting
+	// OpenTofu as imported resources commonly include credentials that end up
+	// in state.
+	openTofuStateEncryptionHint = "# Imported resources may contain secrets which will be written to state. Consider enabling\n" +
+		"# state encryption before importing: https://opentofu.org/docs/language/state/encryption/\n"
+)
+
+// validateTarget ensures that the requested target is one that is supported.
+func validateTarget() error {
+	switch target {
+	case targetTerraform, targetOpenTofu:
+		return nil
+	default:
+		return fmt.Errorf("unsupported target %q, must be one of %s or %s", target, targetTerraform, targetOpenTofu)
+	}
+}
+
+// importCmdPrefix returns the CLI command used to import resources for the
+// configured target.
+func importCmdPrefix() string {
+	if target == targetOpenTofu {
+		return tofuImportCmdPrefix
+	}
+
+	return terraformImportCmdPrefix
+}
+
+// targetBinary returns the name of the CLI for the configured target.
+func targetBinary() string {
+	if target == targetOpenTofu {
+		return "tofu"
+	}
+
+	return "terraform"
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTerraformImportCommand_OpenTofu(t *testing.T) {
+	defer func(prevTarget, prevZone string) { target, zoneID = prevTarget, prevZone }(target, zoneID)
+	zoneID = "0da42c8d2132a9ddaf714f9e7c920711"
+
+	target = targetTerraform
+	assert.Equal(t,
+		"terraform import cloudflare_record.terraform_managed_resource_1234_0 0da42c8d2132a9ddaf714f9e7c920711/1234\n",
+		buildTerraformImportCommand("cloudflare_record", "terraform_managed_resource_1234_0", buildRawImportAddress("cloudflare_record", "1234")),
+	)
+
+	target = targetOpenTofu
+	assert.Equal(t,
+		"tofu import cloudflare_record.terraform_managed_resource_1234_0 0da42c8d2132a9ddaf714f9e7c920711/1234\n",
+		buildTerraformImportCommand("cloudflare_record", "terraform_managed_resource_1234_0", buildRawImportAddress("cloudflare_record", "1234")),
+	)
+}
+
+func TestValidateTarget(t *testing.T) {
+	defer func(prev string) { target = prev }(target)
+
+	target = targetOpenTofu
+	assert.NoError(t, validateTarget())
+
+	target = "pulumi"
+	assert.EqualError(t, validateTarget(), `unsupported target "pulumi", must be one of terraform or opentofu`)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// outputResourceTypes are the resource types whose IDs are exposed in
+// outputs.tf so other configurations can read them from remote state.
+var outputResourceTypes []string
+
+// matchesOutputResourceType reports whether the ID of the resource type should
+// be exposed by --generate-outputs.
+func matchesOutputResourceType(resourceType string) bool {
+	return slices.ContainsFunc(outputResourceTypes, func(pattern string) bool {
+		matched, _ := path.Match(pattern, resourceType)
+		return matched
+	})
+}
+
+// buildResourceOutputs generates an output exposing the ID of each resource of
+// the --generate-outputs types. Resources using `for_each` output a map of IDs
+// by instance key.
+func buildResourceOutputs(files []*hclwrite.File) (*hclwrite.File, error) {
+	f := hclwrite.NewEmptyFile()
+	for _, generated := range files {
+		for _, block := range generated.Body().Blocks() {
+			if block.Type() != "resource" || len(block.Labels()) != 2 || !matchesOutputResourceType(block.Labels()[0]) {
+				continue
+			}
+			resourceType, name := block.Labels()[0], block.Labels()[1]
+			outputName := fmt.Sprintf("%s_%s_id", strings.TrimPrefix(resourceType, "cloudflare_"), name)
+
+			body := f.Body().AppendNewBlock("output", []string{outputName}).Body()
+			if block.Body().GetAttribute("for_each") == nil {
+				body.SetAttributeTraversal("value", hcl.Traversal{
+					hcl.TraverseRoot{Name: resourceType},
+					hcl.TraverseAttr{Name: name},
+					hcl.TraverseAttr{Name: "id"},
+				})
+				f.Body().AppendNewline()
+				continue
+			}
+
+			// There's no builder for `for` expressions so the expression is
+			// parsed from source instead.
+			src := fmt.Sprintf("value = { for key, resource in %s.%s : key => resource.id }\n", resourceType, name)
+			parsed, diags := hclwrite.ParseConfig([]byte(src), outputsFilename, hcl.InitialPos)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("failed to build output for %s.%s: %s", resourceType, name, diags.Error())
+			}
+			body.SetAttributeRaw("value", parsed.Body().GetAttribute("value").Expr().BuildTokens(nil))
+			f.Body().AppendNewline()
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildResourceOutputs(t *testing.T) {
+	defer func(types []string) { outputResourceTypes = types }(outputResourceTypes)
+	outputResourceTypes = []string{"cloudflare_workers_kv_namespace", "cloudflare_load_balancer_*"}
+
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_workers_kv_namespace" "sessions" {
+		  title = "sessions"
+		}
+		resource "cloudflare_load_balancer_pool" "collapsed" {
+		  for_each = local.load_balancer_pool_collapsed
+		  name     = each.value.name
+		}
+		resource "cloudflare_dns_record" "example" {
+		  name = "example.com"
+		}
+	`)), "generated.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	outputs, err := buildResourceOutputs([]*hclwrite.File{f})
+	require.NoError(t, err)
+
+	assert.Equal(t, heredoc.Doc(`
+		output "workers_kv_namespace_sessions_id" {
+		  value = cloudflare_workers_kv_namespace.sessions.id
+		}
+
+		output "load_balancer_pool_collapsed_id" {
+		  value = { for key, resource in cloudflare_load_balancer_pool.collapsed : key => resource.id }
+		}
+
+	`), string(hclwrite.Format(outputs.Bytes())))
+}
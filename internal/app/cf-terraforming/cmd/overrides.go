@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"gopkg.in/yaml.v3"
+)
+
+// overridesFilePath is a YAML file of attributes to drop, rename or set for
+// each resource type.
+var overridesFilePath string
+
+// ignoreChanges are attributes passed with --ignore-changes, such as
+// `cloudflare_dns_record.comment`, that Terraform should ignore changes to.
+var ignoreChanges []string
+
+// setOverrides are attribute values passed with --set, such as
+// `cloudflare_dns_record.proxied=true`.
+var setOverrides []string
+
+// attributeOverrides are the changes made to the attributes of a resource
+// type. Attributes of nested blocks are given as a path, such as
+// `rules.action`.
+type attributeOverrides struct {
+	Drop   []string               `yaml:"drop"`
+	Rename map[string]string      `yaml:"rename"`
+	Set    map[string]interface{} `yaml:"set"`
+
+	// IgnoreChanges are added to `lifecycle { ignore_changes }` for
+	// attributes that would otherwise always show a difference.
+	IgnoreChanges []string `yaml:"ignore_changes"`
+}
+
+// overridesFile holds the overrides by resource type, which can be a glob
+// such as `*` to apply to every resource.
+type overridesFile map[string]attributeOverrides
+
+// loadOverrides reads and validates the overrides file.
+func loadOverrides(filename string) (overridesFile, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides overridesFile
+	if err := yaml.Unmarshal(content, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	for pattern, o := range overrides {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource type %q in %s: %w", pattern, filename, err)
+		}
+		for name := range o.Set {
+			if _, err := overrideValue(o.Set[name]); err != nil {
+				return nil, fmt.Errorf("invalid value for %s.%s in %s: %w", pattern, name, filename, err)
+			}
+		}
+	}
+
+	return overrides, nil
+}
+
+// parseSetOverrides converts the values passed with --set into overrides. Values
+// are read as JSON when possible so `true` and `1` aren't written as strings.
+func parseSetOverrides(values []string) (overridesFile, error) {
+	overrides := make(overridesFile)
+	for _, value := range values {
+		address, raw, ok := strings.Cut(value, "=")
+		resourceType, attribute, hasAttribute := strings.Cut(address, ".")
+		if !ok || !hasAttribute || resourceType == "" || attribute == "" {
+			return nil, fmt.Errorf("invalid --set %q, expected <resource type>.<attribute>=<value>", value)
+		}
+		if _, err := path.Match(resourceType, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource type in --set %q: %w", value, err)
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+			decoded = raw
+		}
+
+		o := overrides[resourceType]
+		if o.Set == nil {
+			o.Set = make(map[string]interface{})
+		}
+		o.Set[attribute] = decoded
+		overrides[resourceType] = o
+	}
+
+	return overrides, nil
+}
+
+// parseIgnoreChanges converts the attributes passed with --ignore-changes into
+// overrides.
+func parseIgnoreChanges(values []string) (overridesFile, error) {
+	overrides := make(overridesFile)
+	for _, value := range values {
+		resourceType, attribute, ok := strings.Cut(value, ".")
+		if !ok || resourceType == "" || attribute == "" {
+			return nil, fmt.Errorf("invalid --ignore-changes %q, expected <resource type>.<attribute>", value)
+		}
+		if _, err := path.Match(resourceType, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource type in --ignore-changes %q: %w", value, err)
+		}
+
+		o := overrides[resourceType]
+		o.IgnoreChanges = append(o.IgnoreChanges, attribute)
+		overrides[resourceType] = o
+	}
+
+	return overrides, nil
+}
+
+// addIgnoreChanges adds a `lifecycle` block to every resource in the file
+// with an attribute to ignore. This happens once resources have been
+// collapsed as DNS records with nested blocks aren't collapsed.
+func (o overridesFile) addIgnoreChanges(f *hclwrite.File) {
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+
+		var attributes []string
+		for _, pattern := range sortedKeys(o) {
+			if matched, _ := path.Match(pattern, block.Labels()[0]); matched {
+				attributes = append(attributes, o[pattern].IgnoreChanges...)
+			}
+		}
+		if len(attributes) == 0 {
+			continue
+		}
+
+		lifecycle := block.Body().FirstMatchingBlock("lifecycle", nil)
+		if lifecycle == nil {
+			block.Body().AppendNewline()
+			lifecycle = block.Body().AppendNewBlock("lifecycle", nil)
+		} else if attr := lifecycle.Body().GetAttribute("ignore_changes"); attr != nil {
+			for _, existing := range strings.Split(strings.Trim(expressionSource(attr.Expr().BuildTokens(nil)), "[]"), ",") {
+				if existing = strings.TrimSpace(existing); existing != "" {
+					attributes = append(attributes, existing)
+				}
+			}
+		}
+		sort.Strings(attributes)
+		attributes = slices.Compact(attributes)
+
+		elems := make([]hclwrite.Tokens, 0, len(attributes))
+		for _, attribute := range attributes {
+			traversal := hcl.Traversal{}
+			for i, name := range strings.Split(attribute, ".") {
+				if i == 0 {
+					traversal = append(traversal, hcl.TraverseRoot{Name: name})
+				} else {
+					traversal = append(traversal, hcl.TraverseAttr{Name: name})
+				}
+			}
+			elems = append(elems, hclwrite.TokensForTraversal(traversal))
+		}
+		lifecycle.Body().SetAttributeRaw("ignore_changes", hclwrite.TokensForTuple(elems))
+	}
+}
+
+// apply changes the attributes of every resource in the file. Attributes are
+// dropped, then renamed and finally set.
+func (o overridesFile) apply(f *hclwrite.File) {
+	patterns := make([]string, 0, len(o))
+	for pattern := range o {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, block.Labels()[0]); !matched {
+				continue
+			}
+
+			overrides := o[pattern]
+			for _, name := range overrides.Drop {
+				for _, body := range overrideBodies(block.Body(), name) {
+					body.RemoveAttribute(overrideAttribute(name))
+				}
+			}
+			for _, from := range sortedKeys(overrides.Rename) {
+				for _, body := range overrideBodies(block.Body(), from) {
+					if attr := body.GetAttribute(overrideAttribute(from)); attr != nil {
+						tokens := attr.Expr().BuildTokens(nil)
+						body.RemoveAttribute(overrideAttribute(from))
+						body.SetAttributeRaw(overrides.Rename[from], tokens)
+					}
+				}
+			}
+			for _, name := range sortedKeys(overrides.Set) {
+				value, _ := overrideValue(overrides.Set[name])
+				for _, body := range overrideBodies(block.Body(), name) {
+					body.SetAttributeValue(overrideAttribute(name), value)
+				}
+			}
+		}
+	}
+}
+
+// overrideBodies returns the bodies containing the attribute at the path,
+// following nested blocks by their type.
+func overrideBodies(body *hclwrite.Body, attributePath string) []*hclwrite.Body {
+	bodies := []*hclwrite.Body{body}
+	parts := strings.Split(attributePath, ".")
+	for _, blockType := range parts[:len(parts)-1] {
+		var nested []*hclwrite.Body
+		for _, b := range bodies {
+			for _, block := range b.Blocks() {
+				if block.Type() == blockType {
+					nested = append(nested, block.Body())
+				}
+			}
+		}
+		bodies = nested
+	}
+
+	return bodies
+}
+
+func overrideAttribute(attributePath string) string {
+	parts := strings.Split(attributePath, ".")
+	return parts[len(parts)-1]
+}
+
+// overrideValue converts a value from the overrides file into a cty value.
+func overrideValue(value interface{}) (cty.Value, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	ty, err := ctyjson.ImpliedType(encoded)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	return ctyjson.Unmarshal(encoded, ty)
+}
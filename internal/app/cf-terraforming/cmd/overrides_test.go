@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverridesApply(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "overrides.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(heredoc.Doc(`
+		"*":
+		  drop:
+		    - created_on
+		cloudflare_dns_record:
+		  rename:
+		    value: content
+		  set:
+		    ttl: 1
+		cloudflare_ruleset:
+		  drop:
+		    - rules.logging
+		  set:
+		    rules.enabled: true
+	`)), 0644))
+
+	overrides, err := loadOverrides(filename)
+	require.NoError(t, err)
+
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "example" {
+		  created_on = "2024-01-01T00:00:00Z"
+		  name       = "example.com"
+		  ttl        = 300
+		  value      = "192.0.2.1"
+		}
+		resource "cloudflare_ruleset" "example" {
+		  created_on = "2024-01-01T00:00:00Z"
+		  name       = "default"
+		  rules {
+		    expression = "true"
+		    logging    = true
+		  }
+		}
+	`)), "generated.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	overrides.apply(f)
+
+	assert.Equal(t, heredoc.Doc(`
+		resource "cloudflare_dns_record" "example" {
+		  name    = "example.com"
+		  ttl     = 1
+		  content = "192.0.2.1"
+		}
+		resource "cloudflare_ruleset" "example" {
+		  name = "default"
+		  rules {
+		    expression = "true"
+		    enabled    = true
+		  }
+		}
+	`), string(hclwrite.Format(f.Bytes())))
+}
+
+func TestLoadOverridesInvalid(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "overrides.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("cloudflare_[:\n  drop: [created_on]\n"), 0644))
+
+	_, err := loadOverrides(filename)
+	assert.ErrorContains(t, err, "invalid resource type")
+}
+
+func TestParseSetOverrides(t *testing.T) {
+	overrides, err := parseSetOverrides([]string{
+		"cloudflare_dns_record.proxied=true",
+		"cloudflare_dns_record.ttl=1",
+		"cloudflare_dns_record.comment=managed by terraform",
+		"cloudflare_ruleset.rules.enabled=false",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, overridesFile{
+		"cloudflare_dns_record": {Set: map[string]interface{}{
+			"proxied": true,
+			"ttl":     float64(1),
+			"comment": "managed by terraform",
+		}},
+		"cloudflare_ruleset": {Set: map[string]interface{}{
+			"rules.enabled": false,
+		}},
+	}, overrides)
+
+	for _, value := range []string{"cloudflare_dns_record=true", "proxied=true", ".proxied=true", "cloudflare_dns_record.proxied"} {
+		_, err := parseSetOverrides([]string{value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAddIgnoreChanges(t *testing.T) {
+	overrides, err := parseIgnoreChanges([]string{
+		"cloudflare_dns_record.meta",
+		"cloudflare_dns_record.comment",
+		"*.modified_on",
+	})
+	require.NoError(t, err)
+
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "example" {
+		  name = "example.com"
+		}
+		resource "cloudflare_dns_record" "existing" {
+		  name = "www.example.com"
+
+		  lifecycle {
+		    ignore_changes = [ttl]
+		  }
+		}
+		resource "cloudflare_list" "example" {
+		  name = "example"
+		}
+	`)), "generated.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	overrides.addIgnoreChanges(f)
+
+	assert.Equal(t, heredoc.Doc(`
+		resource "cloudflare_dns_record" "example" {
+		  name = "example.com"
+
+		  lifecycle {
+		    ignore_changes = [comment, meta, modified_on]
+		  }
+		}
+		resource "cloudflare_dns_record" "existing" {
+		  name = "www.example.com"
+
+		  lifecycle {
+		    ignore_changes = [comment, meta, modified_on, ttl]
+		  }
+		}
+		resource "cloudflare_list" "example" {
+		  name = "example"
+
+		  lifecycle {
+		    ignore_changes = [modified_on]
+		  }
+		}
+	`), string(hclwrite.Format(f.Bytes())))
+
+	_, err = parseIgnoreChanges([]string{"cloudflare_dns_record"})
+	assert.Error(t, err)
+}
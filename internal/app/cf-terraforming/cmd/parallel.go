@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/sirupsen/logrus"
+)
+
+// parallelism is the number of resource types fetched from the API at once.
+var parallelism int
+
+// apiRequest is the list of endpoints to fetch for a resource type along with
+// the path parameters substituted into each.
+type apiRequest struct {
+	resourceType string
+	pathParams   []string
+	endpoints    []string
+}
+
+// apiResponse holds the resources fetched for a request.
+type apiResponse struct {
+	data []interface{}
+	err  error
+}
+
+// resolveAPIRequests builds the requests for every resource type that's
+// generated from the v5 API endpoints, skipping any that are unsupported or
+// have no parent resources.
+func resolveAPIRequests(resources []string) []apiRequest {
+	var requests []apiRequest
+	for _, resourceType := range resources {
+		// Rulesets are still fetched using the old SDK.
+		if resourceType == "cloudflare_ruleset" {
+			continue
+		}
+		// Registered converters fetch their own resources.
+		if _, ok := converterFor(resourceType); ok {
+			continue
+		}
+
+		resourceIDsMap := make(map[string][]string)
+		if isSupportedPathParam(resources, resourceType) {
+			resourceIDsMap = getResourceMappings()
+
+			ids, err := resolvePathParams(resourceIDsMap, resourceType)
+			if err != nil {
+				log.Fatalf("No resource IDs defined in Terraform for resource %s: %s", resourceType, err)
+			}
+			if len(ids) == 0 {
+				log.WithFields(logrus.Fields{
+					"resource": resourceType,
+				}).Info("no parent resources found")
+				continue
+			}
+			resourceIDsMap[resourceType] = ids
+		}
+
+		endpoint := resourceEndpoint(resourceType)
+		if endpoint == "" {
+			log.WithFields(logrus.Fields{
+				"resource": resourceType,
+			}).Warn("Unsupported terraform v5 provider resource")
+			continue
+		}
+
+		request := apiRequest{resourceType: resourceType, endpoints: []string{endpoint}}
+		if pathParams := resourceIDsMap[resourceType]; len(pathParams) > 0 {
+			request.pathParams = pathParams
+			request.endpoints = replacePathParams(pathParams, endpoint, resourceType)
+		}
+		requests = append(requests, request)
+	}
+
+	return requests
+}
+
+// resourceEndpoint returns the v5 API endpoint listing the resource type for
+// the account or zone, or an empty string if it's unsupported.
+func resourceEndpoint(resourceType string) string {
+	return terraforming.ResourceEndpoint(resourceType, runScope())
+}
+
+// fetchAPIResponses fetches the requests using --parallelism workers and
+// returns the responses by resource type. Resource types already in the
+// checkpoint aren't fetched again and the others are added to it.
+func fetchAPIResponses(requests []apiRequest, progress *checkpoint) map[string]apiResponse {
+	responses := make(map[string]apiResponse, len(requests))
+	requests = slices.DeleteFunc(slices.Clone(requests), func(request apiRequest) bool {
+		data, ok := progress.get(request.resourceType)
+		if ok {
+			responses[request.resourceType] = apiResponse{data: data}
+		}
+		return ok
+	})
+
+	workers := max(1, min(parallelism, len(requests)))
+	log.WithFields(logrus.Fields{
+		"resources": len(requests),
+		"workers":   workers,
+	}).Debug("fetching resources")
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		queue  = make(chan apiRequest)
+		failed atomic.Bool
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for request := range queue {
+				// Requests that haven't started are abandoned once interrupted,
+				// or once one has failed with --fail-fast. The failure is left to
+				// the caller to exit with as exiting here would skip the
+				// recovery of in-process runs.
+				if interrupted.Load() || (failFast && failed.Load()) {
+					continue
+				}
+
+				start := time.Now()
+				ctx, span := startSpan(runContext, "fetch", request.resourceType)
+				var response apiResponse
+				response.data, response.err = getAPIResponse(ctx, request.resourceType, nil, request.pathParams, request.endpoints...)
+				endSpan(span, response.err)
+				metrics.addResourceType(request.resourceType, 0, time.Since(start))
+				if response.err == nil {
+					response.err = progress.record(request.resourceType, response.data)
+				}
+				if response.err != nil && !terraforming.IsNoResourcesError(response.err) {
+					failed.Store(true)
+				}
+
+				mu.Lock()
+				responses[request.resourceType] = response
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, request := range requests {
+		queue <- request
+	}
+	close(queue)
+	wg.Wait()
+
+	return responses
+}
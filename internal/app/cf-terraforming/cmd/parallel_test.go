@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchAPIResponses(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := active.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer active.Add(-1)
+		time.Sleep(50 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/zones/0da42c8d2132a9ddaf714f9e7c920711/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"success":false,"errors":[{"code":1000,"message":"not found"}],"result":null}`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":%q}]}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, n int) { api, parallelism = client, n }(api, parallelism)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"), option.WithMaxRetries(0))
+	parallelism = 3
+
+	responses := fetchAPIResponses([]apiRequest{
+		{resourceType: "cloudflare_a", endpoints: []string{"/zones/0da42c8d2132a9ddaf714f9e7c920711/a"}},
+		{resourceType: "cloudflare_b", endpoints: []string{"/zones/0da42c8d2132a9ddaf714f9e7c920711/b"}},
+		{resourceType: "cloudflare_c", endpoints: []string{"/zones/0da42c8d2132a9ddaf714f9e7c920711/c"}},
+		{resourceType: "cloudflare_missing", endpoints: []string{"/zones/0da42c8d2132a9ddaf714f9e7c920711/missing"}},
+	}, nil)
+
+	require.Len(t, responses, 4)
+	for _, name := range []string{"a", "b", "c"} {
+		response := responses["cloudflare_"+name]
+		assert.NoError(t, response.err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"id": "/zones/0da42c8d2132a9ddaf714f9e7c920711/" + name}}, response.data)
+	}
+	assert.Error(t, responses["cloudflare_missing"].err)
+	assert.Greater(t, peak.Load(), int32(1))
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestGetAPIResponsePages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":"record-%s"}],"result_info":{"page":%s,"total_pages":3}}`, page, page)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, n int) { api, parallelism = client, n }(api, parallelism)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	parallelism = 2
+
+	data, err := getAPIResponse(context.Background(), "cloudflare_example", nil, nil, "/zones/0da42c8d2132a9ddaf714f9e7c920711/example")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "record-1"},
+		map[string]interface{}{"id": "record-2"},
+		map[string]interface{}{"id": "record-3"},
+	}, data)
+}
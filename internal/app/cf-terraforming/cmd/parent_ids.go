@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/sirupsen/logrus"
+)
+
+// parentResource describes where the IDs of the parent resource can be found
+// for resources that are nested beneath another.
+type parentResource struct {
+	resourceType string
+	attribute    string
+	// endpoint lists the parent resources when the parent resource type
+	// doesn't have a list endpoint of its own.
+	endpoint string
+	// include, when set, skips the parent resources it returns false for.
+	include func(parent map[string]interface{}) (bool, error)
+}
+
+// parentResources are the resources whose parent IDs can be discovered from
+// the API instead of being provided with `--resource-id`.
+var parentResources = map[string]parentResource{
+	"cloudflare_list_item":                {resourceType: "cloudflare_list", attribute: "id"},
+	"cloudflare_queue_consumer":           {resourceType: "cloudflare_queue", attribute: "queue_id"},
+	"cloudflare_waiting_room_event":       {resourceType: "cloudflare_waiting_room", attribute: "id"},
+	"cloudflare_waiting_room_rules":       {resourceType: "cloudflare_waiting_room", attribute: "id"},
+	"cloudflare_workers_cron_trigger":     workersScriptParent,
+	"cloudflare_workers_deployment":       workersScriptParent,
+	"cloudflare_workers_script_subdomain": workersScriptParent,
+	"cloudflare_zero_trust_tunnel_cloudflared_config": {
+		resourceType: "cloudflare_zero_trust_tunnel_cloudflared",
+		attribute:    "id",
+		include:      remotelyManagedTunnel,
+	},
+}
+
+// workersScriptGlob selects the Workers scripts whose sub-resources are
+// generated when the script names aren't provided with --resource-id.
+var workersScriptGlob string
+
+// workersScriptParent lists the Workers scripts, which are named by their id,
+// for the resources nested beneath a script.
+var workersScriptParent = parentResource{
+	resourceType: "cloudflare_workers_script",
+	attribute:    "id",
+	endpoint:     "/accounts/{account_id}/workers/scripts",
+	include:      matchesWorkersScriptGlob,
+}
+
+// remotelyManagedTunnel returns whether the tunnel hasn't been deleted and
+// has its configuration managed by Cloudflare rather than in a local
+// cloudflared config file, as only those tunnels have a configuration to
+// generate.
+func remotelyManagedTunnel(tunnel map[string]interface{}) (bool, error) {
+	return tunnel["deleted_at"] == nil && tunnel["config_src"] == "cloudflare", nil
+}
+
+// matchesWorkersScriptGlob returns whether the name of the script matches
+// --workers-script-glob.
+func matchesWorkersScriptGlob(script map[string]interface{}) (bool, error) {
+	if workersScriptGlob == "" {
+		return true, nil
+	}
+
+	name, _ := script["id"].(string)
+	matched, err := path.Match(workersScriptGlob, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid --workers-script-glob %q: %w", workersScriptGlob, err)
+	}
+
+	return matched, nil
+}
+
+// includeDefaults generates every discovered zone setting rather than only
+// those changed from Cloudflare's defaults.
+var includeDefaults bool
+
+// resolvePathParams returns the IDs to substitute into the endpoint of a
+// nested resource. IDs provided with `--resource-id` take precedence and
+// otherwise they are discovered by listing the parent resources.
+func resolvePathParams(resourceIDsMap map[string][]string, resourceType string) ([]string, error) {
+	if ids := resourceIDsMap[resourceType]; len(ids) > 0 {
+		return ids, nil
+	}
+	if resourceType == "cloudflare_zone_setting" {
+		return discoverZoneSettings()
+	}
+
+	parent, ok := parentResources[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("no IDs provided with --resource-id for %s", resourceType)
+	}
+
+	return discoverParentIDs(parent)
+}
+
+// discoverParentIDs lists all of the parent resources and returns their IDs.
+func discoverParentIDs(parent parentResource) ([]string, error) {
+	endpoint := parent.endpoint
+	if endpoint == "" {
+		endpoint, _ = terraforming.Endpoints(parent.resourceType)
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s cannot be listed", parent.resourceType)
+	}
+	endpoint = strings.NewReplacer("{account_id}", accountID, "{zone_id}", zoneID).Replace(endpoint)
+
+	var result *http.Response
+	data, err := getAPIResponse(runContext, parent.resourceType, result, nil, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", parent.resourceType, err)
+	}
+
+	if parent.include != nil {
+		var included []interface{}
+		for _, item := range data {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			include, err := parent.include(m)
+			if err != nil {
+				return nil, err
+			}
+			if include {
+				included = append(included, item)
+			}
+		}
+		data = included
+	}
+
+	ids := parentIDs(data, parent.attribute)
+	log.WithFields(logrus.Fields{
+		"resource": parent.resourceType,
+		"count":    len(ids),
+	}).Debug("discovered parent resources")
+
+	return ids, nil
+}
+
+// parentIDs extracts the attribute from each of the API results.
+func parentIDs(data []interface{}, attribute string) []string {
+	var ids []string
+	for _, item := range data {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, ok := m[attribute].(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// discoverZoneSettings lists the settings of the zone and returns the IDs of
+// those to generate.
+func discoverZoneSettings() ([]string, error) {
+	if zoneID == "" {
+		return nil, errors.New("-z/--zone must be set to discover zone settings")
+	}
+
+	var result *http.Response
+	data, err := getAPIResponse(runContext, "cloudflare_zone_setting", result, nil, "/zones/"+zoneID+"/settings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zone settings: %w", err)
+	}
+
+	settings := zoneSettingsToGenerate(data, includeDefaults)
+	log.WithFields(logrus.Fields{
+		"resource": "cloudflare_zone_setting",
+		"count":    len(settings),
+		"skipped":  len(data) - len(settings),
+	}).Debug("discovered zone settings")
+
+	// The listed settings have already had their id remapped to setting_id.
+	return parentIDs(settings, "setting_id"), nil
+}
+
+// zoneSettingsToGenerate returns the settings which can be edited and, unless
+// defaults are included, have been changed. Settings which have never been
+// changed from Cloudflare's defaults have no modified_on.
+func zoneSettingsToGenerate(data []interface{}, defaults bool) []interface{} {
+	var settings []interface{}
+	for _, item := range data {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if editable, ok := m["editable"].(bool); ok && !editable {
+			continue
+		}
+		if modified, _ := m["modified_on"].(string); !defaults && modified == "" {
+			continue
+		}
+		settings = append(settings, item)
+	}
+
+	return settings
+}
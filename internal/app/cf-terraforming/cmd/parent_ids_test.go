@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePathParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/f037e56e89293a057740de681ac9abbe/queues", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"success":true,"result":[{"queue_id":"5c03bec8d113492ba229a671f22235dd"},{"queue_id":"2dde6ac405cd457c9ce59dc4bda20c65"}]}`)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, account, zone, rt string) {
+		api, accountID, zoneID, resourceType = client, account, zone, rt
+	}(api, accountID, zoneID, resourceType)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	accountID, zoneID, resourceType = "f037e56e89293a057740de681ac9abbe", "", "cloudflare_queue_consumer"
+
+	ids, err := resolvePathParams(map[string][]string{"cloudflare_queue_consumer": {"provided"}}, "cloudflare_queue_consumer")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"provided"}, ids)
+
+	ids, err = resolvePathParams(map[string][]string{}, "cloudflare_queue_consumer")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5c03bec8d113492ba229a671f22235dd", "2dde6ac405cd457c9ce59dc4bda20c65"}, ids)
+
+	_, err = resolvePathParams(map[string][]string{}, "cloudflare_hostname_tls_setting")
+	assert.EqualError(t, err, "no IDs provided with --resource-id for cloudflare_hostname_tls_setting")
+}
+
+func TestResolvePathParamsZoneSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/zones/0da42c8d2132a9ddaf714f9e7c920711/settings", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"success":true,"result":[
+			{"id":"always_online","value":"off","editable":true,"modified_on":null},
+			{"id":"cache_level","value":"aggressive","editable":true,"modified_on":"2025-02-13T10:51:23.426493Z"},
+			{"id":"http2","value":"on","editable":false,"modified_on":"2025-02-13T10:51:23.426493Z"}
+		]}`)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, account, zone string, defaults bool) {
+		api, accountID, zoneID, includeDefaults = client, account, zone, defaults
+	}(api, accountID, zoneID, includeDefaults)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+
+	ids, err := resolvePathParams(map[string][]string{"cloudflare_zone_setting": {"always_online"}}, "cloudflare_zone_setting")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"always_online"}, ids)
+
+	includeDefaults = false
+	ids, err = resolvePathParams(map[string][]string{}, "cloudflare_zone_setting")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache_level"}, ids)
+
+	includeDefaults = true
+	ids, err = resolvePathParams(map[string][]string{}, "cloudflare_zone_setting")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"always_online", "cache_level"}, ids)
+
+	zoneID = ""
+	_, err = resolvePathParams(map[string][]string{}, "cloudflare_zone_setting")
+	assert.EqualError(t, err, "-z/--zone must be set to discover zone settings")
+}
+
+func TestResolvePathParamsTunnelConfigs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/f037e56e89293a057740de681ac9abbe/cfd_tunnel", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"success":true,"result":[
+			{"id":"285f508d-d6ef-4ce4-9293-983d5bdc269e","config_src":"cloudflare","deleted_at":null},
+			{"id":"9ab40a4e-6e1c-4b4f-8f8e-6b8c2c1d0c7a","config_src":"local","deleted_at":null},
+			{"id":"f174e90a-fafe-4643-bbbc-4a0ed4fc8415","config_src":"cloudflare","deleted_at":"2025-02-13T10:51:23.426493Z"}
+		]}`)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, account, zone string) {
+		api, accountID, zoneID = client, account, zone
+	}(api, accountID, zoneID)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	accountID, zoneID = "f037e56e89293a057740de681ac9abbe", ""
+
+	ids, err := resolvePathParams(map[string][]string{}, "cloudflare_zero_trust_tunnel_cloudflared_config")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"285f508d-d6ef-4ce4-9293-983d5bdc269e"}, ids)
+}
+
+func TestResolvePathParamsWorkersScripts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/accounts/f037e56e89293a057740de681ac9abbe/workers/scripts", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"success":true,"result":[{"id":"api-orders"},{"id":"api-users"},{"id":"cleanup"}]}`)
+	}))
+	defer server.Close()
+
+	defer func(client *cloudflare.Client, account, zone, glob string) {
+		api, accountID, zoneID, workersScriptGlob = client, account, zone, glob
+	}(api, accountID, zoneID, workersScriptGlob)
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	accountID, zoneID = "f037e56e89293a057740de681ac9abbe", ""
+
+	workersScriptGlob = ""
+	ids, err := resolvePathParams(map[string][]string{}, "cloudflare_workers_cron_trigger")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api-orders", "api-users", "cleanup"}, ids)
+
+	workersScriptGlob = "api-*"
+	for _, resourceType := range []string{"cloudflare_workers_cron_trigger", "cloudflare_workers_deployment", "cloudflare_workers_script_subdomain"} {
+		ids, err = resolvePathParams(map[string][]string{}, resourceType)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"api-orders", "api-users"}, ids, resourceType)
+	}
+
+	workersScriptGlob = "api-["
+	_, err = resolvePathParams(map[string][]string{}, "cloudflare_workers_deployment")
+	assert.EqualError(t, err, `invalid --workers-script-glob "api-[": syntax error in pattern`)
+}
+
+func TestGetResourceMappings(t *testing.T) {
+	defer func(flags []string) { resourceIDFlags = flags }(resourceIDFlags)
+	resourceIDFlags = []string{"cloudflare_zone_setting=always_online", "cache_level"}
+
+	mappings := getResourceMappings()
+	assert.Equal(t, []string{"always_online", "cache_level"}, mappings["cloudflare_zone_setting"])
+
+	// Discovered IDs and the IDs from the flags don't carry over to later runs.
+	mappings["cloudflare_list_item"] = []string{"2a4b8b2017aa4b3cb9e1151b52c81d22"}
+	mappings = getResourceMappings()
+	assert.Equal(t, []string{"always_online", "cache_level"}, mappings["cloudflare_zone_setting"])
+	assert.Empty(t, mappings["cloudflare_list_item"])
+	assert.Empty(t, settingsMap["cloudflare_zone_setting"])
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	postHookModeFile     = "file"
+	postHookModeResource = "resource"
+)
+
+var (
+	// postHook is a command every generated file is piped through before
+	// it's written, such as to apply an organization's policies.
+	postHook string
+
+	// postHookMode is whether the hook is run for each generated file, given
+	// as HCL, or for each resource, given as Terraform's JSON syntax.
+	postHookMode string
+)
+
+// runPostHook pipes the generated files through the hook, replacing each with
+// the output.
+func runPostHook(ctx context.Context, command, mode string, files []*hclwrite.File) ([]*hclwrite.File, error) {
+	hooked := make([]*hclwrite.File, 0, len(files))
+	for _, f := range files {
+		var (
+			out *hclwrite.File
+			err error
+		)
+		if mode == postHookModeResource {
+			out, err = runResourcePostHook(ctx, command, f)
+		} else {
+			out, err = runFilePostHook(ctx, command, f)
+		}
+		if err != nil {
+			return nil, err
+		}
+		hooked = append(hooked, out)
+	}
+
+	return hooked, nil
+}
+
+// runFilePostHook pipes the file through the hook as HCL.
+func runFilePostHook(ctx context.Context, command string, f *hclwrite.File) (*hclwrite.File, error) {
+	output, err := runHookCommand(ctx, command, hclwrite.Format(f.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+
+	hooked, diags := hclwrite.ParseConfig(output, "post-hook.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("post hook %q printed invalid configuration: %s", command, diags.Error())
+	}
+	return hooked, nil
+}
+
+// runResourcePostHook pipes each resource in the file through the hook as
+// Terraform's JSON syntax. The hook can print any number of resources, so
+// printing {} drops the resource. Other blocks are kept as they are.
+func runResourcePostHook(ctx context.Context, command string, f *hclwrite.File) (*hclwrite.File, error) {
+	hooked := hclwrite.NewEmptyFile()
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" {
+			hooked.Body().AppendBlock(block)
+			hooked.Body().AppendNewline()
+			continue
+		}
+
+		resource := hclwrite.NewEmptyFile()
+		resource.Body().AppendBlock(block)
+		input, err := terraforming.ConvertToJSONSyntax(hclwrite.Format(resource.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := runHookCommand(ctx, command, input)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks, err := resourcesFromJSON(output, block)
+		if err != nil {
+			return nil, fmt.Errorf("post hook %q printed invalid JSON configuration: %w", command, err)
+		}
+		for _, b := range blocks {
+			hooked.Body().AppendBlock(b)
+			hooked.Body().AppendNewline()
+		}
+	}
+
+	return hooked, nil
+}
+
+// runHookCommand runs the hook through the shell with the input on stdin and
+// returns what it prints.
+func runHookCommand(ctx context.Context, command string, input []byte) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(input), &stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("post hook %q failed: %w", command, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// resourcesFromJSON converts resources in Terraform's JSON syntax back into
+// blocks. Whether a nested object is a block or an attribute can't be told
+// from the JSON so the original resource's blocks are followed.
+func resourcesFromJSON(src []byte, original *hclwrite.Block) ([]*hclwrite.Block, error) {
+	var config struct {
+		Resource map[string]map[string]map[string]interface{} `json:"resource"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	var blocks []*hclwrite.Block
+	for _, resourceType := range sortedKeys(config.Resource) {
+		for _, name := range sortedKeys(config.Resource[resourceType]) {
+			block := hclwrite.NewBlock("resource", []string{resourceType, name})
+			if err := writeJSONBody(block.Body(), config.Resource[resourceType][name], original.Body(), ""); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", resourceType, name, err)
+			}
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks, nil
+}
+
+// writeJSONBody writes the attributes and then the blocks of a JSON object
+// into the body.
+func writeJSONBody(body *hclwrite.Body, object map[string]interface{}, original *hclwrite.Body, blockType string) error {
+	var blockNames []string
+	for _, name := range sortedKeys(object) {
+		if original != nil && original.FirstMatchingBlock(name, nil) != nil {
+			blockNames = append(blockNames, name)
+			continue
+		}
+
+		tokens, err := jsonValueTokens(object[name], isReferenceList(blockType, name))
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		body.SetAttributeRaw(name, tokens)
+	}
+
+	for _, name := range blockNames {
+		items, ok := object[name].([]interface{})
+		if !ok {
+			items = []interface{}{object[name]}
+		}
+		for _, item := range items {
+			nested, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: expected an object for the block", name)
+			}
+			block := body.AppendNewBlock(name, nil)
+			if err := writeJSONBody(block.Body(), nested, original.FirstMatchingBlock(name, nil).Body(), name); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isReferenceList reports whether the argument is a list of references,
+// which the JSON syntax gives as plain strings.
+func isReferenceList(blockType, name string) bool {
+	return name == "depends_on" || (blockType == "lifecycle" && name == "ignore_changes")
+}
+
+// jsonValueTokens returns the native syntax of a JSON value. Strings are
+// templates in the JSON syntax, so a string of only an interpolation becomes
+// the expression and others are written as quoted templates.
+func jsonValueTokens(value interface{}, references bool) (hclwrite.Tokens, error) {
+	switch v := value.(type) {
+	case nil:
+		return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte("null")}}, nil
+	case bool:
+		return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(fmt.Sprint(v))}}, nil
+	case json.Number:
+		return hclwrite.Tokens{{Type: hclsyntax.TokenNumberLit, Bytes: []byte(v.String())}}, nil
+	case string:
+		if references {
+			return rawExpressionTokens(v)
+		}
+		if expr, ok := interpolationOnly(v); ok {
+			return rawExpressionTokens(expr)
+		}
+		return quotedTemplateTokens(v), nil
+	case []interface{}:
+		items := make([]hclwrite.Tokens, 0, len(v))
+		for _, item := range v {
+			tokens, err := jsonValueTokens(item, references)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, tokens)
+		}
+		return hclwrite.TokensForTuple(items), nil
+	case map[string]interface{}:
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			tokens, err := jsonValueTokens(v[key], false)
+			if err != nil {
+				return nil, err
+			}
+			name := hclwrite.TokensForValue(cty.StringVal(key))
+			if hclsyntax.ValidIdentifier(key) {
+				name = hclwrite.TokensForIdentifier(key)
+			}
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: name, Value: tokens})
+		}
+		return hclwrite.TokensForObject(attrs), nil
+	default:
+		return nil, fmt.Errorf("unexpected JSON value %T", value)
+	}
+}
+
+// interpolationOnly returns the expression of a template which is a single
+// interpolation such as "${cloudflare_zone.example.id}".
+func interpolationOnly(template string) (string, bool) {
+	expr, ok := strings.CutPrefix(template, "${")
+	if !ok || !strings.HasSuffix(expr, "}") {
+		return "", false
+	}
+	expr = strings.TrimSuffix(expr, "}")
+
+	// Templates such as "${a}-${b}" have more than one interpolation.
+	parsed, diags := hclsyntax.ParseTemplate([]byte(template), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", false
+	}
+	if _, ok := parsed.(*hclsyntax.TemplateWrapExpr); !ok {
+		return "", false
+	}
+
+	return expr, true
+}
+
+// rawExpressionTokens parses an expression into tokens.
+func rawExpressionTokens(expr string) (hclwrite.Tokens, error) {
+	if _, diags := hclsyntax.ParseExpression([]byte(expr), "", hcl.InitialPos); diags.HasErrors() {
+		return nil, fmt.Errorf("invalid expression %q: %s", expr, diags.Error())
+	}
+
+	f, diags := hclwrite.ParseConfig([]byte("value = "+expr+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid expression %q: %s", expr, diags.Error())
+	}
+	return f.Body().GetAttribute("value").Expr().BuildTokens(nil), nil
+}
+
+// quotedTemplateTokens quotes the template, keeping any interpolations.
+func quotedTemplateTokens(template string) hclwrite.Tokens {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(template)
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenQuotedLit, Bytes: []byte(escaped)},
+		{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	}
+}
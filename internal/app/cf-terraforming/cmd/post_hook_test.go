@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPostHookFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook uses sed")
+	}
+
+	f := parseGeneratedFile(t, `
+		resource "cloudflare_dns_record" "www" {
+		  name    = "www"
+		  proxied = false
+		}
+	`)
+	hooked, err := runPostHook(context.Background(), "sed 's/proxied = false/proxied = true/'", postHookModeFile, []*hclwrite.File{f})
+	require.NoError(t, err)
+	require.Len(t, hooked, 1)
+	assert.Equal(t, heredoc.Doc(`
+		resource "cloudflare_dns_record" "www" {
+		  name    = "www"
+		  proxied = true
+		}
+	`), string(hclwrite.Format(hooked[0].Bytes())))
+
+	_, err = runPostHook(context.Background(), "echo 'resource {'", postHookModeFile, []*hclwrite.File{f})
+	assert.ErrorContains(t, err, `post hook "echo 'resource {'" printed invalid configuration`)
+
+	_, err = runPostHook(context.Background(), "exit 3", postHookModeFile, []*hclwrite.File{f})
+	assert.EqualError(t, err, `post hook "exit 3" failed: exit status 3`)
+}
+
+func TestRunPostHookResource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook uses sh")
+	}
+
+	f := parseGeneratedFile(t, `
+		resource "cloudflare_dns_record" "www" {
+		  zone_id = cloudflare_zone.example.id
+		  name    = "www"
+		  content = "$${literal}"
+		  data = {
+		    "flags" = 0
+		  }
+		  lifecycle {
+		    ignore_changes = [comment]
+		  }
+		}
+
+		resource "cloudflare_dns_record" "internal" {
+		  name = "internal"
+		}
+
+		moved {
+		  from = cloudflare_record.www
+		  to   = cloudflare_dns_record.www
+		}
+	`)
+
+	// Resources with the name internal are dropped and the others are kept
+	// as they are.
+	hooked, err := runPostHook(context.Background(), `input=$(cat); case "$input" in *'"internal"'*) echo '{}' ;; *) echo "$input" ;; esac`, postHookModeResource, []*hclwrite.File{f})
+	require.NoError(t, err)
+	require.Len(t, hooked, 1)
+	assert.Equal(t, heredoc.Doc(`
+		resource "cloudflare_dns_record" "www" {
+		  content = "$${literal}"
+		  data = {
+		    flags = 0
+		  }
+		  name    = "www"
+		  zone_id = cloudflare_zone.example.id
+		  lifecycle {
+		    ignore_changes = [comment]
+		  }
+		}
+
+		moved {
+		  from = cloudflare_record.www
+		  to   = cloudflare_dns_record.www
+		}
+
+	`), string(hclwrite.Format(hooked[0].Bytes())))
+}
+
+func TestJSONValueTokens(t *testing.T) {
+	tests := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"reference":     {"${cloudflare_zone.example.id}", "cloudflare_zone.example.id"},
+		"template":      {"${var.prefix}-${var.name}", `"${var.prefix}-${var.name}"`},
+		"escaped":       {"a \"quoted\"\nline", `"a \"quoted\"\nline"`},
+		"null":          {nil, "null"},
+		"list":          {[]interface{}{true, "a"}, `[true, "a"]`},
+		"quoted key":    {map[string]interface{}{"a-b.c": "d"}, "{\n  \"a-b.c\" = \"d\"\n}"},
+		"function call": {"${jsonencode({ a = 1 })}", "jsonencode({ a = 1 })"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := jsonValueTokens(tc.value, false)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(hclwrite.Format(tokens.Bytes())))
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// profile is the name of the profile in the config file whose settings are
+// used, so credentials and settings for many accounts can be kept together.
+var profile string
+
+// applyProfile sets the flags from the named profile in the config file.
+// Flags passed on the command line take precedence over the profile, which
+// takes precedence over environment variables.
+func applyProfile(config *viper.Viper, flags *pflag.FlagSet, name string) error {
+	if !config.IsSet("profiles." + name) {
+		return fmt.Errorf("profile %q is not defined in %s", name, config.ConfigFileUsed())
+	}
+
+	settings := config.GetStringMap("profiles." + name)
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		flag := flags.Lookup(key)
+		if flag == nil || key == "profile" || key == "config" {
+			return fmt.Errorf("profile %q sets %q which isn't a supported setting", name, key)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		for _, value := range profileValues(settings[key]) {
+			if err := flag.Value.Set(value); err != nil {
+				return fmt.Errorf("profile %q has an invalid value for %q: %w", name, key, err)
+			}
+		}
+		flag.Changed = true
+	}
+
+	return nil
+}
+
+// profileValues converts a setting into the values to set on a flag, setting
+// lists and maps one item at a time.
+func profileValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case map[string]interface{}:
+		values := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			values = append(values, fmt.Sprintf("%s=%v", key, v[key]))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".cf-terraforming.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(heredoc.Doc(`
+		profiles:
+		  acme:
+		    token: acme-token
+		    account: 0da42c8d2132a9ddaf714f9e7c920711
+		    output-dir: ./acme
+		    modern-import-block: true
+		    exclude-resource-type: [cloudflare_zero_trust_*, cloudflare_waiting_room]
+		  typo:
+		    acount: 0da42c8d2132a9ddaf714f9e7c920711
+	`)), 0600))
+
+	config := viper.New()
+	config.SetConfigFile(path)
+	require.NoError(t, config.ReadInConfig())
+
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("token", "", "")
+		flags.String("account", "", "")
+		flags.String("output-dir", ".", "")
+		flags.Bool("modern-import-block", false, "")
+		flags.StringSlice("exclude-resource-type", []string{}, "")
+		return flags
+	}
+
+	flags := newFlags()
+	require.NoError(t, flags.Parse([]string{"--token", "cli-token"}))
+	require.NoError(t, applyProfile(config, flags, "acme"))
+
+	token, _ := flags.GetString("token")
+	assert.Equal(t, "cli-token", token, "flags on the command line take precedence")
+	account, _ := flags.GetString("account")
+	assert.Equal(t, "0da42c8d2132a9ddaf714f9e7c920711", account)
+	dir, _ := flags.GetString("output-dir")
+	assert.Equal(t, "./acme", dir)
+	modern, _ := flags.GetBool("modern-import-block")
+	assert.True(t, modern)
+	excluded, _ := flags.GetStringSlice("exclude-resource-type")
+	assert.Equal(t, []string{"cloudflare_zero_trust_*", "cloudflare_waiting_room"}, excluded)
+	assert.True(t, flags.Lookup("account").Changed)
+
+	assert.EqualError(t, applyProfile(config, newFlags(), "typo"), `profile "typo" sets "acount" which isn't a supported setting`)
+	assert.ErrorContains(t, applyProfile(config, newFlags(), "missing"), `profile "missing" is not defined`)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	progressFormatText = "text"
+	progressFormatJSON = "json"
+)
+
+var (
+	// showProgress reports the progress of each resource type to stderr.
+	showProgress bool
+
+	// progressFormat is either text for people or JSON lines for tools
+	// wrapping cf-terraforming.
+	progressFormat string
+)
+
+// progressEvent is a single line of progress in the JSON format.
+type progressEvent struct {
+	Event        string `json:"event"`
+	ResourceType string `json:"resource_type"`
+	Page         int    `json:"page,omitempty"`
+	TotalPages   int    `json:"total_pages,omitempty"`
+	Resources    int    `json:"resources,omitempty"`
+}
+
+// progressReporter writes progress events. A nil reporter writes nothing.
+type progressReporter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string
+}
+
+// runProgress reports the progress of the current run when --progress is set.
+var runProgress *progressReporter
+
+// newProgressReporter returns a reporter writing to stderr if --progress is
+// set.
+func newProgressReporter() (*progressReporter, error) {
+	if !showProgress {
+		return nil, nil
+	}
+	if progressFormat != progressFormatText && progressFormat != progressFormatJSON {
+		return nil, fmt.Errorf("unsupported progress format %q", progressFormat)
+	}
+
+	return &progressReporter{w: os.Stderr, format: progressFormat}, nil
+}
+
+// pageFetched reports that a page of resources has been fetched from the API.
+func (p *progressReporter) pageFetched(resourceType string, page, totalPages int) {
+	p.report(progressEvent{Event: "page_fetched", ResourceType: resourceType, Page: page, TotalPages: totalPages})
+}
+
+// converted reports that the resources of a type have been converted into
+// configuration.
+func (p *progressReporter) converted(resourceType string, resources int) {
+	p.report(progressEvent{Event: "converted", ResourceType: resourceType, Resources: resources})
+}
+
+func (p *progressReporter) report(event progressEvent) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.format == progressFormatJSON {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintln(p.w, string(encoded))
+		return
+	}
+
+	switch event.Event {
+	case "page_fetched":
+		// The total isn't known for endpoints using cursor pagination.
+		if event.TotalPages == 0 {
+			_, _ = fmt.Fprintf(p.w, "%s: fetched page %d\n", event.ResourceType, event.Page)
+			break
+		}
+		_, _ = fmt.Fprintf(p.w, "%s: fetched page %d of %d\n", event.ResourceType, event.Page, event.TotalPages)
+	case "converted":
+		_, _ = fmt.Fprintf(p.w, "%s: converted %d resource(s)\n", event.ResourceType, event.Resources)
+	}
+}
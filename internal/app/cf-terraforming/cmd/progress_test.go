@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporter(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		expected string
+	}{
+		"text": {
+			format: progressFormatText,
+			expected: heredoc.Doc(`
+				cloudflare_dns_record: fetched page 1 of 2
+				cloudflare_dns_record: fetched page 2 of 2
+				cloudflare_dns_record: converted 150 resource(s)
+				cloudflare_list_item: fetched page 1
+			`),
+		},
+		"json": {
+			format: progressFormatJSON,
+			expected: heredoc.Doc(`
+				{"event":"page_fetched","resource_type":"cloudflare_dns_record","page":1,"total_pages":2}
+				{"event":"page_fetched","resource_type":"cloudflare_dns_record","page":2,"total_pages":2}
+				{"event":"converted","resource_type":"cloudflare_dns_record","resources":150}
+				{"event":"page_fetched","resource_type":"cloudflare_list_item","page":1}
+			`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var output bytes.Buffer
+			p := &progressReporter{w: &output, format: tc.format}
+
+			p.pageFetched("cloudflare_dns_record", 1, 2)
+			p.pageFetched("cloudflare_dns_record", 2, 2)
+			p.converted("cloudflare_dns_record", 150)
+			p.pageFetched("cloudflare_list_item", 1, 0)
+
+			assert.Equal(t, tc.expected, output.String())
+		})
+	}
+}
+
+func TestProgressReporterDisabled(t *testing.T) {
+	var p *progressReporter
+	assert.NotPanics(t, func() {
+		p.pageFetched("cloudflare_dns_record", 1, 1)
+		p.converted("cloudflare_dns_record", 1)
+	})
+}
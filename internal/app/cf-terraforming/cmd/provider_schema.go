@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// providerSchemaSource is where to read the provider schema from instead of
+// the working directory: "latest" to install the latest release of the
+// provider, or the path to the output of `terraform providers schema -json`.
+var providerSchemaSource string
+
+// providerSchemaLatest installs the latest release of the provider to read its
+// schema.
+const providerSchemaLatest = "latest"
+
+// loadProviderSchemaSource returns the schema and version of the Cloudflare
+// provider from --provider-schema, so that resources can be generated without
+// an initialized working directory.
+func loadProviderSchemaSource(ctx context.Context, execPath, source string) (*tfjson.ProviderSchema, string, error) {
+	if source == providerSchemaLatest {
+		schema, version, err := loadProviderSchema(ctx, execPath, providerVersionConstraint(providerVersionOverride))
+		if err == nil {
+			log.WithField("version", version).Info("using the schema of the latest provider release")
+		}
+		return schema, version, err
+	}
+
+	// A schema file doesn't record the provider version so it must be given.
+	if providerVersionOverride == "" {
+		return nil, "", errors.New("--provider-version must be set with a --provider-schema file")
+	}
+	schema, err := readProviderSchemaFile(source)
+	return schema, providerVersionOverride, err
+}
+
+// readProviderSchemaFile reads the Cloudflare provider schema from the output
+// of `terraform providers schema -json`.
+func readProviderSchemaFile(filename string) (*tfjson.ProviderSchema, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --provider-schema: %w", err)
+	}
+
+	var schemas tfjson.ProviderSchemas
+	if err := json.Unmarshal(content, &schemas); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	for source, schema := range schemas.Schemas {
+		if isCloudflareProviderSource(source) {
+			return schema, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s has no schema for the Cloudflare provider", filename)
+}
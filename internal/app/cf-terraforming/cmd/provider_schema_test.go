@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProviderSchemaFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "schema.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{
+		"format_version": "1.0",
+		"provider_schemas": {
+			"registry.terraform.io/cloudflare/cloudflare": {
+				"resource_schemas": {
+					"cloudflare_dns_record": {
+						"version": 0,
+						"block": {"attributes": {"name": {"type": "string", "required": true}}}
+					}
+				}
+			}
+		}
+	}`), 0600))
+
+	schema, err := readProviderSchemaFile(filename)
+	require.NoError(t, err)
+	require.Contains(t, schema.ResourceSchemas, "cloudflare_dns_record")
+	assert.Contains(t, schema.ResourceSchemas["cloudflare_dns_record"].Block.Attributes, "name")
+
+	other := filepath.Join(dir, "other.json")
+	require.NoError(t, os.WriteFile(other, []byte(`{"format_version": "1.0", "provider_schemas": {"registry.terraform.io/hashicorp/random": {}}}`), 0600))
+	_, err = readProviderSchemaFile(other)
+	assert.EqualError(t, err, other+" has no schema for the Cloudflare provider")
+
+	defer func(v string) { providerVersionOverride = v }(providerVersionOverride)
+	providerVersionOverride = ""
+	_, _, err = loadProviderSchemaSource(context.Background(), "", filename)
+	assert.EqualError(t, err, "--provider-version must be set with a --provider-schema file")
+
+	providerVersionOverride = "5"
+	schema, version, err := loadProviderSchemaSource(context.Background(), "", filename)
+	require.NoError(t, err)
+	assert.NotNil(t, schema)
+	assert.Equal(t, "5", version)
+}
+
+func TestProviderVersionConstraint(t *testing.T) {
+	assert.Equal(t, "", providerVersionConstraint(""))
+	assert.Equal(t, "~> 5.0", providerVersionConstraint("5"))
+	assert.Equal(t, "= 5.3.0", providerVersionConstraint("5.3.0"))
+}
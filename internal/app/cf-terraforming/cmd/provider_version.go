@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// providerVersionOverride is the Cloudflare provider version, either a major
+// version such as 5 or an exact version, expected in the working directory.
+var providerVersionOverride string
+
+// providerLockFile is the dependency lock file written by terraform init.
+const providerLockFile = ".terraform.lock.hcl"
+
+// providerPin is the Cloudflare provider version the working directory asks
+// for, either exactly from the lock file or as a constraint from
+// required_providers.
+type providerPin struct {
+	file       string
+	version    string
+	constraint string
+}
+
+func (p providerPin) String() string {
+	if p.version != "" {
+		return fmt.Sprintf("%s is pinned in %s", p.version, p.file)
+	}
+	return fmt.Sprintf("%q is required in %s", p.constraint, p.file)
+}
+
+// findProviderPin reads the Cloudflare provider version from the lock file in
+// the working directory, falling back to required_providers. The pin is empty
+// if neither is set.
+func findProviderPin(dir string) (providerPin, error) {
+	lockFile := filepath.Join(dir, providerLockFile)
+	body, err := parseHCLBody(lockFile)
+	if err == nil {
+		for _, block := range body.Blocks {
+			if block.Type == "provider" && len(block.Labels) == 1 && isCloudflareProviderSource(block.Labels[0]) {
+				value, err := attributeString(block.Body, "version")
+				return providerPin{file: providerLockFile, version: value}, err
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return providerPin{}, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return providerPin{}, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		body, err := parseHCLBody(file)
+		if err != nil {
+			return providerPin{}, err
+		}
+		for _, terraform := range body.Blocks {
+			if terraform.Type != "terraform" {
+				continue
+			}
+			for _, required := range terraform.Body.Blocks {
+				if required.Type != "required_providers" {
+					continue
+				}
+				for _, attr := range required.Body.Attributes {
+					value, diags := attr.Expr.Value(nil)
+					if diags.HasErrors() || !value.Type().IsObjectType() || !value.Type().HasAttribute("source") || !value.Type().HasAttribute("version") {
+						continue
+					}
+					source, constraint := value.GetAttr("source"), value.GetAttr("version")
+					if source.Type() == cty.String && constraint.Type() == cty.String && isCloudflareProviderSource(source.AsString()) {
+						return providerPin{file: filepath.Base(file), constraint: constraint.AsString()}, nil
+					}
+				}
+			}
+		}
+	}
+
+	return providerPin{}, nil
+}
+
+// isCloudflareProviderSource reports whether the source address, such as
+// registry.terraform.io/cloudflare/cloudflare, is the Cloudflare provider.
+func isCloudflareProviderSource(source string) bool {
+	return strings.EqualFold(source, "cloudflare/cloudflare") || strings.HasSuffix(strings.ToLower(source), "/cloudflare/cloudflare")
+}
+
+// parseHCLBody parses the native syntax HCL file.
+func parseHCLBody(filename string) (*hclsyntax.Body, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, diags.Error())
+	}
+
+	return file.Body.(*hclsyntax.Body), nil
+}
+
+// attributeString returns the value of the string attribute in the body.
+func attributeString(body *hclsyntax.Body, name string) (string, error) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return "", fmt.Errorf("%s is not set", name)
+	}
+
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || value.Type() != cty.String {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+
+	return value.AsString(), nil
+}
+
+// matchesProviderVersion reports whether the version is the expected one,
+// which is either a major version or an exact version.
+func matchesProviderVersion(v, expected string) bool {
+	if !strings.Contains(expected, ".") {
+		return providerMajorVersion(v) == expected
+	}
+	return v == expected
+}
+
+// providerMajorVersion returns the major version of the version.
+func providerMajorVersion(v string) string {
+	return strings.SplitN(v, ".", 2)[0]
+}
+
+// targetsOtherProviderRelease reports whether --provider-version is an exact
+// release other than the one installed, whose schema is then read from a
+// temporary workspace.
+func targetsOtherProviderRelease(installed, override string) bool {
+	return strings.Contains(override, ".") && override != installed
+}
+
+// checkProviderVersion checks the provider installed in the working directory
+// is the one pinned by it and, if set, the --provider-version override. An
+// exact override can target another release of the installed major version.
+func checkProviderVersion(installed string, pin providerPin, override string) error {
+	if override != "" {
+		if pin.version != "" && !matchesProviderVersion(pin.version, override) {
+			return fmt.Errorf("--provider-version %s conflicts with the working directory, where the Cloudflare provider %s", override, pin)
+		}
+		if pin.constraint != "" && strings.Contains(override, ".") {
+			constraints, err := version.NewConstraint(pin.constraint)
+			if err != nil {
+				return fmt.Errorf("invalid Cloudflare provider version, %s: %w", pin, err)
+			}
+			v, err := version.NewVersion(override)
+			if err != nil {
+				return fmt.Errorf("invalid --provider-version %s: %w", override, err)
+			}
+			if !constraints.Check(v) {
+				return fmt.Errorf("--provider-version %s conflicts with the working directory, where the Cloudflare provider %s", override, pin)
+			}
+		}
+		if providerMajorVersion(installed) != providerMajorVersion(override) {
+			return fmt.Errorf("--provider-version %s doesn't match the Cloudflare provider %s installed in the working directory, run terraform init -upgrade", override, installed)
+		}
+	}
+
+	switch {
+	case pin.version != "" && pin.version != installed:
+		return fmt.Errorf("the Cloudflare provider %s is installed in the working directory but %s, run terraform init", installed, pin)
+	case pin.constraint != "":
+		constraints, err := version.NewConstraint(pin.constraint)
+		if err != nil {
+			return fmt.Errorf("invalid Cloudflare provider version, %s: %w", pin, err)
+		}
+		v, err := version.NewVersion(installed)
+		if err != nil {
+			return err
+		}
+		if !constraints.Check(v) {
+			return fmt.Errorf("the Cloudflare provider %s is installed in the working directory but %s, run terraform init -upgrade", installed, pin)
+		}
+	}
+
+	return nil
+}
+
+// checkWorkingDirProvider checks the provider installed in the working
+// directory against the version it pins and --provider-version.
+func checkWorkingDirProvider(dir, installed string) error {
+	pin, err := findProviderPin(dir)
+	if err != nil {
+		return err
+	}
+
+	return checkProviderVersion(installed, pin, providerVersionOverride)
+}
+
+// loadProviderSchema installs the latest release of the Cloudflare provider
+// matching the version constraint in a temporary workspace and returns its
+// schema and version, so that resources can be generated for a release other
+// than the one installed.
+func loadProviderSchema(ctx context.Context, execPath, constraint string) (*tfjson.ProviderSchema, string, error) {
+	workspace, err := os.MkdirTemp("", "cf-terraforming-provider")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(workspace)
+
+	requirement := map[string]cty.Value{"source": cty.StringVal("cloudflare/cloudflare")}
+	if constraint != "" {
+		requirement["version"] = cty.StringVal(constraint)
+	}
+	f := hclwrite.NewEmptyFile()
+	f.Body().AppendNewBlock("terraform", nil).Body().AppendNewBlock("required_providers", nil).Body().SetAttributeValue("cloudflare", cty.ObjectVal(requirement))
+	if err := os.WriteFile(filepath.Join(workspace, "provider.tf"), f.Bytes(), 0644); err != nil {
+		return nil, "", err
+	}
+
+	tf, err := tfexec.NewTerraform(workspace, execPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	log.WithField("constraint", constraint).Info("installing provider release to read its schema")
+	if err := tf.Init(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to install the Cloudflare provider: %w", err)
+	}
+	pin, err := findProviderPin(workspace)
+	if err != nil {
+		return nil, "", err
+	}
+	ps, err := tf.ProvidersSchema(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read the schema of the Cloudflare provider %s: %w", pin.version, err)
+	}
+
+	for source, schema := range ps.Schemas {
+		if isCloudflareProviderSource(source) {
+			return schema, pin.version, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("the schema of the Cloudflare provider %s wasn't found", pin.version)
+}
+
+// providerVersionConstraint returns the constraint installing the version,
+// which is either a major version or an exact release.
+func providerVersionConstraint(v string) string {
+	switch {
+	case v == "":
+		return ""
+	case strings.Contains(v, "."):
+		return "= " + v
+	default:
+		return "~> " + v + ".0"
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProviderPin(t *testing.T) {
+	dir := t.TempDir()
+	pin, err := findProviderPin(dir)
+	require.NoError(t, err)
+	assert.Equal(t, providerPin{}, pin)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "provider.tf"), []byte(heredoc.Doc(`
+		terraform {
+		  required_providers {
+		    random = {
+		      source  = "hashicorp/random"
+		      version = "~> 3.0"
+		    }
+		    cloudflare = {
+		      source  = "cloudflare/cloudflare"
+		      version = "~> 5"
+		    }
+		  }
+		}
+	`)), 0600))
+	pin, err = findProviderPin(dir)
+	require.NoError(t, err)
+	assert.Equal(t, providerPin{file: "provider.tf", constraint: "~> 5"}, pin)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, providerLockFile), []byte(heredoc.Doc(`
+		provider "registry.terraform.io/cloudflare/cloudflare" {
+		  version     = "5.1.0"
+		  constraints = "~> 5"
+		  hashes = [
+		    "h1:abc",
+		  ]
+		}
+	`)), 0600))
+	pin, err = findProviderPin(dir)
+	require.NoError(t, err)
+	assert.Equal(t, providerPin{file: providerLockFile, version: "5.1.0"}, pin)
+}
+
+func TestCheckProviderVersion(t *testing.T) {
+	locked := providerPin{file: providerLockFile, version: "5.1.0"}
+	required := providerPin{file: "provider.tf", constraint: "~> 4.0"}
+
+	assert.NoError(t, checkProviderVersion("5.1.0", providerPin{}, ""))
+	assert.NoError(t, checkProviderVersion("5.1.0", locked, ""))
+	assert.NoError(t, checkProviderVersion("5.1.0", locked, "5"))
+	assert.NoError(t, checkProviderVersion("5.1.0", locked, "5.1.0"))
+	assert.NoError(t, checkProviderVersion("4.52.0", required, ""))
+	assert.NoError(t, checkProviderVersion("5.5.0", providerPin{}, "5.3.0"))
+	assert.NoError(t, checkProviderVersion("4.52.0", required, "4.40.0"))
+
+	assert.EqualError(t, checkProviderVersion("5.1.0", locked, "4"),
+		"--provider-version 4 conflicts with the working directory, where the Cloudflare provider 5.1.0 is pinned in .terraform.lock.hcl")
+	assert.EqualError(t, checkProviderVersion("5.1.0", providerPin{}, "4"),
+		"--provider-version 4 doesn't match the Cloudflare provider 5.1.0 installed in the working directory, run terraform init -upgrade")
+	assert.EqualError(t, checkProviderVersion("5.1.0", providerPin{}, "4.52.0"),
+		"--provider-version 4.52.0 doesn't match the Cloudflare provider 5.1.0 installed in the working directory, run terraform init -upgrade")
+	assert.EqualError(t, checkProviderVersion("5.5.0", locked, "5.3.0"),
+		"--provider-version 5.3.0 conflicts with the working directory, where the Cloudflare provider 5.1.0 is pinned in .terraform.lock.hcl")
+	assert.EqualError(t, checkProviderVersion("4.52.0", required, "5.3.0"),
+		`--provider-version 5.3.0 conflicts with the working directory, where the Cloudflare provider "~> 4.0" is required in provider.tf`)
+	assert.EqualError(t, checkProviderVersion("5.0.0", locked, ""),
+		"the Cloudflare provider 5.0.0 is installed in the working directory but 5.1.0 is pinned in .terraform.lock.hcl, run terraform init")
+	assert.EqualError(t, checkProviderVersion("5.1.0", required, ""),
+		`the Cloudflare provider 5.1.0 is installed in the working directory but "~> 4.0" is required in provider.tf, run terraform init -upgrade`)
+}
+
+func TestTargetsOtherProviderRelease(t *testing.T) {
+	assert.False(t, targetsOtherProviderRelease("5.5.0", ""))
+	assert.False(t, targetsOtherProviderRelease("5.5.0", "5"))
+	assert.False(t, targetsOtherProviderRelease("5.5.0", "5.5.0"))
+	assert.True(t, targetsOtherProviderRelease("5.5.0", "5.3.0"))
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxyURL is the proxy to send API requests through in place of those from
+// HTTP_PROXY and HTTPS_PROXY. Credentials for the proxy can be given in the
+// URL.
+var proxyURL string
+
+// configureProxy sends requests made by the transport through the proxy,
+// which can be http, https or socks5.
+func configureProxy(transport *http.Transport, rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid --proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("invalid --proxy %q, supported schemes are http, https, socks5 and socks5h", u.Redacted())
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid --proxy %q, no host is set", u.Redacted())
+	}
+
+	transport.Proxy = http.ProxyURL(u)
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const outputFormatPulumiImport = "pulumi-import"
+
+// pulumiImportFile is the bulk import file format accepted by
+// `pulumi import --file`.
+//
+// See https://www.pulumi.com/docs/iac/adopting-pulumi/import/#bulk-import-operations
+type pulumiImportFile struct {
+	Resources []pulumiImportResource `json:"resources"`
+}
+
+type pulumiImportResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// pulumiResourceType converts a Terraform resource type into the type token
+// used by the bridged Pulumi Cloudflare provider. For example,
+// `cloudflare_dns_record` becomes `cloudflare:index/dnsRecord:DnsRecord`.
+func pulumiResourceType(resourceType string) string {
+	parts := strings.Split(strings.TrimPrefix(resourceType, "cloudflare_"), "_")
+
+	var camel, pascal strings.Builder
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		titled := strings.ToUpper(part[:1]) + part[1:]
+		if i == 0 {
+			camel.WriteString(part)
+		} else {
+			camel.WriteString(titled)
+		}
+		pascal.WriteString(titled)
+	}
+
+	return fmt.Sprintf("cloudflare:index/%s:%s", camel.String(), pascal.String())
+}
+
+// writePulumiImportFile outputs the discovered resources as a Pulumi bulk
+// import file.
+func writePulumiImportFile(cmd *cobra.Command, resources []pulumiImportResource) {
+	if resources == nil {
+		resources = []pulumiImportResource{}
+	}
+
+	output, err := json.MarshalIndent(pulumiImportFile{Resources: resources}, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(output))
+}
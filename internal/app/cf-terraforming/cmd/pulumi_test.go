@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPulumiResourceType(t *testing.T) {
+	tests := map[string]string{
+		"cloudflare_dns_record":                           "cloudflare:index/dnsRecord:DnsRecord",
+		"cloudflare_zone":                                 "cloudflare:index/zone:Zone",
+		"cloudflare_zero_trust_tunnel_cloudflared_config": "cloudflare:index/zeroTrustTunnelCloudflaredConfig:ZeroTrustTunnelCloudflaredConfig",
+	}
+
+	for resourceType, expected := range tests {
+		t.Run(resourceType, func(t *testing.T) {
+			assert.Equal(t, expected, pulumiResourceType(resourceType))
+		})
+	}
+}
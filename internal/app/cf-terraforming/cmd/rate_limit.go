@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultMaxRequestsPerSecond keeps within the global API rate limit of
+	// 1200 requests every five minutes.
+	defaultMaxRequestsPerSecond = 4
+
+	rateLimitBaseDelay = time.Second
+)
+
+var (
+	// maxRequestsPerSecond limits how often requests are made to the API
+	// across every client. Disabled when 0.
+	maxRequestsPerSecond float64
+
+	// maxRetries is the number of times a failed request is retried.
+	maxRetries int
+
+	// retryMaxBackoff is the longest time to wait between retries, unless the
+	// API asks for longer.
+	retryMaxBackoff time.Duration
+
+	// httpTimeout is how long to wait for each API request. Disabled when 0.
+	httpTimeout time.Duration
+)
+
+// rateLimitTransport is an http.RoundTripper that limits the rate of requests
+// and retries those that are rate limited, fail with a server error or time
+// out, waiting for as long as the Retry-After header asks or otherwise backing
+// off exponentially with jitter. It's the only layer retrying requests.
+type rateLimitTransport struct {
+	rt         http.RoundTripper
+	limiter    *rate.Limiter
+	maxRetries int
+	maxDelay   time.Duration
+	// timeout limits each attempt, including reading its response. Disabled
+	// when 0.
+	timeout time.Duration
+
+	// sleep waits between retries unless the request is cancelled first and
+	// is replaced in tests.
+	sleep func(context.Context, time.Duration) error
+}
+
+func newRateLimitTransport(rt http.RoundTripper, requestsPerSecond float64, retries int, maxDelay, timeout time.Duration) *rateLimitTransport {
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if requestsPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), max(1, int(requestsPerSecond)))
+	}
+
+	return &rateLimitTransport{rt: rt, limiter: limiter, maxRetries: retries, maxDelay: maxDelay, timeout: timeout, sleep: sleepContext}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		if t.timeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+		}
+		attemptReq := req.Clone(ctx)
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				cancel()
+				return nil, fmt.Errorf("unable to retry %s %s as the body can't be read again", req.Method, req.URL)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := t.rt.RoundTrip(attemptReq)
+		// Errors are only retried while the request itself is still wanted.
+		retryable := err != nil && req.Context().Err() == nil
+		if err == nil {
+			retryable = retryableStatus(resp.StatusCode)
+		}
+		if !retryable || attempt >= t.maxRetries {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		var retryAfter string
+		if resp != nil {
+			retryAfter = resp.Header.Get("Retry-After")
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		cancel()
+
+		delay := retryDelay(retryAfter, attempt, t.maxDelay, time.Now())
+		switch {
+		case err != nil:
+			log.Warnf("requesting %s failed, retrying in %s: %s", req.URL.Path, delay.Round(time.Millisecond), err)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			log.Warnf("rate limited requesting %s, retrying in %s", req.URL.Path, delay.Round(time.Millisecond))
+		default:
+			log.Warnf("requesting %s failed with %s, retrying in %s", req.URL.Path, resp.Status, delay.Round(time.Millisecond))
+		}
+
+		metrics.retries.Add(1)
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleepContext waits for the duration, returning early with the context's
+// error if it's done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelBody cancels the context of the attempt once its response is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// retryableStatus reports whether a response with the status is retried.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// retryDelay returns how long to wait before retrying a rate limited request.
+// The Retry-After header is used when present and otherwise the delay doubles
+// with each attempt up to the maximum, with up to a quarter added as jitter so
+// that concurrent requests don't retry at the same time.
+func retryDelay(retryAfter string, attempt int, maxDelay time.Duration, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(retryAfter); err == nil {
+		return max(0, at.Sub(now))
+	}
+
+	delay := min(rateLimitBaseDelay<<min(attempt, 30), maxDelay)
+	return delay + rand.N(delay/4+1)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	var delays []time.Duration
+	transport := newRateLimitTransport(http.DefaultTransport, 0, 5, time.Minute, 0)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second}, delays)
+}
+
+func TestRateLimitTransportRetriesServerErrors(t *testing.T) {
+	statuses := []int{http.StatusBadGateway, http.StatusNotFound}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[requests])
+		requests++
+	}))
+	defer server.Close()
+
+	transport := newRateLimitTransport(http.DefaultTransport, 0, 5, time.Minute, 0)
+	transport.sleep = func(context.Context, time.Duration) error { return nil }
+
+	// Server errors are retried but client errors other than rate limiting
+	// aren't.
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRateLimitTransportRetriesTimeouts(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	transport := newRateLimitTransport(http.DefaultTransport, 0, 5, time.Minute, 50*time.Millisecond)
+	transport.sleep = func(context.Context, time.Duration) error { return nil }
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, 2, requests)
+}
+
+func TestRateLimitTransportGivesUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport := newRateLimitTransport(http.DefaultTransport, 0, 2, time.Minute, 0)
+	transport.sleep = func(context.Context, time.Duration) error { return nil }
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, 3, requests)
+}
+
+func TestRateLimitTransportCancelledWhileWaiting(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	// The wait for the Retry-After header ends once the request is cancelled.
+	transport := newRateLimitTransport(http.DefaultTransport, 0, 5, time.Minute, 0)
+	_, err = (&http.Client{Transport: transport}).Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, requests)
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, retryDelay("30", 0, time.Second, now))
+	assert.Equal(t, 10*time.Second, retryDelay("Wed, 01 May 2024 12:00:10 GMT", 0, time.Minute, now))
+	assert.Equal(t, time.Duration(0), retryDelay("Wed, 01 May 2024 11:59:00 GMT", 0, time.Minute, now))
+
+	for attempt, base := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		delay := retryDelay("", attempt, time.Minute, now)
+		assert.GreaterOrEqual(t, delay, base)
+		assert.LessOrEqual(t, delay, base+base/4)
+	}
+	assert.LessOrEqual(t, retryDelay("", 20, 10*time.Second, now), 10*time.Second+10*time.Second/4)
+	assert.LessOrEqual(t, retryDelay("", 100, time.Minute, now), time.Minute+time.Minute/4)
+}
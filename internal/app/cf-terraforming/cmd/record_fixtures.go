@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// The account and zone the test fixtures are recorded as, which the
+// resource generation tests run against.
+const (
+	fixtureAccountID = "f037e56e89293a057740de681ac9abbe"
+	fixtureZoneID    = "0da42c8d2132a9ddaf714f9e7c920711"
+	fixtureDomain    = "example.com"
+)
+
+var (
+	// fixtureName names the cassette and golden file, defaulting to the
+	// resource type.
+	fixtureName string
+
+	// fixtureTestdataDir is the repository's testdata directory.
+	fixtureTestdataDir string
+
+	// fixtureVersion is the major version of the provider the fixture is for,
+	// either v4 or v5.
+	fixtureVersion string
+
+	// fixtureRealDomain is the domain of the zone the fixture is recorded
+	// against, which is replaced with example.com.
+	fixtureRealDomain string
+
+	// fixtureScrubRulesPath is a YAML file of additional scrubbing rules.
+	fixtureScrubRulesPath string
+)
+
+// fixtureDroppedHeaders are removed from every interaction as they're either
+// credentials or differ between recordings.
+var fixtureDroppedHeaders = []string{
+	"Authorization",
+	"Cf-Access-Client-Id",
+	"Cf-Access-Client-Secret",
+	"Cf-Cache-Status",
+	"Cf-Ray",
+	"Cookie",
+	"Date",
+	"Server",
+	"Set-Cookie",
+	"User-Agent",
+	"X-Auth-Email",
+	"X-Auth-Key",
+	"X-Auth-User-Service-Key",
+	"X-Envoy-Upstream-Service-Time",
+}
+
+func init() {
+	rootCmd.AddCommand(recordFixturesCmd)
+
+	recordFixturesCmd.Flags().StringVar(&fixtureName, "fixture-name", "", "Name of the cassette and golden file to write. Defaults to the resource type")
+	recordFixturesCmd.Flags().StringVar(&fixtureTestdataDir, "testdata-dir", "testdata", "The repository's testdata directory to write into")
+	recordFixturesCmd.Flags().StringVar(&fixtureVersion, "fixture-version", "v5", "Major version of the provider to record for. Supported values: v4, v5")
+	recordFixturesCmd.Flags().StringVar(&fixtureRealDomain, "fixture-domain", "", "Domain of the zone recorded against, replaced with example.com")
+	recordFixturesCmd.Flags().StringVar(&fixtureScrubRulesPath, "scrub-rules", "", "YAML file of patterns to replace, attributes to redact and headers to drop from the fixtures")
+}
+
+var recordFixturesCmd = &cobra.Command{
+	Use:   "record-fixtures",
+	Short: "Record the VCR cassette and expected configuration used by the tests for a resource type",
+	Long: "Record the VCR cassette and expected configuration used by the tests for a resource type, for contributors. " +
+		"The API responses of a real account or zone are recorded, scrubbed of IDs, domains and secrets, then replayed to " +
+		"regenerate the golden .tf file.",
+	Run:    runRecordFixtures,
+	PreRun: sharedPreRun,
+}
+
+// fixtureScrubRules are the additional rules the cassette is scrubbed with.
+type fixtureScrubRules struct {
+	// Replace replaces every match of the regular expressions in URLs and
+	// bodies.
+	Replace []fixtureReplacement `yaml:"replace"`
+	// Redact replaces the values of these attributes anywhere in JSON
+	// bodies, along with the known secrets.
+	Redact []string `yaml:"redact"`
+	// DropHeaders are removed along with fixtureDroppedHeaders.
+	DropHeaders []string `yaml:"drop_headers"`
+}
+
+type fixtureReplacement struct {
+	Pattern string `yaml:"pattern"`
+	With    string `yaml:"with"`
+
+	re *regexp.Regexp
+}
+
+func runRecordFixtures(cmd *cobra.Command, args []string) {
+	if resourceType == "" || strings.Contains(resourceType, ",") {
+		log.Fatal("--resource-type must be a single resource type to record fixtures for")
+	}
+	if accountID == "" && zoneID == "" {
+		log.Fatal("--account or --zone must be set to record fixtures against")
+	}
+	if fixtureVersion != "v4" && fixtureVersion != "v5" {
+		log.Fatalf("unsupported --fixture-version %q", fixtureVersion)
+	}
+	if cacheDir != "" {
+		log.Fatal("--cache-dir can't be used to record fixtures as cached responses aren't recorded")
+	}
+
+	rules, err := loadFixtureScrubRules(fixtureScrubRulesPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rules.Replace = append(fixtureIDReplacements(accountID, zoneID, fixtureRealDomain), rules.Replace...)
+
+	name := fixtureName
+	if name == "" {
+		name = resourceType
+	}
+	cassettePath := filepath.Join(fixtureTestdataDir, "cloudflare", fixtureVersion, name+".yaml")
+	goldenDir := filepath.Join(fixtureTestdataDir, "terraform", fixtureVersion, name)
+
+	defer func(transport http.RoundTripper) { apiTransport = transport }(apiTransport)
+
+	interactions, err := recordFixtureCassette(cmd, args, rules, cassettePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("recorded %d interactions to %s", interactions, cassettePath)
+
+	// The golden file is generated by replaying the scrubbed cassette the same
+	// way the tests do, so they're guaranteed to agree.
+	replay, err := recorder.New(strings.TrimSuffix(cassettePath, ".yaml"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiTransport = replay
+
+	// The flags are set rather than viper so the IDs don't outlast the run.
+	flags := cmd.Flags()
+	defer func(account, zone string) {
+		_ = flags.Set("account", account)
+		_ = flags.Set("zone", zone)
+	}(flags.Lookup("account").Value.String(), flags.Lookup("zone").Value.String())
+	if accountID != "" {
+		_ = flags.Set("account", fixtureAccountID)
+	} else {
+		_ = flags.Set("zone", fixtureZoneID)
+	}
+	defer os.Setenv("USE_STATIC_RESOURCE_IDS", os.Getenv("USE_STATIC_RESOURCE_IDS"))
+	if err := os.Setenv("USE_STATIC_RESOURCE_IDS", "true"); err != nil {
+		log.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	if err := runFixtureGeneration(cmd, args, &output); err != nil {
+		log.Fatalf("failed to replay %s: %s", cassettePath, err)
+	}
+	if err := writeGoldenFiles(goldenDir, output.Bytes()); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Infof("wrote %s, add a test case with the testdataFilename %q to include it in the tests", filepath.Join(goldenDir, "test.tf"), name)
+}
+
+// recordFixtureCassette records the API responses of a run, scrubs them and
+// writes the cassette, returning the number of interactions recorded. The
+// responses are recorded as they are so that the run behaves as it would
+// against the API, and are kept in a temporary directory until they're
+// scrubbed so that secrets are never written into testdata.
+func recordFixtureCassette(cmd *cobra.Command, args []string, rules *fixtureScrubRules, cassettePath string) (int, error) {
+	dir, err := os.MkdirTemp("", "cf-terraforming-fixtures-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	rawPath := filepath.Join(dir, filepath.Base(cassettePath))
+	apiTransport = newHTTPTranscriptTransport(sharedTransport, rawPath)
+	if err := runFixtureGeneration(cmd, args, io.Discard); err != nil {
+		return 0, fmt.Errorf("failed to record %s: %w", cassettePath, err)
+	}
+
+	c, err := cassette.Load(strings.TrimSuffix(rawPath, ".yaml"))
+	if err != nil {
+		return 0, err
+	}
+	for _, interaction := range c.Interactions {
+		rules.scrub(interaction)
+	}
+
+	c.Name, c.File = strings.TrimSuffix(cassettePath, ".yaml"), cassettePath
+	if err := c.Save(); err != nil {
+		return 0, err
+	}
+
+	return len(c.Interactions), nil
+}
+
+// runFixtureGeneration generates the resources with new API clients for the
+// current apiTransport, returning an error where generate would exit.
+func runFixtureGeneration(cmd *cobra.Command, args []string, out io.Writer) error {
+	if err := configureAPIClients(); err != nil {
+		return err
+	}
+
+	cmd.SetOut(out)
+	defer cmd.SetOut(nil)
+
+	return catchExit(func() { generateResources(nil)(cmd, args) })
+}
+
+// writeGoldenFiles writes the expected configuration, along with the
+// provider requirements if the fixture is new.
+func writeGoldenFiles(dir string, configuration []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	providerPath := filepath.Join(dir, "provider.tf")
+	if _, err := os.Stat(providerPath); os.IsNotExist(err) {
+		provider := fmt.Sprintf(`terraform {
+  required_providers {
+    cloudflare = {
+      source = "cloudflare/cloudflare"
+      version = "~> %s"
+    }
+  }
+}
+`, strings.TrimPrefix(fixtureVersion, "v"))
+		if err := os.WriteFile(providerPath, []byte(provider), 0644); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(filepath.Join(dir, "test.tf"), configuration, 0644)
+}
+
+// loadFixtureScrubRules reads the rules, if there are any.
+func loadFixtureScrubRules(path string) (*fixtureScrubRules, error) {
+	rules := &fixtureScrubRules{}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(rules); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse --scrub-rules %s: %w", path, err)
+		}
+	}
+
+	for i, replacement := range rules.Replace {
+		re, err := regexp.Compile(replacement.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --scrub-rules pattern %q: %w", replacement.Pattern, err)
+		}
+		rules.Replace[i].re = re
+	}
+
+	return rules, nil
+}
+
+// fixtureIDReplacements replace the real account and zone IDs and domain with
+// those the tests use.
+func fixtureIDReplacements(account, zone, domain string) []fixtureReplacement {
+	var replacements []fixtureReplacement
+	for _, r := range []struct{ real, fixture string }{
+		{account, fixtureAccountID},
+		{zone, fixtureZoneID},
+		{domain, fixtureDomain},
+	} {
+		if r.real != "" {
+			replacements = append(replacements, fixtureReplacement{
+				Pattern: r.real,
+				With:    r.fixture,
+				re:      regexp.MustCompile(regexp.QuoteMeta(r.real)),
+			})
+		}
+	}
+
+	return replacements
+}
+
+// scrub removes headers and replaces and redacts values throughout the
+// interaction.
+func (rules *fixtureScrubRules) scrub(i *cassette.Interaction) {
+	for _, name := range slices.Concat(fixtureDroppedHeaders, rules.DropHeaders) {
+		i.Request.Headers.Del(name)
+		i.Response.Headers.Del(name)
+	}
+	// Replaying sleeps for the duration of each interaction.
+	i.Response.Duration = ""
+
+	i.Request.URL = rules.replace(i.Request.URL)
+	i.Request.Body = rules.scrubBody(i.Request.Body)
+	i.Response.Body = rules.scrubBody(i.Response.Body)
+}
+
+func (rules *fixtureScrubRules) replace(s string) string {
+	for _, replacement := range rules.Replace {
+		s = replacement.re.ReplaceAllString(s, replacement.With)
+	}
+
+	return s
+}
+
+// scrubBody redacts secrets from a JSON body before replacing values, leaving
+// its formatting alone if there are no secrets.
+func (rules *fixtureScrubRules) scrubBody(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err == nil {
+		if redactAttributes(value, slices.Concat(sensitiveAttributeNames, rules.Redact)) {
+			if redacted, err := json.MarshalIndent(value, "", "  "); err == nil {
+				body = string(redacted)
+			}
+		}
+	}
+
+	return rules.replace(body)
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/dnaeon/go-vcr/cassette"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFixtureScrubRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrub.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(heredoc.Doc(`
+		replace:
+		  - pattern: "[a-z]+@corp\\.example"
+		    with: "user@example.com"
+		redact:
+		  - api_key
+		drop_headers:
+		  - X-Request-Id
+	`)), 0600))
+
+	rules, err := loadFixtureScrubRules(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api_key"}, rules.Redact)
+	assert.Equal(t, []string{"X-Request-Id"}, rules.DropHeaders)
+	require.Len(t, rules.Replace, 1)
+	assert.Equal(t, "owner user@example.com", rules.replace("owner jane@corp.example"))
+
+	require.NoError(t, os.WriteFile(path, []byte("replace:\n  - pattern: \"(\"\n"), 0600))
+	_, err = loadFixtureScrubRules(path)
+	assert.ErrorContains(t, err, `invalid --scrub-rules pattern "("`)
+
+	require.NoError(t, os.WriteFile(path, []byte("redacted: [api_key]\n"), 0600))
+	_, err = loadFixtureScrubRules(path)
+	assert.ErrorContains(t, err, "field redacted not found")
+
+	rules, err = loadFixtureScrubRules("")
+	require.NoError(t, err)
+	assert.Empty(t, rules.Replace)
+}
+
+func TestFixtureScrubRulesScrub(t *testing.T) {
+	rules := &fixtureScrubRules{
+		Redact:      []string{"api_key"},
+		DropHeaders: []string{"X-Request-Id"},
+	}
+	rules.Replace = fixtureIDReplacements("", "9a7806061c88ada191ed06f989cc3dac", "terraform.cfapi.net")
+
+	interaction := &cassette.Interaction{
+		Request: cassette.Request{
+			URL:     "https://api.cloudflare.com/client/v4/zones/9a7806061c88ada191ed06f989cc3dac/dns_records",
+			Headers: http.Header{"Authorization": {"Bearer secret"}, "Accept": {"application/json"}},
+		},
+		Response: cassette.Response{
+			Body:     `{"result":[{"zone_id":"9a7806061c88ada191ed06f989cc3dac","name":"www.terraform.cfapi.net","api_key":"abc","secret":"def"}]}`,
+			Headers:  http.Header{"Cf-Ray": {"8a1b2c3d4e5f6a7b-LHR"}, "X-Request-Id": {"1"}, "Content-Type": {"application/json"}},
+			Duration: "120ms",
+		},
+	}
+	rules.scrub(interaction)
+
+	assert.Equal(t, "https://api.cloudflare.com/client/v4/zones/"+fixtureZoneID+"/dns_records", interaction.Request.URL)
+	assert.Equal(t, http.Header{"Accept": {"application/json"}}, interaction.Request.Headers)
+	assert.Equal(t, http.Header{"Content-Type": {"application/json"}}, interaction.Response.Headers)
+	assert.Empty(t, interaction.Response.Duration)
+	assert.JSONEq(t, `{"result":[{"zone_id":"`+fixtureZoneID+`","name":"www.example.com","api_key":"REDACTED","secret":"REDACTED"}]}`, interaction.Response.Body)
+}
+
+func TestFixtureScrubRulesScrubBodyWithoutSecrets(t *testing.T) {
+	rules := &fixtureScrubRules{Replace: fixtureIDReplacements(cloudflareTestAccountID, "", "")}
+
+	body := "{\n   \"result\": {\"id\": \"" + cloudflareTestAccountID + "\"}\n}"
+	assert.Equal(t, body, rules.scrubBody(body), "the formatting is kept")
+	assert.Equal(t, "not json", rules.scrubBody("not json"))
+}
+
+func TestRunRecordFixtures(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_dns_record", map[string]string{
+		"/zones/4d5f6c8d2132a9ddaf714f9e7c920711/dns_records": `[{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"www.corp.example","type":"A","content":"192.0.2.1","ttl":1}]`,
+	})
+	testdata := t.TempDir()
+	args = append([]string{"record-fixtures", "--zone", "4d5f6c8d2132a9ddaf714f9e7c920711", "--fixture-domain", "corp.example", "--testdata-dir", testdata}, args...)
+	// The unscrubbed responses are recorded into the temporary directory.
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+
+	content, err := os.ReadFile(filepath.Join(testdata, "cloudflare", "v5", "cloudflare_dns_record.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "/zones/0da42c8d2132a9ddaf714f9e7c920711/dns_records")
+	assert.NotContains(t, string(content), "4d5f6c8d2132a9ddaf714f9e7c920711")
+	assert.NotContains(t, string(content), "corp.example")
+
+	golden, err := os.ReadFile(filepath.Join(testdata, "terraform", "v5", "cloudflare_dns_record", "test.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(golden), `name    = "www.example.com"`)
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Nothing is written into testdata if the recording fails.
+	args[2] = "5e6f7c8d2132a9ddaf714f9e7c920711"
+	args = append(args, "--fixture-name", "failed")
+	require.Error(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+	assert.NoFileExists(t, filepath.Join(testdata, "cloudflare", "v5", "failed.yaml"))
+	entries, err = os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// resourceReferences lists, for each resource type, the other resource types
+// whose IDs can be found within its attributes. When both are generated in
+// the same run, the IDs are replaced with references to the other resource.
+var resourceReferences = map[string][]string{
+	"cloudflare_list_item":                            {"cloudflare_list"},
+	"cloudflare_load_balancer":                        {"cloudflare_load_balancer_pool"},
+	"cloudflare_load_balancer_pool":                   {"cloudflare_load_balancer_monitor"},
+	"cloudflare_notification_policy":                  {"cloudflare_notification_policy_webhooks"},
+	"cloudflare_queue_consumer":                       {"cloudflare_queue"},
+	"cloudflare_waiting_room_event":                   {"cloudflare_waiting_room"},
+	"cloudflare_waiting_room_rules":                   {"cloudflare_waiting_room"},
+	"cloudflare_zero_trust_access_application":        {"cloudflare_zero_trust_access_policy"},
+	"cloudflare_zero_trust_access_policy":             {"cloudflare_zero_trust_access_group"},
+	"cloudflare_zero_trust_tunnel_cloudflared_config": {"cloudflare_zero_trust_tunnel_cloudflared"},
+	"cloudflare_zero_trust_tunnel_cloudflared_route":  {"cloudflare_zero_trust_tunnel_cloudflared", "cloudflare_zero_trust_tunnel_cloudflared_virtual_network"},
+}
+
+// resourceDependencies lists, for each resource type, the other resource
+// types which must be created first even though nothing in the configuration
+// references them. An explicit `depends_on` is added when both are generated
+// in the same run.
+var resourceDependencies = map[string][]string{
+	"cloudflare_custom_hostname":           {"cloudflare_custom_hostname_fallback_origin"},
+	"cloudflare_ruleset":                   {"cloudflare_list"},
+	"cloudflare_snippet_rules":             {"cloudflare_snippet"},
+	"cloudflare_zero_trust_gateway_policy": {"cloudflare_zero_trust_list"},
+}
+
+// resourceIDAttributes overrides the attribute holding the ID in the API
+// response for resources that don't use `id`.
+var resourceIDAttributes = map[string]string{
+	"cloudflare_queue": "queue_id",
+}
+
+// referenceIndex maps the API ID of each generated resource to its address,
+// keyed by resource type.
+type referenceIndex map[string]map[string]string
+
+// add records the ID of a generated resource.
+func (idx referenceIndex) add(resourceType, name string, data map[string]interface{}) {
+	id := resourceAPIID(resourceType, data)
+	if id == "" {
+		return
+	}
+
+	if idx[resourceType] == nil {
+		idx[resourceType] = make(map[string]string)
+	}
+	idx[resourceType][id] = fmt.Sprintf("%s.%s", resourceType, name)
+}
+
+// resourceAPIID returns the ID of the resource in the API response, or an
+// empty string when it doesn't have one.
+func resourceAPIID(resourceType string, data map[string]interface{}) string {
+	attribute := "id"
+	if a, ok := resourceIDAttributes[resourceType]; ok {
+		attribute = a
+	}
+
+	id, _ := data[attribute].(string)
+	return id
+}
+
+// linkReferences replaces the IDs of other generated resources within the
+// resources in the file with references to them.
+func linkReferences(f *hclwrite.File, idx referenceIndex) {
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+
+		if block.Labels()[0] != "cloudflare_zone" {
+			linkZoneID(block.Body(), idx["cloudflare_zone"])
+		}
+
+		targets := make(map[string]string)
+		for _, referenced := range resourceReferences[block.Labels()[0]] {
+			for id, address := range idx[referenced] {
+				targets[id] = address
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		linkBodyReferences(block.Body(), targets)
+	}
+}
+
+// linkZoneID replaces the `zone_id` of a resource with a reference to the
+// zone when it was generated in the same run.
+func linkZoneID(body *hclwrite.Body, zones map[string]string) {
+	attr := body.GetAttribute("zone_id")
+	if attr == nil || len(zones) == 0 {
+		return
+	}
+
+	if linked, ok := replaceIDTokens(attr.Expr().BuildTokens(nil), zones); ok {
+		body.SetAttributeRaw("zone_id", linked)
+	}
+}
+
+func linkBodyReferences(body *hclwrite.Body, targets map[string]string) {
+	for _, name := range sortedKeys(body.Attributes()) {
+		tokens := body.GetAttribute(name).Expr().BuildTokens(nil)
+		if linked, ok := replaceIDTokens(tokens, targets); ok {
+			body.SetAttributeRaw(name, linked)
+		}
+	}
+
+	for _, block := range body.Blocks() {
+		linkBodyReferences(block.Body(), targets)
+	}
+}
+
+// replaceIDTokens replaces any quoted string that exactly matches one of the
+// targets with a traversal to the ID of the target resource.
+func replaceIDTokens(tokens hclwrite.Tokens, targets map[string]string) (hclwrite.Tokens, bool) {
+	var (
+		output   hclwrite.Tokens
+		replaced bool
+	)
+	for i := 0; i < len(tokens); i++ {
+		if i+2 < len(tokens) &&
+			tokens[i].Type == hclsyntax.TokenOQuote &&
+			tokens[i+1].Type == hclsyntax.TokenQuotedLit &&
+			tokens[i+2].Type == hclsyntax.TokenCQuote {
+			if address, ok := targets[string(tokens[i+1].Bytes)]; ok {
+				output = append(output, hclwrite.TokensForTraversal(referenceTraversal(address+".id"))...)
+				replaced = true
+				i += 2
+				continue
+			}
+		}
+		output = append(output, tokens[i])
+	}
+
+	return output, replaced
+}
+
+// addDependencies adds a `depends_on` to each resource in the file listing the
+// generated resources that it must be created after.
+func addDependencies(f *hclwrite.File, addresses map[string][]string) {
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+
+		var dependencies []hclwrite.Tokens
+		for _, dependency := range resourceDependencies[block.Labels()[0]] {
+			for _, address := range addresses[dependency] {
+				dependencies = append(dependencies, hclwrite.TokensForTraversal(referenceTraversal(address)))
+			}
+		}
+		if len(dependencies) == 0 {
+			continue
+		}
+
+		block.Body().SetAttributeRaw("depends_on", hclwrite.TokensForTuple(dependencies))
+	}
+}
+
+// addressesByType groups the addresses of the resources in the files by their
+// resource type.
+func addressesByType(files []*hclwrite.File) map[string][]string {
+	addresses := make(map[string][]string)
+	for _, f := range files {
+		for _, address := range resourceAddresses(f) {
+			resourceType, _, _ := strings.Cut(address, ".")
+			addresses[resourceType] = append(addresses[resourceType], address)
+		}
+	}
+
+	return addresses
+}
+
+// referenceTraversal parses a reference such as a resource address.
+func referenceTraversal(reference string) hcl.Traversal {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(reference), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		log.Fatalf("invalid reference %q: %s", reference, diags.Error())
+	}
+
+	return traversal
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkReferences(t *testing.T) {
+	input := heredoc.Doc(`
+		resource "cloudflare_load_balancer" "terraform_managed_resource_0" {
+		  default_pool_ids = ["17b5962d775c646f3f9725cbc7a53df4", "9290f38c5d07c2e2f4df57b1f61d4196"]
+		  fallback_pool    = "17b5962d775c646f3f9725cbc7a53df4"
+		  name             = "www.example.com"
+		  region_pools = {
+		    WNAM = ["17b5962d775c646f3f9725cbc7a53df4"]
+		  }
+		}
+
+		resource "cloudflare_load_balancer_pool" "terraform_managed_resource_1" {
+		  monitor = "f1aba936b94213e5b8dca0c0dbf1f9cc"
+		}
+	`)
+	expected := heredoc.Doc(`
+		resource "cloudflare_load_balancer" "terraform_managed_resource_0" {
+		  default_pool_ids = [cloudflare_load_balancer_pool.primary.id, "9290f38c5d07c2e2f4df57b1f61d4196"]
+		  fallback_pool    = cloudflare_load_balancer_pool.primary.id
+		  name             = "www.example.com"
+		  region_pools = {
+		    WNAM = [cloudflare_load_balancer_pool.primary.id]
+		  }
+		}
+
+		resource "cloudflare_load_balancer_pool" "terraform_managed_resource_1" {
+		  monitor = "f1aba936b94213e5b8dca0c0dbf1f9cc"
+		}
+	`)
+
+	idx := make(referenceIndex)
+	idx.add("cloudflare_load_balancer_pool", "primary", map[string]interface{}{"id": "17b5962d775c646f3f9725cbc7a53df4"})
+	// The monitor was not generated in this run so the pool isn't linked.
+	idx.add("cloudflare_load_balancer", "www", map[string]interface{}{"id": "f1aba936b94213e5b8dca0c0dbf1f9cc"})
+
+	f, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	assert.False(t, diags.HasErrors())
+
+	linkReferences(f, idx)
+	assert.Equal(t, expected, string(hclwrite.Format(f.Bytes())))
+}
+
+func TestReferenceIndexAdd(t *testing.T) {
+	idx := make(referenceIndex)
+	idx.add("cloudflare_queue", "jobs", map[string]interface{}{"queue_id": "023e105f4ecef8ad9ca31a8372d0c353"})
+	idx.add("cloudflare_list", "missing", map[string]interface{}{"name": "blocked"})
+
+	assert.Equal(t, referenceIndex{
+		"cloudflare_queue": {"023e105f4ecef8ad9ca31a8372d0c353": "cloudflare_queue.jobs"},
+	}, idx)
+}
+
+func TestAddDependencies(t *testing.T) {
+	input := heredoc.Doc(`
+		resource "cloudflare_custom_hostname" "terraform_managed_resource_0" {
+		  hostname = "app.example.com"
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_1" {
+		  name = "example.com"
+		}
+	`)
+	expected := heredoc.Doc(`
+		resource "cloudflare_custom_hostname" "terraform_managed_resource_0" {
+		  hostname   = "app.example.com"
+		  depends_on = [cloudflare_custom_hostname_fallback_origin.terraform_managed_resource_2]
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_1" {
+		  name = "example.com"
+		}
+	`)
+
+	f, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	assert.False(t, diags.HasErrors())
+
+	addDependencies(f, map[string][]string{
+		"cloudflare_custom_hostname_fallback_origin": {"cloudflare_custom_hostname_fallback_origin.terraform_managed_resource_2"},
+		"cloudflare_dns_record":                      {"cloudflare_dns_record.terraform_managed_resource_1"},
+	})
+	assert.Equal(t, expected, string(hclwrite.Format(f.Bytes())))
+}
+
+func TestLinkReferencesZoneID(t *testing.T) {
+	input := heredoc.Doc(`
+		resource "cloudflare_zone" "terraform_managed_resource_0" {
+		  name = "example.com"
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_1" {
+		  content = "0da42c8d2132a9ddaf714f9e7c920711"
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_2" {
+		  zone_id = "023e105f4ecef8ad9ca31a8372d0c353"
+		}
+	`)
+	expected := heredoc.Doc(`
+		resource "cloudflare_zone" "terraform_managed_resource_0" {
+		  name = "example.com"
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_1" {
+		  content = "0da42c8d2132a9ddaf714f9e7c920711"
+		  zone_id = cloudflare_zone.terraform_managed_resource_0.id
+		}
+
+		resource "cloudflare_dns_record" "terraform_managed_resource_2" {
+		  zone_id = "023e105f4ecef8ad9ca31a8372d0c353"
+		}
+	`)
+
+	idx := make(referenceIndex)
+	idx.add("cloudflare_zone", "terraform_managed_resource_0", map[string]interface{}{"id": "0da42c8d2132a9ddaf714f9e7c920711"})
+
+	f, diags := hclwrite.ParseConfig([]byte(input), "main.tf", hcl.InitialPos)
+	assert.False(t, diags.HasErrors())
+
+	linkReferences(f, idx)
+	assert.Equal(t, expected, string(hclwrite.Format(f.Bytes())))
+}
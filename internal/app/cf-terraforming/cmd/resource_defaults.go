@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// resourceTypeDefaults are the settings for a resource type in the
+// `resources` section of the config file, so recurring generation can be
+// configured without long flag strings.
+type resourceTypeDefaults struct {
+	NameTemplate      string   `mapstructure:"name-template"`
+	ExcludeAttributes []string `mapstructure:"exclude-attributes"`
+	Filter            []string `mapstructure:"filter"`
+
+	filters []resourceFilter
+}
+
+// resourceDefaults holds the settings by resource type, which can be a glob
+// such as `cloudflare_zero_trust_*`.
+var resourceDefaults map[string]resourceTypeDefaults
+
+// loadResourceDefaults reads and validates the `resources` section of the
+// config file.
+func loadResourceDefaults(config *viper.Viper) (map[string]resourceTypeDefaults, error) {
+	var defaults map[string]resourceTypeDefaults
+	if err := config.UnmarshalKey("resources", &defaults, func(c *mapstructure.DecoderConfig) {
+		c.ErrorUnused = true
+	}); err != nil {
+		return nil, fmt.Errorf("invalid resources in %s: %w", config.ConfigFileUsed(), err)
+	}
+
+	for pattern, d := range defaults {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource type %q in %s: %w", pattern, config.ConfigFileUsed(), err)
+		}
+
+		if _, err := terraforming.NewNamer(terraforming.Naming{Template: d.NameTemplate}, terraforming.Scope{}); err != nil {
+			return nil, fmt.Errorf("invalid name-template for %s: %w", pattern, err)
+		}
+		for _, expression := range d.Filter {
+			filter, err := parseResourceFilter(expression)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter for %s: %w", pattern, err)
+			}
+			d.filters = append(d.filters, filter)
+		}
+		defaults[pattern] = d
+	}
+
+	return defaults, nil
+}
+
+// defaultsFor returns the settings for the resource type. Settings for the
+// resource type itself take precedence over those matched by a glob.
+func defaultsFor(resourceType string) resourceTypeDefaults {
+	if d, ok := resourceDefaults[resourceType]; ok {
+		return d
+	}
+
+	patterns := make([]string, 0, len(resourceDefaults))
+	for pattern := range resourceDefaults {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, resourceType); matched {
+			return resourceDefaults[pattern]
+		}
+	}
+
+	return resourceTypeDefaults{}
+}
+
+// hasDefaultFilters reports whether any resource type has filters.
+func hasDefaultFilters() bool {
+	for _, d := range resourceDefaults {
+		if len(d.filters) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultOverrides converts the attributes excluded for each resource type
+// into overrides dropping them.
+func defaultOverrides() overridesFile {
+	overrides := make(overridesFile)
+	for pattern, d := range resourceDefaults {
+		if len(d.ExcludeAttributes) > 0 {
+			overrides[pattern] = attributeOverrides{Drop: d.ExcludeAttributes}
+		}
+	}
+
+	return overrides
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestConfig(t *testing.T, contents string) *viper.Viper {
+	path := filepath.Join(t.TempDir(), ".cf-terraforming.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(heredoc.Doc(contents)), 0600))
+
+	config := viper.New()
+	config.SetConfigFile(path)
+	require.NoError(t, config.ReadInConfig())
+	return config
+}
+
+func TestLoadResourceDefaults(t *testing.T) {
+	defaults, err := loadResourceDefaults(readTestConfig(t, `
+		resources:
+		  cloudflare_dns_record:
+		    name-template: "{{ slug .name }}_{{ slug .type }}"
+		    exclude-attributes: [ttl, comment]
+		    filter: ['type == "CNAME"']
+		  cloudflare_zero_trust_*:
+		    exclude-attributes: [created_at]
+	`))
+	require.NoError(t, err)
+
+	defer func(d map[string]resourceTypeDefaults) { resourceDefaults = d }(resourceDefaults)
+	resourceDefaults = defaults
+
+	record := defaultsFor("cloudflare_dns_record")
+	assert.Equal(t, []string{"ttl", "comment"}, record.ExcludeAttributes)
+	assert.Equal(t, "{{ slug .name }}_{{ slug .type }}", record.NameTemplate)
+	require.Len(t, record.filters, 1)
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "CNAME"}}, filterResources(record.filters, []interface{}{
+		map[string]interface{}{"type": "A"},
+		map[string]interface{}{"type": "CNAME"},
+	}))
+
+	assert.Equal(t, []string{"created_at"}, defaultsFor("cloudflare_zero_trust_list").ExcludeAttributes)
+	assert.Empty(t, defaultsFor("cloudflare_ruleset"))
+	assert.True(t, hasDefaultFilters())
+	assert.Equal(t, overridesFile{
+		"cloudflare_dns_record":   {Drop: []string{"ttl", "comment"}},
+		"cloudflare_zero_trust_*": {Drop: []string{"created_at"}},
+	}, defaultOverrides())
+
+	namer := newResourceNamer(nil)
+	assert.Equal(t, "www_cname", namer.name("cloudflare_dns_record", map[string]interface{}{"name": "www", "type": "CNAME"}))
+}
+
+func TestLoadResourceDefaultsErrors(t *testing.T) {
+	_, err := loadResourceDefaults(readTestConfig(t, `
+		resources:
+		  cloudflare_dns_record:
+		    exclude-attribute: [ttl]
+	`))
+	assert.ErrorContains(t, err, "exclude-attribute")
+
+	_, err = loadResourceDefaults(readTestConfig(t, `
+		resources:
+		  cloudflare_dns_record:
+		    filter: ["type"]
+	`))
+	assert.ErrorContains(t, err, "invalid filter for cloudflare_dns_record")
+
+	_, err = loadResourceDefaults(readTestConfig(t, `
+		resources:
+		  "cloudflare_[":
+		    exclude-attributes: [ttl]
+	`))
+	assert.ErrorContains(t, err, `invalid resource type "cloudflare_["`)
+
+	defaults, err := loadResourceDefaults(viper.New())
+	assert.NoError(t, err)
+	assert.Empty(t, defaults)
+}
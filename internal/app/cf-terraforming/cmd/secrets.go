@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const secretsExampleFilename = "secrets.auto.tfvars.example"
+
+// sensitiveAttributeNames are attribute names that are always treated as
+// secrets, even if the provider schema doesn't mark them as sensitive.
+var sensitiveAttributeNames = []string{
+	"client_secret",
+	"private_key",
+	"secret",
+	"tunnel_secret",
+}
+
+// secretVariable is a sensitive attribute that has been replaced with a
+// variable reference.
+type secretVariable struct {
+	name      string
+	address   string
+	attribute string
+}
+
+// extractSensitiveAttributes walks the resource data alongside the provider
+// schema and replaces any sensitive attribute values with variable
+// references, returning the variables that need to be declared.
+func extractSensitiveAttributes(resourceType, resourceName string, block *tfjson.SchemaBlock, data map[string]interface{}) []secretVariable {
+	prefix := sanitiseTerraformResourceName(strings.TrimPrefix(resourceType, "cloudflare_") + "_" + resourceName)
+	address := fmt.Sprintf("%s.%s", resourceType, resourceName)
+
+	return extractSensitiveFromBlock(block, data, prefix, address, nil)
+}
+
+func extractSensitiveFromBlock(block *tfjson.SchemaBlock, data map[string]interface{}, prefix, address string, path []string) []secretVariable {
+	if block == nil || data == nil {
+		return nil
+	}
+
+	var secrets []secretVariable
+	secrets = append(secrets, extractSensitiveFromAttributes(block.Attributes, data, prefix, address, path)...)
+
+	for _, name := range sortedKeys(block.NestedBlocks) {
+		nested := block.NestedBlocks[name]
+		secrets = append(secrets, extractSensitiveFromNested(data[name], func(item map[string]interface{}, itemPath []string) []secretVariable {
+			return extractSensitiveFromBlock(nested.Block, item, prefix, address, itemPath)
+		}, appendPath(path, name))...)
+	}
+
+	return secrets
+}
+
+func extractSensitiveFromAttributes(attributes map[string]*tfjson.SchemaAttribute, data map[string]interface{}, prefix, address string, path []string) []secretVariable {
+	var secrets []secretVariable
+	for _, name := range sortedKeys(attributes) {
+		attr := attributes[name]
+		if attr.Computed && !attr.Optional {
+			continue
+		}
+
+		attrPath := appendPath(path, name)
+		if isSensitiveAttribute(name, attr) {
+			value, ok := data[name]
+			isEmpty := !ok || value == nil || value == ""
+			// Only top level required attributes need a variable when the API
+			// omits the value, otherwise the configuration would be invalid.
+			if isEmpty && !(attr.Required && len(path) == 0) {
+				continue
+			}
+
+			secret := secretVariable{
+				name:      prefix + "_" + strings.Join(attrPath, "_"),
+				address:   address,
+				attribute: strings.Join(attrPath, "."),
+			}
+			data[name] = terraforming.Variable(secret.name)
+			secrets = append(secrets, secret)
+			continue
+		}
+
+		if attr.AttributeNestedType != nil {
+			nestedAttributes := attr.AttributeNestedType.Attributes
+			secrets = append(secrets, extractSensitiveFromNested(data[name], func(item map[string]interface{}, itemPath []string) []secretVariable {
+				return extractSensitiveFromAttributes(nestedAttributes, item, prefix, address, itemPath)
+			}, attrPath)...)
+		}
+	}
+
+	return secrets
+}
+
+// extractSensitiveFromNested handles both the single object and the list of
+// objects representations of nested values.
+func extractSensitiveFromNested(value interface{}, extract func(map[string]interface{}, []string) []secretVariable, path []string) []secretVariable {
+	var secrets []secretVariable
+	switch v := value.(type) {
+	case map[string]interface{}:
+		secrets = append(secrets, extract(v, path)...)
+	case []interface{}:
+		for i, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				secrets = append(secrets, extract(m, appendPath(path, fmt.Sprintf("%d", i)))...)
+			}
+		}
+	case []map[string]interface{}:
+		for i, item := range v {
+			secrets = append(secrets, extract(item, appendPath(path, fmt.Sprintf("%d", i)))...)
+		}
+	}
+
+	return secrets
+}
+
+// appendPath copies the path before appending so that sibling paths don't
+// share a backing array.
+func appendPath(path []string, elems ...string) []string {
+	return append(append([]string{}, path...), elems...)
+}
+
+func isSensitiveAttribute(name string, attr *tfjson.SchemaAttribute) bool {
+	if attr.AttributeNestedType != nil {
+		return false
+	}
+
+	if attr.Sensitive {
+		return true
+	}
+
+	return attr.AttributeType == cty.String && contains(sensitiveAttributeNames, name)
+}
+
+// appendSecretVariables declares a sensitive variable for each of the
+// extracted secrets.
+func appendSecretVariables(body *hclwrite.Body, secrets []secretVariable) {
+	for _, secret := range secrets {
+		variable := body.AppendNewBlock("variable", []string{secret.name}).Body()
+		variable.SetAttributeValue("description", cty.StringVal(fmt.Sprintf("Value for %s on %s", secret.attribute, secret.address)))
+		variable.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+		variable.SetAttributeValue("sensitive", cty.True)
+		body.AppendNewline()
+	}
+}
+
+// buildSecretsExampleFile generates an example tfvars file containing an
+// empty assignment for each of the extracted secrets.
+func buildSecretsExampleFile(secrets []secretVariable) *hclwrite.File {
+	sorted := append([]secretVariable{}, secrets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+
+	f := hclwrite.NewEmptyFile()
+	for _, secret := range sorted {
+		f.Body().AppendUnstructuredTokens(hclwrite.Tokens{
+			{Type: hclsyntax.TokenComment, Bytes: []byte(fmt.Sprintf("# %s (%s)\n", secret.address, secret.attribute))},
+		})
+		f.Body().SetAttributeValue(secret.name, cty.StringVal(""))
+	}
+
+	return f
+}
+
+// writeSecretsExampleFile outputs the example tfvars file for the secrets
+// extracted so far into the configured output directory.
+func writeSecretsExampleFile(secrets []secretVariable) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	path := filepath.Join(outputDir, secretsExampleFilename)
+	if err := os.WriteFile(path, hclwrite.Format(buildSecretsExampleFile(secrets).Bytes()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExtractSensitiveAttributes(t *testing.T) {
+	schema := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":          {AttributeType: cty.String, Required: true},
+			"tunnel_secret": {AttributeType: cty.String, Optional: true, Sensitive: true},
+			"config": {
+				Optional: true,
+				AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+					NestingMode: tfjson.SchemaNestingModeSingle,
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"client_id":     {AttributeType: cty.String, Optional: true},
+						"client_secret": {AttributeType: cty.String, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	data := map[string]interface{}{
+		"name":          "example",
+		"tunnel_secret": "c2VjcmV0",
+		"config": map[string]interface{}{
+			"client_id":     "abc",
+			"client_secret": "def",
+		},
+	}
+
+	secrets := extractSensitiveAttributes("cloudflare_zero_trust_tunnel_cloudflared", "terraform_managed_resource", schema, data)
+
+	assert.Equal(t, []secretVariable{
+		{
+			name:      "zero_trust_tunnel_cloudflared_terraform_managed_resource_config_client_secret",
+			address:   "cloudflare_zero_trust_tunnel_cloudflared.terraform_managed_resource",
+			attribute: "config.client_secret",
+		},
+		{
+			name:      "zero_trust_tunnel_cloudflared_terraform_managed_resource_tunnel_secret",
+			address:   "cloudflare_zero_trust_tunnel_cloudflared.terraform_managed_resource",
+			attribute: "tunnel_secret",
+		},
+	}, secrets)
+
+	f := hclwrite.NewEmptyFile()
+	terraforming.WriteAttribute(f.Body(), "config", data["config"], terraforming.HCLOptions{})
+	terraforming.WriteAttribute(f.Body(), "tunnel_secret", data["tunnel_secret"], terraforming.HCLOptions{})
+
+	expected := heredoc.Doc(`
+		config = {
+		  client_id     = "abc"
+		  client_secret = var.zero_trust_tunnel_cloudflared_terraform_managed_resource_config_client_secret
+		}
+		tunnel_secret = var.zero_trust_tunnel_cloudflared_terraform_managed_resource_tunnel_secret
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(f.Bytes())))
+}
+
+func TestExtractSensitiveAttributes_OmittedValues(t *testing.T) {
+	schema := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"secret":      {AttributeType: cty.String, Required: true, Sensitive: true},
+			"private_key": {AttributeType: cty.String, Optional: true, Sensitive: true},
+		},
+	}
+	data := map[string]interface{}{}
+
+	secrets := extractSensitiveAttributes("cloudflare_dns_zone_transfers_tsig", "tsig", schema, data)
+
+	assert.Len(t, secrets, 1)
+	assert.Equal(t, terraforming.Variable("dns_zone_transfers_tsig_tsig_secret"), data["secret"])
+	assert.NotContains(t, data, "private_key")
+}
+
+func TestBuildSecretsExampleFile(t *testing.T) {
+	secrets := []secretVariable{
+		{name: "b_secret", address: "cloudflare_b.b", attribute: "secret"},
+		{name: "a_secret", address: "cloudflare_a.a", attribute: "config.client_secret"},
+	}
+
+	expected := heredoc.Doc(`
+		# cloudflare_a.a (config.client_secret)
+		a_secret = ""
+		# cloudflare_b.b (secret)
+		b_secret = ""
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(buildSecretsExampleFile(secrets).Bytes())))
+}
+
+func TestRunSecretsExampleFilePerRun(t *testing.T) {
+	dir := t.TempDir()
+	for _, tunnel := range []string{"first", "second"} {
+		args := fakeGenerateArgs(t, "cloudflare_zero_trust_tunnel_cloudflared", map[string]string{
+			"/accounts/f037e56e89293a057740de681ac9abbe/cfd_tunnel": `[{"id":"285f508d-d6ef-4ce4-9293-983d5bdc269e","name":"` + tunnel + `","tunnel_secret":"c2VjcmV0"}]`,
+		})
+		args = append([]string{"generate", "--account", "f037e56e89293a057740de681ac9abbe", "--output-dir", dir, "--extract-secrets"}, args...)
+
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, Run(context.Background(), RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr}))
+	}
+
+	// The secrets of the first run aren't written again by the second.
+	content, err := os.ReadFile(filepath.Join(dir, secretsExampleFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "zero_trust_tunnel_cloudflared_second_tunnel_secret")
+	assert.NotContains(t, string(content), "zero_trust_tunnel_cloudflared_first_tunnel_secret")
+}
@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveListenAddress is the address serve and mockserver listen on.
+var serveListenAddress string
+
+// serveToken is the bearer token requests to the serve API must authenticate
+// with.
+var serveToken string
+
+// resourceTypePattern matches the name of a Terraform resource type.
+var resourceTypePattern = regexp.MustCompile(`^cloudflare_[a-z0-9_]+$`)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListenAddress, "listen", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "serve-token", "", "Bearer token requests must authenticate with. Required unless --listen is a loopback address")
+	if err := viper.BindPFlag("serve-token", serveCmd.Flags().Lookup("serve-token")); err != nil {
+		log.Fatal(err)
+	}
+	if err := viper.BindEnv("serve-token", "CF_TERRAFORMING_SERVE_TOKEN"); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API generating configuration and import blocks",
+	Long: "Serve an HTTP API so other tools can generate configuration and import blocks without running the CLI for each request. " +
+		"Requests use the credentials the server was started with unless they set their own API token. " +
+		"Requests must authenticate with --serve-token, which can only be left unset when listening on a loopback address. " +
+		"Requests are handled one at a time.",
+	Run:    runServe,
+	PreRun: sharedPreRun,
+}
+
+// serveRequest is the body of a generate or import request.
+type serveRequest struct {
+	AccountID     string   `json:"account_id"`
+	ZoneID        string   `json:"zone_id"`
+	ResourceTypes []string `json:"resource_types"`
+	// APIToken, if set, is used instead of the server's credentials.
+	APIToken     string `json:"api_token"`
+	NameTemplate string `json:"name_template"`
+	NamePrefix   string `json:"name_prefix"`
+	NameSuffix   string `json:"name_suffix"`
+	UseVariables bool   `json:"use_variables"`
+}
+
+// validate checks the request has a single scope and valid resource types.
+func (r serveRequest) validate() error {
+	if (r.AccountID == "") == (r.ZoneID == "") {
+		return errors.New("exactly one of account_id or zone_id must be set")
+	}
+	if len(r.ResourceTypes) == 0 {
+		return errors.New("resource_types must be set")
+	}
+	for _, resourceType := range r.ResourceTypes {
+		if !resourceTypePattern.MatchString(resourceType) {
+			return fmt.Errorf("invalid resource type %q", resourceType)
+		}
+	}
+
+	return nil
+}
+
+// serveCredentials are the settings of the server which every request runs
+// with, captured on start as each run resets the flags.
+type serveCredentials struct {
+	configFile  string
+	installPath string
+	apiToken    string
+	apiKey      string
+	apiEmail    string
+}
+
+// serveHandler handles the API requests by running the commands in-process.
+type serveHandler struct {
+	// token, if set, must be sent by each request as a bearer token.
+	token       string
+	credentials serveCredentials
+	run         func(ctx context.Context, config RunConfig) error
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	token := viper.GetString("serve-token")
+	if token == "" && !isLoopbackAddress(serveListenAddress) {
+		log.Fatalf("--serve-token must be set to listen on %s as it isn't a loopback address", serveListenAddress)
+	}
+
+	handler := &serveHandler{
+		token: token,
+		credentials: serveCredentials{
+			configFile:  cfgFile,
+			installPath: viper.GetString("terraform-install-path"),
+			apiToken:    apiToken,
+			apiKey:      apiKey,
+			apiEmail:    apiEmail,
+		},
+		run: Run,
+	}
+
+	log.Infof("serving the API on %s", serveListenAddress)
+	if err := http.ListenAndServe(serveListenAddress, handler.routes()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// routes returns the API's routes.
+func (h *serveHandler) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeServeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("POST /v1/generate", h.authenticate(h.handle("generate")))
+	mux.HandleFunc("POST /v1/import", h.authenticate(h.handle("import")))
+
+	return mux
+}
+
+// authenticate rejects requests without the server's bearer token.
+func (h *serveHandler) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeServeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// isLoopbackAddress reports whether the listen address only accepts
+// connections from the same machine.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// handle returns the handler running the command for each request.
+func (h *serveHandler) handle(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req serveRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			writeServeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+		if err := req.validate(); err != nil {
+			writeServeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if command == "import" {
+			h.handleImport(w, r, req)
+			return
+		}
+
+		args := h.args(command, req, req.ResourceTypes)
+		// The variable files are written into a directory of their own so
+		// requests don't overwrite each other's, or the server's, files.
+		var dir string
+		if req.UseVariables {
+			var err error
+			if dir, err = os.MkdirTemp("", "cf-terraforming-serve-"); err != nil {
+				writeServeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			defer os.RemoveAll(dir)
+			args = append(args, "--output-dir", dir)
+		}
+
+		var stdout bytes.Buffer
+		err := h.run(r.Context(), RunConfig{
+			Args:   args,
+			Stdout: &stdout,
+		})
+		if err != nil {
+			log.Errorf("failed to %s %s: %s", command, strings.Join(req.ResourceTypes, ","), err)
+			writeServeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response := serveGenerateResponse{Configuration: stdout.String()}
+		if dir != "" {
+			if response.Files, err = readServeFiles(dir); err != nil {
+				writeServeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		writeServeJSON(w, http.StatusOK, response)
+	}
+}
+
+// serveGenerateResponse is the body of a generate response.
+type serveGenerateResponse struct {
+	Configuration string `json:"configuration"`
+	// Files are the supplementary files, such as variables.tf, by name.
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// readServeFiles reads the files a run wrote into the directory.
+func readServeFiles(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = string(content)
+	}
+
+	return files, nil
+}
+
+// handleImport imports each resource type separately, as import only handles
+// a single type per run, and responds with the records of every type.
+func (h *serveHandler) handleImport(w http.ResponseWriter, r *http.Request, req serveRequest) {
+	imports := []json.RawMessage{}
+	for _, resourceType := range req.ResourceTypes {
+		var stdout bytes.Buffer
+		err := h.run(r.Context(), RunConfig{
+			Args:   h.args("import", req, []string{resourceType}),
+			Stdout: &stdout,
+		})
+		if err != nil {
+			log.Errorf("failed to import %s: %s", resourceType, err)
+			writeServeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		var records []json.RawMessage
+		if output := bytes.TrimSpace(stdout.Bytes()); len(output) > 0 {
+			if err := json.Unmarshal(output, &records); err != nil {
+				writeServeError(w, http.StatusInternalServerError, fmt.Errorf("failed to read the imports of %s: %w", resourceType, err))
+				return
+			}
+		}
+		imports = append(imports, records...)
+	}
+
+	writeServeJSON(w, http.StatusOK, map[string][]json.RawMessage{"imports": imports})
+}
+
+// args converts the request into the command's arguments for the resource
+// types.
+func (h *serveHandler) args(command string, req serveRequest, resourceTypes []string) []string {
+	args := []string{command, "--resource-type", strings.Join(resourceTypes, ",")}
+	if command == "import" {
+		args = append(args, "--format", outputFormatJSON)
+	}
+	if req.UseVariables {
+		args = append(args, "--use-variables")
+	}
+
+	credentials := h.credentials
+	if req.APIToken != "" {
+		credentials.apiToken, credentials.apiKey, credentials.apiEmail = req.APIToken, "", ""
+	}
+
+	flags := []struct{ name, value string }{
+		{"config", credentials.configFile},
+		{"terraform-install-path", credentials.installPath},
+		{"token", credentials.apiToken},
+		{"key", credentials.apiKey},
+		{"email", credentials.apiEmail},
+		{"account", req.AccountID},
+		{"zone", req.ZoneID},
+		{"name-template", req.NameTemplate},
+		{"name-prefix", req.NamePrefix},
+		{"name-suffix", req.NameSuffix},
+	}
+	for _, flag := range flags {
+		if flag.value != "" {
+			args = append(args, "--"+flag.name, flag.value)
+		}
+	}
+
+	return args
+}
+
+// writeServeError responds with the error as JSON.
+func writeServeError(w http.ResponseWriter, status int, err error) {
+	writeServeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeServeJSON responds with the value encoded as JSON.
+func writeServeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Debugf("failed to write the response: %s", err)
+	}
+}
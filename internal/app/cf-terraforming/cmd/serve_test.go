@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeHandler(t *testing.T) {
+	var runs [][]string
+	var outputDir string
+	handler := &serveHandler{
+		credentials: serveCredentials{installPath: "/srv/terraform", apiToken: "server-token"},
+		run: func(ctx context.Context, config RunConfig) error {
+			// The variable files are written into the temporary output
+			// directory, whose name is replaced as it differs each run.
+			args := slices.Clone(config.Args)
+			if i := slices.Index(args, "--output-dir"); i >= 0 {
+				for _, name := range []string{"variables.tf", "terraform.tfvars"} {
+					if err := os.WriteFile(filepath.Join(args[i+1], name), []byte(name+"\n"), 0644); err != nil {
+						return err
+					}
+				}
+				outputDir, args[i+1] = args[i+1], "<dir>"
+			}
+			runs = append(runs, args)
+			if strings.Contains(strings.Join(config.Args, " "), "cloudflare_broken") {
+				return fmt.Errorf("failed to fetch cloudflare_broken")
+			}
+			if config.Args[0] == "import" {
+				_, _ = fmt.Fprintf(config.Stdout, "[{\"resource_type\":%q,\"resource_name\":\"www\",\"import_id\":\"z/1\"}]\n", config.Args[2])
+				return nil
+			}
+			_, _ = fmt.Fprint(config.Stdout, "resource \"cloudflare_dns_record\" \"www\" {}\n")
+			return nil
+		},
+	}
+	server := httptest.NewServer(handler.routes())
+	defer server.Close()
+
+	tests := map[string]struct {
+		path     string
+		body     string
+		status   int
+		response string
+		runs     [][]string
+	}{
+		"generate": {
+			path:   "/v1/generate",
+			body:   `{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["cloudflare_dns_record"], "use_variables": true}`,
+			status: http.StatusOK,
+			response: `{"configuration":"resource \"cloudflare_dns_record\" \"www\" {}\n",` +
+				`"files":{"variables.tf":"variables.tf\n","terraform.tfvars":"terraform.tfvars\n"}}`,
+			runs: [][]string{{"generate", "--resource-type", "cloudflare_dns_record", "--use-variables",
+				"--terraform-install-path", "/srv/terraform", "--token", "server-token", "--zone", "0da42c8d2132a9ddaf714f9e7c920711",
+				"--output-dir", "<dir>"}},
+		},
+		"generate without variables": {
+			path:     "/v1/generate",
+			body:     `{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["cloudflare_dns_record"]}`,
+			status:   http.StatusOK,
+			response: `{"configuration":"resource \"cloudflare_dns_record\" \"www\" {}\n"}`,
+			runs: [][]string{{"generate", "--resource-type", "cloudflare_dns_record",
+				"--terraform-install-path", "/srv/terraform", "--token", "server-token", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"}},
+		},
+		"import with the request's token": {
+			path:     "/v1/import",
+			body:     `{"account_id": "f037e56e89293a057740de681ac9abbe", "resource_types": ["cloudflare_dns_record"], "api_token": "request-token"}`,
+			status:   http.StatusOK,
+			response: `{"imports":[{"resource_type":"cloudflare_dns_record","resource_name":"www","import_id":"z/1"}]}`,
+			runs: [][]string{{"import", "--resource-type", "cloudflare_dns_record", "--format", "json",
+				"--terraform-install-path", "/srv/terraform", "--token", "request-token", "--account", "f037e56e89293a057740de681ac9abbe"}},
+		},
+		"import of each resource type": {
+			path:   "/v1/import",
+			body:   `{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["cloudflare_dns_record", "cloudflare_page_rule"]}`,
+			status: http.StatusOK,
+			response: `{"imports":[{"resource_type":"cloudflare_dns_record","resource_name":"www","import_id":"z/1"},` +
+				`{"resource_type":"cloudflare_page_rule","resource_name":"www","import_id":"z/1"}]}`,
+			runs: [][]string{
+				{"import", "--resource-type", "cloudflare_dns_record", "--format", "json",
+					"--terraform-install-path", "/srv/terraform", "--token", "server-token", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"},
+				{"import", "--resource-type", "cloudflare_page_rule", "--format", "json",
+					"--terraform-install-path", "/srv/terraform", "--token", "server-token", "--zone", "0da42c8d2132a9ddaf714f9e7c920711"},
+			},
+		},
+		"failed run": {
+			path:     "/v1/generate",
+			body:     `{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["cloudflare_broken"]}`,
+			status:   http.StatusInternalServerError,
+			response: `{"error":"failed to fetch cloudflare_broken"}`,
+		},
+		"no scope": {
+			path:     "/v1/generate",
+			body:     `{"resource_types": ["cloudflare_dns_record"]}`,
+			status:   http.StatusBadRequest,
+			response: `{"error":"exactly one of account_id or zone_id must be set"}`,
+		},
+		"flag as resource type": {
+			path:     "/v1/generate",
+			body:     `{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["--post-hook"]}`,
+			status:   http.StatusBadRequest,
+			response: `{"error":"invalid resource type \"--post-hook\""}`,
+		},
+		"unknown field": {
+			path:     "/v1/generate",
+			body:     `{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["cloudflare_dns_record"], "args": ["--post-hook"]}`,
+			status:   http.StatusBadRequest,
+			response: `{"error":"invalid request: json: unknown field \"args\""}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			runs, outputDir = nil, ""
+			resp, err := http.Post(server.URL+tc.path, "application/json", strings.NewReader(tc.body))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+			assert.JSONEq(t, tc.response, string(body))
+			if tc.runs != nil {
+				assert.Equal(t, tc.runs, runs)
+			}
+			if outputDir != "" {
+				assert.NoDirExists(t, outputDir)
+			}
+		})
+	}
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestServeHandlerToken(t *testing.T) {
+	handler := &serveHandler{
+		token: "serve-token",
+		run: func(ctx context.Context, config RunConfig) error {
+			_, _ = fmt.Fprint(config.Stdout, "resource \"cloudflare_dns_record\" \"www\" {}\n")
+			return nil
+		},
+	}
+	server := httptest.NewServer(handler.routes())
+	defer server.Close()
+
+	tests := map[string]struct {
+		authorization string
+		status        int
+	}{
+		"valid token":   {authorization: "Bearer serve-token", status: http.StatusOK},
+		"invalid token": {authorization: "Bearer other-token", status: http.StatusUnauthorized},
+		"basic auth":    {authorization: "Basic c2VydmUtdG9rZW4=", status: http.StatusUnauthorized},
+		"no token":      {status: http.StatusUnauthorized},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/generate",
+				strings.NewReader(`{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711", "resource_types": ["cloudflare_dns_record"]}`))
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if assert.NoError(t, err) {
+				defer resp.Body.Close()
+				assert.Equal(t, tc.status, resp.StatusCode)
+			}
+		})
+	}
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	tests := map[string]struct {
+		address  string
+		expected bool
+	}{
+		"IPv4 loopback":  {address: "127.0.0.1:8080", expected: true},
+		"IPv6 loopback":  {address: "[::1]:8080", expected: true},
+		"localhost":      {address: "localhost:8080", expected: true},
+		"all interfaces": {address: ":8080", expected: false},
+		"unspecified":    {address: "0.0.0.0:8080", expected: false},
+		"private":        {address: "10.0.0.1:8080", expected: false},
+		"hostname":       {address: "cf-terraforming.internal:8080", expected: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isLoopbackAddress(tc.address))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// shard is the part of the resource types generated by this run, such as
+// `2/4` for the second of four.
+var shard string
+
+// shardTypes returns the resource types in the shard. Types are sorted and
+// dealt out in turn so every run given the same types agrees on the shards
+// and each gets a similar number.
+func shardTypes(types, shard string) (string, error) {
+	index, count, err := parseShard(shard)
+	if err != nil {
+		return "", err
+	}
+
+	all := strings.Split(types, ",")
+	sort.Strings(all)
+
+	var selected []string
+	for i, t := range all {
+		if i%count == index-1 {
+			selected = append(selected, t)
+		}
+	}
+
+	return strings.Join(selected, ","), nil
+}
+
+// parseShard parses a shard in the form `N/M` where N is between 1 and M.
+func parseShard(shard string) (int, int, error) {
+	n, m, ok := strings.Cut(shard, "/")
+	index, indexErr := strconv.Atoi(n)
+	count, countErr := strconv.Atoi(m)
+	if !ok || indexErr != nil || countErr != nil || count < 1 || index < 1 || index > count {
+		return 0, 0, fmt.Errorf("invalid --shard %q, expected N/M where N is between 1 and M", shard)
+	}
+
+	return index, count, nil
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardTypes(t *testing.T) {
+	types := "cloudflare_zone,cloudflare_dns_record,cloudflare_ruleset,cloudflare_list,cloudflare_page_rule"
+
+	var all []string
+	for _, shard := range []string{"1/2", "2/2"} {
+		selected, err := shardTypes(types, shard)
+		assert.NoError(t, err)
+		all = append(all, selected)
+	}
+	assert.Equal(t, []string{
+		"cloudflare_dns_record,cloudflare_page_rule,cloudflare_zone",
+		"cloudflare_list,cloudflare_ruleset",
+	}, all)
+
+	selected, err := shardTypes("cloudflare_zone", "2/3")
+	assert.NoError(t, err)
+	assert.Empty(t, selected)
+
+	for _, shard := range []string{"0/2", "3/2", "1", "a/b", "1/0", "-1/2"} {
+		_, err := shardTypes(types, shard)
+		assert.Error(t, err, shard)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"sort"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// unorderedListAttributes are list attributes where the order returned by the
+// API isn't significant so they are sorted to keep the output stable between
+// runs. Attributes with a set type are always sorted.
+var unorderedListAttributes = map[string]bool{
+	"allowed_headers": true,
+	"allowed_idps":    true,
+	"allowed_methods": true,
+	"allowed_origins": true,
+	"emails":          true,
+	"tags":            true,
+}
+
+// sortUnorderedLists sorts the lists in the API response for attributes and
+// nested blocks which are sets or listed in unorderedListAttributes. Any
+// other lists, such as the rules of a ruleset, keep the order from the API.
+func sortUnorderedLists(block *tfjson.SchemaBlock, data map[string]interface{}) {
+	for key, value := range data {
+		var (
+			attributes map[string]*tfjson.SchemaAttribute
+			nested     *tfjson.SchemaBlock
+			unordered  = unorderedListAttributes[key]
+		)
+		if block != nil {
+			if attr, ok := block.Attributes[key]; ok {
+				if attr.AttributeType.IsSetType() {
+					unordered = true
+				}
+				if attr.AttributeNestedType != nil {
+					attributes = attr.AttributeNestedType.Attributes
+					unordered = unordered || attr.AttributeNestedType.NestingMode == tfjson.SchemaNestingModeSet
+				}
+			}
+			if blockType, ok := block.NestedBlocks[key]; ok {
+				nested = blockType.Block
+				unordered = unordered || blockType.NestingMode == tfjson.SchemaNestingModeSet
+			}
+		}
+		if attributes != nil {
+			nested = &tfjson.SchemaBlock{Attributes: attributes}
+		}
+
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				if m, ok := item.(map[string]interface{}); ok {
+					sortUnorderedLists(nested, m)
+				}
+			}
+			if unordered {
+				sortList(v)
+			}
+		case map[string]interface{}:
+			sortUnorderedLists(nested, v)
+		}
+	}
+}
+
+// sortList sorts the list in place by the JSON encoding of each item so that
+// lists of any type have a stable order.
+func sortList(list []interface{}) {
+	type item struct {
+		key   string
+		value interface{}
+	}
+
+	items := make([]item, len(list))
+	for i, value := range list {
+		encoded, _ := json.Marshal(value)
+		items[i] = item{key: string(encoded), value: value}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].key < items[j].key })
+
+	for i := range items {
+		list[i] = items[i].value
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSortUnorderedLists(t *testing.T) {
+	block := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"self_hosted_domains": {AttributeType: cty.Set(cty.String)},
+			"destinations":        {AttributeType: cty.List(cty.String)},
+			"cors_headers": {AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+				NestingMode: tfjson.SchemaNestingModeSingle,
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"allowed_origins": {AttributeType: cty.List(cty.String)},
+				},
+			}},
+			"rules": {AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+				NestingMode: tfjson.SchemaNestingModeList,
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"expression": {AttributeType: cty.String},
+				},
+			}},
+			"policies": {AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+				NestingMode: tfjson.SchemaNestingModeSet,
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"id": {AttributeType: cty.String},
+				},
+			}},
+		},
+	}
+
+	data := map[string]interface{}{
+		"self_hosted_domains": []interface{}{"b.example.com", "a.example.com"},
+		"destinations":        []interface{}{"b.example.com", "a.example.com"},
+		"tags":                []interface{}{"team:web", "env:prod"},
+		"cors_headers": map[string]interface{}{
+			"allowed_origins": []interface{}{"https://b.example.com", "https://a.example.com"},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{"expression": "b"},
+			map[string]interface{}{"expression": "a"},
+		},
+		"policies": []interface{}{
+			map[string]interface{}{"id": "f174e90a"},
+			map[string]interface{}{"id": "699d98642c"},
+		},
+	}
+
+	sortUnorderedLists(block, data)
+
+	assert.Equal(t, map[string]interface{}{
+		"self_hosted_domains": []interface{}{"a.example.com", "b.example.com"},
+		"destinations":        []interface{}{"b.example.com", "a.example.com"},
+		"tags":                []interface{}{"env:prod", "team:web"},
+		"cors_headers": map[string]interface{}{
+			"allowed_origins": []interface{}{"https://a.example.com", "https://b.example.com"},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{"expression": "b"},
+			map[string]interface{}{"expression": "a"},
+		},
+		"policies": []interface{}{
+			map[string]interface{}{"id": "699d98642c"},
+			map[string]interface{}{"id": "f174e90a"},
+		},
+	}, data)
+}
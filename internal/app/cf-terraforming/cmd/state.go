@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/sirupsen/logrus"
+)
+
+// stateImport is a single resource to be imported into state.
+type stateImport struct {
+	address string
+	id      string
+}
+
+// pendingStateImports filters out any resources which are already present in
+// the state so that an interrupted run can be resumed without errors.
+func pendingStateImports(state *tfjson.State, imports []stateImport) []stateImport {
+	if state == nil || state.Values == nil || state.Values.RootModule == nil {
+		return imports
+	}
+
+	existing := make(map[string]bool)
+	for _, r := range state.Values.RootModule.Resources {
+		existing[r.Address] = true
+	}
+
+	var pending []stateImport
+	for _, imp := range imports {
+		if existing[imp.address] {
+			log.WithFields(logrus.Fields{
+				"address": imp.address,
+			}).Debug("resource already exists in state")
+			continue
+		}
+		pending = append(pending, imp)
+	}
+
+	return pending
+}
+
+// writeState imports each of the resources directly into the state of the
+// Terraform working directory. The generated configuration for the resources
+// must already be present in the working directory.
+func writeState(ctx context.Context, tf *tfexec.Terraform, imports []stateImport) error {
+	state, err := tf.Show(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read existing state: %w", err)
+	}
+
+	pending := pendingStateImports(state, imports)
+	for i, imp := range pending {
+		log.WithFields(logrus.Fields{
+			"address":  imp.address,
+			"id":       imp.id,
+			"progress": fmt.Sprintf("%d/%d", i+1, len(pending)),
+		}).Info("importing resource into state")
+
+		if err := tf.Import(ctx, imp.address, imp.id); err != nil {
+			return fmt.Errorf("failed to import %s: %w", imp.address, err)
+		}
+	}
+
+	return nil
+}
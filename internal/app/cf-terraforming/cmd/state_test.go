@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingStateImports(t *testing.T) {
+	imports := []stateImport{
+		{address: "cloudflare_dns_record.terraform_managed_resource_abc_0", id: "0da42c8d2132a9ddaf714f9e7c920711/abc"},
+		{address: "cloudflare_dns_record.terraform_managed_resource_def_1", id: "0da42c8d2132a9ddaf714f9e7c920711/def"},
+	}
+
+	assert.Equal(t, imports, pendingStateImports(nil, imports))
+	assert.Equal(t, imports, pendingStateImports(&tfjson.State{}, imports))
+
+	state := &tfjson.State{
+		Values: &tfjson.StateValues{
+			RootModule: &tfjson.StateModule{
+				Resources: []*tfjson.StateResource{
+					{Address: "cloudflare_dns_record.terraform_managed_resource_abc_0"},
+				},
+			},
+		},
+	}
+	assert.Equal(t, imports[1:], pendingStateImports(state, imports))
+}
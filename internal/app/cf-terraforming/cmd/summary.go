@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const (
+	summaryFormatMarkdown = "markdown"
+	summaryFilename       = "summary.md"
+)
+
+// buildMarkdownSummary builds a table of how many resources of each type were
+// generated, suitable for a pull request description.
+func buildMarkdownSummary(files []*hclwrite.File) string {
+	counts := make(map[string]int)
+	total := 0
+	for _, f := range files {
+		for _, address := range resourceAddresses(f) {
+			resourceType, _, _ := strings.Cut(address, ".")
+			counts[resourceType]++
+			total++
+		}
+	}
+
+	var summary strings.Builder
+	summary.WriteString("| Resource type | Count | Account | Zone |\n")
+	summary.WriteString("| --- | ---: | --- | --- |\n")
+	for _, resourceType := range sortedKeys(counts) {
+		fmt.Fprintf(&summary, "| `%s` | %d | %s | %s |\n", resourceType, counts[resourceType], markdownCode(accountID), markdownCode(zoneID))
+	}
+	fmt.Fprintf(&summary, "| **Total** | **%d** | | |\n", total)
+
+	return summary.String()
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + s + "`"
+}
+
+// writeMarkdownSummary writes the summary into the output directory.
+func writeMarkdownSummary(files []*hclwrite.File) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+
+	path := filepath.Join(outputDir, summaryFilename)
+	if err := os.WriteFile(path, []byte(buildMarkdownSummary(files)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+
+	return nil
+}
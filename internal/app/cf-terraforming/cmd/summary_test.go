@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMarkdownSummary(t *testing.T) {
+	accountID, zoneID = "", "0da42c8d2132a9ddaf714f9e7c920711"
+	defer func() { zoneID = "" }()
+
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "www_example_com_a" {}
+		resource "cloudflare_dns_record" "example_com_a" {}
+		resource "cloudflare_ruleset" "firewall_custom_rules" {}
+	`)), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	expected := "| Resource type | Count | Account | Zone |\n" +
+		"| --- | ---: | --- | --- |\n" +
+		"| `cloudflare_dns_record` | 2 |  | `0da42c8d2132a9ddaf714f9e7c920711` |\n" +
+		"| `cloudflare_ruleset` | 1 |  | `0da42c8d2132a9ddaf714f9e7c920711` |\n" +
+		"| **Total** | **3** | | |\n"
+	assert.Equal(t, expected, buildMarkdownSummary([]*hclwrite.File{f}))
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/cobra"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVar(&configDir, "config-dir", ".", "Directory containing the Terraform configuration to update")
+	syncCmd.Flags().StringVar(&statePath, "state", "", "Terraform state file used to find the ID of each resource in --config-dir")
+}
+
+var syncCmd = &cobra.Command{
+	Use:    "sync",
+	Short:  "Update the attributes of resources already in --config-dir with their current values from Cloudflare",
+	Run:    runSync(),
+	PreRun: sharedPreRun,
+}
+
+func runSync() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if mappingFilePath == "" && statePath == "" {
+			log.Fatal("sync requires either --mapping-file or --state to match existing resources to Cloudflare")
+		}
+
+		existingIDs, err := existingResourceIDs()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		output, idx, err := generateLiveConfiguration(cmd, args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		live, err := liveBlocksByID(output, idx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		paths, err := filepath.Glob(filepath.Join(configDir, "*.tf"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		resourceTypes := strings.Split(resourceType, ",")
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			f, diags := hclwrite.ParseConfig(content, path, hcl.InitialPos)
+			if diags.HasErrors() {
+				log.Fatalf("failed to parse %s: %s", path, diags.Error())
+			}
+
+			if !syncFile(cmd.OutOrStdout(), f, resourceTypes, existingIDs, live) {
+				continue
+			}
+			if err := os.WriteFile(path, hclwrite.Format(f.Bytes()), 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+// existingResourceIDs returns the ID of each existing resource by its address
+// from the state file or otherwise the mapping file.
+func existingResourceIDs() (map[string]string, error) {
+	if statePath == "" {
+		idx, err := loadMapping(mappingFilePath)
+		if err != nil {
+			return nil, err
+		}
+		return idx.ids(), nil
+	}
+
+	content, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := stateDiffResources(content, strings.Split(resourceType, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", statePath, err)
+	}
+
+	ids := make(map[string]string, len(resources))
+	for _, r := range resources {
+		if r.id != "" {
+			ids[r.address] = r.id
+		}
+	}
+
+	return ids, nil
+}
+
+// liveBlocksByID parses the generated configuration and indexes each resource
+// by its ID.
+func liveBlocksByID(output []byte, idx referenceIndex) (map[string]*hclwrite.Block, error) {
+	f, diags := hclwrite.ParseConfig(output, "generated.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse generated configuration: %s", diags.Error())
+	}
+
+	ids := idx.ids()
+	blocks := make(map[string]*hclwrite.Block)
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 {
+			continue
+		}
+		if id, ok := ids[strings.Join(block.Labels(), ".")]; ok {
+			blocks[id] = block
+		}
+	}
+
+	return blocks, nil
+}
+
+// syncFile updates the literal attributes of each resource in the file that
+// can be matched to a live resource, reporting whether anything changed.
+// Attributes using references or variables are left as they are, as are live
+// values using them, such as with --use-variables.
+func syncFile(w io.Writer, f *hclwrite.File, resourceTypes []string, existingIDs map[string]string, live map[string]*hclwrite.Block) bool {
+	changed := false
+	for _, block := range f.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) != 2 || !slices.Contains(resourceTypes, block.Labels()[0]) {
+			continue
+		}
+
+		address := strings.Join(block.Labels(), ".")
+		liveBlock, ok := live[existingIDs[address]]
+		if !ok {
+			log.Debugf("%s was not found in Cloudflare", address)
+			continue
+		}
+
+		var updated []string
+		liveAttributes := liveBlock.Body().Attributes()
+		for _, name := range sortedKeys(liveAttributes) {
+			liveTokens := liveAttributes[name].Expr().BuildTokens(nil)
+			value, ok := literalValue(liveTokens)
+			if !ok {
+				continue
+			}
+
+			if attr := block.Body().GetAttribute(name); attr != nil {
+				existing, ok := literalValue(attr.Expr().BuildTokens(nil))
+				if !ok || existing.RawEquals(value) {
+					continue
+				}
+			}
+
+			block.Body().SetAttributeRaw(name, liveTokens)
+			updated = append(updated, name)
+		}
+
+		if len(updated) > 0 {
+			changed = true
+			_, _ = fmt.Fprintf(w, "updated %s (%s)\n", address, strings.Join(updated, ", "))
+		}
+	}
+
+	return changed
+}
+
+// literalValue evaluates the expression, reporting false if it depends on
+// any variables or functions so it can't be safely replaced.
+func literalValue(tokens hclwrite.Tokens) (cty.Value, bool) {
+	expr, diags := hclsyntax.ParseExpression(tokens.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+
+	value, diags := expr.Value(nil)
+	return value, !diags.HasErrors()
+}
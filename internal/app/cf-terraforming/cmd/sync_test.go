@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncFile(t *testing.T) {
+	existing := heredoc.Doc(`
+		# Public website.
+		resource "cloudflare_dns_record" "website" {
+		  zone_id = var.zone_id
+		  name    = "www.example.com"
+		  content = "192.0.2.1" # Load balanced by the origin.
+		  ttl     = 1
+		  proxied = true
+		}
+
+		resource "cloudflare_dns_record" "api" {
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		  name    = "api.example.com"
+		  content = "192.0.2.3"
+		}
+
+		resource "cloudflare_dns_record" "unmanaged" {
+		  name = "old.example.com"
+		}
+	`)
+	generated := heredoc.Doc(`
+		resource "cloudflare_dns_record" "www_example_com_a" {
+		  content = "192.0.2.2"
+		  name    = "www.example.com"
+		  proxied = true
+		  ttl     = 1
+		  type    = "A"
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		}
+
+		resource "cloudflare_dns_record" "api_example_com_a" {
+		  content = "192.0.2.4"
+		  name    = "api.example.com"
+		  comment = "Managed by ${var.team}"
+		  zone_id = var.zone_id
+		}
+	`)
+
+	live, err := liveBlocksByID([]byte(generated), referenceIndex{
+		"cloudflare_dns_record": {
+			"372e67954025e0ba6aaa6d586b9e0b59": "cloudflare_dns_record.www_example_com_a",
+			"48ab0c4d2132a9ddaf714f9e7c920711": "cloudflare_dns_record.api_example_com_a",
+		},
+	})
+	require.NoError(t, err)
+
+	f, diags := hclwrite.ParseConfig([]byte(existing), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	var output bytes.Buffer
+	changed := syncFile(&output, f, []string{"cloudflare_dns_record"}, map[string]string{
+		"cloudflare_dns_record.website": "372e67954025e0ba6aaa6d586b9e0b59",
+		"cloudflare_dns_record.api":     "48ab0c4d2132a9ddaf714f9e7c920711",
+	}, live)
+
+	assert.True(t, changed)
+	// Live values using references or variables aren't copied over literals.
+	assert.Equal(t, "updated cloudflare_dns_record.website (content, type)\nupdated cloudflare_dns_record.api (content)\n", output.String())
+	assert.Equal(t, heredoc.Doc(`
+		# Public website.
+		resource "cloudflare_dns_record" "website" {
+		  zone_id = var.zone_id
+		  name    = "www.example.com"
+		  content = "192.0.2.2" # Load balanced by the origin.
+		  ttl     = 1
+		  proxied = true
+		  type    = "A"
+		}
+
+		resource "cloudflare_dns_record" "api" {
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		  name    = "api.example.com"
+		  content = "192.0.2.4"
+		}
+
+		resource "cloudflare_dns_record" "unmanaged" {
+		  name = "old.example.com"
+		}
+	`), string(hclwrite.Format(f.Bytes())))
+}
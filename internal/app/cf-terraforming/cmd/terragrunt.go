@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const terragruntFilename = "terragrunt.hcl"
+
+// buildTerragruntRootFile generates the parent terragrunt.hcl that every
+// account or zone directory includes. It is responsible for generating the
+// provider requirements so they don't need repeating in each directory.
+func buildTerragruntRootFile(providerVersion string) *hclwrite.File {
+	providers := hclwrite.NewEmptyFile()
+	appendRequiredProviders(providers.Body(), providerVersion)
+	contents := string(hclwrite.Format(providers.Bytes()))
+
+	f := hclwrite.NewEmptyFile()
+	generate := f.Body().AppendNewBlock("generate", []string{"provider"}).Body()
+	generate.SetAttributeValue("path", cty.StringVal("provider.tf"))
+	generate.SetAttributeValue("if_exists", cty.StringVal("overwrite_terragrunt"))
+	generate.SetAttributeRaw("contents", tokensForHeredoc(contents))
+
+	return f
+}
+
+// buildTerragruntFile generates the terragrunt.hcl for a single account or
+// zone which includes the root configuration and passes the identifier in as
+// a module input.
+func buildTerragruntFile(vars map[string]string) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	include := f.Body().AppendNewBlock("include", []string{"root"}).Body()
+	include.SetAttributeRaw("path", hclwrite.TokensForFunctionCall("find_in_parent_folders"))
+	f.Body().AppendNewline()
+
+	inputs := make(map[string]cty.Value, len(vars))
+	for name, value := range vars {
+		inputs[name] = cty.StringVal(value)
+	}
+	f.Body().SetAttributeValue("inputs", cty.ObjectVal(inputs))
+
+	return f
+}
+
+// prepareTerragruntOutput writes the Terragrunt configuration for the
+// targeted account or zone and points the output directory at it so that the
+// generated resources and variables are written alongside.
+func prepareTerragruntOutput(providerVersion string) (*os.File, error) {
+	vars := scopeVariables()
+	if len(vars) == 0 {
+		return nil, errors.New("--terragrunt requires either --account or --zone")
+	}
+
+	rootPath := filepath.Join(outputDir, terragruntFilename)
+	if _, err := os.Stat(rootPath); errors.Is(err, fs.ErrNotExist) {
+		if err := writeHCLFile(rootPath, buildTerragruntRootFile(providerVersion)); err != nil {
+			return nil, err
+		}
+	}
+
+	var identifier string
+	for _, value := range vars {
+		identifier = value
+	}
+	outputDir = filepath.Join(outputDir, identifier)
+
+	if err := writeHCLFile(filepath.Join(outputDir, terragruntFilename), buildTerragruntFile(vars)); err != nil {
+		return nil, err
+	}
+
+	return createMainFile()
+}
+
+// writeHCLFile formats and writes a file, creating any parent directories.
+func writeHCLFile(path string, f *hclwrite.File) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, hclwrite.Format(f.Bytes()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+
+	return nil
+}
+
+// tokensForHeredoc builds the tokens for a heredoc string. The content must
+// end in a newline.
+func tokensForHeredoc(content string) hclwrite.Tokens {
+	return hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<EOF\n")},
+		{Type: hclsyntax.TokenStringLit, Bytes: []byte(content)},
+		{Type: hclsyntax.TokenCHeredoc, Bytes: []byte("EOF")},
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTerragruntFiles(t *testing.T) {
+	expectedRoot := heredoc.Doc(`
+		generate "provider" {
+		  path      = "provider.tf"
+		  if_exists = "overwrite_terragrunt"
+		  contents  = <<EOF
+		terraform {
+		  required_providers {
+		    cloudflare = {
+		      source  = "cloudflare/cloudflare"
+		      version = "~> 5"
+		    }
+		  }
+		}
+		EOF
+		}
+	`)
+	assert.Equal(t, expectedRoot, string(hclwrite.Format(buildTerragruntRootFile("5.1.0").Bytes())))
+
+	expected := heredoc.Doc(`
+		include "root" {
+		  path = find_in_parent_folders()
+		}
+
+		inputs = {
+		  zone_id = "0da42c8d2132a9ddaf714f9e7c920711"
+		}
+	`)
+	assert.Equal(t, expected, string(hclwrite.Format(buildTerragruntFile(map[string]string{"zone_id": "0da42c8d2132a9ddaf714f9e7c920711"}).Bytes())))
+}
+
+func TestPrepareTerragruntOutput(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, terragruntFilename)
+	require.NoError(t, os.WriteFile(root, []byte("# existing\n"), 0644))
+
+	defer func(dir, zone, account string) { outputDir, zoneID, accountID = dir, zone, account }(outputDir, zoneID, accountID)
+	outputDir, zoneID, accountID = dir, "0da42c8d2132a9ddaf714f9e7c920711", ""
+
+	out, err := prepareTerragruntOutput("5.1.0")
+	require.NoError(t, err)
+	defer out.Close()
+
+	zoneDir := filepath.Join(dir, "0da42c8d2132a9ddaf714f9e7c920711")
+	assert.Equal(t, zoneDir, outputDir)
+	assert.Equal(t, filepath.Join(zoneDir, "main.tf"), out.Name())
+	assert.FileExists(t, filepath.Join(zoneDir, terragruntFilename))
+
+	// The root configuration is likely customised so is never overwritten.
+	content, err := os.ReadFile(root)
+	require.NoError(t, err)
+	assert.Equal(t, "# existing\n", string(content))
+}
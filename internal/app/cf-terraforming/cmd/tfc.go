@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const tfcDefaultHostname = "app.terraform.io"
+
+// tfcClient is a minimal client for the parts of the Terraform Cloud (and
+// Terraform Enterprise) API needed to run an import.
+//
+// See https://developer.hashicorp.com/terraform/cloud-docs/api-docs
+type tfcClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// tfcDocument is the JSON:API document wrapping every request and response.
+type tfcDocument struct {
+	Data tfcResource `json:"data"`
+}
+
+type tfcResource struct {
+	ID            string                     `json:"id,omitempty"`
+	Type          string                     `json:"type"`
+	Attributes    map[string]interface{}     `json:"attributes,omitempty"`
+	Relationships map[string]tfcRelationship `json:"relationships,omitempty"`
+}
+
+type tfcRelationship struct {
+	Data tfcResource `json:"data"`
+}
+
+var errTFCNotFound = errors.New("not found")
+
+func newTFCClient(hostname, token string) *tfcClient {
+	return &tfcClient{
+		baseURL:    "https://" + hostname,
+		token:      token,
+		httpClient: &http.Client{Transport: &userAgentTransport{rt: sharedTransport}},
+	}
+}
+
+func (c *tfcClient) do(ctx context.Context, method, path string, body *tfcDocument) (*tfcDocument, error) {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v2"+path, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errTFCNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, b)
+	}
+
+	var doc tfcDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode response from %s %s: %w", method, path, err)
+	}
+
+	return &doc, nil
+}
+
+// workspace returns the ID of the named workspace, creating it if it doesn't
+// already exist.
+func (c *tfcClient) workspace(ctx context.Context, organization, name string) (string, error) {
+	path := fmt.Sprintf("/organizations/%s/workspaces", url.PathEscape(organization))
+
+	doc, err := c.do(ctx, http.MethodGet, path+"/"+url.PathEscape(name), nil)
+	if err == nil {
+		return doc.Data.ID, nil
+	}
+	if !errors.Is(err, errTFCNotFound) {
+		return "", err
+	}
+
+	log.WithFields(logrus.Fields{
+		"organization": organization,
+		"workspace":    name,
+	}).Info("creating Terraform Cloud workspace")
+
+	doc, err = c.do(ctx, http.MethodPost, path, &tfcDocument{Data: tfcResource{
+		Type:       "workspaces",
+		Attributes: map[string]interface{}{"name": name},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	return doc.Data.ID, nil
+}
+
+// uploadConfiguration creates a new configuration version for the workspace
+// containing the Terraform files in dir.
+func (c *tfcClient) uploadConfiguration(ctx context.Context, workspaceID, dir string) (string, error) {
+	doc, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/workspaces/%s/configuration-versions", workspaceID), &tfcDocument{Data: tfcResource{
+		Type:       "configuration-versions",
+		Attributes: map[string]interface{}{"auto-queue-runs": false},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to create configuration version: %w", err)
+	}
+
+	uploadURL, _ := doc.Data.Attributes["upload-url"].(string)
+	if uploadURL == "" {
+		return "", errors.New("configuration version is missing an upload URL")
+	}
+
+	archive, err := archiveConfiguration(dir)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, archive)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload configuration: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("failed to upload configuration: %s", resp.Status)
+	}
+
+	return doc.Data.ID, nil
+}
+
+// queueRun queues a run of the configuration version. The run plans the
+// imports and waits for confirmation before applying them.
+func (c *tfcClient) queueRun(ctx context.Context, workspaceID, configurationVersionID string) (string, error) {
+	doc, err := c.do(ctx, http.MethodPost, "/runs", &tfcDocument{Data: tfcResource{
+		Type:       "runs",
+		Attributes: map[string]interface{}{"message": "Import existing Cloudflare resources with cf-terraforming"},
+		Relationships: map[string]tfcRelationship{
+			"workspace":             {Data: tfcResource{Type: "workspaces", ID: workspaceID}},
+			"configuration-version": {Data: tfcResource{Type: "configuration-versions", ID: configurationVersionID}},
+		},
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to queue run: %w", err)
+	}
+
+	return doc.Data.ID, nil
+}
+
+// archiveConfiguration builds a gzipped tarball of the files in dir.
+func archiveConfiguration(dir string) (io.Reader, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tw.WriteHeader(&tar.Header{Name: entry.Name(), Mode: 0644, Size: int64(len(content))}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
+
+// publishToTerraformCloud uploads the configuration in the output directory to
+// a Terraform Cloud workspace and queues a run to import the resources.
+func publishToTerraformCloud(ctx context.Context) error {
+	organization := viper.GetString("tfc-organization")
+	workspace := viper.GetString("tfc-workspace")
+	if organization == "" || workspace == "" {
+		return errors.New("both --tfc-organization and --tfc-workspace must be set")
+	}
+
+	token := viper.GetString("tfc-token")
+	if token == "" {
+		return errors.New("a Terraform Cloud API token must be set with --tfc-token or TFE_TOKEN")
+	}
+
+	hostname := viper.GetString("tfc-hostname")
+	client := newTFCClient(hostname, token)
+
+	workspaceID, err := client.workspace(ctx, organization, workspace)
+	if err != nil {
+		return err
+	}
+
+	configurationVersionID, err := client.uploadConfiguration(ctx, workspaceID, outputDir)
+	if err != nil {
+		return err
+	}
+
+	runID, err := client.queueRun(ctx, workspaceID, configurationVersionID)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"url": fmt.Sprintf("https://%s/app/%s/workspaces/%s/runs/%s", hostname, organization, workspace, runID),
+	}).Info("queued Terraform Cloud run to import resources. Ensure the Cloudflare credentials are configured as workspace variables before applying")
+
+	return nil
+}
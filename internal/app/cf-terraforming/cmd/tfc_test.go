@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTFCClient(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("# main\n"), 0644))
+
+	var (
+		requests []string
+		uploaded []string
+	)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		if r.URL.Path != "/upload" {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v2/organizations/example/workspaces/cloudflare":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /api/v2/organizations/example/workspaces":
+			var doc tfcDocument
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&doc))
+			assert.Equal(t, "cloudflare", doc.Data.Attributes["name"])
+			_, _ = fmt.Fprint(w, `{"data":{"id":"ws-123","type":"workspaces"}}`)
+		case "POST /api/v2/workspaces/ws-123/configuration-versions":
+			_, _ = fmt.Fprintf(w, `{"data":{"id":"cv-123","type":"configuration-versions","attributes":{"upload-url":"%s/upload"}}}`, server.URL)
+		case "PUT /upload":
+			gz, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			tr := tar.NewReader(gz)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+				uploaded = append(uploaded, header.Name)
+			}
+		case "POST /api/v2/runs":
+			var doc tfcDocument
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&doc))
+			assert.Equal(t, "cv-123", doc.Data.Relationships["configuration-version"].Data.ID)
+			_, _ = fmt.Fprint(w, `{"data":{"id":"run-123","type":"runs"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &tfcClient{baseURL: server.URL, token: "token", httpClient: server.Client()}
+	ctx := context.Background()
+
+	workspaceID, err := client.workspace(ctx, "example", "cloudflare")
+	assert.NoError(t, err)
+	assert.Equal(t, "ws-123", workspaceID)
+
+	configurationVersionID, err := client.uploadConfiguration(ctx, workspaceID, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "cv-123", configurationVersionID)
+	assert.Equal(t, []string{"main.tf"}, uploaded)
+
+	runID, err := client.queueRun(ctx, workspaceID, configurationVersionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "run-123", runID)
+
+	assert.Equal(t, []string{
+		"GET /api/v2/organizations/example/workspaces/cloudflare",
+		"POST /api/v2/organizations/example/workspaces",
+		"POST /api/v2/workspaces/ws-123/configuration-versions",
+		"PUT /upload",
+		"POST /api/v2/runs",
+	}, requests)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// tftestFilename is where the `terraform test` file is written within
+// --output-dir, matching the directory Terraform reads tests from by default.
+var tftestFilename = filepath.Join("tests", "generated.tftest.hcl")
+
+// generateTests writes a `terraform test` file asserting the identifying
+// attributes of every generated resource.
+var generateTests bool
+
+// buildTestFile generates a test which plans the configuration and asserts
+// that the attributes used to name each resource, such as the name of a DNS
+// record, are unchanged. Only attributes with a literal value are asserted and
+// resources using `for_each` are skipped.
+func buildTestFile(files []*hclwrite.File) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	run := f.Body().AppendNewBlock("run", []string{"generated"}).Body()
+	run.SetAttributeTraversal("command", hcl.Traversal{hcl.TraverseRoot{Name: "plan"}})
+
+	for _, generated := range files {
+		for _, block := range generated.Body().Blocks() {
+			if block.Type() != "resource" || len(block.Labels()) != 2 || block.Body().GetAttribute("for_each") != nil {
+				continue
+			}
+			resourceType, name := block.Labels()[0], block.Labels()[1]
+
+			var asserted []string
+			for _, attrName := range terraforming.NameAttributes(resourceType) {
+				attr := block.Body().GetAttribute(attrName)
+				if attr == nil || slices.Contains(asserted, attrName) {
+					continue
+				}
+				asserted = append(asserted, attrName)
+				value, ok := literalValue(attr.Expr().BuildTokens(nil))
+				if !ok || !value.IsKnown() || value.IsNull() || !value.Type().IsPrimitiveType() {
+					continue
+				}
+
+				condition := hclwrite.TokensForTraversal(hcl.Traversal{
+					hcl.TraverseRoot{Name: resourceType},
+					hcl.TraverseAttr{Name: name},
+					hcl.TraverseAttr{Name: attrName},
+				})
+				condition = append(condition, &hclwrite.Token{Type: hclsyntax.TokenEqualOp, Bytes: []byte("==")})
+				condition = append(condition, hclwrite.TokensForValue(value)...)
+
+				run.AppendNewline()
+				assert := run.AppendNewBlock("assert", nil).Body()
+				assert.SetAttributeRaw("condition", condition)
+				assert.SetAttributeValue("error_message", cty.StringVal(fmt.Sprintf("%s.%s.%s has changed", resourceType, name, attrName)))
+			}
+		}
+	}
+
+	return f
+}
+
+// writeTestFile writes the `terraform test` file into the output directory.
+func writeTestFile(files []*hclwrite.File) error {
+	path := filepath.Join(outputDir, tftestFilename)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, hclwrite.Format(buildTestFile(files).Bytes()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Debugf("wrote %s", path)
+
+	return nil
+}
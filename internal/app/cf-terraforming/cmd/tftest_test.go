@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTestFile(t *testing.T) {
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(`
+		resource "cloudflare_dns_record" "www_example_com_a" {
+		  zone_id = var.zone_id
+		  name    = "www.example.com"
+		  type    = "A"
+		  content = "192.0.2.1"
+		}
+		resource "cloudflare_dns_record" "terraform_managed_resource" {
+		  for_each = local.dns_records
+		  name     = each.value.name
+		}
+		resource "cloudflare_list" "blocked" {
+		  name        = var.list_name
+		  description = "Blocked IPs"
+		}
+	`)), "generated.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+
+	assert.Equal(t, heredoc.Doc(`
+		run "generated" {
+		  command = plan
+
+		  assert {
+		    condition     = cloudflare_dns_record.www_example_com_a.name == "www.example.com"
+		    error_message = "cloudflare_dns_record.www_example_com_a.name has changed"
+		  }
+
+		  assert {
+		    condition     = cloudflare_dns_record.www_example_com_a.type == "A"
+		    error_message = "cloudflare_dns_record.www_example_com_a.type has changed"
+		  }
+
+		  assert {
+		    condition     = cloudflare_list.blocked.description == "Blocked IPs"
+		    error_message = "cloudflare_list.blocked.description has changed"
+		  }
+		}
+	`), string(hclwrite.Format(buildTestFile([]*hclwrite.File{f}).Bytes())))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+var (
+	// clientCertFile and clientKeyFile are the PEM certificate and key
+	// presented to proxies or gateways enforcing mTLS.
+	clientCertFile, clientKeyFile string
+
+	// caCertFile is a PEM bundle of extra certificate authorities to trust,
+	// such as the one used by a TLS intercepting proxy.
+	caCertFile string
+)
+
+// configureTLS sets the client certificate and trusted certificate
+// authorities used by the transport.
+func configureTLS(transport *http.Transport, certFile, keyFile, caFile string) error {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if transport.TLSClientConfig != nil {
+		config = transport.TLSClientConfig.Clone()
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return errors.New("--client-cert and --client-key must be set together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read --ca-cert: %w", err)
+		}
+		// The bundle is trusted alongside the system roots.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	transport.TLSClientConfig = config
+	return nil
+}
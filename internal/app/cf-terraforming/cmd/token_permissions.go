@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// requiredPermissionGroups are the API token permission groups needed to read
+// each resource type. A permission group granting write access also grants
+// read access.
+var requiredPermissionGroups = map[string][]string{
+	"cloudflare_access_rule":                          {"Firewall Services Read"},
+	"cloudflare_account":                              {"Account Settings Read"},
+	"cloudflare_account_member":                       {"Account Settings Read"},
+	"cloudflare_api_shield":                           {"API Gateway Read"},
+	"cloudflare_argo_smart_routing":                   {"Zone Settings Read"},
+	"cloudflare_argo_tiered_caching":                  {"Zone Settings Read"},
+	"cloudflare_authenticated_origin_pulls":           {"SSL and Certificates Read"},
+	"cloudflare_bot_management":                       {"Bot Management Read"},
+	"cloudflare_certificate_pack":                     {"SSL and Certificates Read"},
+	"cloudflare_custom_hostname":                      {"SSL and Certificates Read"},
+	"cloudflare_custom_pages":                         {"Custom Pages Read"},
+	"cloudflare_custom_ssl":                           {"SSL and Certificates Read"},
+	"cloudflare_d1_database":                          {"D1 Read"},
+	"cloudflare_dns_record":                           {"DNS Read"},
+	"cloudflare_email_routing_address":                {"Email Routing Addresses Read"},
+	"cloudflare_email_routing_catch_all":              {"Email Routing Rules Read"},
+	"cloudflare_email_routing_rule":                   {"Email Routing Rules Read"},
+	"cloudflare_filter":                               {"Firewall Services Read"},
+	"cloudflare_healthcheck":                          {"Health Checks Read"},
+	"cloudflare_hyperdrive_config":                    {"Hyperdrive Read"},
+	"cloudflare_list":                                 {"Account Filter Lists Read"},
+	"cloudflare_list_item":                            {"Account Filter Lists Read"},
+	"cloudflare_load_balancer":                        {"Load Balancers Read"},
+	"cloudflare_load_balancer_monitor":                {"Load Balancing: Monitors and Pools Read"},
+	"cloudflare_load_balancer_pool":                   {"Load Balancing: Monitors and Pools Read"},
+	"cloudflare_logpush_job":                          {"Logs Read"},
+	"cloudflare_managed_transforms":                   {"Zone Transform Rules Read"},
+	"cloudflare_notification_policy":                  {"Notifications Read"},
+	"cloudflare_notification_policy_webhooks":         {"Notifications Read"},
+	"cloudflare_page_rule":                            {"Page Rules Read"},
+	"cloudflare_pages_domain":                         {"Pages Read"},
+	"cloudflare_pages_project":                        {"Pages Read"},
+	"cloudflare_queue":                                {"Queues Read"},
+	"cloudflare_queue_consumer":                       {"Queues Read"},
+	"cloudflare_r2_bucket":                            {"Workers R2 Storage Read"},
+	"cloudflare_r2_custom_domain":                     {"Workers R2 Storage Read"},
+	"cloudflare_r2_managed_domain":                    {"Workers R2 Storage Read"},
+	"cloudflare_rate_limit":                           {"Firewall Services Read"},
+	"cloudflare_spectrum_application":                 {"Zone Read"},
+	"cloudflare_stream":                               {"Stream Read"},
+	"cloudflare_tiered_cache":                         {"Zone Settings Read"},
+	"cloudflare_total_tls":                            {"SSL and Certificates Read"},
+	"cloudflare_turnstile_widget":                     {"Turnstile Sites Read"},
+	"cloudflare_url_normalization_settings":           {"Zone Settings Read"},
+	"cloudflare_waiting_room":                         {"Waiting Rooms Read"},
+	"cloudflare_waiting_room_event":                   {"Waiting Rooms Read"},
+	"cloudflare_waiting_room_rules":                   {"Waiting Rooms Read"},
+	"cloudflare_waiting_room_settings":                {"Waiting Rooms Read"},
+	"cloudflare_web_analytics_rule":                   {"Account Analytics Read"},
+	"cloudflare_web_analytics_site":                   {"Account Analytics Read"},
+	"cloudflare_workers_cron_trigger":                 {"Workers Scripts Read"},
+	"cloudflare_workers_custom_domain":                {"Workers Scripts Read"},
+	"cloudflare_workers_deployment":                   {"Workers Scripts Read"},
+	"cloudflare_workers_kv":                           {"Workers KV Storage Read"},
+	"cloudflare_workers_kv_namespace":                 {"Workers KV Storage Read"},
+	"cloudflare_workers_route":                        {"Workers Routes Read"},
+	"cloudflare_workers_script_subdomain":             {"Workers Scripts Read"},
+	"cloudflare_zero_trust_access_application":        {"Access: Apps and Policies Read"},
+	"cloudflare_zero_trust_access_group":              {"Access: Organizations, Identity Providers, and Groups Read"},
+	"cloudflare_zero_trust_access_identity_provider":  {"Access: Organizations, Identity Providers, and Groups Read"},
+	"cloudflare_zero_trust_access_policy":             {"Access: Apps and Policies Read"},
+	"cloudflare_zero_trust_access_service_token":      {"Access: Service Tokens Read"},
+	"cloudflare_zero_trust_device_posture_rule":       {"Zero Trust Read"},
+	"cloudflare_zero_trust_dns_location":              {"Zero Trust Read"},
+	"cloudflare_zero_trust_gateway_policy":            {"Zero Trust Read"},
+	"cloudflare_zero_trust_gateway_settings":          {"Zero Trust Read"},
+	"cloudflare_zero_trust_list":                      {"Zero Trust Read"},
+	"cloudflare_zero_trust_organization":              {"Access: Organizations, Identity Providers, and Groups Read"},
+	"cloudflare_zero_trust_tunnel_cloudflared":        {"Cloudflare Tunnel Read"},
+	"cloudflare_zero_trust_tunnel_cloudflared_config": {"Cloudflare Tunnel Read"},
+	"cloudflare_zero_trust_tunnel_cloudflared_route":  {"Cloudflare Tunnel Read"},
+	"cloudflare_zone":                                 {"Zone Read"},
+	"cloudflare_zone_cache_reserve":                   {"Zone Settings Read"},
+	"cloudflare_zone_dnssec":                          {"DNS Read"},
+	"cloudflare_zone_lockdown":                        {"Firewall Services Read"},
+	"cloudflare_zone_setting":                         {"Zone Settings Read"},
+}
+
+// hasPermissionGroup reports whether the granted permission groups include the
+// required one or the equivalent with write access.
+func hasPermissionGroup(granted []string, required string) bool {
+	if slices.Contains(granted, required) {
+		return true
+	}
+
+	base, ok := strings.CutSuffix(required, " Read")
+	return ok && (slices.Contains(granted, base+" Write") || slices.Contains(granted, base+" Edit"))
+}
+
+// missingPermissionGroups returns the resource types that need permission
+// groups which haven't been granted, by permission group.
+func missingPermissionGroups(resourceTypes, granted []string) map[string][]string {
+	missing := make(map[string][]string)
+	for _, resourceType := range resourceTypes {
+		for _, required := range requiredPermissionGroups[resourceType] {
+			if !hasPermissionGroup(granted, required) {
+				missing[required] = append(missing[required], resourceType)
+			}
+		}
+	}
+
+	return missing
+}
+
+// tokenPermissionGroups returns the names of the permission groups allowed
+// by the current API token's policies. Reading the token requires it to have
+// the API Tokens Read permission.
+func tokenPermissionGroups(ctx context.Context) ([]string, error) {
+	endpoint := "/user/tokens"
+	token, err := doctorGet(ctx, endpoint+"/verify")
+	// Tokens owned by an account can only be read through the account.
+	if err != nil && accountID != "" {
+		endpoint = fmt.Sprintf("/accounts/%s/tokens", accountID)
+		token, err = doctorGet(ctx, endpoint+"/verify")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := doctorGet(ctx, endpoint+"/"+token.Get("id").String())
+	if err != nil {
+		log.Debugf("failed to read the API token: %s", err)
+		return nil, errors.New("the API token can't read its own permissions without the API Tokens Read permission")
+	}
+
+	var allowed, denied []string
+	for _, policy := range details.Get("policies").Array() {
+		for _, group := range policy.Get("permission_groups.#.name").Array() {
+			if policy.Get("effect").String() == "deny" {
+				denied = append(denied, group.String())
+			} else {
+				allowed = append(allowed, group.String())
+			}
+		}
+	}
+
+	return slices.DeleteFunc(allowed, func(group string) bool {
+		return slices.Contains(denied, group)
+	}), nil
+}
+
+// checkTokenPermissions checks the API token has the permission groups needed
+// for every requested resource type.
+func checkTokenPermissions(ctx context.Context, resourceTypes []string) doctorCheck {
+	const name = "Token permissions"
+	if apiToken == "" {
+		return checkSkipped(name, "an API key has the same permissions as its user")
+	}
+
+	granted, err := tokenPermissionGroups(ctx)
+	if err != nil {
+		return checkSkipped(name, err.Error())
+	}
+
+	missing := missingPermissionGroups(resourceTypes, granted)
+	if len(missing) == 0 {
+		return checkPassed(name, "the API token has every permission needed")
+	}
+
+	groups := make([]string, 0, len(missing))
+	for group := range missing {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	details := make([]string, 0, len(groups))
+	for _, group := range groups {
+		details = append(details, fmt.Sprintf("%s (%s)", group, strings.Join(missing[group], ", ")))
+	}
+
+	return checkFailed(name, fmt.Errorf("the API token is missing %s", strings.Join(details, ", ")))
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasPermissionGroup(t *testing.T) {
+	assert.True(t, hasPermissionGroup([]string{"DNS Read"}, "DNS Read"))
+	assert.True(t, hasPermissionGroup([]string{"DNS Write"}, "DNS Read"))
+	assert.True(t, hasPermissionGroup([]string{"Workers Scripts Edit"}, "Workers Scripts Read"))
+	assert.False(t, hasPermissionGroup([]string{"DNS Write"}, "Zone Read"))
+	assert.False(t, hasPermissionGroup(nil, "DNS Read"))
+}
+
+func TestMissingPermissionGroups(t *testing.T) {
+	missing := missingPermissionGroups(
+		[]string{"cloudflare_dns_record", "cloudflare_workers_route", "cloudflare_workers_cron_trigger", "cloudflare_workers_kv", "cloudflare_example"},
+		[]string{"DNS Read", "Workers KV Storage Write"},
+	)
+
+	assert.Equal(t, map[string][]string{
+		"Workers Routes Read":  {"cloudflare_workers_route"},
+		"Workers Scripts Read": {"cloudflare_workers_cron_trigger"},
+	}, missing)
+}
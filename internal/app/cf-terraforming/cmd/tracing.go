@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingShutdownTimeout is how long is spent exporting the remaining spans
+// before exiting.
+const tracingShutdownTimeout = 5 * time.Second
+
+var (
+	// otlpEndpoint is the URL spans are exported to. The standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variables are also respected.
+	otlpEndpoint string
+
+	// tracer creates the spans for each resource type and API page. Until
+	// tracing is configured the spans are discarded.
+	tracer = otel.Tracer("github.com/cloudflare/cf-terraforming")
+
+	// runContext holds the span for the whole run which every other span is
+	// a child of.
+	runContext = context.Background()
+
+	shutdownTracing = func() {}
+)
+
+// tracingEnabled reports whether an OTLP endpoint has been configured.
+func tracingEnabled() bool {
+	return otlpEndpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// configureTracing exports spans over OTLP if an endpoint is configured and
+// starts the span for the run. The spans are exported when the run exits.
+func configureTracing(command string) error {
+	if !tracingEnabled() {
+		return nil
+	}
+
+	var options []otlptracehttp.Option
+	if otlpEndpoint != "" {
+		options = append(options, otlptracehttp.WithEndpointURL(otlpEndpoint))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName("cf-terraforming"),
+			semconv.ServiceVersion(versionString),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	var span trace.Span
+	runContext, span = tracer.Start(context.Background(), command, trace.WithAttributes(
+		attribute.String("cloudflare.account_id", accountID),
+		attribute.String("cloudflare.zone_id", zoneID),
+	))
+
+	var once sync.Once
+	shutdownTracing = func() {
+		once.Do(func() {
+			span.End()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+			if err := provider.Shutdown(ctx); err != nil {
+				log.Warnf("failed to export traces: %s", err)
+			}
+		})
+	}
+	logrus.RegisterExitHandler(shutdownTracing)
+
+	return nil
+}
+
+// startSpan starts a span for a resource type as a child of the context.
+func startSpan(ctx context.Context, name, resourceType string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attribute.String("cloudflare.resource_type", resourceType)))
+}
+
+// endSpan ends the span, recording the error unless it means there are no
+// resources.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !terraforming.IsNoResourcesError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
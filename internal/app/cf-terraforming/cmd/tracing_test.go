@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestFetchSpans(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":"record-%s"}],"result_info":{"page":%s,"total_pages":2}}`, page, page)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	defer func(client *cloudflare.Client, n int) { api, parallelism = client, n }(api, parallelism)
+	previous := tracer
+	defer func() { tracer = previous }()
+
+	api = cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	parallelism = 1
+	tracer = provider.Tracer("test")
+
+	ctx, span := startSpan(context.Background(), "fetch", "cloudflare_example")
+	_, err := getAPIResponse(ctx, "cloudflare_example", nil, nil, "/zones/0da42c8d2132a9ddaf714f9e7c920711/example")
+	endSpan(span, err)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, page := range spans[:2] {
+		assert.Equal(t, "fetch page", page.Name())
+		assert.Equal(t, spans[2].SpanContext().SpanID(), page.Parent().SpanID())
+		assert.Contains(t, page.Attributes(), attribute.String("cloudflare.resource_type", "cloudflare_example"))
+	}
+	assert.Equal(t, "fetch", spans[2].Name())
+	assert.Equal(t, codes.Unset, spans[2].Status().Code)
+}
+
+func TestEndSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tr := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, span := tr.Start(context.Background(), "fetch")
+	endSpan(span, terraforming.ErrNoResult)
+	_, span = tr.Start(context.Background(), "fetch")
+	endSpan(span, fmt.Errorf("failed to fetch API endpoint: %w", &cloudflare.Error{StatusCode: http.StatusForbidden}))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
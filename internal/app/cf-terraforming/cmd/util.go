@@ -11,25 +11,21 @@ import (
 	"sort"
 	"strings"
 
-	cfv0 "github.com/cloudflare/cloudflare-go"
-	"github.com/cloudflare/cloudflare-go/v4"
-	"github.com/cloudflare/cloudflare-go/v4/option"
-	"github.com/hashicorp/hcl/v2/hclwrite"
-	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"github.com/tidwall/gjson"
-	"github.com/zclconf/go-cty/cty"
 )
 
 var hasNumber = regexp.MustCompile("[0-9]+").MatchString
 
 // userAgentTransport is an http.RoundTripper that appends a cf-terraforming
 // product token to the outgoing User-Agent header so that API requests
-// originating from this tool are identifiable in Cloudflare's logs.
+// originating from this tool are identifiable in Cloudflare's logs. The
+// --user-agent-suffix and --request-header values are added to API requests.
 type userAgentTransport struct {
-	rt http.RoundTripper
+	rt      http.RoundTripper
+	suffix  string
+	headers http.Header
 }
 
 func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -38,7 +34,13 @@ func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error
 	if existing := req.Header.Get("User-Agent"); existing != "" {
 		ua = existing + " " + ua
 	}
+	if t.suffix != "" {
+		ua += " " + t.suffix
+	}
 	req.Header.Set("User-Agent", ua)
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
 	return t.rt.RoundTrip(req)
 }
 
@@ -52,6 +54,17 @@ func contains(slice []string, item string) bool {
 	return ok
 }
 
+// sortedKeys returns the keys of a map in a deterministic order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 func executeCommandC(root *cobra.Command, args ...string) (output string, err error) {
 	buf := new(bytes.Buffer)
 	root.SetOut(buf)
@@ -92,12 +105,93 @@ func sharedPreRun(cmd *cobra.Command, args []string) {
 	accountID = viper.GetString("account")
 	zoneID = viper.GetString("zone")
 	hostname = viper.GetString("hostname")
+	apiURL = viper.GetString("api-url")
+	accessClientID = viper.GetString("access-client-id")
+	accessClientSecret = viper.GetString("access-client-secret")
+	proxyURL = viper.GetString("proxy")
+	providerVersionOverride = viper.GetString("provider-version")
+	providerSchemaSource = viper.GetString("provider-schema")
+	userAgentSuffix = viper.GetString("user-agent-suffix")
+	notifyWebhookURL = viper.GetString("notify-webhook")
+	notifyFormat = viper.GetString("notify-format")
+	// Reading the flag through viper would split values on commas.
+	if !cmd.Flags().Changed("request-header") {
+		requestHeaders = viper.GetStringSlice("request-header")
+	}
+	if !cmd.Flags().Changed("converter-plugin") {
+		converterPlugins = viper.GetStringSlice("converter-plugin")
+	}
 
 	if accountID != "" && zoneID != "" {
 		log.Fatal("--account and --zone are mutually exclusive, support for both is deprecated")
 	}
 
-	if apiToken = viper.GetString("token"); apiToken == "" {
+	if notifyFormat != notifyFormatJSON && notifyFormat != notifyFormatSlack {
+		log.Fatalf("unsupported --notify-format %q", notifyFormat)
+	}
+
+	if len(excludeResourceTypes) > 0 {
+		types, err := excludeTypes(resourceType, excludeResourceTypes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if types == "" {
+			log.Fatal("every resource type is excluded by --exclude-resource-type")
+		}
+		resourceType = types
+	}
+
+	if shard != "" {
+		types, err := shardTypes(resourceType, shard)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if types == "" {
+			log.Warnf("shard %s has no resource types to generate", shard)
+			log.Exit(0)
+		}
+		log.Debugf("generating resource types %s in shard %s", types, shard)
+		resourceType = types
+	}
+
+	var err error
+	if runProgress, err = newProgressReporter(); err != nil {
+		log.Fatal(err)
+	}
+	if resourceDefaults, err = loadResourceDefaults(viper.GetViper()); err != nil {
+		log.Fatal(err)
+	}
+	if err := loadConverterPlugins(converterPlugins); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := configureTracing(cmd.Name()); err != nil {
+		log.Fatal(err)
+	}
+
+	// Credentials from flags, the environment or the config file take
+	// precedence over the credential helper and then the keychain. They're
+	// kept out of viper so they don't outlast the run.
+	apiToken = viper.GetString("token")
+	if helper := viper.GetString("credential-helper"); helper != "" && apiToken == "" && viper.GetString("key") == "" {
+		token, err := runCredentialHelper(runContext, helper)
+		if err != nil {
+			log.Fatal(err)
+		}
+		apiToken = token
+	}
+	if apiToken == "" && viper.GetString("key") == "" {
+		token, err := keychainToken()
+		if err != nil {
+			log.Warnf("failed to read the API token from the keychain: %s", err)
+		}
+		if token != "" {
+			log.Debugf("using the API token for %s from the keychain", keychainUser())
+			apiToken = token
+		}
+	}
+
+	if apiToken == "" {
 		if apiEmail = viper.GetString("email"); apiEmail == "" {
 			log.Error("'email' must be set.")
 		}
@@ -118,39 +212,8 @@ func sharedPreRun(cmd *cobra.Command, args []string) {
 		}).Debug("initializing cloudflare-go with API Token")
 	}
 
-	var options []cfv0.Option
-
-	if hostname != "" {
-		options = append(options, cfv0.BaseURL("https://"+hostname+"/client/v4"))
-	}
-
-	if verbose {
-		options = append(options, cfv0.Debug(true))
-	}
-
-	var err error
-
-	httpClient := &http.Client{
-		Transport: &userAgentTransport{rt: http.DefaultTransport},
-	}
-	options = append(options, cfv0.HTTPClient(httpClient))
-
-	// Don't initialise a client in CI as this messes with VCR and the ability to
-	// mock out the HTTP interactions.
-	if os.Getenv("CI") != "true" {
-		var useToken = apiToken != ""
-
-		if useToken {
-			apiV0, err = cfv0.NewWithAPIToken(apiToken, options...)
-			api = cloudflare.NewClient(option.WithAPIToken(apiToken), option.WithHTTPClient(httpClient))
-		} else {
-			apiV0, err = cfv0.New(apiKey, apiEmail, options...)
-			api = cloudflare.NewClient(option.WithAPIKey(apiKey), option.WithAPIEmail(apiEmail), option.WithHTTPClient(httpClient))
-		}
-
-		if err != nil {
-			log.Fatal(err)
-		}
+	if err := configureAPIClients(); err != nil {
+		log.Fatal(err)
 	}
 }
 
@@ -197,201 +260,6 @@ func flattenAttrMap(l []interface{}) map[string]interface{} {
 	return result
 }
 
-func processBlocks(schemaBlock *tfjson.SchemaBlock, structData map[string]interface{}, parent *hclwrite.Body, parentBlock string) {
-	keys := make([]string, 0, len(structData))
-	for k := range structData {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, block := range keys {
-		if _, ok := schemaBlock.NestedBlocks[block]; ok {
-			if schemaBlock.NestedBlocks[block].NestingMode == "list" || schemaBlock.NestedBlocks[block].NestingMode == "set" {
-				child := hclwrite.NewBlock(block, []string{})
-				switch s := structData[block].(type) {
-				case []map[string]interface{}:
-					for _, nestedItem := range s {
-						stepChild := hclwrite.NewBlock(block, []string{})
-						processBlocks(schemaBlock.NestedBlocks[block].Block, nestedItem, stepChild.Body(), block)
-						if len(stepChild.Body().Attributes()) != 0 || len(stepChild.Body().Blocks()) != 0 {
-							parent.AppendBlock(stepChild)
-						}
-					}
-				case map[string]interface{}:
-					processBlocks(schemaBlock.NestedBlocks[block].Block, s, child.Body(), block)
-				case []interface{}:
-					for _, nestedItem := range s {
-						stepChild := hclwrite.NewBlock(block, []string{})
-						processBlocks(schemaBlock.NestedBlocks[block].Block, nestedItem.(map[string]interface{}), stepChild.Body(), block)
-						if len(stepChild.Body().Attributes()) != 0 || len(stepChild.Body().Blocks()) != 0 {
-							parent.AppendBlock(stepChild)
-						}
-					}
-				default:
-					log.Debugf("unable to generate recursively nested blocks for %T", s)
-				}
-				if len(child.Body().Attributes()) != 0 || len(child.Body().Blocks()) != 0 {
-					parent.AppendBlock(child)
-				}
-			}
-		} else {
-			if parentBlock == "" && block == "id" {
-				continue
-			}
-			if _, ok := schemaBlock.Attributes[block]; ok && (schemaBlock.Attributes[block].Optional || schemaBlock.Attributes[block].Required) {
-				writeAttrLine(block, structData[block], parentBlock, parent)
-			}
-		}
-	}
-}
-
-// writeAttrLine outputs a line of HCL configuration with a configurable depth
-// for known types.
-func writeAttrLine(key string, value interface{}, parentName string, body *hclwrite.Body) {
-	if body == nil || value == nil {
-		log.Debug("body or value is nil")
-		return
-	}
-
-	switch values := value.(type) {
-	case []map[string]interface{}:
-		// Use tuple approach for heterogeneous maps
-		var tupleValues []cty.Value
-		for _, item := range values {
-			mapCty := make(map[string]cty.Value)
-			for k, v := range item {
-				mapCty[k] = processExpression(v)
-			}
-			tupleValues = append(tupleValues, cty.ObjectVal(mapCty))
-		}
-		body.SetAttributeValue(key, cty.TupleVal(tupleValues))
-	case map[string]interface{}:
-		ctyMap := make(map[string]cty.Value)
-
-		// Sort keys for consistent output
-		sortedKeys := make([]string, 0, len(values))
-		for k := range values {
-			sortedKeys = append(sortedKeys, k)
-		}
-		sort.Strings(sortedKeys)
-
-		for _, k := range sortedKeys {
-			ctyMap[k] = processExpression(values[k])
-		}
-		body.SetAttributeValue(key, cty.ObjectVal(ctyMap))
-	case []interface{}:
-		if len(values) == 0 {
-			body.SetAttributeValue(key, cty.EmptyTupleVal)
-			return
-		}
-
-		// Convert all slice elements using processExpression for consistency
-		var tupleValues []cty.Value
-		for _, item := range values {
-			tupleValues = append(tupleValues, processExpression(item))
-		}
-		body.SetAttributeValue(key, cty.TupleVal(tupleValues))
-	case []int:
-		var vals []cty.Value
-		for _, i := range values {
-			vals = append(vals, cty.NumberIntVal(int64(i)))
-		}
-		body.SetAttributeValue(key, cty.TupleVal(vals))
-	case []string:
-		if len(values) > 0 {
-			var vals []cty.Value
-			for _, item := range values {
-				vals = append(vals, cty.StringVal(item))
-			}
-			body.SetAttributeValue(key, cty.TupleVal(vals))
-		} else {
-			body.SetAttributeValue(key, cty.EmptyTupleVal)
-		}
-	case string:
-		if parentName == "query" && key == "value" && value == "" {
-			body.SetAttributeValue(key, cty.StringVal(""))
-		}
-		if value != "" {
-			body.SetAttributeValue(key, cty.StringVal(values))
-		}
-	case int:
-		body.SetAttributeValue(key, cty.NumberIntVal(int64(values)))
-	case float64:
-		body.SetAttributeValue(key, cty.NumberFloatVal(values))
-	case bool:
-		body.SetAttributeValue(key, cty.BoolVal(values))
-	default:
-		fmt.Printf("Warning: Unknown attribute type: key %s, value %v, value type %T\n", key, value, value)
-		// Convert unknown types to string representation
-		body.SetAttributeValue(key, cty.StringVal(fmt.Sprintf("%v", value)))
-	}
-}
-
-// Process any expression into its appropriate cty.Value and also modified to use TupleVal consistently.
-func processExpression(val interface{}) cty.Value {
-	if val == nil {
-		return cty.NullVal(cty.DynamicPseudoType)
-	}
-
-	switch v := val.(type) {
-	case string:
-		return cty.StringVal(v)
-	case int:
-		return cty.NumberIntVal(int64(v))
-	case float64:
-		return cty.NumberFloatVal(v)
-	case bool:
-		return cty.BoolVal(v)
-	case []string:
-		var vals []cty.Value
-		for _, s := range v {
-			vals = append(vals, cty.StringVal(s))
-		}
-		return cty.TupleVal(vals)
-	case []int:
-		var vals []cty.Value
-		for _, i := range v {
-			vals = append(vals, cty.NumberIntVal(int64(i)))
-		}
-		return cty.TupleVal(vals)
-	case []interface{}:
-		if len(v) == 0 {
-			return cty.EmptyTupleVal
-		}
-
-		var vals []cty.Value
-		for _, item := range v {
-			vals = append(vals, processExpression(item))
-		}
-		return cty.TupleVal(vals)
-	case map[string]interface{}:
-		ctyMap := make(map[string]cty.Value)
-		// Sort keys for consistent output
-		sortedKeys := make([]string, 0, len(v))
-		for k := range v {
-			sortedKeys = append(sortedKeys, k)
-		}
-		sort.Strings(sortedKeys)
-
-		for _, k := range sortedKeys {
-			ctyMap[k] = processExpression(v[k])
-		}
-		return cty.ObjectVal(ctyMap)
-	case []map[string]interface{}:
-		var vals []cty.Value
-		for _, m := range v {
-			// Convert map to object
-			objMap := make(map[string]cty.Value)
-			for mk, mv := range m {
-				objMap[mk] = processExpression(mv)
-			}
-			vals = append(vals, cty.ObjectVal(objMap))
-		}
-		return cty.TupleVal(vals)
-	default:
-		return cty.StringVal(fmt.Sprintf("%v", val))
-	}
-}
-
 // boolToEnabledOrDisabled outputs a string representation of a boolean in the form of `enabled` or `disabled`.
 func boolToEnabledOrDisabled(value bool) string {
 	if value {
@@ -399,24 +267,3 @@ func boolToEnabledOrDisabled(value bool) string {
 	}
 	return "disabled"
 }
-
-// transformToCollection takes a JSON payload that is a singlular resource but
-// operates as a `list` endpoint and transforms it into a JSON to correctly
-// handle the output.
-func transformToCollection(value string) string {
-	return fmt.Sprintf("[%s]", value)
-}
-
-// modifyResponsePayload takes the current resource and the `gjson.Result`
-// to run arbitrary modifications to the JSON before passing it to be overlayed
-// the provider schema.
-func modifyResponsePayload(resourceName string, value gjson.Result) string {
-	output := value.String()
-
-	switch resourceName {
-	case "cloudflare_zero_trust_organization":
-		output = transformToCollection(output)
-	}
-
-	return output
-}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// validateOutput parses the generated configuration back and checks its
+// structure before it's written, catching generator bugs early.
+var validateOutput bool
+
+// validateGenerated parses the generated files and returns the problems
+// found: syntax errors, duplicate resource addresses, required attributes
+// which are missing or empty and references to resources which weren't
+// generated.
+func validateGenerated(files []*hclwrite.File, schema *tfjson.ProviderSchema) []string {
+	var src []byte
+	for _, f := range files {
+		src = append(src, hclwrite.Format(f.Bytes())...)
+		src = append(src, '\n')
+	}
+
+	file, diags := hclparse.NewParser().ParseHCL(src, "generated.tf")
+	if diags.HasErrors() {
+		var problems []string
+		for _, diag := range diags.Errs() {
+			problems = append(problems, diag.Error())
+		}
+		return problems
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	var problems []string
+	addresses := make(map[string]bool)
+	for _, block := range body.Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			continue
+		}
+		address := strings.Join(block.Labels, ".")
+		if addresses[address] {
+			problems = append(problems, fmt.Sprintf("%s is defined more than once", address))
+		}
+		addresses[address] = true
+
+		if resourceSchema, ok := schema.ResourceSchemas[block.Labels[0]]; ok && resourceSchema.Block != nil {
+			for _, name := range sortedKeys(resourceSchema.Block.Attributes) {
+				if resourceSchema.Block.Attributes[name].Required && !hasValue(block.Body, name) {
+					problems = append(problems, fmt.Sprintf("%s is missing the required attribute %s", address, name))
+				}
+			}
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			continue
+		}
+		address := strings.Join(block.Labels, ".")
+		for _, reference := range referencedAddresses(block.Body) {
+			if !addresses[reference] {
+				problems = append(problems, fmt.Sprintf("%s references %s which isn't defined", address, reference))
+			}
+		}
+	}
+
+	return problems
+}
+
+// hasValue reports whether the attribute is set to something other than null
+// or an empty string.
+func hasValue(body *hclsyntax.Body, name string) bool {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return false
+	}
+
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		// Expressions such as references can't be evaluated here.
+		return true
+	}
+
+	return !value.IsNull() && !(value.Type() == cty.String && value.AsString() == "")
+}
+
+// referencedAddresses returns the addresses of the Cloudflare resources
+// referenced anywhere within the body.
+func referencedAddresses(body *hclsyntax.Body) []string {
+	seen := make(map[string]bool)
+	var walk func(body *hclsyntax.Body)
+	walk = func(body *hclsyntax.Body) {
+		for _, attr := range body.Attributes {
+			for _, traversal := range attr.Expr.Variables() {
+				if address, ok := resourceAddress(traversal); ok {
+					seen[address] = true
+				}
+			}
+		}
+		for _, block := range body.Blocks {
+			walk(block.Body)
+		}
+	}
+	walk(body)
+
+	references := make([]string, 0, len(seen))
+	for address := range seen {
+		references = append(references, address)
+	}
+	sort.Strings(references)
+
+	return references
+}
+
+// resourceAddress returns the address of the Cloudflare resource the
+// traversal refers to, if any.
+func resourceAddress(traversal hcl.Traversal) (string, bool) {
+	root := traversal.RootName()
+	if !strings.HasPrefix(root, "cloudflare_") || len(traversal) < 2 {
+		return "", false
+	}
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+
+	return root + "." + attr.Name, true
+}
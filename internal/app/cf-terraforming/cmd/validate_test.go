@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseGeneratedFile(t *testing.T, src string) *hclwrite.File {
+	f, diags := hclwrite.ParseConfig([]byte(heredoc.Doc(src)), "test.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return f
+}
+
+func TestValidateGenerated(t *testing.T) {
+	schema := &tfjson.ProviderSchema{ResourceSchemas: map[string]*tfjson.Schema{
+		"cloudflare_dns_record": {Block: &tfjson.SchemaBlock{Attributes: map[string]*tfjson.SchemaAttribute{
+			"zone_id": {Required: true},
+			"name":    {Required: true},
+			"comment": {Optional: true},
+		}}},
+	}}
+
+	valid := []*hclwrite.File{
+		parseGeneratedFile(t, `
+			resource "cloudflare_zone" "example" {
+			  name = "example.com"
+			}
+		`),
+		parseGeneratedFile(t, `
+			resource "cloudflare_dns_record" "www" {
+			  zone_id = cloudflare_zone.example.id
+			  name    = "www"
+			}
+		`),
+	}
+	assert.Empty(t, validateGenerated(valid, schema))
+
+	invalid := []*hclwrite.File{
+		parseGeneratedFile(t, `
+			resource "cloudflare_dns_record" "www" {
+			  zone_id = cloudflare_zone.missing.id
+			  name    = ""
+			}
+
+			resource "cloudflare_dns_record" "www" {
+			  comment    = "duplicate"
+			  depends_on = [cloudflare_ruleset.missing]
+			}
+		`),
+	}
+	assert.Equal(t, []string{
+		"cloudflare_dns_record.www is missing the required attribute name",
+		"cloudflare_dns_record.www is defined more than once",
+		"cloudflare_dns_record.www is missing the required attribute name",
+		"cloudflare_dns_record.www is missing the required attribute zone_id",
+		"cloudflare_dns_record.www references cloudflare_zone.missing which isn't defined",
+		"cloudflare_dns_record.www references cloudflare_ruleset.missing which isn't defined",
+	}, validateGenerated(invalid, schema))
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/cf-terraforming/pkg/terraforming"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	variablesFilename = "variables.tf"
+	tfvarsFilename    = "terraform.tfvars"
+)
+
+// scopeVariables returns the variable name and value for the account or zone
+// the command is targeting. Only one is ever returned as `--account` and
+// `--zone` are mutually exclusive.
+func scopeVariables() map[string]string {
+	if accountID != "" {
+		return map[string]string{"account_id": accountID}
+	}
+
+	if zoneID != "" {
+		return map[string]string{"zone_id": zoneID}
+	}
+
+	return map[string]string{}
+}
+
+// runScope returns the account or zone the command is targeting.
+func runScope() terraforming.Scope {
+	return terraforming.Scope{AccountID: accountID, ZoneID: zoneID}
+}
+
+// writeVariableReference sets `key` to a `var.<name>` traversal instead of a
+// literal value.
+func writeVariableReference(key, name string, body *hclwrite.Body) {
+	terraforming.WriteAttribute(body, key, terraforming.Variable(name), hclOptions())
+}
+
+// buildVariablesFile generates the `variable` declarations for the provided
+// variable names.
+func buildVariablesFile(vars map[string]string) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	for _, name := range sortedKeys(vars) {
+		body := f.Body().AppendNewBlock("variable", []string{name}).Body()
+		body.SetAttributeValue("description", cty.StringVal(fmt.Sprintf("Cloudflare %s the generated resources belong to", variableDescription(name))))
+		body.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+		f.Body().AppendNewline()
+	}
+
+	return f
+}
+
+// buildTFVarsFile generates the variable assignments for the provided
+// variables.
+func buildTFVarsFile(vars map[string]string) *hclwrite.File {
+	f := hclwrite.NewEmptyFile()
+	for _, name := range sortedKeys(vars) {
+		f.Body().SetAttributeValue(name, cty.StringVal(vars[name]))
+	}
+
+	return f
+}
+
+// writeVariableFiles outputs variables.tf and terraform.tfvars into the
+// configured output directory. Modules have the values passed in by the caller
+// so terraform.tfvars is omitted when generating one.
+func writeVariableFiles(vars map[string]string) error {
+	if err := writeConfigFile(variablesFilename, buildVariablesFile(vars)); err != nil {
+		return err
+	}
+
+	if writesModule() {
+		return nil
+	}
+
+	return writeConfigFile(tfvarsFilename, buildTFVarsFile(vars))
+}
+
+func variableDescription(name string) string {
+	switch name {
+	case "account_id":
+		return "account ID"
+	case "zone_id":
+		return "zone ID"
+	default:
+		return name
+	}
+}
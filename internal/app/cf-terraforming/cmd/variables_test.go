@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildVariablesFile(t *testing.T) {
+	vars := map[string]string{"zone_id": cloudflareTestZoneID}
+
+	expectedVariables := heredoc.Doc(`
+		variable "zone_id" {
+		  description = "Cloudflare zone ID the generated resources belong to"
+		  type        = string
+		}
+
+	`)
+	assert.Equal(t, expectedVariables, string(hclwrite.Format(buildVariablesFile(vars).Bytes())))
+
+	expectedTFVars := "zone_id = \"" + cloudflareTestZoneID + "\"\n"
+	assert.Equal(t, expectedTFVars, string(hclwrite.Format(buildTFVarsFile(vars).Bytes())))
+}
+
+func TestWriteVariableReference(t *testing.T) {
+	f := hclwrite.NewEmptyFile()
+	writeVariableReference("account_id", "account_id", f.Body())
+	assert.Equal(t, "account_id = var.account_id\n", string(f.Bytes()))
+}
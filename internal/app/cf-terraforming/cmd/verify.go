@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// verifyResult is the outcome of planning the generated configuration for a
+// single resource type.
+type verifyResult struct {
+	resourceType string
+	resources    int
+	changes      []string
+}
+
+// summarisePlan groups the planned changes by resource type. A resource that
+// is only being imported without any other changes is considered verified.
+func summarisePlan(plan *tfjson.Plan) []verifyResult {
+	results := make(map[string]*verifyResult)
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode != tfjson.ManagedResourceMode || rc.Change == nil {
+			continue
+		}
+
+		result, ok := results[rc.Type]
+		if !ok {
+			result = &verifyResult{resourceType: rc.Type}
+			results[rc.Type] = result
+		}
+		result.resources++
+
+		if !rc.Change.Actions.NoOp() {
+			result.changes = append(result.changes, fmt.Sprintf("%s (%s)", rc.Address, actionsString(rc.Change.Actions)))
+		}
+	}
+
+	summary := make([]verifyResult, 0, len(results))
+	for _, resourceType := range sortedKeys(results) {
+		summary = append(summary, *results[resourceType])
+	}
+
+	return summary
+}
+
+func actionsString(actions tfjson.Actions) string {
+	names := make([]string, 0, len(actions))
+	for _, action := range actions {
+		names = append(names, string(action))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// writeVerifyReport outputs the verification results and reports whether the
+// plan was empty.
+func writeVerifyReport(w io.Writer, results []verifyResult) bool {
+	clean := true
+	for _, result := range results {
+		if len(result.changes) == 0 {
+			_, _ = fmt.Fprintf(w, "ok    %s (%d resources)\n", result.resourceType, result.resources)
+			continue
+		}
+
+		clean = false
+		_, _ = fmt.Fprintf(w, "diff  %s (%d of %d resources have changes)\n", result.resourceType, len(result.changes), result.resources)
+		for _, change := range result.changes {
+			_, _ = fmt.Fprintf(w, "        %s\n", change)
+		}
+	}
+
+	return clean
+}
+
+// verifyConfiguration copies the configuration in dir into a temporary
+// workspace and runs a plan against it to check that importing the resources
+// results in no further changes.
+func verifyConfiguration(ctx context.Context, dir string) ([]verifyResult, error) {
+	workspace, err := os.MkdirTemp("", "cf-terraforming-verify")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workspace)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(workspace, entry.Name()), content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	execPath, err := findOrInstallTerraform()
+	if err != nil {
+		return nil, fmt.Errorf("could not find or install Terraform: %w", err)
+	}
+
+	tf, err := tfexec.NewTerraform(workspace, execPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := tf.SetEnv(providerEnv()); err != nil {
+		return nil, err
+	}
+
+	log.Info("initialising temporary workspace to verify configuration")
+	if err := tf.Init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialise: %w", err)
+	}
+
+	log.Info("planning generated configuration")
+	planFile := filepath.Join(workspace, "verify.tfplan")
+	if _, err := tf.Plan(ctx, tfexec.Out(planFile)); err != nil {
+		return nil, fmt.Errorf("failed to plan: %w", err)
+	}
+
+	plan, err := tf.ShowPlanFile(ctx, planFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	return summarisePlan(plan), nil
+}
+
+// providerEnv returns the current environment along with the credentials used
+// by cf-terraforming so the provider authenticates in the same way.
+func providerEnv() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && !strings.HasPrefix(k, "TF_") {
+			env[k] = v
+		}
+	}
+
+	if apiToken != "" {
+		env["CLOUDFLARE_API_TOKEN"] = apiToken
+	} else {
+		env["CLOUDFLARE_API_KEY"] = apiKey
+		env["CLOUDFLARE_EMAIL"] = apiEmail
+	}
+
+	return env
+}
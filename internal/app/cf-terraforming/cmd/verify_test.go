@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarisePlan(t *testing.T) {
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: "cloudflare_dns_record.a",
+				Type:    "cloudflare_dns_record",
+				Mode:    tfjson.ManagedResourceMode,
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}, Importing: &tfjson.Importing{ID: "zone/a"}},
+			},
+			{
+				Address: "cloudflare_page_rule.b",
+				Type:    "cloudflare_page_rule",
+				Mode:    tfjson.ManagedResourceMode,
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionUpdate}, Importing: &tfjson.Importing{ID: "zone/b"}},
+			},
+			{
+				Address: "cloudflare_page_rule.c",
+				Type:    "cloudflare_page_rule",
+				Mode:    tfjson.ManagedResourceMode,
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}},
+			},
+			{
+				Address: "data.cloudflare_zone.d",
+				Type:    "cloudflare_zone",
+				Mode:    tfjson.DataResourceMode,
+				Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionRead}},
+			},
+		},
+	}
+
+	results := summarisePlan(plan)
+	assert.Equal(t, []verifyResult{
+		{resourceType: "cloudflare_dns_record", resources: 1},
+		{resourceType: "cloudflare_page_rule", resources: 2, changes: []string{"cloudflare_page_rule.b (update)"}},
+	}, results)
+
+	var output bytes.Buffer
+	assert.False(t, writeVerifyReport(&output, results))
+	assert.Equal(t, heredoc.Doc(`
+		ok    cloudflare_dns_record (1 resources)
+		diff  cloudflare_page_rule (1 of 2 resources have changes)
+		        cloudflare_page_rule.b (update)
+	`), output.String())
+}
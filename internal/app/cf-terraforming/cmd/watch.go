@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// exitCodeDrift is the exit code of watch --once when drift is found,
+// matching terraform plan -detailed-exitcode.
+const exitCodeDrift = 2
+
+var (
+	// watchInterval is how long watch waits between runs.
+	watchInterval time.Duration
+
+	// watchOnce compares a single run against the snapshot, such as from cron.
+	watchOnce bool
+
+	// watchSnapshotPath is the file the last run's resources are kept in so
+	// drift is found across restarts and --once runs.
+	watchSnapshotPath string
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 6*time.Hour, "How long to wait between runs")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Run once against --watch-snapshot and exit with code 2 if drift is found, such as from cron")
+	watchCmd.Flags().StringVar(&watchSnapshotPath, "watch-snapshot", "", "File to keep the resources of the last run in, so drift is found across restarts")
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Periodically discover the resources and report drift since the last run as JSON events",
+	Long: "Periodically discover the resources and report those added, removed or changed since the last run as JSON events. " +
+		"The first run records the snapshot that later runs are compared against.",
+	Run:    runWatch,
+	PreRun: sharedPreRun,
+}
+
+// driftEvent is a single resource which drifted between runs.
+type driftEvent struct {
+	Time         time.Time `json:"time"`
+	Event        string    `json:"event"`
+	Address      string    `json:"address"`
+	ResourceType string    `json:"resource_type"`
+	ID           string    `json:"id,omitempty"`
+	ZoneID       string    `json:"zone_id,omitempty"`
+	AccountID    string    `json:"account_id,omitempty"`
+	Attributes   []string  `json:"attributes,omitempty"`
+}
+
+const (
+	driftEventAdded   = "added"
+	driftEventRemoved = "removed"
+	driftEventChanged = "changed"
+)
+
+// watchSnapshot is the resources discovered by a run.
+type watchSnapshot struct {
+	Time      time.Time               `json:"time"`
+	Resources []watchSnapshotResource `json:"resources"`
+}
+
+type watchSnapshotResource struct {
+	Address    string                 `json:"address"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	if watchOnce && watchSnapshotPath == "" {
+		log.Fatal("--once requires --watch-snapshot to compare against")
+	}
+	if !watchOnce && watchInterval <= 0 {
+		log.Fatal("--interval must be positive")
+	}
+
+	previous, err := loadWatchSnapshot(watchSnapshotPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		// Each run starts afresh rather than adding to the state of the last,
+		// so the metrics reported are those of the last run.
+		resetRunState()
+
+		var live []diffResource
+		// A failed run is reported and retried at the next interval rather
+		// than stopping the watch.
+		err := catchExit(func() {
+			var fetchErr error
+			if live, fetchErr = fetchLiveResources(cmd, args); fetchErr != nil {
+				log.Fatal(fetchErr)
+			}
+		})
+		if interrupted.Load() {
+			return
+		}
+
+		drifted := false
+		if err != nil {
+			log.Errorf("failed to discover the resources: %s", err)
+			notifyRun(ctx, runSummary{Command: cmd.Name(), AccountID: accountID, ZoneID: zoneID, Failures: []runFailure{{Error: err.Error()}}})
+			if watchOnce {
+				log.Exit(exitCodeOf(err))
+			}
+		} else {
+			current := newWatchSnapshot(live, time.Now())
+			if previous == nil {
+				log.Infof("recorded a snapshot of %d resources to compare later runs against", len(live))
+			} else if events := driftEvents(previous, current); len(events) > 0 {
+				if err := writeDriftEvents(cmd.OutOrStdout(), events); err != nil {
+					log.Fatal(err)
+				}
+				notifyRun(ctx, runSummary{Command: cmd.Name(), AccountID: accountID, ZoneID: zoneID, Drift: events})
+				drifted = true
+			} else {
+				log.Info("no drift found")
+			}
+
+			if err := saveWatchSnapshot(watchSnapshotPath, current); err != nil {
+				log.Fatal(err)
+			}
+			previous = current
+		}
+
+		if watchOnce {
+			if drifted {
+				log.Exit(exitCodeDrift)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+// newWatchSnapshot records the discovered resources.
+func newWatchSnapshot(live []diffResource, now time.Time) *watchSnapshot {
+	snapshot := &watchSnapshot{Time: now.UTC(), Resources: make([]watchSnapshotResource, 0, len(live))}
+	for _, r := range live {
+		snapshot.Resources = append(snapshot.Resources, watchSnapshotResource{
+			Address:    r.address,
+			ID:         r.id,
+			Attributes: r.attributes,
+		})
+	}
+
+	return snapshot
+}
+
+// diffResources returns the resources in the snapshot.
+func (s *watchSnapshot) diffResources() []diffResource {
+	resources := make([]diffResource, 0, len(s.Resources))
+	for _, r := range s.Resources {
+		resources = append(resources, diffResource{address: r.Address, id: r.ID, attributes: r.Attributes})
+	}
+
+	return resources
+}
+
+// driftEvents compares the snapshots by resource ID, falling back to the
+// address for resources without one.
+func driftEvents(previous, current *watchSnapshot) []driftEvent {
+	withKeys := func(resources []diffResource) []diffResource {
+		for i := range resources {
+			if resources[i].id == "" {
+				resources[i].id = resources[i].address
+			}
+		}
+		return resources
+	}
+	report := compareResources(withKeys(current.diffResources()), withKeys(previous.diffResources()), true)
+
+	ids := make(map[string]string)
+	for _, r := range slices.Concat(previous.Resources, current.Resources) {
+		ids[r.Address] = r.ID
+	}
+
+	var events []driftEvent
+	event := func(kind, address, id string, attributes []string) {
+		resourceType, _, _ := strings.Cut(address, ".")
+		events = append(events, driftEvent{
+			Time:         current.Time,
+			Event:        kind,
+			Address:      address,
+			ResourceType: resourceType,
+			ID:           id,
+			ZoneID:       zoneID,
+			AccountID:    accountID,
+			Attributes:   attributes,
+		})
+	}
+	for _, r := range report.onlyCloudflare {
+		event(driftEventAdded, r.address, ids[r.address], nil)
+	}
+	for _, r := range report.onlyTerraform {
+		event(driftEventRemoved, r.address, ids[r.address], nil)
+	}
+	for _, address := range sortedKeys(report.differs) {
+		event(driftEventChanged, address, ids[address], report.differs[address])
+	}
+
+	return events
+}
+
+// writeDriftEvents outputs each event as a line of JSON.
+func writeDriftEvents(w io.Writer, events []driftEvent) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadWatchSnapshot reads the snapshot, returning nil if there isn't one.
+func loadWatchSnapshot(path string) (*watchSnapshot, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot watchSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse --watch-snapshot %s: %w", path, err)
+	}
+
+	return &snapshot, nil
+}
+
+// saveWatchSnapshot writes the snapshot, replacing the previous one.
+func saveWatchSnapshot(path string, snapshot *watchSnapshot) error {
+	if path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return fmt.Errorf("failed to write --watch-snapshot: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftEvents(t *testing.T) {
+	defer func(zone string) { zoneID = zone }(zoneID)
+	zoneID = "0da42c8d2132a9ddaf714f9e7c920711"
+
+	previous := newWatchSnapshot([]diffResource{
+		{address: "cloudflare_dns_record.www", id: "1", attributes: map[string]interface{}{"content": "192.0.2.1", "proxied": true}},
+		{address: "cloudflare_dns_record.old", id: "2", attributes: map[string]interface{}{"content": "192.0.2.2"}},
+		{address: "cloudflare_zone_setting.ssl", attributes: map[string]interface{}{"value": "full"}},
+	}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC)
+	current := newWatchSnapshot([]diffResource{
+		{address: "cloudflare_dns_record.www", id: "1", attributes: map[string]interface{}{"content": "192.0.2.10", "proxied": true}},
+		{address: "cloudflare_dns_record.new", id: "3", attributes: map[string]interface{}{"content": "192.0.2.3"}},
+		{address: "cloudflare_zone_setting.ssl", attributes: map[string]interface{}{"value": "full"}},
+	}, now)
+
+	assert.Equal(t, []driftEvent{
+		{Time: now, Event: driftEventAdded, Address: "cloudflare_dns_record.new", ResourceType: "cloudflare_dns_record", ID: "3", ZoneID: zoneID},
+		{Time: now, Event: driftEventRemoved, Address: "cloudflare_dns_record.old", ResourceType: "cloudflare_dns_record", ID: "2", ZoneID: zoneID},
+		{Time: now, Event: driftEventChanged, Address: "cloudflare_dns_record.www", ResourceType: "cloudflare_dns_record", ID: "1", ZoneID: zoneID, Attributes: []string{"content"}},
+	}, driftEvents(previous, current))
+	assert.Empty(t, driftEvents(current, current))
+
+	var out bytes.Buffer
+	require.NoError(t, writeDriftEvents(&out, driftEvents(previous, current)[:1]))
+	assert.Equal(t, `{"time":"2025-01-01T06:00:00Z","event":"added","address":"cloudflare_dns_record.new","resource_type":"cloudflare_dns_record","id":"3","zone_id":"0da42c8d2132a9ddaf714f9e7c920711"}`+"\n", out.String())
+}
+
+func TestWatchSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	snapshot, err := loadWatchSnapshot(path)
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	saved := newWatchSnapshot([]diffResource{
+		{address: "cloudflare_dns_record.www", id: "1", attributes: map[string]interface{}{"ttl": float64(1)}},
+	}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, saveWatchSnapshot(path, saved))
+
+	snapshot, err = loadWatchSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved, snapshot)
+}
+
+// cancelTransport cancels the run once it has sent a number of requests.
+type cancelTransport struct {
+	remaining int
+	cancel    context.CancelFunc
+}
+
+func (t *cancelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.remaining--; t.remaining == 0 {
+		t.cancel()
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRunWatchPerRunState(t *testing.T) {
+	args := fakeGenerateArgs(t, "cloudflare_zone_setting", map[string]string{
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/settings":             `[{"id":"cache_level","value":"aggressive","editable":true,"modified_on":"2025-02-13T10:51:23.426493Z"}]`,
+		"/zones/0da42c8d2132a9ddaf714f9e7c920711/settings/cache_level": `{"id":"cache_level","value":"aggressive","editable":true,"modified_on":"2025-02-13T10:51:23.426493Z"}`,
+	})
+	args = append([]string{"watch", "--zone", "0da42c8d2132a9ddaf714f9e7c920711", "--interval", "1ms"}, args...)
+
+	// Each run lists the settings and fetches the one discovered, so the
+	// watch stops after its third run.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	transport := &cancelTransport{remaining: 6, cancel: cancel}
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, Run(ctx, RunConfig{Args: args, Stdout: &stdout, Stderr: &stderr, Transport: transport}))
+
+	// The settings discovered by a run don't carry over to the next so the
+	// runs find the same resources.
+	assert.Equal(t, 2, strings.Count(stderr.String(), "no drift found"))
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// automaticTTL is the TTL that records with an automatic TTL are written with
+// as BIND has no equivalent.
+const automaticTTL = 300
+
+// zoneFilePath is where DNS records are written as a BIND zone file.
+var zoneFilePath string
+
+// zoneFileTargetTypes are the record types whose content is a hostname so
+// needs to be fully qualified.
+var zoneFileTargetTypes = map[string]bool{
+	"CNAME": true,
+	"DNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"PTR":   true,
+}
+
+// zoneFilePriorityTypes are the record types where the priority is returned
+// separately from the content.
+var zoneFilePriorityTypes = map[string]bool{
+	"MX":  true,
+	"SRV": true,
+	"URI": true,
+}
+
+// buildZoneFile formats DNS records from the API as a BIND zone file. Proxied
+// records are tagged in the same way as the zone file export in the dashboard.
+func buildZoneFile(records []interface{}) string {
+	lines := make([]string, 0, len(records))
+	for _, item := range records {
+		record, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := record["name"].(string)
+		recordType, _ := record["type"].(string)
+		content, _ := record["content"].(string)
+
+		ttl := automaticTTL
+		if value, ok := record["ttl"].(float64); ok && value > 1 {
+			ttl = int(value)
+		}
+
+		switch {
+		case recordType == "TXT" && !strings.HasPrefix(content, `"`):
+			content = strconv.Quote(content)
+		case zoneFileTargetTypes[recordType]:
+			content = fullyQualified(content)
+		case recordType == "SRV":
+			// The target follows the weight and port.
+			if fields := strings.Fields(content); len(fields) == 3 {
+				fields[2] = fullyQualified(fields[2])
+				content = strings.Join(fields, " ")
+			}
+		}
+		if priority, ok := record["priority"].(float64); ok && zoneFilePriorityTypes[recordType] {
+			content = fmt.Sprintf("%d %s", int(priority), content)
+		}
+
+		line := fmt.Sprintf("%s\t%d\tIN\t%s\t%s", fullyQualified(name), ttl, recordType, content)
+		if proxied, _ := record["proxied"].(bool); proxied {
+			line += " ; cf_tags=cf-proxied:true"
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+
+	return ";; Exported by cf-terraforming\n\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// writeZoneFile writes DNS records from the API to the --zone-file path.
+func writeZoneFile(records []interface{}) error {
+	if dir := filepath.Dir(zoneFilePath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", zoneFilePath, err)
+		}
+	}
+
+	if err := os.WriteFile(zoneFilePath, []byte(buildZoneFile(records)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", zoneFilePath, err)
+	}
+	log.Debugf("wrote %s", zoneFilePath)
+
+	return nil
+}
+
+func fullyQualified(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
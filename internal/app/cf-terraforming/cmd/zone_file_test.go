@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildZoneFile(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"name": "www.example.com", "type": "A", "content": "198.51.100.4", "ttl": float64(1), "proxied": true},
+		map[string]interface{}{"name": "example.com", "type": "MX", "content": "mail.example.com", "priority": float64(10), "ttl": float64(3600)},
+		map[string]interface{}{"name": "example.com", "type": "TXT", "content": "v=spf1 -all", "ttl": float64(300)},
+		map[string]interface{}{"name": "_sip._tcp.example.com", "type": "SRV", "content": "5 5060 sip.example.com", "priority": float64(1), "ttl": float64(300)},
+		map[string]interface{}{"name": "blog.example.com", "type": "CNAME", "content": "example.com", "ttl": float64(1), "proxied": false},
+	}
+
+	expected := heredoc.Doc(`
+		;; Exported by cf-terraforming
+
+		_sip._tcp.example.com.	300	IN	SRV	1 5 5060 sip.example.com.
+		blog.example.com.	300	IN	CNAME	example.com.
+		example.com.	300	IN	TXT	"v=spf1 -all"
+		example.com.	3600	IN	MX	10 mail.example.com.
+		www.example.com.	300	IN	A	198.51.100.4 ; cf_tags=cf-proxied:true
+	`)
+	assert.Equal(t, expected, buildZoneFile(records))
+}
@@ -0,0 +1,78 @@
+package terraforming
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Converter fetches the resources of a type which isn't supported yet, or
+// replaces how a supported type is fetched. The resources are then generated
+// from the provider schema like any other.
+//
+// Converters are registered with RegisterConverter or loaded from Go plugins
+// with --converter-plugin, which export them in a variable named Converters
+// of type map[string]Converter.
+type Converter struct {
+	// Fetch returns the resources in the account or zone, only one of which
+	// is set, with their attributes named as in the provider schema.
+	Fetch func(ctx context.Context, client *cloudflare.Client, accountID, zoneID string) ([]map[string]interface{}, error)
+
+	// PostProcess, if set, modifies the generated configuration.
+	PostProcess func(f *hclwrite.File)
+
+	// ImportID, if set, is the format of the import ID such as
+	// ":account_id/:id", using the same placeholders as the built-in formats.
+	ImportID string
+}
+
+var (
+	convertersMu sync.RWMutex
+	converters   = make(map[string]Converter)
+)
+
+// RegisterConverter registers the converter for the resource type, replacing
+// any registered before. It's typically called from an init function.
+func RegisterConverter(resourceType string, converter Converter) {
+	if converter.Fetch == nil {
+		panic(fmt.Sprintf("converter for %s has no Fetch function", resourceType))
+	}
+
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[resourceType] = converter
+}
+
+// UnregisterConverter removes the converter registered for the resource type,
+// if any.
+func UnregisterConverter(resourceType string) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	delete(converters, resourceType)
+}
+
+// LookupConverter returns the converter registered for the resource type.
+func LookupConverter(resourceType string) (Converter, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	converter, ok := converters[resourceType]
+	return converter, ok
+}
+
+// Resources returns the resources from Fetch in the form the API responses
+// are generated from.
+func (c Converter) Resources(ctx context.Context, client *cloudflare.Client, scope Scope) ([]interface{}, error) {
+	resources, err := c.Fetch(ctx, client, scope.AccountID, scope.ZoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]interface{}, len(resources))
+	for i, resource := range resources {
+		data[i] = resource
+	}
+	return data, nil
+}
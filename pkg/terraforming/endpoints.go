@@ -0,0 +1,55 @@
+package terraforming
+
+import (
+	"sort"
+	"strings"
+)
+
+// Endpoints returns the v5 API endpoints listing the resources of the type
+// and getting a single one, with their path parameters left in place. Either
+// is empty where the API doesn't have one.
+func Endpoints(resourceType string) (list, get string) {
+	return resourceToEndpoint[resourceType]["list"], resourceToEndpoint[resourceType]["get"]
+}
+
+// SupportedResourceTypes returns the resource types with v5 API endpoints in
+// order.
+func SupportedResourceTypes() []string {
+	resourceTypes := make([]string, 0, len(resourceToEndpoint))
+	for resourceType := range resourceToEndpoint {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	return resourceTypes
+}
+
+// ResourceEndpoint returns the v5 API endpoint listing the resource type for
+// the account or zone, or an empty string if it's unsupported. Path
+// parameters for parent resources are left in place.
+func ResourceEndpoint(resourceType string, scope Scope) string {
+	// by default, we want to use the `list` operation however, there are times
+	// when resources exist only as `get` operations but contain multiple
+	// resources.
+	endpoint, get := Endpoints(resourceType)
+	if endpoint == "" {
+		endpoint = get
+	}
+	if endpoint == "" {
+		return ""
+	}
+
+	// if we encounter a combined endpoint, we need to rewrite to use the correct
+	// endpoint depending on what parameters are being provided.
+	if strings.Contains(endpoint, "{accounts_or_zones}") {
+		if scope.AccountID != "" {
+			endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/accounts/{account_id}/", 1)
+		} else {
+			endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/zones/{zone_id}/", 1)
+		}
+	}
+
+	// replace the URL placeholders with the actual values we have.
+	placeholderReplacer := strings.NewReplacer("{account_id}", scope.AccountID, "{zone_id}", scope.ZoneID)
+	return placeholderReplacer.Replace(endpoint)
+}
@@ -0,0 +1,323 @@
+package terraforming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FetchOptions configure how resources are fetched from the API.
+type FetchOptions struct {
+	Scope Scope
+
+	// Parallelism is how many pages of an endpoint are fetched at once.
+	// Defaults to one at a time.
+	Parallelism int
+
+	// MaxListItems caps the items fetched for each list so that lists with
+	// many thousands of entries, such as IP lists, stay manageable. Disabled
+	// when 0.
+	MaxListItems int
+
+	// OnPage, if set, is called once each page has been fetched along with
+	// the total number of pages, which is 0 when using cursor pagination.
+	OnPage func(resourceType string, page, totalPages int)
+
+	// Tracer creates a span for each page. Defaults to the global tracer
+	// provider.
+	Tracer trace.Tracer
+
+	// Logger receives the debug logs and warnings. Defaults to discarding
+	// them.
+	Logger logrus.FieldLogger
+}
+
+func (o FetchOptions) logger() logrus.FieldLogger {
+	if o.Logger == nil {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+		return logger
+	}
+
+	return o.Logger
+}
+
+func (o FetchOptions) tracer() trace.Tracer {
+	if o.Tracer == nil {
+		return otel.Tracer("github.com/cloudflare/cf-terraforming")
+	}
+
+	return o.Tracer
+}
+
+// limit returns the most resources of the type fetched from each endpoint, or
+// 0 when they're all fetched.
+func (o FetchOptions) limit(resourceType string) int {
+	if resourceType == "cloudflare_list_item" {
+		return o.MaxListItems
+	}
+
+	return 0
+}
+
+// ErrNoResult is returned when an API response doesn't contain a result.
+var ErrNoResult = errors.New("no result found")
+
+// IsNoResourcesError reports whether the error means there are no resources,
+// rather than that they couldn't be fetched.
+func IsNoResourcesError(err error) bool {
+	var apierr *cloudflare.Error
+	return errors.Is(err, ErrNoResult) || (errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound)
+}
+
+// ConversionError is an API response that couldn't be converted to match the
+// provider schema.
+type ConversionError struct {
+	// Endpoint is the request the response came from, such as "GET
+	// /zones/023e105f4ecef8ad9ca31a8372d0c353/rulesets".
+	Endpoint string
+	Err      error
+}
+
+func (e *ConversionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ConvertResource calls convert, returning a panic as an error so that a
+// resource with an unexpected API response doesn't stop the others from being
+// generated.
+func ConvertResource(convert func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to convert the API response: %v", r)
+		}
+	}()
+	convert()
+
+	return nil
+}
+
+// Fetch returns the resources of the type from each of the endpoints, along
+// with every page of them, reshaped to match the provider schema. pathParams
+// are the IDs of the parent resources each endpoint lists the resources of,
+// if any.
+func Fetch(ctx context.Context, client *cloudflare.Client, resourceType string, endpoints, pathParams []string, opts FetchOptions) ([]interface{}, error) {
+	var allResults []interface{}
+
+	for i, baseEndpoint := range endpoints {
+		param := ""
+		if len(pathParams) > 0 {
+			param = pathParams[i]
+		}
+
+		// no page param for first request
+		jsonStructData, info, err := fetchPage(ctx, client, resourceType, baseEndpoint, param, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.pageFetched(resourceType, 1, info.totalPages)
+
+		limit := opts.limit(resourceType)
+		// Pages using cursor pagination can only be fetched one after another,
+		// stopping once the limit is reached.
+		for page := 2; info.cursor != "" && (limit <= 0 || len(jsonStructData) < limit); page++ {
+			var data []interface{}
+			data, info, err = fetchPage(ctx, client, resourceType, withQueryParam(baseEndpoint, "cursor", info.cursor), param, opts)
+			if err != nil {
+				return nil, err
+			}
+			opts.pageFetched(resourceType, page, info.totalPages)
+			jsonStructData = append(jsonStructData, data...)
+		}
+
+		// The remaining pages are fetched concurrently once the total is known,
+		// up to those needed for the limit.
+		totalPages := info.totalPages
+		if limit > 0 && info.cursor == "" && len(jsonStructData) > 0 {
+			totalPages = min(totalPages, (limit+len(jsonStructData)-1)/len(jsonStructData))
+		}
+		pages, err := fetchPages(totalPages-1, opts.Parallelism, func(i int) ([]interface{}, error) {
+			data, _, err := fetchPage(ctx, client, resourceType, withQueryParam(baseEndpoint, "page", strconv.Itoa(i+2)), param, opts)
+			if err == nil {
+				opts.pageFetched(resourceType, i+2, info.totalPages)
+			}
+			return data, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			jsonStructData = append(jsonStructData, page...)
+		}
+
+		allResults = append(allResults, opts.limitResources(resourceType, baseEndpoint, jsonStructData, limit, info.cursor != "" || totalPages < info.totalPages)...)
+	}
+	return allResults, nil
+}
+
+func (o FetchOptions) pageFetched(resourceType string, page, totalPages int) {
+	if o.OnPage != nil {
+		o.OnPage(resourceType, page, totalPages)
+	}
+}
+
+// limitResources returns the first limit resources fetched from the endpoint,
+// warning when any are left out, including when there are more pages which
+// weren't fetched.
+func (o FetchOptions) limitResources(resourceType, endpoint string, data []interface{}, limit int, morePages bool) []interface{} {
+	if limit <= 0 || (len(data) <= limit && !morePages) {
+		return data
+	}
+
+	o.logger().WithFields(logrus.Fields{
+		"resource": resourceType,
+		"endpoint": endpoint,
+		"limit":    limit,
+	}).Warn("more resources than the maximum items per list, only the first are generated")
+
+	return data[:min(len(data), limit)]
+}
+
+// pageInfo is how the rest of a paginated response is fetched: by page number
+// up to totalPages or, for endpoints using cursor pagination, with the cursor
+// of the next page, which is empty on the last page. totalPages is 0 when
+// using cursor pagination.
+type pageInfo struct {
+	totalPages int
+	cursor     string
+}
+
+// withQueryParam adds the query parameter to the endpoint.
+func withQueryParam(endpoint, key, value string) string {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+
+	return endpoint + sep + key + "=" + url.QueryEscape(value)
+}
+
+// fetchPage fetches a single page of resources along with the total number
+// of pages.
+func fetchPage(ctx context.Context, client *cloudflare.Client, resourceType, endpoint, param string, opts FetchOptions) ([]interface{}, pageInfo, error) {
+	var result *http.Response
+	ctx, span := opts.tracer().Start(ctx, "fetch page", trace.WithAttributes(
+		attribute.String("cloudflare.resource_type", resourceType),
+		attribute.String("cloudflare.endpoint", endpoint),
+	))
+	start := time.Now()
+	err := client.Get(ctx, endpoint, nil, &result)
+	if err != nil && !IsNoResourcesError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	opts.logger().WithFields(logrus.Fields{
+		"resource": resourceType,
+		"endpoint": endpoint,
+		"duration": time.Since(start),
+	}).Debug("fetched API endpoint")
+	if err != nil {
+		var apierr *cloudflare.Error
+		if errors.As(err, &apierr) && apierr.StatusCode == http.StatusNotFound {
+			opts.logger().WithFields(logrus.Fields{
+				"resource": resourceType,
+				"endpoint": endpoint,
+			}).Debug("no resources found")
+			return nil, pageInfo{}, err
+		}
+		return nil, pageInfo{}, fmt.Errorf("failed to fetch API endpoint: %w", err)
+	}
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, pageInfo{}, fmt.Errorf("failed to read API response: %w", err)
+	}
+
+	resultVal := gjson.Get(string(body), "result")
+	if resultVal.Type == gjson.Null {
+		opts.logger().WithFields(logrus.Fields{
+			"resource": resourceType,
+			"endpoint": endpoint,
+		}).Debug("no result found")
+		return nil, pageInfo{}, ErrNoResult
+	}
+
+	modifiedJSON := modifyResponsePayload(resourceType, resultVal)
+	jsonStructData, err := unMarshallJSONStructData(modifiedJSON)
+	if err != nil {
+		return nil, pageInfo{}, fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+
+	if jsonStructData, err = ProcessResponse(ctx, client, resourceType, param, jsonStructData, opts); err != nil {
+		return nil, pageInfo{}, &ConversionError{Endpoint: "GET " + endpoint, Err: err}
+	}
+
+	info := pageInfo{totalPages: 1, cursor: gjson.Get(string(body), "result_info.cursors.after").String()}
+	if totalPagesVal := gjson.Get(string(body), "result_info.total_pages"); totalPagesVal.Exists() {
+		info.totalPages = int(totalPagesVal.Int())
+	} else if gjson.Get(string(body), "result_info.cursors").Exists() {
+		// The total isn't known when using cursor pagination.
+		info.totalPages = 0
+	}
+
+	return jsonStructData, info, nil
+}
+
+// fetchPages calls fetch for each of the pages using up to parallelism
+// workers and returns the results in page order. The first error by page is
+// returned.
+func fetchPages(pages, parallelism int, fetch func(page int) ([]interface{}, error)) ([][]interface{}, error) {
+	if pages <= 0 {
+		return nil, nil
+	}
+
+	var (
+		wg      sync.WaitGroup
+		queue   = make(chan int)
+		results = make([][]interface{}, pages)
+		errs    = make([]error, pages)
+	)
+	for range max(1, min(parallelism, pages)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range queue {
+				results[page], errs[page] = fetch(page)
+			}
+		}()
+	}
+
+	for page := range pages {
+		queue <- page
+	}
+	close(queue)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
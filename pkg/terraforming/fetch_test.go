@@ -0,0 +1,65 @@
+package terraforming
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"success":true,"result":[{"id":"record-%s"}],"result_info":{"page":%s,"total_pages":3}}`, page, page)
+	}))
+	defer server.Close()
+
+	client := cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"))
+	var fetched []int
+	data, err := Fetch(context.Background(), client, "cloudflare_example", []string{"/zones/0da42c8d2132a9ddaf714f9e7c920711/example"}, nil, FetchOptions{
+		OnPage: func(_ string, page, _ int) { fetched = append(fetched, page) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "record-1"},
+		map[string]interface{}{"id": "record-2"},
+		map[string]interface{}{"id": "record-3"},
+	}, data)
+	assert.Equal(t, []int{1, 2, 3}, fetched)
+}
+
+func TestFetchPages(t *testing.T) {
+	pages, err := fetchPages(6, 4, func(page int) ([]interface{}, error) {
+		// Later pages finish first to check the order is kept.
+		time.Sleep(time.Duration(6-page) * 5 * time.Millisecond)
+		return []interface{}{page}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{0}, {1}, {2}, {3}, {4}, {5}}, pages)
+
+	_, err = fetchPages(3, 4, func(page int) ([]interface{}, error) {
+		return nil, fmt.Errorf("page %d failed", page)
+	})
+	assert.EqualError(t, err, "page 0 failed")
+
+	pages, err = fetchPages(0, 4, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, pages)
+}
+
+func TestIsNoResourcesError(t *testing.T) {
+	assert.True(t, IsNoResourcesError(ErrNoResult))
+	assert.True(t, IsNoResourcesError(&cloudflare.Error{StatusCode: http.StatusNotFound}))
+	assert.False(t, IsNoResourcesError(fmt.Errorf("failed to fetch API endpoint: %w", &cloudflare.Error{StatusCode: http.StatusForbidden})))
+}
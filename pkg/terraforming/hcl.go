@@ -0,0 +1,357 @@
+package terraforming
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// HCLOptions configure how resources are written as HCL.
+type HCLOptions struct {
+	// Scope is the account or zone the resources belong to, which is written
+	// for their account_id or zone_id.
+	Scope Scope
+
+	// ScopeVariables writes var.account_id or var.zone_id rather than the ID
+	// of the account or zone.
+	ScopeVariables bool
+
+	// JSONEncode writes strings containing JSON objects or arrays using
+	// `jsonencode` so they can be read and modified as HCL.
+	JSONEncode bool
+
+	// HeredocThreshold is the length at which multi-line strings are written
+	// as a heredoc rather than a single escaped line. Zero disables heredocs.
+	HeredocThreshold int
+
+	// NestedSyntax overrides whether the nested objects at a path, such as
+	// `cloudflare_load_balancer.rules`, are written as blocks or attributes,
+	// using NestedSyntaxBlock or NestedSyntaxAttribute. This is for v4
+	// resources where the schema doesn't match the syntax the provider
+	// accepts.
+	NestedSyntax map[string]string
+
+	// Logger receives the debug logs and warnings. Defaults to discarding
+	// them.
+	Logger logrus.FieldLogger
+}
+
+func (o HCLOptions) logger() logrus.FieldLogger {
+	if o.Logger == nil {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+		return logger
+	}
+
+	return o.Logger
+}
+
+// WriteResource writes the attributes and blocks of a resource from its API
+// response into the body of its resource block, following the provider
+// schema. Computed attributes which can't be set are left out. The top-level
+// attributes are removed from data as they're written.
+func WriteResource(body *hclwrite.Body, resourceType string, schema *tfjson.SchemaBlock, data map[string]interface{}, opts HCLOptions) {
+	sortedBlockAttributes := make([]string, 0, len(schema.Attributes))
+	for k := range schema.Attributes {
+		sortedBlockAttributes = append(sortedBlockAttributes, k)
+	}
+	sort.Strings(sortedBlockAttributes)
+
+	// Block attributes are for any attributes where assignment is involved.
+	for _, attrName := range sortedBlockAttributes {
+		// Don't bother outputting the ID for the resource as that is only for
+		// internal use (such as importing state).
+		if attrName == "id" {
+			continue
+		}
+
+		// No need to output computed attributes that are also not
+		// optional.
+		if schema.Attributes[attrName].Computed && !schema.Attributes[attrName].Optional {
+			continue
+		}
+		if attrName == "account_id" && opts.Scope.AccountID != "" {
+			if opts.ScopeVariables {
+				WriteAttribute(body, attrName, Variable("account_id"), opts)
+			} else {
+				WriteAttribute(body, attrName, opts.Scope.AccountID, opts)
+			}
+			continue
+		}
+
+		if attrName == "zone_id" && opts.Scope.ZoneID != "" && opts.Scope.AccountID == "" {
+			if opts.ScopeVariables {
+				WriteAttribute(body, attrName, Variable("zone_id"), opts)
+			} else {
+				WriteAttribute(body, attrName, opts.Scope.ZoneID, opts)
+			}
+			continue
+		}
+
+		if opts.NestedSyntax[resourceType+"."+attrName] == NestedSyntaxBlock {
+			appendAttributeBlocks(body, attrName, data[attrName], opts)
+			delete(data, attrName)
+			continue
+		}
+
+		ty := schema.Attributes[attrName].AttributeType
+		switch {
+		case ty.IsPrimitiveType():
+			switch ty {
+			case cty.String, cty.Bool, cty.Number:
+				WriteAttribute(body, attrName, data[attrName], opts)
+				delete(data, attrName)
+			default:
+				opts.logger().Debugf("unexpected primitive type %q", ty.FriendlyName())
+			}
+		case ty.IsCollectionType():
+			switch {
+			case ty.IsListType(), ty.IsSetType(), ty.IsMapType():
+				WriteAttribute(body, attrName, data[attrName], opts)
+				delete(data, attrName)
+			default:
+				opts.logger().Debugf("unexpected collection type %q", ty.FriendlyName())
+			}
+		default:
+			opts.logger().Debugf("attribute %q has not been generated", attrName)
+		}
+	}
+
+	processBlocks(schema, data, body, "", resourceType, opts)
+}
+
+func processBlocks(schemaBlock *tfjson.SchemaBlock, structData map[string]interface{}, parent *hclwrite.Body, parentBlock, path string, opts HCLOptions) {
+	keys := make([]string, 0, len(structData))
+	for k := range structData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, block := range keys {
+		if _, ok := schemaBlock.NestedBlocks[block]; ok {
+			if schemaBlock.NestedBlocks[block].NestingMode == "list" || schemaBlock.NestedBlocks[block].NestingMode == "set" {
+				// Blocks overridden to be written as an attribute are built in a
+				// separate body before being converted.
+				target := parent
+				if opts.NestedSyntax[path+"."+block] == NestedSyntaxAttribute {
+					target = hclwrite.NewEmptyFile().Body()
+				}
+
+				child := hclwrite.NewBlock(block, []string{})
+				switch s := structData[block].(type) {
+				case []map[string]interface{}:
+					for _, nestedItem := range s {
+						stepChild := hclwrite.NewBlock(block, []string{})
+						processBlocks(schemaBlock.NestedBlocks[block].Block, nestedItem, stepChild.Body(), block, path+"."+block, opts)
+						if len(stepChild.Body().Attributes()) != 0 || len(stepChild.Body().Blocks()) != 0 {
+							target.AppendBlock(stepChild)
+						}
+					}
+				case map[string]interface{}:
+					processBlocks(schemaBlock.NestedBlocks[block].Block, s, child.Body(), block, path+"."+block, opts)
+				case []interface{}:
+					for _, nestedItem := range s {
+						stepChild := hclwrite.NewBlock(block, []string{})
+						processBlocks(schemaBlock.NestedBlocks[block].Block, nestedItem.(map[string]interface{}), stepChild.Body(), block, path+"."+block, opts)
+						if len(stepChild.Body().Attributes()) != 0 || len(stepChild.Body().Blocks()) != 0 {
+							target.AppendBlock(stepChild)
+						}
+					}
+				default:
+					opts.logger().Debugf("unable to generate recursively nested blocks for %T", s)
+				}
+				if len(child.Body().Attributes()) != 0 || len(child.Body().Blocks()) != 0 {
+					target.AppendBlock(child)
+				}
+
+				if target != parent {
+					_, isMap := structData[block].(map[string]interface{})
+					setBlocksAsAttribute(parent, block, target.Blocks(), !isMap)
+				}
+			}
+		} else {
+			if parentBlock == "" && block == "id" {
+				continue
+			}
+			if _, ok := schemaBlock.Attributes[block]; ok && (schemaBlock.Attributes[block].Optional || schemaBlock.Attributes[block].Required) {
+				if opts.NestedSyntax[path+"."+block] == NestedSyntaxBlock {
+					appendAttributeBlocks(parent, block, structData[block], opts)
+					continue
+				}
+				writeAttrLine(block, structData[block], parentBlock, parent, opts)
+			}
+		}
+	}
+}
+
+// WriteAttribute sets the attribute of the body to the value, which may
+// contain Variable references. Null values aren't written.
+func WriteAttribute(body *hclwrite.Body, name string, value interface{}, opts HCLOptions) {
+	writeAttrLine(name, value, "", body, opts)
+}
+
+// writeAttrLine outputs a line of HCL configuration with a configurable depth
+// for known types.
+func writeAttrLine(key string, value interface{}, parentName string, body *hclwrite.Body, opts HCLOptions) {
+	if body == nil || value == nil {
+		opts.logger().Debug("body or value is nil")
+		return
+	}
+
+	if containsVariableReference(value) {
+		body.SetAttributeRaw(key, tokensForValue(value))
+		return
+	}
+
+	switch values := value.(type) {
+	case []map[string]interface{}:
+		// Use tuple approach for heterogeneous maps
+		var tupleValues []cty.Value
+		for _, item := range values {
+			mapCty := make(map[string]cty.Value)
+			for k, v := range item {
+				mapCty[k] = processExpression(v)
+			}
+			tupleValues = append(tupleValues, cty.ObjectVal(mapCty))
+		}
+		body.SetAttributeValue(key, cty.TupleVal(tupleValues))
+	case map[string]interface{}:
+		ctyMap := make(map[string]cty.Value)
+
+		// Sort keys for consistent output
+		sortedKeys := make([]string, 0, len(values))
+		for k := range values {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, k := range sortedKeys {
+			ctyMap[k] = processExpression(values[k])
+		}
+		body.SetAttributeValue(key, cty.ObjectVal(ctyMap))
+	case []interface{}:
+		if len(values) == 0 {
+			body.SetAttributeValue(key, cty.EmptyTupleVal)
+			return
+		}
+
+		// Convert all slice elements using processExpression for consistency
+		var tupleValues []cty.Value
+		for _, item := range values {
+			tupleValues = append(tupleValues, processExpression(item))
+		}
+		body.SetAttributeValue(key, cty.TupleVal(tupleValues))
+	case []int:
+		var vals []cty.Value
+		for _, i := range values {
+			vals = append(vals, cty.NumberIntVal(int64(i)))
+		}
+		body.SetAttributeValue(key, cty.TupleVal(vals))
+	case []string:
+		if len(values) > 0 {
+			var vals []cty.Value
+			for _, item := range values {
+				vals = append(vals, cty.StringVal(item))
+			}
+			body.SetAttributeValue(key, cty.TupleVal(vals))
+		} else {
+			body.SetAttributeValue(key, cty.EmptyTupleVal)
+		}
+	case string:
+		if parentName == "query" && key == "value" && value == "" {
+			body.SetAttributeValue(key, cty.StringVal(""))
+		}
+		var jsonTokens hclwrite.Tokens
+		if opts.JSONEncode {
+			jsonTokens, _ = tokensForJSONEncode(values)
+		}
+
+		if jsonTokens != nil {
+			body.SetAttributeRaw(key, jsonTokens)
+		} else if opts.useHeredoc(values) {
+			body.SetAttributeRaw(key, tokensForStringHeredoc(values))
+		} else if value != "" {
+			body.SetAttributeValue(key, cty.StringVal(values))
+		}
+	case int:
+		body.SetAttributeValue(key, cty.NumberIntVal(int64(values)))
+	case float64:
+		body.SetAttributeValue(key, cty.NumberFloatVal(values))
+	case bool:
+		body.SetAttributeValue(key, cty.BoolVal(values))
+	default:
+		opts.logger().Warnf("unknown attribute type: key %s, value %v, value type %T", key, value, value)
+		// Convert unknown types to string representation
+		body.SetAttributeValue(key, cty.StringVal(fmt.Sprintf("%v", value)))
+	}
+}
+
+// Process any expression into its appropriate cty.Value and also modified to use TupleVal consistently.
+func processExpression(val interface{}) cty.Value {
+	if val == nil {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+
+	switch v := val.(type) {
+	case string:
+		return cty.StringVal(v)
+	case int:
+		return cty.NumberIntVal(int64(v))
+	case float64:
+		return cty.NumberFloatVal(v)
+	case bool:
+		return cty.BoolVal(v)
+	case []string:
+		var vals []cty.Value
+		for _, s := range v {
+			vals = append(vals, cty.StringVal(s))
+		}
+		return cty.TupleVal(vals)
+	case []int:
+		var vals []cty.Value
+		for _, i := range v {
+			vals = append(vals, cty.NumberIntVal(int64(i)))
+		}
+		return cty.TupleVal(vals)
+	case []interface{}:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal
+		}
+
+		var vals []cty.Value
+		for _, item := range v {
+			vals = append(vals, processExpression(item))
+		}
+		return cty.TupleVal(vals)
+	case map[string]interface{}:
+		ctyMap := make(map[string]cty.Value)
+		// Sort keys for consistent output
+		sortedKeys := make([]string, 0, len(v))
+		for k := range v {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+
+		for _, k := range sortedKeys {
+			ctyMap[k] = processExpression(v[k])
+		}
+		return cty.ObjectVal(ctyMap)
+	case []map[string]interface{}:
+		var vals []cty.Value
+		for _, m := range v {
+			// Convert map to object
+			objMap := make(map[string]cty.Value)
+			for mk, mv := range m {
+				objMap[mk] = processExpression(mv)
+			}
+			vals = append(vals, cty.ObjectVal(objMap))
+		}
+		return cty.TupleVal(vals)
+	default:
+		return cty.StringVal(fmt.Sprintf("%v", val))
+	}
+}
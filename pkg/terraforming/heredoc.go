@@ -0,0 +1,55 @@
+package terraforming
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// heredocTemplateSequences are escaped so that the heredoc isn't treated as a
+// template.
+var heredocTemplateSequences = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// useHeredoc reports whether the string should be written as a heredoc.
+func (o HCLOptions) useHeredoc(value string) bool {
+	return o.HeredocThreshold > 0 && len(value) >= o.HeredocThreshold && strings.Contains(value, "\n")
+}
+
+// tokensForStringHeredoc builds the tokens for a heredoc with the same value
+// as the string. Heredocs always end in a newline so strings without a
+// trailing newline are wrapped in `chomp`.
+func tokensForStringHeredoc(value string) hclwrite.Tokens {
+	delimiter := "EOT"
+	for i := 2; heredocContainsLine(value, delimiter); i++ {
+		delimiter = "EOT" + strings.Repeat("_", i-1)
+	}
+
+	content := heredocTemplateSequences.Replace(value)
+	trailingNewline := strings.HasSuffix(content, "\n")
+	if !trailingNewline {
+		content += "\n"
+	}
+
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<" + delimiter + "\n")},
+		{Type: hclsyntax.TokenStringLit, Bytes: []byte(content)},
+		{Type: hclsyntax.TokenCHeredoc, Bytes: []byte(delimiter)},
+	}
+	if trailingNewline {
+		return tokens
+	}
+
+	// The closing delimiter must be on a line of its own.
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	return hclwrite.TokensForFunctionCall("chomp", tokens)
+}
+
+func heredocContainsLine(value, line string) bool {
+	for _, l := range strings.Split(value, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
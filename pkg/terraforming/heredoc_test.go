@@ -0,0 +1,81 @@
+package terraforming
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestWriteAttributeHeredoc(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  string
+	}{
+		"short": {
+			value: "a\nb",
+			want:  "content = \"a\\nb\"\n",
+		},
+		"single line": {
+			value: "export default { fetch() {} }",
+			want:  "content = \"export default { fetch() {} }\"\n",
+		},
+		"trailing newline": {
+			value: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+			want: heredoc.Doc(`
+				content = <<EOT
+				-----BEGIN CERTIFICATE-----
+				MIIB
+				-----END CERTIFICATE-----
+				EOT
+			`),
+		},
+		"no trailing newline": {
+			value: "<html>\n  <p>${name}</p>\n</html>",
+			want: heredoc.Doc(`
+				content = chomp(<<EOT
+				<html>
+				  <p>$${name}</p>
+				</html>
+				EOT
+				)
+			`),
+		},
+		"contains delimiter": {
+			value: "cat <<EOT\nhello\nEOT\n",
+			want: heredoc.Doc(`
+				content = <<EOT_
+				cat <<EOT
+				hello
+				EOT
+				EOT_
+			`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := hclwrite.NewEmptyFile()
+			WriteAttribute(f.Body(), "content", tc.value, HCLOptions{HeredocThreshold: 20})
+			assert.Equal(t, tc.want, string(hclwrite.Format(f.Bytes())))
+
+			// The heredoc must evaluate to the original string.
+			parsed, diags := hclsyntax.ParseConfig(f.Bytes(), "main.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+			attrs, _ := parsed.Body.JustAttributes()
+			value, diags := attrs["content"].Expr.Value(evalContextWithChomp())
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, tc.value, value.AsString())
+		})
+	}
+}
+
+func evalContextWithChomp() *hcl.EvalContext {
+	return &hcl.EvalContext{Functions: map[string]function.Function{"chomp": stdlib.ChompFunc}}
+}
@@ -0,0 +1,146 @@
+package terraforming
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// resourceImportIDFormatsV5 defines the import ID for v5 resources that cannot
+// be derived from the API endpoint, typically because the import ID is made up
+// of the parent resource as well as the resource itself.
+//
+// Each `:` prefixed segment is replaced with the account or zone ID
+// (`:account_id`, `:zone_id`), the resource ID (`:id`) or otherwise the
+// attribute of the same name from the API response.
+var resourceImportIDFormatsV5 = map[string]string{
+	"cloudflare_api_shield_operation_schema_validation_settings": ":zone_id/:operation_id",
+	"cloudflare_authenticated_origin_pulls":                      ":zone_id/:hostname",
+	"cloudflare_list_item":                                       ":account_id/:list_id/:id",
+	"cloudflare_pages_domain":                                    ":account_id/:project_name/:name",
+	"cloudflare_queue_consumer":                                  ":account_id/:queue_id/:consumer_id",
+	"cloudflare_r2_custom_domain":                                ":account_id/:bucket_name/:domain",
+	"cloudflare_r2_managed_domain":                               ":account_id/:bucket_name",
+	"cloudflare_waiting_room_event":                              ":zone_id/:waiting_room_id/:id",
+	"cloudflare_waiting_room_rules":                              ":zone_id/:waiting_room_id",
+	"cloudflare_web_analytics_rule":                              ":account_id/:ruleset_id/:id",
+	"cloudflare_workers_cron_trigger":                            ":account_id/:script_name",
+	"cloudflare_workers_script_subdomain":                        ":account_id/:script_name",
+	"cloudflare_zero_trust_dlp_predefined_profile":               ":account_id/:profile_id",
+	"cloudflare_zero_trust_tunnel_cloudflared_config":            ":account_id/:tunnel_id",
+	"cloudflare_zone_setting":                                    ":zone_id/:setting_id",
+}
+
+var endpointPlaceholders = regexp.MustCompile("({[a-z0-9_]*})")
+
+// ImportIDFormat returns the format of the v5 import ID for the resource type
+// where it can't be derived from the API endpoint.
+func ImportIDFormat(resourceType string) (string, bool) {
+	format, ok := resourceImportIDFormatsV5[resourceType]
+	return format, ok
+}
+
+// ImportID returns the v5 import ID for a resource, using the format of its
+// converter or an explicit format where one is defined and otherwise deriving
+// it from the endpoint. It fails if the resource is missing an attribute the
+// format needs, rather than returning an ID that can't be imported.
+func ImportID(resourceType, resourceID string, data map[string]interface{}, scope Scope) (string, error) {
+	format, ok := ImportIDFormat(resourceType)
+	if converter, found := LookupConverter(resourceType); found && converter.ImportID != "" {
+		format, ok = converter.ImportID, true
+	}
+	if !ok {
+		_, endpoint := Endpoints(resourceType)
+		return endpointImportID(endpoint, resourceID, scope), nil
+	}
+
+	id, err := formatImportID(format, resourceID, data, scope)
+	if err != nil {
+		return "", fmt.Errorf("failed to build the import ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// formatImportID replaces the placeholders in an import ID format.
+func formatImportID(format, resourceID string, data map[string]interface{}, scope Scope) (string, error) {
+	segments := strings.Split(format, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+
+		var value string
+		switch name {
+		case "account_id":
+			value = scope.AccountID
+		case "zone_id":
+			value = scope.ZoneID
+		case "id":
+			value = resourceID
+		default:
+			switch v := data[name].(type) {
+			case string:
+				value = v
+			case float64:
+				value = fmt.Sprintf("%d", int(v))
+			}
+		}
+
+		if value == "" {
+			return "", fmt.Errorf("no value found for %q", name)
+		}
+		segments[i] = value
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// endpointImportID builds the import ID from the path parameters of the
+// endpoint getting a single resource.
+func endpointImportID(endpoint, resourceID string, scope Scope) string {
+	prefix := ""
+	if strings.Contains(endpoint, "{accounts_or_zones}") {
+		if scope.AccountID != "" {
+			prefix = "accounts"
+			endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/accounts/{account_id}/", 1)
+		} else {
+			prefix = "zones"
+			endpoint = strings.Replace(endpoint, "/{accounts_or_zones}/{account_or_zone_id}/", "/zones/{zone_id}/", 1)
+		}
+	}
+
+	matches := endpointPlaceholders.FindAllString(endpoint, -1)
+
+	if len(matches) > 0 {
+		// Naive assumptions below but if we only have a single placeholder (`{}`)
+		// we can replace that with the `resourceID` however, if we have more than
+		// a single one, we assume it is the second match since that is our URL
+		// conventions.
+		//
+		// Note: this will likely break on un-RESTful routes.
+		if len(matches) == 1 {
+			matches[0] = resourceID
+		} else {
+			if matches[0] == "{account_id}" {
+				matches[0] = scope.AccountID
+			} else if matches[0] == "{zone_id}" {
+				matches[0] = scope.ZoneID
+			}
+			matches[1] = resourceID
+		}
+	}
+
+	output := strings.Join(matches, "/")
+
+	replacer := strings.NewReplacer(
+		"{account_id}", scope.AccountID,
+		"{zone_id}", scope.ZoneID,
+	)
+
+	if prefix != "" {
+		output = prefix + "/" + output
+	}
+	return replacer.Replace(output)
+}
@@ -0,0 +1,148 @@
+package terraforming
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ConvertToJSONSyntax takes generated HCL native syntax and converts it to the
+// equivalent Terraform JSON configuration syntax.
+//
+// See https://developer.hashicorp.com/terraform/language/syntax/json
+func ConvertToJSONSyntax(src []byte) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, "generated.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse generated configuration: %s", diags.Error())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("unexpected body type %T", file.Body)
+	}
+
+	// Expressions and HTML often contain characters such as `<` and `&` that
+	// shouldn't be escaped when a human needs to read the output.
+	var output bytes.Buffer
+	encoder := json.NewEncoder(&output)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bodyToJSON(body, src, "")); err != nil {
+		return nil, err
+	}
+
+	return output.Bytes(), nil
+}
+
+// bodyToJSON converts the attributes and blocks of a body into a JSON object.
+// Labelled blocks are nested as objects keyed by each label whereas unlabelled
+// blocks are always output as a list to support repeated blocks.
+func bodyToJSON(body *hclsyntax.Body, src []byte, blockType string) map[string]interface{} {
+	output := make(map[string]interface{})
+
+	for name, attr := range body.Attributes {
+		output[name] = expressionToJSON(blockType, name, attr.Expr, src)
+	}
+
+	for _, block := range body.Blocks {
+		value := bodyToJSON(block.Body, src, block.Type)
+
+		if len(block.Labels) == 0 {
+			existing, _ := output[block.Type].([]interface{})
+			output[block.Type] = append(existing, value)
+			continue
+		}
+
+		parent, ok := output[block.Type].(map[string]interface{})
+		if !ok {
+			parent = make(map[string]interface{})
+			output[block.Type] = parent
+		}
+		for _, label := range block.Labels[:len(block.Labels)-1] {
+			child, ok := parent[label].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[label] = child
+			}
+			parent = child
+		}
+		parent[block.Labels[len(block.Labels)-1]] = value
+	}
+
+	return output
+}
+
+// expressionToJSON converts a single expression to its JSON representation.
+// Literal values are output as-is whereas anything that requires evaluation
+// (references, function calls) is wrapped in a template interpolation.
+func expressionToJSON(blockType, name string, expr hclsyntax.Expression, src []byte) interface{} {
+	source := strings.TrimSpace(string(expr.Range().SliceBytes(src)))
+
+	// Some arguments are not expressions in the JSON syntax and are instead
+	// provided as plain strings.
+	switch {
+	case blockType == "variable" && name == "type",
+		(blockType == "import" || blockType == "moved") && (name == "to" || name == "from"):
+		return source
+	case name == "depends_on", blockType == "lifecycle" && name == "ignore_changes":
+		if tuple, ok := expr.(*hclsyntax.TupleConsExpr); ok {
+			items := make([]interface{}, 0, len(tuple.Exprs))
+			for _, item := range tuple.Exprs {
+				items = append(items, strings.TrimSpace(string(item.Range().SliceBytes(src))))
+			}
+			return items
+		}
+	}
+
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		return "${" + source + "}"
+	}
+
+	return ctyToJSON(value)
+}
+
+// ctyToJSON converts a cty value into a value suitable for encoding/json.
+// Strings, including object keys, are escaped as the JSON syntax interprets
+// them all as templates.
+func ctyToJSON(value cty.Value) interface{} {
+	if value.IsNull() {
+		return nil
+	}
+
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return escapeTemplateSequences(value.AsString())
+	case ty == cty.Number:
+		return json.Number(value.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		return value.True()
+	case ty.IsListType(), ty.IsSetType(), ty.IsTupleType():
+		items := make([]interface{}, 0, value.LengthInt())
+		for it := value.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			items = append(items, ctyToJSON(v))
+		}
+		return items
+	case ty.IsMapType(), ty.IsObjectType():
+		items := make(map[string]interface{})
+		for it := value.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			// Object keys are templates too.
+			items[escapeTemplateSequences(k.AsString())] = ctyToJSON(v)
+		}
+		return items
+	default:
+		return value.GoString()
+	}
+}
+
+func escapeTemplateSequences(s string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+}
@@ -0,0 +1,68 @@
+package terraforming
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertToJSONSyntax(t *testing.T) {
+	input := heredoc.Doc(`
+		resource "cloudflare_dns_record" "terraform_managed_resource_0" {
+		  content = "198.51.100.4"
+		  name    = "example.com"
+		  proxied = true
+		  ttl     = 1
+		  zone_id = var.zone_id
+		  settings = {
+		    ipv4_only = false
+		  }
+		  comment = "literal $${template} && <b>"
+		}
+
+		variable "zone_id" {
+		  type = string
+		}
+
+		import {
+		  to = cloudflare_dns_record.terraform_managed_resource_0
+		  id = "0da42c8d2132a9ddaf714f9e7c920711/1234"
+		}
+	`)
+
+	expected := heredoc.Doc(`
+		{
+		  "import": [
+		    {
+		      "id": "0da42c8d2132a9ddaf714f9e7c920711/1234",
+		      "to": "cloudflare_dns_record.terraform_managed_resource_0"
+		    }
+		  ],
+		  "resource": {
+		    "cloudflare_dns_record": {
+		      "terraform_managed_resource_0": {
+		        "comment": "literal $${template} && <b>",
+		        "content": "198.51.100.4",
+		        "name": "example.com",
+		        "proxied": true,
+		        "settings": {
+		          "ipv4_only": false
+		        },
+		        "ttl": 1,
+		        "zone_id": "${var.zone_id}"
+		      }
+		    }
+		  },
+		  "variable": {
+		    "zone_id": {
+		      "type": "string"
+		    }
+		  }
+		}
+	`)
+
+	output, err := ConvertToJSONSyntax([]byte(input))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(output))
+}
@@ -0,0 +1,39 @@
+package terraforming
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// tokensForJSONEncode builds a `jsonencode` expression which evaluates to the
+// same string. Only strings that Terraform would encode identically are
+// converted so that planning doesn't show any changes.
+func tokensForJSONEncode(value string) (hclwrite.Tokens, bool) {
+	trimmed := bytes.TrimSpace([]byte(value))
+	if len(trimmed) < 2 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, false
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(trimmed, &decoded); err != nil {
+		return nil, false
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil || string(encoded) != value {
+		return nil, false
+	}
+
+	ty, err := ctyjson.ImpliedType(encoded)
+	if err != nil {
+		return nil, false
+	}
+	v, err := ctyjson.Unmarshal(encoded, ty)
+	if err != nil {
+		return nil, false
+	}
+
+	return hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForValue(v)), true
+}
@@ -0,0 +1,57 @@
+package terraforming
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestWriteAttributeJSONEncode(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  string
+	}{
+		"object": {
+			value: `{"response":"your request has been rate limited","status":429}`,
+			want:  "body = jsonencode({\n  response = \"your request has been rate limited\"\n  status   = 429\n})\n",
+		},
+		"array": {
+			value: `[{"key":"a"},{"key":"${b}"}]`,
+			want:  "body = jsonencode([{\n  key = \"a\"\n  }, {\n  key = \"$${b}\"\n}])\n",
+		},
+		"not json": {
+			value: "{not json}",
+			want:  "body = \"{not json}\"\n",
+		},
+		"scalar": {
+			value: `"quoted"`,
+			want:  "body = \"\\\"quoted\\\"\"\n",
+		},
+		"formatted differently": {
+			value: `{"b": 1, "a": 2}`,
+			want:  "body = \"{\\\"b\\\": 1, \\\"a\\\": 2}\"\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := hclwrite.NewEmptyFile()
+			WriteAttribute(f.Body(), "body", tc.value, HCLOptions{JSONEncode: true})
+			assert.Equal(t, tc.want, string(hclwrite.Format(f.Bytes())))
+
+			// The expression must evaluate to the original string.
+			parsed, diags := hclsyntax.ParseConfig(f.Bytes(), "main.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+			attrs, _ := parsed.Body.JustAttributes()
+			value, diags := attrs["body"].Expr.Value(&hcl.EvalContext{Functions: map[string]function.Function{"jsonencode": stdlib.JSONEncodeFunc}})
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, tc.value, value.AsString())
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package terraforming
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// defaultResourceName is the name of resources without any attributes to
+// name them after, and the prefix of those named after a numeric ID.
+const defaultResourceName = "terraform_managed_resource"
+
+// resourceNameAttributes lists the attributes combined to name resources of
+// each type. Types that aren't listed are named after the first of
+// defaultResourceNameAttributes they have.
+var resourceNameAttributes = map[string][]string{
+	"cloudflare_custom_hostname":                     {"hostname"},
+	"cloudflare_dns_record":                          {"name", "type"},
+	"cloudflare_email_routing_address":               {"email"},
+	"cloudflare_record":                              {"name", "type"},
+	"cloudflare_worker_route":                        {"pattern"},
+	"cloudflare_workers_custom_domain":               {"hostname"},
+	"cloudflare_workers_route":                       {"pattern"},
+	"cloudflare_zero_trust_tunnel_cloudflared_route": {"network"},
+}
+
+var defaultResourceNameAttributes = []string{"name", "hostname", "title", "description"}
+
+// NameAttributes returns the attributes which resources of the type can be
+// named after.
+func NameAttributes(resourceType string) []string {
+	return slices.Concat(resourceNameAttributes[resourceType], defaultResourceNameAttributes)
+}
+
+var nonIdentifierCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Naming controls the names given to the generated resources.
+type Naming struct {
+	// Template is a Go template with access to the resource's attributes as
+	// well as resource_type, type_short, account and zone, e.g.
+	// {{ .name | slug }}.
+	Template string
+	Prefix   string
+	Suffix   string
+}
+
+// Namer derives readable resource names from the attributes of each resource
+// of a type, adding a numeric suffix when the name has already been used. The
+// same API response always produces the same names.
+type Namer struct {
+	naming   Naming
+	scope    Scope
+	template *template.Template
+	used     map[string]bool
+}
+
+// NewNamer returns a namer for a single resource type in the account or zone.
+func NewNamer(naming Naming, scope Scope) (*Namer, error) {
+	n := &Namer{naming: naming, scope: scope, used: make(map[string]bool)}
+	if naming.Template != "" {
+		tmpl, err := template.New("name").Funcs(nameTemplateFuncs).Parse(naming.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the name template: %w", err)
+		}
+		n.template = tmpl
+	}
+
+	return n, nil
+}
+
+// Reserve stops the name from being given to any resource, such as when it's
+// already used by existing configuration.
+func (n *Namer) Reserve(name string) {
+	n.used[name] = true
+}
+
+// Name returns the next unused name for the resource.
+func (n *Namer) Name(resourceType string, data map[string]interface{}) (string, error) {
+	base := resourceNameBase(resourceType, data, n.scope)
+	if n.template != nil {
+		var err error
+		if base, err = n.executeTemplate(resourceType, data); err != nil {
+			return "", err
+		}
+	}
+
+	base = n.naming.Prefix + base + n.naming.Suffix
+	if !hclsyntax.ValidIdentifier(base) {
+		return "", fmt.Errorf("%q is not a valid resource name, check the name prefix and suffix", base)
+	}
+
+	name := base
+	for i := 2; n.used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	n.used[name] = true
+
+	return name, nil
+}
+
+// resourceNameBase builds a name from the resource's attributes, falling back
+// to its ID or the account or zone it belongs to.
+func resourceNameBase(resourceType string, data map[string]interface{}, scope Scope) string {
+	parts := nameParts(resourceType, data)
+	if len(parts) == 0 {
+		switch id := data["id"].(type) {
+		case string:
+			parts = append(parts, id)
+		case float64:
+			parts = append(parts, fmt.Sprintf("%d", int(id)))
+		default:
+			if scope.ZoneID != "" {
+				parts = append(parts, scope.ZoneID)
+			} else if scope.AccountID != "" {
+				parts = append(parts, scope.AccountID)
+			}
+		}
+	}
+
+	name := sanitiseResourceName(strings.Join(parts, "_"))
+	if name == "" {
+		return defaultResourceName
+	}
+
+	// Identifiers can't start with a digit so IDs keep the previous prefix.
+	if name[0] >= '0' && name[0] <= '9' {
+		return fmt.Sprintf("%s_%s", defaultResourceName, name)
+	}
+
+	return name
+}
+
+// nameParts returns the values of the attributes the resource is named after.
+func nameParts(resourceType string, data map[string]interface{}) []string {
+	var parts []string
+	for _, attribute := range resourceNameAttributes[resourceType] {
+		if value, ok := data[attribute].(string); ok && value != "" {
+			parts = append(parts, value)
+		}
+	}
+	if len(parts) > 0 {
+		return parts
+	}
+
+	for _, attribute := range defaultResourceNameAttributes {
+		if value, ok := data[attribute].(string); ok && value != "" {
+			return []string{value}
+		}
+	}
+
+	return nil
+}
+
+// DisplayName returns the human readable name of a resource using the same
+// attributes that resources are named after, or an empty string if it has
+// none of them.
+func DisplayName(resourceType string, data map[string]interface{}) string {
+	return strings.Join(nameParts(resourceType, data), " ")
+}
+
+// sanitiseResourceName converts a value into a valid Terraform identifier. For
+// example, `*.example.com` becomes `wildcard_example_com`.
+func sanitiseResourceName(value string) string {
+	value = strings.ReplaceAll(strings.ToLower(value), "*", "wildcard")
+	return strings.Trim(nonIdentifierCharacters.ReplaceAllString(value, "_"), "_")
+}
+
+var nameTemplateFuncs = template.FuncMap{
+	"slug": func(value interface{}) string {
+		if value == nil {
+			return ""
+		}
+		return sanitiseResourceName(fmt.Sprint(value))
+	},
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.ReplaceAll,
+}
+
+// NameTemplateFuncs returns the functions name templates have access to, such
+// as slug, so that other templates can offer the same.
+func NameTemplateFuncs() template.FuncMap {
+	funcs := make(template.FuncMap, len(nameTemplateFuncs))
+	for name, fn := range nameTemplateFuncs {
+		funcs[name] = fn
+	}
+
+	return funcs
+}
+
+// executeTemplate names a resource using the template. The template has
+// access to the resource's attributes as well as `resource_type`,
+// `type_short`, `account` and `zone`.
+func (n *Namer) executeTemplate(resourceType string, data map[string]interface{}) (string, error) {
+	values := make(map[string]interface{}, len(data)+4)
+	for k, v := range data {
+		values[k] = v
+	}
+	values["resource_type"] = resourceType
+	values["type_short"] = strings.TrimPrefix(resourceType, "cloudflare_")
+	values["account"] = n.scope.AccountID
+	values["zone"] = n.scope.ZoneID
+
+	var name strings.Builder
+	if err := n.template.Execute(&name, values); err != nil {
+		return "", fmt.Errorf("failed to execute the name template for %s: %w", resourceType, err)
+	}
+	if !hclsyntax.ValidIdentifier(name.String()) {
+		return "", fmt.Errorf("the name template produced %q for %s which is not a valid resource name", name.String(), resourceType)
+	}
+
+	return name.String(), nil
+}
@@ -0,0 +1,60 @@
+package terraforming
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceNameBase(t *testing.T) {
+	tests := map[string]struct {
+		resourceType string
+		data         map[string]interface{}
+		expected     string
+	}{
+		"default attribute":   {resourceType: "cloudflare_zero_trust_access_application", data: map[string]interface{}{"name": "Internal Wiki", "domain": "wiki.example.com"}, expected: "internal_wiki"},
+		"string id fallback":  {resourceType: "cloudflare_zone_setting", data: map[string]interface{}{"id": "always_online"}, expected: "always_online"},
+		"numeric id fallback": {resourceType: "cloudflare_healthcheck", data: map[string]interface{}{"id": float64(1234)}, expected: "terraform_managed_resource_1234"},
+		"no attributes":       {resourceType: "cloudflare_bot_management", data: map[string]interface{}{}, expected: "terraform_managed_resource"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resourceNameBase(tc.resourceType, tc.data, Scope{}))
+		})
+	}
+}
+
+func TestNamerTemplate(t *testing.T) {
+	scope := Scope{ZoneID: "0da42c8d2132a9ddaf714f9e7c920711"}
+
+	namer, err := NewNamer(Naming{Template: "{{ .type_short }}_{{ .name | slug }}_{{ .type | lower }}"}, scope)
+	require.NoError(t, err)
+	name, err := namer.Name("cloudflare_dns_record", map[string]interface{}{"name": "www.example.com", "type": "AAAA"})
+	assert.NoError(t, err)
+	assert.Equal(t, "dns_record_www_example_com_aaaa", name)
+
+	namer, err = NewNamer(Naming{Template: "{{ .zone }}"}, scope)
+	require.NoError(t, err)
+	_, err = namer.Name("cloudflare_dns_record", map[string]interface{}{})
+	assert.EqualError(t, err, `the name template produced "0da42c8d2132a9ddaf714f9e7c920711" for cloudflare_dns_record which is not a valid resource name`)
+
+	_, err = NewNamer(Naming{Template: "{{ .name"}, scope)
+	assert.ErrorContains(t, err, "failed to parse the name template")
+}
+
+func TestNamerReserve(t *testing.T) {
+	namer, err := NewNamer(Naming{Prefix: "cf_"}, Scope{})
+	require.NoError(t, err)
+	namer.Reserve("cf_example_com_a")
+
+	name, err := namer.Name("cloudflare_dns_record", map[string]interface{}{"name": "example.com", "type": "A"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cf_example_com_a_2", name)
+
+	namer, err = NewNamer(Naming{Prefix: "1"}, Scope{})
+	require.NoError(t, err)
+	_, err = namer.Name("cloudflare_dns_record", map[string]interface{}{"name": "example.com", "type": "A"})
+	assert.EqualError(t, err, `"1example_com_a" is not a valid resource name, check the name prefix and suffix`)
+}
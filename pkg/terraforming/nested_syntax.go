@@ -0,0 +1,108 @@
+package terraforming
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// The syntaxes HCLOptions.NestedSyntax can override nested objects with.
+const (
+	NestedSyntaxBlock     = "block"
+	NestedSyntaxAttribute = "attribute"
+)
+
+// appendAttributeBlocks writes an object, or list of objects, as nested
+// blocks rather than an attribute.
+func appendAttributeBlocks(body *hclwrite.Body, name string, value interface{}, opts HCLOptions) {
+	var items []map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		items = append(items, v)
+	case []map[string]interface{}:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				opts.logger().Warnf("%s contains values other than objects so is written as an attribute", name)
+				WriteAttribute(body, name, value, opts)
+				return
+			}
+			items = append(items, m)
+		}
+	default:
+		WriteAttribute(body, name, value, opts)
+		return
+	}
+
+	for _, item := range items {
+		keys := make([]string, 0, len(item))
+		for k := range item {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		block := body.AppendNewBlock(name, nil).Body()
+		for _, k := range keys {
+			writeAttrLine(k, item[k], name, block, opts)
+		}
+	}
+}
+
+// setBlocksAsAttribute writes nested blocks as an attribute instead. Blocks
+// which can be repeated become a list of objects.
+func setBlocksAsAttribute(body *hclwrite.Body, name string, blocks []*hclwrite.Block, list bool) {
+	if len(blocks) == 0 {
+		return
+	}
+
+	objects := make([]hclwrite.Tokens, 0, len(blocks))
+	for _, block := range blocks {
+		objects = append(objects, blockToObject(block))
+	}
+
+	if list {
+		body.SetAttributeRaw(name, hclwrite.TokensForTuple(objects))
+	} else {
+		body.SetAttributeRaw(name, objects[0])
+	}
+}
+
+// blockToObject converts a block, including any blocks nested within it, to
+// an object expression. Nested blocks of the same type which are repeated
+// become a list of objects.
+func blockToObject(block *hclwrite.Block) hclwrite.Tokens {
+	values := make(map[string]hclwrite.Tokens)
+	for name, attr := range block.Body().Attributes() {
+		values[name] = attr.Expr().BuildTokens(nil)
+	}
+
+	nested := make(map[string][]hclwrite.Tokens)
+	for _, b := range block.Body().Blocks() {
+		nested[b.Type()] = append(nested[b.Type()], blockToObject(b))
+	}
+	for name, objects := range nested {
+		if len(objects) == 1 {
+			values[name] = objects[0]
+		} else {
+			values[name] = hclwrite.TokensForTuple(objects)
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]hclwrite.ObjectAttrTokens, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(name),
+			Value: values[name],
+		})
+	}
+
+	return hclwrite.TokensForObject(attrs)
+}
@@ -0,0 +1,106 @@
+package terraforming
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestProcessBlocksNestedSyntax(t *testing.T) {
+	schema := &tfjson.SchemaBlock{
+		Attributes: map[string]*tfjson.SchemaAttribute{
+			"name":      {AttributeType: cty.String, Optional: true},
+			"overrides": {AttributeType: cty.DynamicPseudoType, Optional: true},
+		},
+		NestedBlocks: map[string]*tfjson.SchemaBlockType{
+			"rules": {
+				NestingMode: tfjson.SchemaNestingModeList,
+				Block: &tfjson.SchemaBlock{
+					Attributes: map[string]*tfjson.SchemaAttribute{
+						"name":     {AttributeType: cty.String, Optional: true},
+						"priority": {AttributeType: cty.Number, Optional: true},
+					},
+				},
+			},
+		},
+	}
+	data := map[string]interface{}{
+		"name": "example",
+		"overrides": map[string]interface{}{
+			"ttl": float64(30),
+		},
+		"rules": []interface{}{
+			map[string]interface{}{"name": "first", "priority": float64(1)},
+			map[string]interface{}{"name": "second", "priority": float64(2)},
+		},
+	}
+
+	tests := map[string]struct {
+		syntax map[string]string
+		want   string
+	}{
+		"schema": {
+			syntax: map[string]string{},
+			want: heredoc.Doc(`
+				name = "example"
+				overrides = {
+				  ttl = 30
+				}
+				rules {
+				  name     = "first"
+				  priority = 1
+				}
+				rules {
+				  name     = "second"
+				  priority = 2
+				}
+			`),
+		},
+		"rules as attribute": {
+			syntax: map[string]string{"cloudflare_load_balancer.rules": NestedSyntaxAttribute},
+			want: heredoc.Doc(`
+				name = "example"
+				overrides = {
+				  ttl = 30
+				}
+				rules = [{
+				  name     = "first"
+				  priority = 1
+				  }, {
+				  name     = "second"
+				  priority = 2
+				}]
+			`),
+		},
+		"overrides as block": {
+			syntax: map[string]string{"cloudflare_load_balancer.overrides": NestedSyntaxBlock},
+			want: heredoc.Doc(`
+				name = "example"
+				overrides {
+				  ttl = 30
+				}
+				rules {
+				  name     = "first"
+				  priority = 1
+				}
+				rules {
+				  name     = "second"
+				  priority = 2
+				}
+			`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := hclwrite.NewEmptyFile()
+			processBlocks(schema, data, f.Body(), "", "cloudflare_load_balancer", HCLOptions{NestedSyntax: tc.syntax})
+
+			assert.Equal(t, tc.want, string(hclwrite.Format(f.Bytes())))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package terraforming
+
+import (
+	"encoding/json"
+)
+
+// NormaliseValue round trips a value through JSON so that values decoded
+// from different sources can be compared, such as typed slices and integers
+// against the []interface{} and float64 the API responses decode to.
+func NormaliseValue(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return NormaliseJSON(encoded)
+}
+
+// NormaliseJSON decodes JSON into the same form as NormaliseValue.
+func NormaliseJSON(encoded []byte) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// NormaliseAttributeValue returns the value an attribute written for the
+// value has when the configuration is read back, in the same form as
+// NormaliseValue, and whether the attribute is written at all. Null values
+// and empty strings aren't written as they're the same as leaving the
+// attribute out.
+func NormaliseAttributeValue(value interface{}) (interface{}, bool, error) {
+	if value == nil || value == "" {
+		return nil, false, nil
+	}
+
+	normalised, err := NormaliseValue(value)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return normalised, true, nil
+}
@@ -0,0 +1,28 @@
+package terraforming
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessResponseCronTrigger(t *testing.T) {
+	response := []interface{}{
+		map[string]interface{}{"schedules": []interface{}{
+			map[string]interface{}{"cron": "*/30 * * * *", "created_on": "2025-02-13T10:51:23.426493Z", "modified_on": "2025-02-13T10:51:23.426493Z"},
+		}},
+		map[string]interface{}{"schedules": []interface{}{}},
+		map[string]interface{}{"schedules": "*/30 * * * *"},
+		"*/30 * * * *",
+	}
+
+	response, err := ProcessResponse(context.Background(), nil, "cloudflare_workers_cron_trigger", "script_2", response, FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"script_name": "script_2", "schedules": []interface{}{
+			map[string]interface{}{"cron": "*/30 * * * *"},
+		}},
+	}, response)
+}
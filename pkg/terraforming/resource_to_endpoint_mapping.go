@@ -1,5 +1,5 @@
 // This file is automatically generated. Any manual edits here will be overwritten on the next update.
-package cmd
+package terraforming
 
 var resourceToEndpoint = map[string]map[string]string{
 
@@ -0,0 +1,396 @@
+package terraforming
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// The round trip tests write generated values as HCL, read the configuration
+// back in both the native and JSON syntax and check it has the same values,
+// catching the quoting and nesting bugs that only show up with unusual
+// values.
+
+// roundTripIterations is how many values are generated for each property.
+const roundTripIterations = 300
+
+// roundTripStrings are combined into the generated strings and keys as they
+// need escaping in at least one of the syntaxes.
+var roundTripStrings = []string{
+	"", "a", "www", " ", "é", "🙂", "\"", "\\", "\\n", "'", "`",
+	"${", "$${", "${var.zone_id}", "%{", "%%{", "%{ if true }", "$", "%", "{", "}", "}}",
+	"\n", "\r\n", "\t", "\x01", " ",
+	"EOT", "<<EOT", "#", "//", "/*", "*/", "=", ":", ",", "[", "]",
+	"true", "null", "1", "-1", "1e3", "var.zone_id", "cloudflare_zone.example.id",
+}
+
+// roundTripGenerator generates random JSON values like those from the API.
+type roundTripGenerator struct {
+	r *rand.Rand
+}
+
+func (g roundTripGenerator) string() string {
+	var s strings.Builder
+	for i := g.r.Intn(5); i > 0; i-- {
+		s.WriteString(roundTripStrings[g.r.Intn(len(roundTripStrings))])
+	}
+	return s.String()
+}
+
+func (g roundTripGenerator) number() float64 {
+	switch g.r.Intn(4) {
+	case 0:
+		return float64(g.r.Intn(100))
+	case 1:
+		return -float64(g.r.Int63())
+	case 2:
+		return g.r.NormFloat64() * 1e6
+	default:
+		return []float64{0.1, 1e21, 1e-7, 9007199254740993, 3.14159}[g.r.Intn(5)]
+	}
+}
+
+// value generates a value which is nested up to the depth.
+func (g roundTripGenerator) value(depth int) interface{} {
+	kinds := 5
+	if depth > 0 {
+		kinds = 7
+	}
+	switch g.r.Intn(kinds) {
+	case 0:
+		return nil
+	case 1, 2:
+		return g.string()
+	case 3:
+		return g.number()
+	case 4:
+		return g.r.Intn(2) == 0
+	case 5:
+		items := make([]interface{}, g.r.Intn(4))
+		for i := range items {
+			items[i] = g.value(depth - 1)
+		}
+		return items
+	default:
+		object := make(map[string]interface{})
+		for i := g.r.Intn(4); i > 0; i-- {
+			object[g.string()] = g.value(depth - 1)
+		}
+		return object
+	}
+}
+
+// jsonString generates a value encoded as JSON, which --jsonencode rewrites.
+func (g roundTripGenerator) jsonString() string {
+	encoded, _ := json.Marshal(g.value(2))
+	return string(encoded)
+}
+
+// roundTripEvalContext has the functions the written configuration calls.
+var roundTripEvalContext = &hcl.EvalContext{
+	Functions: map[string]function.Function{
+		"chomp":      stdlib.ChompFunc,
+		"jsonencode": stdlib.JSONEncodeFunc,
+	},
+}
+
+// roundTripSyntaxes read the written configuration back in each syntax.
+var roundTripSyntaxes = map[string]func(t *testing.T, src []byte) hcl.Body{
+	"native": func(t *testing.T, src []byte) hcl.Body {
+		file, diags := hclsyntax.ParseConfig(src, "generated.tf", hcl.InitialPos)
+		require.False(t, diags.HasErrors(), "%s\n%s", diags.Error(), src)
+		return resourceBody(t, file.Body, src)
+	},
+	"json": func(t *testing.T, src []byte) hcl.Body {
+		converted, err := ConvertToJSONSyntax(src)
+		require.NoError(t, err, "%s", src)
+		file, diags := hcljson.Parse(converted, "generated.tf.json")
+		require.False(t, diags.HasErrors(), "%s\n%s", diags.Error(), converted)
+		return resourceBody(t, file.Body, converted)
+	},
+}
+
+// resourceBody returns the body of the only resource in the file.
+func resourceBody(t *testing.T, body hcl.Body, src []byte) hcl.Body {
+	content, diags := body.Content(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "resource", LabelNames: []string{"type", "name"}}},
+	})
+	require.False(t, diags.HasErrors(), "%s\n%s", diags.Error(), src)
+	require.Len(t, content.Blocks, 1, "%s", src)
+	return content.Blocks[0].Body
+}
+
+// readAttributes evaluates the attributes of the body in the same form as
+// NormaliseValue.
+func readAttributes(t *testing.T, body hcl.Body, src []byte) map[string]interface{} {
+	attrs, diags := body.JustAttributes()
+	require.False(t, diags.HasErrors(), "%s\n%s", diags.Error(), src)
+
+	values := make(map[string]interface{}, len(attrs))
+	for name, attr := range attrs {
+		values[name] = evalNormalised(t, attr.Expr, src)
+	}
+	return values
+}
+
+func evalNormalised(t *testing.T, expr hcl.Expression, src []byte) interface{} {
+	value, diags := expr.Value(roundTripEvalContext)
+	require.False(t, diags.HasErrors(), "%s\n%s", diags.Error(), src)
+	encoded, err := ctyjson.Marshal(value, cty.DynamicPseudoType)
+	require.NoError(t, err)
+
+	// The value is wrapped with its type by DynamicPseudoType.
+	var wrapped struct {
+		Value json.RawMessage `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &wrapped))
+	normalised, err := NormaliseJSON(wrapped.Value)
+	require.NoError(t, err)
+	return normalised
+}
+
+// writeResource writes a resource with the attribute set by WriteAttribute.
+func writeResource(name string, value interface{}, opts HCLOptions) []byte {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body().AppendNewBlock("resource", []string{"cloudflare_example", "example"}).Body()
+	WriteAttribute(body, name, value, opts)
+	return hclwrite.Format(f.Bytes())
+}
+
+// checkAttributeRoundTrip checks the value survives being written as an
+// attribute and read back in each syntax.
+func checkAttributeRoundTrip(t *testing.T, value interface{}, opts HCLOptions) {
+	t.Helper()
+
+	expected, written, err := NormaliseAttributeValue(value)
+	require.NoError(t, err)
+	src := writeResource("attr", value, opts)
+
+	for syntax, read := range roundTripSyntaxes {
+		attributes := readAttributes(t, read(t, src), src)
+		actual, ok := attributes["attr"]
+		if !assert.Equal(t, written, ok, "%s syntax of %#v\n%s", syntax, value, src) || !written {
+			continue
+		}
+		// jsonencode writes numbers differently, such as 1e21 in full, so
+		// JSON strings are compared by their values.
+		if s, isString := value.(string); isString && opts.JSONEncode && json.Valid([]byte(s)) {
+			assert.JSONEq(t, s, actual.(string), "%s syntax of %#v\n%s", syntax, value, src)
+			continue
+		}
+		assert.Equal(t, expected, actual, "%s syntax of %#v\n%s", syntax, value, src)
+	}
+}
+
+func TestWriteAttributeRoundTrip(t *testing.T) {
+	modes := map[string]HCLOptions{
+		"quoted":     {},
+		"heredoc":    {HeredocThreshold: 1},
+		"jsonencode": {JSONEncode: true},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			g := roundTripGenerator{r: rand.New(rand.NewSource(1))}
+			for i := 0; i < roundTripIterations; i++ {
+				var value interface{}
+				if opts.JSONEncode && i%2 == 0 {
+					value = g.jsonString()
+				} else {
+					value = g.value(3)
+				}
+				checkAttributeRoundTrip(t, value, opts)
+			}
+		})
+	}
+}
+
+func FuzzWriteAttribute(f *testing.F) {
+	for _, seed := range []string{
+		`"${var.zone_id}"`,
+		`"%{ if true }yes%{ endif }"`,
+		`"line one\nline two\n"`,
+		`"\u0001 "`,
+		`{"a b": 1, "": null, "${x}": {"\"": [true, "\\"]}}`,
+		`[{"name": "www"}, {"content": ["a", 1]}, []]`,
+		`[1e21, -0, 0.1, 9007199254740993]`,
+		`{"EOT": "EOT\nEOT\n"}`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data string) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			t.Skip()
+		}
+		checkAttributeRoundTrip(t, value, HCLOptions{})
+	})
+}
+
+// roundTripBlockSchema is a resource with a repeated nested block holding
+// another, like rulesets' rules and their action parameters.
+var roundTripBlockSchema = &tfjson.SchemaBlock{
+	Attributes: map[string]*tfjson.SchemaAttribute{
+		"name": {Required: true},
+	},
+	NestedBlocks: map[string]*tfjson.SchemaBlockType{
+		"rules": {
+			NestingMode: tfjson.SchemaNestingModeList,
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"expression": {Required: true},
+					"enabled":    {Optional: true},
+					"tags":       {Optional: true},
+					"ref":        {Computed: true},
+				},
+				NestedBlocks: map[string]*tfjson.SchemaBlockType{
+					"action_parameters": {
+						NestingMode: tfjson.SchemaNestingModeList,
+						Block: &tfjson.SchemaBlock{
+							Attributes: map[string]*tfjson.SchemaAttribute{
+								"uri":     {Optional: true},
+								"headers": {Optional: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// blockData generates the data of a resource following roundTripBlockSchema.
+func (g roundTripGenerator) blockData() map[string]interface{} {
+	maybe := func(value func() interface{}) interface{} {
+		if g.r.Intn(4) == 0 {
+			return nil
+		}
+		return value()
+	}
+
+	rules := make([]interface{}, g.r.Intn(4))
+	for i := range rules {
+		parameters := make([]interface{}, g.r.Intn(3))
+		for j := range parameters {
+			parameters[j] = map[string]interface{}{
+				"uri":     maybe(func() interface{} { return g.string() }),
+				"headers": maybe(func() interface{} { return g.value(2) }),
+			}
+		}
+		rules[i] = map[string]interface{}{
+			"expression":        maybe(func() interface{} { return g.string() }),
+			"enabled":           maybe(func() interface{} { return g.r.Intn(2) == 0 }),
+			"tags":              maybe(func() interface{} { return g.value(1) }),
+			"ref":               g.string(),
+			"action_parameters": parameters,
+		}
+	}
+
+	return map[string]interface{}{"name": g.string(), "rules": rules}
+}
+
+// expectedBlocks is the data as it's expected to be read back: computed,
+// null and empty string attributes and blocks without any attributes are
+// left out.
+func expectedBlocks(t *testing.T, schema *tfjson.SchemaBlock, data map[string]interface{}) map[string]interface{} {
+	expected := make(map[string]interface{})
+	for name, value := range data {
+		if nested, ok := schema.NestedBlocks[name]; ok {
+			var items []interface{}
+			for _, item := range value.([]interface{}) {
+				if e := expectedBlocks(t, nested.Block, item.(map[string]interface{})); len(e) > 0 {
+					items = append(items, e)
+				}
+			}
+			if len(items) > 0 {
+				expected[name] = items
+			}
+			continue
+		}
+		if attr, ok := schema.Attributes[name]; !ok || !(attr.Optional || attr.Required) {
+			continue
+		}
+		normalised, written, err := NormaliseAttributeValue(value)
+		require.NoError(t, err)
+		if written {
+			expected[name] = normalised
+		}
+	}
+	return expected
+}
+
+// readBlocks reads the attributes and nested blocks of the body back into
+// the same form as expectedBlocks.
+func readBlocks(t *testing.T, schema *tfjson.SchemaBlock, body hcl.Body, src []byte) map[string]interface{} {
+	bodySchema := &hcl.BodySchema{}
+	for name := range schema.Attributes {
+		bodySchema.Attributes = append(bodySchema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+	for name := range schema.NestedBlocks {
+		bodySchema.Blocks = append(bodySchema.Blocks, hcl.BlockHeaderSchema{Type: name})
+	}
+	content, diags := body.Content(bodySchema)
+	require.False(t, diags.HasErrors(), "%s\n%s", diags.Error(), src)
+
+	values := make(map[string]interface{})
+	for name, attr := range content.Attributes {
+		values[name] = evalNormalised(t, attr.Expr, src)
+	}
+	for _, block := range content.Blocks {
+		items, _ := values[block.Type].([]interface{})
+		values[block.Type] = append(items, readBlocks(t, schema.NestedBlocks[block.Type].Block, block.Body, src))
+	}
+	return values
+}
+
+func TestProcessBlocksRoundTrip(t *testing.T) {
+	overrides := map[string]map[string]string{
+		"blocks": {},
+		// Both levels are converted, so the attribute is built from blocks
+		// which have already been converted to attributes.
+		"attributes": {
+			"cloudflare_example.rules":                   NestedSyntaxAttribute,
+			"cloudflare_example.rules.action_parameters": NestedSyntaxAttribute,
+		},
+	}
+	for name, override := range overrides {
+		t.Run(name, func(t *testing.T) {
+			opts := HCLOptions{NestedSyntax: override}
+
+			g := roundTripGenerator{r: rand.New(rand.NewSource(1))}
+			for i := 0; i < roundTripIterations; i++ {
+				data := g.blockData()
+				expected := expectedBlocks(t, roundTripBlockSchema, data)
+
+				f := hclwrite.NewEmptyFile()
+				body := f.Body().AppendNewBlock("resource", []string{"cloudflare_example", "example"}).Body()
+				processBlocks(roundTripBlockSchema, data, body, "", "cloudflare_example", opts)
+				src := hclwrite.Format(f.Bytes())
+
+				for syntax, read := range roundTripSyntaxes {
+					var actual map[string]interface{}
+					if len(override) > 0 {
+						actual = readAttributes(t, read(t, src), src)
+					} else {
+						actual = readBlocks(t, roundTripBlockSchema, read(t, src), src)
+					}
+					assert.Equal(t, expected, actual, "%s syntax of %s\n%s", syntax, fmt.Sprint(data), src)
+				}
+			}
+		})
+	}
+}
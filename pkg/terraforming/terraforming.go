@@ -0,0 +1,234 @@
+// Package terraforming generates Terraform configuration and import blocks
+// for existing Cloudflare resources, so that Go tools can embed
+// cf-terraforming rather than running the CLI and reading its output.
+//
+// The CLI is built on the same functions: Fetch gets the resources of a type
+// from the API, Namer names them, WriteResource writes them as HCL following
+// the provider schema and ImportID builds their import IDs. A Generator
+// combines them for v5 resource types. Nothing is kept in package state other
+// than the registered converters, so generators can run at the same time.
+package terraforming
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Scope is the account or zone whose resources are generated. Exactly one
+// must be set.
+type Scope struct {
+	AccountID string
+	ZoneID    string
+}
+
+// Options configure a Generator.
+type Options struct {
+	// Client sends the API requests, such as one created with
+	// cloudflare.NewClient.
+	Client *cloudflare.Client
+	Scope  Scope
+
+	// ResourceTypes are the Terraform resource types to generate, such as
+	// cloudflare_dns_record.
+	ResourceTypes []string
+
+	// Schema is the schema of the Cloudflare provider, as output by
+	// `terraform providers schema -json`, which the configuration follows.
+	// Only Generate needs it.
+	Schema *tfjson.ProviderSchema
+
+	Naming Naming
+
+	// Fetch and HCL configure how the resources are fetched and written.
+	// Their Scope is set from Scope.
+	Fetch FetchOptions
+	HCL   HCLOptions
+
+	// Output receives the generated configuration or import blocks.
+	Output io.Writer
+}
+
+// Generator generates Terraform configuration for existing resources.
+type Generator struct {
+	opts Options
+}
+
+// New returns a Generator after checking the options.
+func New(opts Options) (*Generator, error) {
+	if opts.Client == nil {
+		return nil, errors.New("an API client must be set")
+	}
+	if len(opts.ResourceTypes) == 0 {
+		return nil, errors.New("at least one resource type must be set")
+	}
+	if (opts.Scope.AccountID == "") == (opts.Scope.ZoneID == "") {
+		return nil, errors.New("exactly one of the account or zone ID must be set")
+	}
+	if opts.Output == nil {
+		return nil, errors.New("an output writer must be set")
+	}
+	if _, err := NewNamer(opts.Naming, opts.Scope); err != nil {
+		return nil, err
+	}
+	opts.Fetch.Scope, opts.HCL.Scope = opts.Scope, opts.Scope
+
+	return &Generator{opts: opts}, nil
+}
+
+// Generate writes the Terraform configuration of the resources to the output.
+// A resource type which fails doesn't stop the others from being generated,
+// and its error is returned along with those of any others once they've all
+// been written.
+func (g *Generator) Generate(ctx context.Context) error {
+	if g.opts.Schema == nil {
+		return errors.New("a provider schema must be set to generate")
+	}
+
+	var errs []error
+	for _, resourceType := range g.opts.ResourceTypes {
+		if err := g.generate(ctx, resourceType); err != nil {
+			errs = append(errs, fmt.Errorf("failed to generate %s: %w", resourceType, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (g *Generator) generate(ctx context.Context, resourceType string) error {
+	r, ok := g.opts.Schema.ResourceSchemas[resourceType]
+	if !ok || r.Block == nil {
+		return fmt.Errorf("%s is not in the provider schema", resourceType)
+	}
+
+	data, err := g.resources(ctx, resourceType)
+	if err != nil {
+		return err
+	}
+
+	namer, err := NewNamer(g.opts.Naming, g.opts.Scope)
+	if err != nil {
+		return err
+	}
+
+	f := hclwrite.NewEmptyFile()
+	for _, item := range data {
+		structData, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected %T in the API response", item)
+		}
+		name, err := namer.Name(resourceType, structData)
+		if err != nil {
+			return err
+		}
+
+		block := hclwrite.NewBlock("resource", []string{resourceType, name})
+		if err := ConvertResource(func() { WriteResource(block.Body(), resourceType, r.Block, structData, g.opts.HCL) }); err != nil {
+			return fmt.Errorf("%s.%s: %w", resourceType, name, err)
+		}
+		f.Body().AppendBlock(block)
+		f.Body().AppendNewline()
+	}
+
+	if converter, ok := LookupConverter(resourceType); ok && converter.PostProcess != nil {
+		converter.PostProcess(f)
+	}
+
+	_, err = g.opts.Output.Write(hclwrite.Format(f.Bytes()))
+	return err
+}
+
+// Import writes import blocks for the resources to the output, using the
+// same names as Generate.
+func (g *Generator) Import(ctx context.Context) error {
+	var errs []error
+	for _, resourceType := range g.opts.ResourceTypes {
+		if err := g.importBlocks(ctx, resourceType); err != nil {
+			errs = append(errs, fmt.Errorf("failed to import %s: %w", resourceType, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (g *Generator) importBlocks(ctx context.Context, resourceType string) error {
+	data, err := g.resources(ctx, resourceType)
+	if err != nil {
+		return err
+	}
+
+	namer, err := NewNamer(g.opts.Naming, g.opts.Scope)
+	if err != nil {
+		return err
+	}
+
+	f := hclwrite.NewEmptyFile()
+	for _, item := range data {
+		structData, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected %T in the API response", item)
+		}
+		name, err := namer.Name(resourceType, structData)
+		if err != nil {
+			return err
+		}
+		id, err := ImportID(resourceType, g.resourceID(structData), structData, g.opts.Scope)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", resourceType, name, err)
+		}
+
+		imp := f.Body().AppendNewBlock("import", nil).Body()
+		imp.SetAttributeRaw("to", hclwrite.TokensForIdentifier(resourceType+"."+name))
+		imp.SetAttributeValue("id", cty.StringVal(id))
+		f.Body().AppendNewline()
+	}
+
+	_, err = g.opts.Output.Write(hclwrite.Format(f.Bytes()))
+	return err
+}
+
+// resources returns the resources of the type from its converter or
+// otherwise its API endpoint.
+func (g *Generator) resources(ctx context.Context, resourceType string) ([]interface{}, error) {
+	if converter, ok := LookupConverter(resourceType); ok {
+		return converter.Resources(ctx, g.opts.Client, g.opts.Scope)
+	}
+
+	endpoint := ResourceEndpoint(resourceType, g.opts.Scope)
+	if endpoint == "" {
+		return nil, fmt.Errorf("%s is not supported", resourceType)
+	}
+	if strings.Contains(endpoint, "{") {
+		return nil, fmt.Errorf("%s is listed for each parent resource, which is only supported by the CLI", resourceType)
+	}
+
+	data, err := Fetch(ctx, g.opts.Client, resourceType, []string{endpoint}, nil, g.opts.Fetch)
+	if IsNoResourcesError(err) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+// resourceID returns the ID of the resource, or that of the account or zone
+// for resources without one such as settings.
+func (g *Generator) resourceID(data map[string]interface{}) string {
+	switch id := data["id"].(type) {
+	case string:
+		return id
+	case float64:
+		return fmt.Sprintf("%d", int(id))
+	}
+
+	if g.opts.Scope.AccountID != "" {
+		return g.opts.Scope.AccountID
+	}
+	return g.opts.Scope.ZoneID
+}
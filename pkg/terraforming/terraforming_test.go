@@ -0,0 +1,166 @@
+package terraforming
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const testZoneID = "0da42c8d2132a9ddaf714f9e7c920711"
+
+// testSchema is the part of the provider schema the tests generate.
+var testSchema = &tfjson.ProviderSchema{
+	ResourceSchemas: map[string]*tfjson.Schema{
+		"cloudflare_dns_record": {Block: &tfjson.SchemaBlock{
+			Attributes: map[string]*tfjson.SchemaAttribute{
+				"id":      {AttributeType: cty.String, Computed: true},
+				"zone_id": {AttributeType: cty.String, Required: true},
+				"name":    {AttributeType: cty.String, Required: true},
+				"type":    {AttributeType: cty.String, Required: true},
+				"content": {AttributeType: cty.String, Optional: true},
+				"ttl":     {AttributeType: cty.Number, Required: true},
+			},
+		}},
+	},
+}
+
+// testClient returns a client for an API serving two DNS records in the test
+// zone.
+func testClient(t *testing.T) *cloudflare.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/zones/"+testZoneID+"/dns_records" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"success":false,"errors":[{"code":1000,"message":"not found"}],"result":null}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"success":true,"result":[
+			{"id":"372e67954025e0ba6aaa6d586b9e0b59","name":"example.com","type":"A","content":"198.51.100.4","ttl":1},
+			{"id":"372e67954025e0ba6aaa6d586b9e0b60","name":"www.example.com","type":"CNAME","content":"example.com","ttl":3600}
+		],"result_info":{"page":1,"total_pages":1}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"), option.WithMaxRetries(0))
+}
+
+func TestNew(t *testing.T) {
+	valid := Options{
+		Client:        cloudflare.NewClient(option.WithAPIToken("token")),
+		Scope:         Scope{ZoneID: testZoneID},
+		ResourceTypes: []string{"cloudflare_dns_record"},
+		Output:        &bytes.Buffer{},
+	}
+	g, err := New(valid)
+	require.NoError(t, err)
+	assert.Equal(t, valid.Scope, g.opts.Fetch.Scope)
+	assert.Equal(t, valid.Scope, g.opts.HCL.Scope)
+
+	for name, tc := range map[string]struct {
+		change func(*Options)
+		err    string
+	}{
+		"no client":         {func(o *Options) { o.Client = nil }, "an API client must be set"},
+		"no resource types": {func(o *Options) { o.ResourceTypes = nil }, "at least one resource type must be set"},
+		"account and zone":  {func(o *Options) { o.Scope.AccountID = "f037e56e89293a057740de681ac9abbe" }, "exactly one of the account or zone ID must be set"},
+		"no scope":          {func(o *Options) { o.Scope = Scope{} }, "exactly one of the account or zone ID must be set"},
+		"no output":         {func(o *Options) { o.Output = nil }, "an output writer must be set"},
+		"invalid template":  {func(o *Options) { o.Naming.Template = "{{ .name" }, `failed to parse the name template: template: name:1: unclosed action`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := valid
+			tc.change(&opts)
+			_, err := New(opts)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var out bytes.Buffer
+	g, err := New(Options{
+		Client:        testClient(t),
+		Scope:         Scope{ZoneID: testZoneID},
+		ResourceTypes: []string{"cloudflare_dns_record"},
+		Schema:        testSchema,
+		Naming:        Naming{Prefix: "acme_"},
+		HCL:           HCLOptions{ScopeVariables: true},
+		Output:        &out,
+	})
+	require.NoError(t, err)
+	require.NoError(t, g.Generate(context.Background()))
+
+	assert.Equal(t, heredoc.Doc(`
+		resource "cloudflare_dns_record" "acme_example_com_a" {
+		  content = "198.51.100.4"
+		  name    = "example.com"
+		  ttl     = 1
+		  type    = "A"
+		  zone_id = var.zone_id
+		}
+
+		resource "cloudflare_dns_record" "acme_www_example_com_cname" {
+		  content = "example.com"
+		  name    = "www.example.com"
+		  ttl     = 3600
+		  type    = "CNAME"
+		  zone_id = var.zone_id
+		}
+
+	`), out.String())
+}
+
+func TestGenerateReturnsErrors(t *testing.T) {
+	var out bytes.Buffer
+	g, err := New(Options{
+		Client:        testClient(t),
+		Scope:         Scope{ZoneID: testZoneID},
+		ResourceTypes: []string{"cloudflare_not_real", "cloudflare_dns_record"},
+		Schema:        testSchema,
+		Output:        &out,
+	})
+	require.NoError(t, err)
+
+	// The resource types after the failed one are still generated.
+	assert.EqualError(t, g.Generate(context.Background()), "failed to generate cloudflare_not_real: cloudflare_not_real is not in the provider schema")
+	assert.Contains(t, out.String(), `resource "cloudflare_dns_record" "example_com_a"`)
+
+	g.opts.Schema = nil
+	assert.EqualError(t, g.Generate(context.Background()), "a provider schema must be set to generate")
+}
+
+func TestImport(t *testing.T) {
+	var out bytes.Buffer
+	g, err := New(Options{
+		Client:        testClient(t),
+		Scope:         Scope{ZoneID: testZoneID},
+		ResourceTypes: []string{"cloudflare_dns_record"},
+		Output:        &out,
+	})
+	require.NoError(t, err)
+	require.NoError(t, g.Import(context.Background()))
+
+	assert.Equal(t, heredoc.Doc(`
+		import {
+		  to = cloudflare_dns_record.example_com_a
+		  id = "0da42c8d2132a9ddaf714f9e7c920711/372e67954025e0ba6aaa6d586b9e0b59"
+		}
+
+		import {
+		  to = cloudflare_dns_record.www_example_com_cname
+		  id = "0da42c8d2132a9ddaf714f9e7c920711/372e67954025e0ba6aaa6d586b9e0b60"
+		}
+
+	`), out.String())
+}
@@ -0,0 +1,87 @@
+package terraforming
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Variable is a placeholder that can be used in place of a value from the API
+// response to output a `var.<name>` reference instead of a literal.
+type Variable string
+
+func (v Variable) traversal() hcl.Traversal {
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: "var"},
+		hcl.TraverseAttr{Name: string(v)},
+	}
+}
+
+// containsVariableReference reports whether a Variable is present anywhere
+// within the value.
+func containsVariableReference(value interface{}) bool {
+	switch v := value.(type) {
+	case Variable:
+		return true
+	case map[string]interface{}:
+		for _, item := range v {
+			if containsVariableReference(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsVariableReference(item) {
+				return true
+			}
+		}
+	case []map[string]interface{}:
+		for _, item := range v {
+			if containsVariableReference(item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// tokensForValue builds the raw HCL tokens for a value that may contain
+// variable references. cty values cannot represent traversals so these need
+// to be constructed by hand.
+func tokensForValue(value interface{}) hclwrite.Tokens {
+	switch v := value.(type) {
+	case Variable:
+		return hclwrite.TokensForTraversal(v.traversal())
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attrs := make([]hclwrite.ObjectAttrTokens, 0, len(v))
+		for _, k := range keys {
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{
+				Name:  hclwrite.TokensForIdentifier(k),
+				Value: tokensForValue(v[k]),
+			})
+		}
+		return hclwrite.TokensForObject(attrs)
+	case []interface{}:
+		elems := make([]hclwrite.Tokens, 0, len(v))
+		for _, item := range v {
+			elems = append(elems, tokensForValue(item))
+		}
+		return hclwrite.TokensForTuple(elems)
+	case []map[string]interface{}:
+		elems := make([]hclwrite.Tokens, 0, len(v))
+		for _, item := range v {
+			elems = append(elems, tokensForValue(item))
+		}
+		return hclwrite.TokensForTuple(elems)
+	default:
+		return hclwrite.TokensForValue(processExpression(v))
+	}
+}